@@ -13,7 +13,7 @@ DB_PASSWORD=${DB_PASSWORD:-password}
 DB_NAME=${DB_NAME:-mydb}
 DB_URL="postgres://${DB_USER}:${DB_PASSWORD}@${DB_HOST}:${DB_PORT}/${DB_NAME}?sslmode=disable"
 
-MIGRATIONS_PATH="./migrations"
+MIGRATIONS_PATH="./src/internal/migrations/sql"
 MIGRATE_TOOL="migrate"
 
 # Colors for output
@@ -168,7 +168,7 @@ func (suite *E2ETestSuite) TestE2EUserLoginInvalidCredentials() {
 	var response map[string]interface{}
 	err = json.NewDecoder(resp.Body).Decode(&response)
 	assert.NoError(suite.T(), err)
-	assert.Equal(suite.T(), "Invalid credentials", response["error"])
+	assert.Equal(suite.T(), handlers.CodeInvalidCredential, response["code"])
 }
 
 // TestE2ETokenRefresh tests token refresh through real HTTP server
@@ -258,7 +258,7 @@ func (suite *E2ETestSuite) TestE2EInvalidInputValidation() {
 	var response map[string]interface{}
 	err = json.NewDecoder(resp.Body).Decode(&response)
 	assert.NoError(suite.T(), err)
-	assert.Contains(suite.T(), response["error"].(string), "Invalid input")
+	assert.Equal(suite.T(), handlers.CodeInvalidInput, response["code"])
 
 	// Test short password
 	reqBody = handlers.RegisterRequest{
@@ -13,12 +13,21 @@ import (
 	"backend/internal/config"
 	"backend/internal/database"
 	"backend/internal/handlers"
+	"backend/internal/item"
 	"backend/internal/jwt"
+	"backend/internal/logger"
+	"backend/internal/notify"
+	"backend/internal/organization"
+	"backend/internal/reset"
+	"backend/internal/tag"
 	"backend/internal/user"
+	"backend/internal/webhook"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
+	"go.uber.org/fx/fxtest"
+	"golang.org/x/crypto/bcrypt"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
@@ -66,12 +75,19 @@ func (suite *IntegrationTestSuite) SetupSuite() {
 			AccessTokenDuration:  time.Minute * 15,
 			RefreshTokenDuration: time.Hour * 24,
 		},
+		Security: config.SecurityConfig{
+			BcryptCost: bcrypt.MinCost,
+		},
 	}
 
 	// Clear any existing data and drop tables
 	suite.db.Exec("DROP TABLE IF EXISTS item_tags CASCADE")
 	suite.db.Exec("DROP TABLE IF EXISTS organization_users CASCADE")
 	suite.db.Exec("DROP TABLE IF EXISTS reset_tokens CASCADE")
+	suite.db.Exec("DROP TABLE IF EXISTS refresh_tokens CASCADE")
+	suite.db.Exec("DROP TABLE IF EXISTS revoked_tokens CASCADE")
+	suite.db.Exec("DROP TABLE IF EXISTS tag_merge_items CASCADE")
+	suite.db.Exec("DROP TABLE IF EXISTS tag_merges CASCADE")
 	suite.db.Exec("DROP TABLE IF EXISTS items CASCADE")
 	suite.db.Exec("DROP TABLE IF EXISTS tags CASCADE")
 	suite.db.Exec("DROP TABLE IF EXISTS users CASCADE")
@@ -79,16 +95,19 @@ func (suite *IntegrationTestSuite) SetupSuite() {
 	suite.db.Exec("DROP TABLE IF EXISTS back_pack_id_next_numbers CASCADE")
 
 	// Auto migrate all models for integration tests
-	err = suite.db.AutoMigrate(&database.Organization{}, &database.User{}, &database.Item{}, &database.Tag{}, &database.ResetToken{}, &database.BackPackIdNextNumber{})
+	err = database.AutoMigrate(suite.db)
 	if err != nil {
 		suite.T().Fatalf("Failed to auto-migrate test database: %v", err)
 	}
 
 	// Setup services
 
-	suite.userService = user.NewUserService(suite.db)
-	suite.jwtService = jwt.NewJWTService(cfg)
-	suite.handlers = handlers.NewHandlers(suite.userService, suite.jwtService, suite.db)
+	suite.userService = user.NewUserService(suite.db, cfg)
+	suite.jwtService, err = jwt.NewJWTService(cfg, suite.db, logger.Discard())
+	if err != nil {
+		suite.T().Fatalf("Failed to create JWT service: %v", err)
+	}
+	suite.handlers = handlers.NewHandlers(suite.userService, suite.jwtService, item.NewItemService(suite.db, cfg), tag.NewTagService(suite.db), organization.NewOrganizationService(suite.db), reset.NewResetService(suite.db, logger.Discard()), notify.NewLogMailer(logger.Discard()), webhook.NewDispatcher(fxtest.NewLifecycle(suite.T()), cfg, logger.Discard()), suite.db, cfg, logger.Discard())
 
 	// Setup router
 	gin.SetMode(gin.TestMode)
@@ -117,6 +136,8 @@ func (suite *IntegrationTestSuite) SetupTest() {
 	suite.db.Exec("DELETE FROM item_tags")
 	suite.db.Exec("DELETE FROM organization_users")
 	suite.db.Exec("DELETE FROM reset_tokens")
+	suite.db.Exec("DELETE FROM refresh_tokens")
+	suite.db.Exec("DELETE FROM revoked_tokens")
 	suite.db.Exec("DELETE FROM items")
 	suite.db.Exec("DELETE FROM tags")
 	suite.db.Exec("DELETE FROM users")
@@ -159,7 +180,7 @@ func (suite *IntegrationTestSuite) TestUserRegistration() {
 	err = suite.db.Where("email = ?", email).First(&user).Error
 	assert.NoError(suite.T(), err)
 	assert.Equal(suite.T(), email, user.Email)
-	assert.Equal(suite.T(), password, user.Password)
+	assert.NoError(suite.T(), bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)))
 }
 
 // TestUserLogin tests the complete user login flow
@@ -235,7 +256,7 @@ func (suite *IntegrationTestSuite) TestUserLoginInvalidCredentials() {
 	var response map[string]interface{}
 	err := json.Unmarshal(w.Body.Bytes(), &response)
 	assert.NoError(suite.T(), err)
-	assert.Equal(suite.T(), "Invalid credentials", response["error"])
+	assert.Equal(suite.T(), handlers.CodeInvalidCredential, response["code"])
 }
 
 // TestUserRegistrationDuplicate tests duplicate user registration
@@ -261,13 +282,13 @@ func (suite *IntegrationTestSuite) TestUserRegistrationDuplicate() {
 	w = httptest.NewRecorder()
 	suite.router.ServeHTTP(w, req)
 
-	// Should return error
-	assert.Equal(suite.T(), http.StatusInternalServerError, w.Code)
+	// Should return conflict
+	assert.Equal(suite.T(), http.StatusConflict, w.Code)
 
 	var response map[string]interface{}
 	err := json.Unmarshal(w.Body.Bytes(), &response)
 	assert.NoError(suite.T(), err)
-	assert.Equal(suite.T(), "Failed to create user", response["error"])
+	assert.Equal(suite.T(), handlers.CodeUserExists, response["code"])
 }
 
 // TestTokenRefresh tests the token refresh flow
@@ -341,7 +362,7 @@ func (suite *IntegrationTestSuite) TestInvalidInputValidation() {
 	var response map[string]interface{}
 	err := json.Unmarshal(w.Body.Bytes(), &response)
 	assert.NoError(suite.T(), err)
-	assert.Contains(suite.T(), response["error"].(string), "Invalid input")
+	assert.Equal(suite.T(), handlers.CodeInvalidInput, response["code"])
 
 	// Test short password
 	reqBody = handlers.RegisterRequest{
@@ -2,60 +2,464 @@ package config
 
 import (
 	"os"
+	"strconv"
+	"strings"
 	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// DefaultMinPasswordLength is the minimum password length used when none is configured
+const DefaultMinPasswordLength = 8
+
+// Default iss and aud claims of issued JWTs
+const (
+	DefaultJWTIssuer   = "schwiftybox"
+	DefaultJWTAudience = "schwiftybox-api"
 )
 
 // Config holds application configuration
 type Config struct {
-	Database DatabaseConfig
-	JWT      JWTConfig
-	Server   ServerConfig
+	// Env is the deployment environment, e.g. "development" or "production"
+	Env string `yaml:"env"`
+
+	Database DatabaseConfig        `yaml:"db"`
+	JWT      JWTConfig             `yaml:"jwt"`
+	Server   ServerConfig          `yaml:"server"`
+	Upload   UploadConfig          `yaml:"upload"`
+	Security SecurityConfig        `yaml:"security"`
+	Webhook  WebhookConfig         `yaml:"webhook"`
+	SMTP     SMTPConfig            `yaml:"smtp"`
+	Search   SearchConfig          `yaml:"search"`
+	Tag      TagConfig             `yaml:"tag"`
+	Item     ItemConfig            `yaml:"item"`
+	Reset    ResetConfig           `yaml:"reset"`
+	Headers  SecurityHeadersConfig `yaml:"headers"`
+	Log      LogConfig             `yaml:"log"`
+
+	Organization OrganizationConfig `yaml:"organization"`
+
+	// CORS applies to the JSON API, UploadCORS to the upload routes only
+	CORS       CORSConfig `yaml:"cors"`
+	UploadCORS CORSConfig `yaml:"upload_cors"`
+
+	// warnings are problems found while loading, which happens before a logger exists;
+	// Validate logs them
+	warnings []warning
+}
+
+// warning is a structured log message recorded while loading the configuration
+type warning struct {
+	msg  string
+	args []any
+}
+
+// warn records a warning for Validate to log
+func (c *Config) warn(msg string, args ...any) {
+	c.warnings = append(c.warnings, warning{msg: msg, args: args})
 }
 
 // DatabaseConfig holds database configuration
 type DatabaseConfig struct {
-	Host     string
-	User     string
-	Password string
-	DBName   string
-	Port     string
-	SSLMode  string
+	// Driver selects the database, "postgres" or "sqlite"; Path is the SQLite file
+	Driver string `yaml:"driver"`
+	Path   string `yaml:"path"`
+
+	Host     string `yaml:"host"`
+	User     string `yaml:"user"`
+	Password string `yaml:"password"`
+	DBName   string `yaml:"name"`
+	Port     string `yaml:"port"`
+	SSLMode  string `yaml:"sslmode"`
+
+	// Connection pool limits applied to the underlying sql.DB
+	MaxOpenConns    int           `yaml:"max_open_conns"`
+	MaxIdleConns    int           `yaml:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime"`
+
+	// RunMigrations applies pending SQL migrations on startup (Postgres only)
+	RunMigrations bool `yaml:"run_migrations"`
 }
 
 // JWTConfig holds JWT configuration
 type JWTConfig struct {
-	SecretKey            string
-	AccessTokenDuration  time.Duration
-	RefreshTokenDuration time.Duration
+	SecretKey            string        `yaml:"secret"`
+	AccessTokenDuration  time.Duration `yaml:"access_token_duration"`
+	RefreshTokenDuration time.Duration `yaml:"refresh_token_duration"`
+
+	// PrivateKeyPath and PublicKeyPath point to PEM-encoded RSA keys; when a
+	// private key is set tokens are signed with RS256 instead of SecretKey
+	PrivateKeyPath string `yaml:"private_key_path"`
+	PublicKeyPath  string `yaml:"public_key_path"`
+
+	// NotBeforeOffset delays when newly issued tokens become valid (nbf claim)
+	NotBeforeOffset time.Duration `yaml:"not_before_offset"`
+	// Leeway tolerates clock skew when validating exp and nbf
+	Leeway time.Duration `yaml:"leeway"`
+
+	// Issuer and Audience are set as the iss and aud claims of issued tokens and
+	// required when validating; an empty value disables the claim
+	Issuer   string `yaml:"issuer"`
+	Audience string `yaml:"audience"`
+	// AcceptLegacyTokens accepts tokens issued without iss and aud claims; tokens
+	// carrying a mismatched issuer or audience are still rejected
+	AcceptLegacyTokens bool `yaml:"accept_legacy_tokens"`
+
+	// CookieName is the cookie access tokens are read from when a request has no
+	// Authorization header; empty disables cookie authentication
+	CookieName string `yaml:"cookie_name"`
+	// RefreshCookie additionally sets the refresh token as an HttpOnly cookie on login
+	// and refresh, and clears it on logout
+	RefreshCookie bool `yaml:"refresh_cookie"`
 }
 
 // ServerConfig holds server configuration
 type ServerConfig struct {
-	Port string
+	Port            string        `yaml:"port"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+
+	// ReadTimeout, WriteTimeout and IdleTimeout bound how long a connection may take to
+	// send a request, to receive the response and to sit idle between requests, so slow
+	// clients can't hold connections open
+	ReadTimeout  time.Duration `yaml:"read_timeout"`
+	WriteTimeout time.Duration `yaml:"write_timeout"`
+	IdleTimeout  time.Duration `yaml:"idle_timeout"`
+
+	// Compression gzips responses for clients that accept it; bodies shorter than
+	// CompressionMinSize bytes are sent uncompressed
+	Compression        bool `yaml:"compression"`
+	CompressionMinSize int  `yaml:"compression_min_size"`
+
+	// ResponseTimeHeader adds an X-Response-Time header with the handler duration
+	ResponseTimeHeader bool `yaml:"response_time_header"`
+
+	// AccessLogSkipPaths are request paths left out of the access log
+	AccessLogSkipPaths []string `yaml:"access_log_skip_paths"`
+
+	// TLSCertFile and TLSKeyFile are a PEM certificate and key; when both are set the
+	// server serves HTTPS instead of HTTP
+	TLSCertFile string `yaml:"tls_cert_file"`
+	TLSKeyFile  string `yaml:"tls_key_file"`
+
+	// GinMode is the Gin mode, "debug", "release" or "test"; empty derives it from Env
+	GinMode string `yaml:"gin_mode"`
+}
+
+// UploadConfig holds file upload configuration
+type UploadConfig struct {
+	Dir string `yaml:"dir"`
+
+	// MaxSize is the default size limit in bytes (0 means unlimited);
+	// MaxSizes overrides it per content type, e.g. "image/png" or "image/*"
+	MaxSize  int64            `yaml:"max_size"`
+	MaxSizes map[string]int64 `yaml:"max_sizes"`
 }
 
-// NewConfig creates a new config instance with default values
+// MaxSizeFor returns the size limit in bytes for a content type
+func (c *UploadConfig) MaxSizeFor(contentType string) int64 {
+	if size, ok := c.MaxSizes[contentType]; ok {
+		return size
+	}
+	if i := strings.Index(contentType, "/"); i >= 0 {
+		if size, ok := c.MaxSizes[contentType[:i]+"/*"]; ok {
+			return size
+		}
+	}
+	return c.MaxSize
+}
+
+// MaxRequestSize returns the largest size limit of any content type, or 0 when some
+// content type is unlimited
+func (c *UploadConfig) MaxRequestSize() int64 {
+	largest := c.MaxSize
+	if largest <= 0 {
+		return 0
+	}
+	for _, size := range c.MaxSizes {
+		if size <= 0 {
+			return 0
+		}
+		largest = max(largest, size)
+	}
+	return largest
+}
+
+// SecurityConfig holds password hashing configuration
+type SecurityConfig struct {
+	BcryptCost int `yaml:"bcrypt_cost"`
+
+	// MinPasswordLength is the shortest password accepted anywhere a password is set
+	MinPasswordLength int `yaml:"min_password_length"`
+
+	// RequireEmailVerification blocks login until the user has verified their email
+	RequireEmailVerification bool `yaml:"require_email_verification"`
+}
+
+// SMTPConfig holds outgoing email configuration; without a host emails are only logged
+type SMTPConfig struct {
+	Host     string `yaml:"host"`
+	Port     string `yaml:"port"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	From     string `yaml:"from"`
+}
+
+// WebhookConfig holds outbound webhook configuration
+type WebhookConfig struct {
+	ItemURL    string        `yaml:"item_url"`
+	Secret     string        `yaml:"secret"`
+	MaxRetries int           `yaml:"max_retries"`
+	Timeout    time.Duration `yaml:"timeout"`
+}
+
+// SearchConfig holds search configuration
+type SearchConfig struct {
+	// ResultLimit caps the number of results returned per result type
+	ResultLimit int `yaml:"result_limit"`
+}
+
+// ItemConfig holds item configuration
+type ItemConfig struct {
+	// MaxPerUser caps how many items a single user may own (0 means unlimited)
+	MaxPerUser int `yaml:"max_per_user"`
+}
+
+// TagConfig holds tag management configuration
+type TagConfig struct {
+	// MergeRestoreWindow is how long after a merge it can still be undone
+	MergeRestoreWindow time.Duration `yaml:"merge_restore_window"`
+}
+
+// OrganizationConfig holds organization membership configuration
+type OrganizationConfig struct {
+	// InvitationTTL is how long an invitation can be accepted after it is created
+	InvitationTTL time.Duration `yaml:"invitation_ttl"`
+}
+
+// ResetConfig holds password reset configuration
+type ResetConfig struct {
+	// CleanupInterval is how often expired reset tokens are removed; 0 disables the cleanup
+	CleanupInterval time.Duration `yaml:"cleanup_interval"`
+}
+
+// LogConfig holds logging configuration
+type LogConfig struct {
+	// Level is the minimum level logged: debug, info, warn or error
+	Level string `yaml:"level"`
+}
+
+// SecurityHeadersConfig holds the security response header configuration
+type SecurityHeadersConfig struct {
+	Enabled bool `yaml:"enabled"`
+
+	// HSTS sends Strict-Transport-Security on requests served over TLS,
+	// directly or behind a proxy that sets X-Forwarded-Proto
+	HSTS       bool          `yaml:"hsts"`
+	HSTSMaxAge time.Duration `yaml:"hsts_max_age"`
+
+	FrameOptions   string `yaml:"frame_options"`
+	ReferrerPolicy string `yaml:"referrer_policy"`
+}
+
+// CORSConfig holds CORS configuration
+type CORSConfig struct {
+	AllowedOrigins   []string `yaml:"allowed_origins"`
+	AllowedMethods   []string `yaml:"allowed_methods"`
+	AllowedHeaders   []string `yaml:"allowed_headers"`
+	AllowCredentials bool     `yaml:"allow_credentials"`
+}
+
+// NewConfig creates a new config instance from environment variables, using default values for unset ones
 func NewConfig() *Config {
+	cfg := defaultConfig()
+	cfg.applyEnv()
+	return cfg
+}
+
+// LoadConfig creates a new config instance from the defaults, the optional CONFIG_FILE
+// and environment variables, each overriding the previous
+func LoadConfig() (*Config, error) {
+	cfg := defaultConfig()
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		if err := cfg.loadFile(path); err != nil {
+			return nil, err
+		}
+	}
+	cfg.applyEnv()
+	return cfg, nil
+}
+
+// defaultConfig returns the configuration used when nothing is set
+func defaultConfig() *Config {
 	return &Config{
+		Env: "development",
 		Database: DatabaseConfig{
-			Host:     getEnv("DB_HOST", "localhost"),
-			User:     getEnv("DB_USER", "user"),
-			Password: getEnv("DB_PASSWORD", "password"),
-			DBName:   getEnv("DB_NAME", "mydb"),
-			Port:     getEnv("DB_PORT", "5432"),
-			SSLMode:  getEnv("DB_SSLMODE", "disable"),
+			Driver:   "postgres",
+			Path:     "schwiftybox.db",
+			Host:     "localhost",
+			User:     "user",
+			Password: "password",
+			DBName:   "mydb",
+			Port:     "5432",
+			SSLMode:  "disable",
+
+			MaxOpenConns:    25,
+			MaxIdleConns:    5,
+			ConnMaxLifetime: time.Minute * 30,
 		},
 		JWT: JWTConfig{
-			SecretKey:            getEnv("JWT_SECRET", "secret"),
+			SecretKey:            DefaultJWTSecret,
 			AccessTokenDuration:  time.Minute * 15,
 			RefreshTokenDuration: time.Hour * 24,
+			Issuer:               DefaultJWTIssuer,
+			Audience:             DefaultJWTAudience,
 		},
 		Server: ServerConfig{
-			Port: getEnv("SERVER_PORT", ":8080"),
+			Port:            ":8080",
+			ShutdownTimeout: time.Second * 10,
+			ReadTimeout:     time.Second * 30,
+			WriteTimeout:    time.Second * 30,
+			IdleTimeout:     time.Second * 120,
+
+			Compression:        true,
+			CompressionMinSize: 1024,
+
+			AccessLogSkipPaths: []string{"/health"},
+		},
+		Upload: UploadConfig{
+			Dir:      "./uploads",
+			MaxSize:  10 << 20,
+			MaxSizes: make(map[string]int64),
+		},
+		Security: SecurityConfig{
+			BcryptCost:        bcrypt.DefaultCost,
+			MinPasswordLength: DefaultMinPasswordLength,
+		},
+		Search: SearchConfig{
+			ResultLimit: 20,
+		},
+		Tag: TagConfig{
+			MergeRestoreWindow: time.Hour * 24 * 7,
+		},
+		Organization: OrganizationConfig{
+			InvitationTTL: time.Minute * 5,
+		},
+		Reset: ResetConfig{
+			CleanupInterval: time.Minute * 10,
+		},
+		SMTP: SMTPConfig{
+			Port: "587",
+			From: "no-reply@schwiftybox.local",
+		},
+		Webhook: WebhookConfig{
+			MaxRetries: 3,
+			Timeout:    time.Second * 10,
+		},
+		Headers: SecurityHeadersConfig{
+			Enabled:        true,
+			HSTSMaxAge:     365 * 24 * time.Hour,
+			FrameOptions:   "DENY",
+			ReferrerPolicy: "strict-origin-when-cross-origin",
+		},
+		Log: LogConfig{
+			Level: "info",
+		},
+		CORS: CORSConfig{
+			AllowedMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+			AllowedHeaders: []string{"Authorization", "Content-Type"},
+		},
+		UploadCORS: CORSConfig{
+			AllowedMethods: []string{"POST", "OPTIONS"},
+			AllowedHeaders: []string{"Authorization", "Content-Type"},
 		},
 	}
 }
 
+// applyEnv overrides configuration values with the environment variables that are set
+func (c *Config) applyEnv() {
+	c.Env = getEnv("APP_ENV", c.Env)
+
+	c.Database.Driver = getEnv("DB_DRIVER", c.Database.Driver)
+	c.Database.Path = getEnv("DB_PATH", c.Database.Path)
+	c.Database.Host = getEnv("DB_HOST", c.Database.Host)
+	c.Database.User = getEnv("DB_USER", c.Database.User)
+	c.Database.Password = getEnv("DB_PASSWORD", c.Database.Password)
+	c.Database.DBName = getEnv("DB_NAME", c.Database.DBName)
+	c.Database.Port = getEnv("DB_PORT", c.Database.Port)
+	c.Database.SSLMode = getEnv("DB_SSLMODE", c.Database.SSLMode)
+	c.Database.MaxOpenConns = getEnvInt("DB_MAX_OPEN_CONNS", c.Database.MaxOpenConns)
+	c.Database.MaxIdleConns = getEnvInt("DB_MAX_IDLE_CONNS", c.Database.MaxIdleConns)
+	c.Database.ConnMaxLifetime = getEnvDuration("DB_CONN_MAX_LIFETIME", c.Database.ConnMaxLifetime)
+	c.Database.RunMigrations = getEnvBool("RUN_MIGRATIONS", c.Database.RunMigrations)
+
+	c.JWT.SecretKey = getEnv("JWT_SECRET", c.JWT.SecretKey)
+	c.JWT.PrivateKeyPath = getEnv("JWT_PRIVATE_KEY_PATH", c.JWT.PrivateKeyPath)
+	c.JWT.PublicKeyPath = getEnv("JWT_PUBLIC_KEY_PATH", c.JWT.PublicKeyPath)
+	c.JWT.NotBeforeOffset = getEnvDuration("JWT_NOT_BEFORE_OFFSET", c.JWT.NotBeforeOffset)
+	c.JWT.Leeway = getEnvDuration("JWT_LEEWAY", c.JWT.Leeway)
+	c.JWT.Issuer = getEnv("JWT_ISSUER", c.JWT.Issuer)
+	c.JWT.Audience = getEnv("JWT_AUDIENCE", c.JWT.Audience)
+	c.JWT.AcceptLegacyTokens = getEnvBool("JWT_ACCEPT_LEGACY_TOKENS", c.JWT.AcceptLegacyTokens)
+	c.JWT.CookieName = getEnv("AUTH_COOKIE_NAME", c.JWT.CookieName)
+	c.JWT.RefreshCookie = getEnvBool("JWT_REFRESH_COOKIE", c.JWT.RefreshCookie)
+
+	c.Server.Port = getEnv("SERVER_PORT", c.Server.Port)
+	c.Server.ShutdownTimeout = getEnvDuration("SERVER_SHUTDOWN_TIMEOUT", c.Server.ShutdownTimeout)
+	c.Server.ReadTimeout = getEnvDuration("SERVER_READ_TIMEOUT", c.Server.ReadTimeout)
+	c.Server.WriteTimeout = getEnvDuration("SERVER_WRITE_TIMEOUT", c.Server.WriteTimeout)
+	c.Server.IdleTimeout = getEnvDuration("SERVER_IDLE_TIMEOUT", c.Server.IdleTimeout)
+	c.Server.GinMode = getEnv("GIN_MODE", c.Server.GinMode)
+	c.Server.Compression = getEnvBool("SERVER_COMPRESSION", c.Server.Compression)
+	c.Server.CompressionMinSize = getEnvInt("SERVER_COMPRESSION_MIN_SIZE", c.Server.CompressionMinSize)
+	c.Server.ResponseTimeHeader = getEnvBool("SERVER_RESPONSE_TIME_HEADER", c.Server.ResponseTimeHeader)
+	c.Server.TLSCertFile = getEnv("TLS_CERT_FILE", c.Server.TLSCertFile)
+	c.Server.TLSKeyFile = getEnv("TLS_KEY_FILE", c.Server.TLSKeyFile)
+	c.Server.AccessLogSkipPaths = getEnvList("ACCESS_LOG_SKIP_PATHS", c.Server.AccessLogSkipPaths)
+
+	c.Upload.Dir = getEnv("UPLOAD_DIR", c.Upload.Dir)
+	c.Upload.MaxSize = int64(getEnvInt("UPLOAD_MAX_SIZE", int(c.Upload.MaxSize)))
+	c.Upload.MaxSizes = c.getEnvSizeMap("UPLOAD_MAX_SIZES", c.Upload.MaxSizes)
+
+	c.Security.BcryptCost = c.getBcryptCost(c.Security.BcryptCost)
+	c.Security.MinPasswordLength = getEnvInt("MIN_PASSWORD_LENGTH", c.Security.MinPasswordLength)
+	c.Security.RequireEmailVerification = getEnvBool("REQUIRE_EMAIL_VERIFICATION", c.Security.RequireEmailVerification)
+
+	c.Search.ResultLimit = getEnvInt("SEARCH_RESULT_LIMIT", c.Search.ResultLimit)
+	c.Tag.MergeRestoreWindow = getEnvDuration("TAG_MERGE_RESTORE_WINDOW", c.Tag.MergeRestoreWindow)
+	c.Item.MaxPerUser = getEnvInt("MAX_ITEMS_PER_USER", c.Item.MaxPerUser)
+	c.Organization.InvitationTTL = getEnvDuration("ORG_INVITATION_TTL", c.Organization.InvitationTTL)
+	c.Reset.CleanupInterval = getEnvDuration("RESET_CLEANUP_INTERVAL", c.Reset.CleanupInterval)
+
+	c.SMTP.Host = getEnv("SMTP_HOST", c.SMTP.Host)
+	c.SMTP.Port = getEnv("SMTP_PORT", c.SMTP.Port)
+	c.SMTP.Username = getEnv("SMTP_USERNAME", c.SMTP.Username)
+	c.SMTP.Password = getEnv("SMTP_PASSWORD", c.SMTP.Password)
+	c.SMTP.From = getEnv("SMTP_FROM", c.SMTP.From)
+
+	c.Webhook.ItemURL = getEnv("ITEM_WEBHOOK_URL", c.Webhook.ItemURL)
+	c.Webhook.Secret = getEnv("ITEM_WEBHOOK_SECRET", c.Webhook.Secret)
+	c.Webhook.MaxRetries = getEnvInt("ITEM_WEBHOOK_MAX_RETRIES", c.Webhook.MaxRetries)
+
+	c.Headers.Enabled = getEnvBool("SECURITY_HEADERS_ENABLED", c.Headers.Enabled)
+	c.Headers.HSTS = getEnvBool("SECURITY_HSTS_ENABLED", c.Headers.HSTS)
+	c.Headers.HSTSMaxAge = getEnvDuration("SECURITY_HSTS_MAX_AGE", c.Headers.HSTSMaxAge)
+	c.Headers.FrameOptions = getEnv("SECURITY_FRAME_OPTIONS", c.Headers.FrameOptions)
+	c.Headers.ReferrerPolicy = getEnv("SECURITY_REFERRER_POLICY", c.Headers.ReferrerPolicy)
+
+	c.Log.Level = getEnv("LOG_LEVEL", c.Log.Level)
+
+	c.CORS.applyEnv("CORS_")
+	c.UploadCORS.applyEnv("UPLOAD_CORS_")
+}
+
+// applyEnv overrides CORS values with the environment variables starting with prefix
+func (c *CORSConfig) applyEnv(prefix string) {
+	c.AllowedOrigins = getEnvList(prefix+"ALLOWED_ORIGINS", c.AllowedOrigins)
+	c.AllowedMethods = getEnvList(prefix+"ALLOWED_METHODS", c.AllowedMethods)
+	c.AllowedHeaders = getEnvList(prefix+"ALLOWED_HEADERS", c.AllowedHeaders)
+	c.AllowCredentials = getEnvBool(prefix+"ALLOW_CREDENTIALS", c.AllowCredentials)
+}
+
 // getEnv gets environment variable with fallback
 func getEnv(key, fallback string) string {
 	if value := os.Getenv(key); value != "" {
@@ -64,6 +468,84 @@ func getEnv(key, fallback string) string {
 	return fallback
 }
 
+// getEnvList gets a comma-separated environment variable as a list with fallback
+func getEnvList(key string, fallback []string) []string {
+	env := os.Getenv(key)
+	if env == "" {
+		return fallback
+	}
+
+	var list []string
+	for _, value := range strings.Split(env, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			list = append(list, value)
+		}
+	}
+	return list
+}
+
+// getEnvInt gets an integer environment variable with fallback
+func getEnvInt(key string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+// getEnvSizeMap gets a comma-separated list of type=bytes pairs with fallback, skipping malformed entries
+func (c *Config) getEnvSizeMap(key string, fallback map[string]int64) map[string]int64 {
+	if os.Getenv(key) == "" {
+		return fallback
+	}
+
+	sizes := make(map[string]int64)
+	for _, entry := range getEnvList(key, nil) {
+		name, value, ok := strings.Cut(entry, "=")
+		size, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if !ok || err != nil || size < 0 {
+			c.warn("ignoring invalid configuration entry", "key", key, "entry", entry)
+			continue
+		}
+		sizes[strings.ToLower(strings.TrimSpace(name))] = size
+	}
+	return sizes
+}
+
+// getEnvDuration gets a duration environment variable (e.g. "15s") with fallback
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+// getEnvBool gets a boolean environment variable with fallback
+func getEnvBool(key string, fallback bool) bool {
+	value, err := strconv.ParseBool(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+// getBcryptCost gets the bcrypt cost from BCRYPT_COST, falling back when unset or out of range
+func (c *Config) getBcryptCost(fallback int) int {
+	value := os.Getenv("BCRYPT_COST")
+	if value == "" {
+		return fallback
+	}
+
+	cost, err := strconv.Atoi(value)
+	if err != nil || cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+		c.warn("invalid BCRYPT_COST, using the default",
+			"value", value, "min", bcrypt.MinCost, "max", bcrypt.MaxCost, "default", fallback)
+		return fallback
+	}
+	return cost
+}
+
 // ConnectionString returns the database connection string
 func (c *DatabaseConfig) ConnectionString() string {
 	return "host=" + c.Host +
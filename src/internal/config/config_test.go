@@ -1,9 +1,14 @@
 package config
 
 import (
+	"bytes"
+	"log/slog"
 	"os"
+	"strings"
 	"testing"
 	"time"
+
+	"golang.org/x/crypto/bcrypt"
 )
 
 func TestNewConfig(t *testing.T) {
@@ -99,6 +104,31 @@ func TestNewConfigWithEnvironmentVariables(t *testing.T) {
 	}
 }
 
+func TestNewConfig_JWTIssuerAndAudience(t *testing.T) {
+	cfg := NewConfig()
+	if cfg.JWT.Issuer != DefaultJWTIssuer || cfg.JWT.Audience != DefaultJWTAudience {
+		t.Errorf("Expected default issuer and audience, got %q and %q", cfg.JWT.Issuer, cfg.JWT.Audience)
+	}
+	if cfg.JWT.AcceptLegacyTokens {
+		t.Error("Legacy tokens should not be accepted by default")
+	}
+
+	t.Setenv("JWT_ISSUER", "issuer.example.com")
+	t.Setenv("JWT_AUDIENCE", "api.example.com")
+	t.Setenv("JWT_ACCEPT_LEGACY_TOKENS", "true")
+
+	cfg = NewConfig()
+	if cfg.JWT.Issuer != "issuer.example.com" {
+		t.Errorf("Expected issuer 'issuer.example.com', got '%s'", cfg.JWT.Issuer)
+	}
+	if cfg.JWT.Audience != "api.example.com" {
+		t.Errorf("Expected audience 'api.example.com', got '%s'", cfg.JWT.Audience)
+	}
+	if !cfg.JWT.AcceptLegacyTokens {
+		t.Error("Expected legacy tokens to be accepted")
+	}
+}
+
 func TestGetEnv(t *testing.T) {
 	// Test with existing environment variable
 	os.Setenv("TEST_KEY", "test_value")
@@ -169,3 +199,119 @@ func TestDatabaseConfig_ConnectionStringWithSpecialCharacters(t *testing.T) {
 		}
 	}
 }
+
+func TestGetBcryptCost(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected int
+	}{
+		{"unset", "", bcrypt.DefaultCost},
+		{"custom", "12", 12},
+		{"minimum", "4", bcrypt.MinCost},
+		{"too low", "3", bcrypt.DefaultCost},
+		{"too high", "32", bcrypt.DefaultCost},
+		{"not a number", "abc", bcrypt.DefaultCost},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("BCRYPT_COST", tt.value)
+
+			if cost := NewConfig().Security.BcryptCost; cost != tt.expected {
+				t.Errorf("Expected bcrypt cost %d, got %d", tt.expected, cost)
+			}
+		})
+	}
+}
+
+func TestUploadConfig_MaxSizeFor(t *testing.T) {
+	t.Setenv("UPLOAD_MAX_SIZE", "100")
+	t.Setenv("UPLOAD_MAX_SIZES", "image/*=50, application/pdf=200, bogus")
+
+	upload := NewConfig().Upload
+
+	tests := map[string]int64{
+		"image/png":       50,
+		"application/pdf": 200,
+		"text/plain":      100,
+	}
+	for contentType, expected := range tests {
+		if size := upload.MaxSizeFor(contentType); size != expected {
+			t.Errorf("Expected limit %d for %s, got %d", expected, contentType, size)
+		}
+	}
+}
+
+func TestUploadConfig_MaxRequestSize(t *testing.T) {
+	tests := []struct {
+		upload   UploadConfig
+		expected int64
+	}{
+		{UploadConfig{MaxSize: 100}, 100},
+		{UploadConfig{MaxSize: 100, MaxSizes: map[string]int64{"image/*": 50, "application/pdf": 200}}, 200},
+		{UploadConfig{MaxSize: 0, MaxSizes: map[string]int64{"image/*": 50}}, 0},
+		{UploadConfig{MaxSize: 100, MaxSizes: map[string]int64{"application/pdf": 0}}, 0},
+	}
+	for _, tt := range tests {
+		if size := tt.upload.MaxRequestSize(); size != tt.expected {
+			t.Errorf("Expected request limit %d for %+v, got %d", tt.expected, tt.upload, size)
+		}
+	}
+}
+
+func TestLogEffective_RedactsSecrets(t *testing.T) {
+	t.Setenv("DB_HOST", "db.internal")
+	t.Setenv("DB_PASSWORD", "db-password-value")
+	t.Setenv("JWT_SECRET", "jwt-secret-value")
+	t.Setenv("ITEM_WEBHOOK_SECRET", "webhook-secret-value")
+	t.Setenv("SERVER_PORT", ":9090")
+
+	var buf bytes.Buffer
+	NewConfig().LogEffective(slog.New(slog.NewTextHandler(&buf, nil)))
+	output := buf.String()
+
+	for _, secret := range []string{"db-password-value", "jwt-secret-value", "webhook-secret-value"} {
+		if strings.Contains(output, secret) {
+			t.Errorf("Expected secret %q to be redacted, got: %s", secret, output)
+		}
+	}
+	for _, expected := range []string{`db.password=***`, `jwt.secret=***`, `webhook.secret=***`, `db.host=db.internal`, `server.port=:9090`} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected log to contain %s, got: %s", expected, output)
+		}
+	}
+}
+
+func TestNewConfig_ServerTimeouts(t *testing.T) {
+	cfg := NewConfig()
+	if cfg.Server.ReadTimeout != 30*time.Second || cfg.Server.WriteTimeout != 30*time.Second || cfg.Server.IdleTimeout != 120*time.Second {
+		t.Errorf("Unexpected default timeouts: read %v, write %v, idle %v", cfg.Server.ReadTimeout, cfg.Server.WriteTimeout, cfg.Server.IdleTimeout)
+	}
+
+	t.Setenv("SERVER_READ_TIMEOUT", "5s")
+	t.Setenv("SERVER_WRITE_TIMEOUT", "10s")
+	t.Setenv("SERVER_IDLE_TIMEOUT", "1m")
+
+	cfg = NewConfig()
+	if cfg.Server.ReadTimeout != 5*time.Second {
+		t.Errorf("Expected read timeout 5s, got %v", cfg.Server.ReadTimeout)
+	}
+	if cfg.Server.WriteTimeout != 10*time.Second {
+		t.Errorf("Expected write timeout 10s, got %v", cfg.Server.WriteTimeout)
+	}
+	if cfg.Server.IdleTimeout != time.Minute {
+		t.Errorf("Expected idle timeout 1m, got %v", cfg.Server.IdleTimeout)
+	}
+}
+
+func TestNewConfig_RunMigrations(t *testing.T) {
+	if NewConfig().Database.RunMigrations {
+		t.Error("Expected migrations to be off by default")
+	}
+
+	t.Setenv("RUN_MIGRATIONS", "true")
+	if !NewConfig().Database.RunMigrations {
+		t.Error("Expected RUN_MIGRATIONS to enable migrations")
+	}
+}
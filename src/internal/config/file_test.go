@@ -0,0 +1,147 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeConfigFile writes content to a config file in a temporary directory and points CONFIG_FILE at it
+func writeConfigFile(t *testing.T, name, content string) {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	t.Setenv("CONFIG_FILE", path)
+}
+
+func TestLoadConfig_YAMLFile(t *testing.T) {
+	writeConfigFile(t, "config.yaml", `
+db:
+  host: db.internal
+  name: schwiftybox
+smtp:
+  host: smtp.example.com
+  port: "2525"
+cors:
+  allowed_origins:
+    - https://app.example.com
+    - https://admin.example.com
+reset:
+  cleanup_interval: 1h
+`)
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if cfg.Database.Host != "db.internal" || cfg.Database.DBName != "schwiftybox" {
+		t.Errorf("Expected database settings from the file, got %+v", cfg.Database)
+	}
+	if cfg.SMTP.Host != "smtp.example.com" || cfg.SMTP.Port != "2525" {
+		t.Errorf("Expected SMTP settings from the file, got %+v", cfg.SMTP)
+	}
+	expectedOrigins := []string{"https://app.example.com", "https://admin.example.com"}
+	if !reflect.DeepEqual(cfg.CORS.AllowedOrigins, expectedOrigins) {
+		t.Errorf("Expected CORS origins %v, got %v", expectedOrigins, cfg.CORS.AllowedOrigins)
+	}
+	if cfg.Reset.CleanupInterval != time.Hour {
+		t.Errorf("Expected cleanup interval 1h, got %v", cfg.Reset.CleanupInterval)
+	}
+
+	// Keys the file leaves out keep their defaults
+	if cfg.Database.Port != "5432" {
+		t.Errorf("Expected default port '5432', got '%s'", cfg.Database.Port)
+	}
+	if cfg.SMTP.From != "no-reply@schwiftybox.local" {
+		t.Errorf("Expected default SMTP sender, got '%s'", cfg.SMTP.From)
+	}
+}
+
+func TestLoadConfig_JSONFile(t *testing.T) {
+	writeConfigFile(t, "config.json", `{"server": {"port": ":9090"}, "item": {"max_per_user": 50}}`)
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.Server.Port != ":9090" {
+		t.Errorf("Expected server port ':9090', got '%s'", cfg.Server.Port)
+	}
+	if cfg.Item.MaxPerUser != 50 {
+		t.Errorf("Expected max items per user 50, got %d", cfg.Item.MaxPerUser)
+	}
+}
+
+func TestLoadConfig_EnvOverridesFile(t *testing.T) {
+	writeConfigFile(t, "config.yaml", `
+db:
+  host: db.internal
+  user: file-user
+cors:
+  allowed_origins: [https://file.example.com]
+`)
+	t.Setenv("DB_HOST", "env-host")
+	t.Setenv("CORS_ALLOWED_ORIGINS", "https://env.example.com")
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.Database.Host != "env-host" {
+		t.Errorf("Expected DB_HOST to override the file, got '%s'", cfg.Database.Host)
+	}
+	if cfg.Database.User != "file-user" {
+		t.Errorf("Expected the file user without DB_USER set, got '%s'", cfg.Database.User)
+	}
+	if !reflect.DeepEqual(cfg.CORS.AllowedOrigins, []string{"https://env.example.com"}) {
+		t.Errorf("Expected CORS_ALLOWED_ORIGINS to override the file, got %v", cfg.CORS.AllowedOrigins)
+	}
+}
+
+func TestLoadConfig_WithoutFile(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("DB_HOST", "env-host")
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if !reflect.DeepEqual(cfg, NewConfig()) {
+		t.Errorf("Expected the env-only configuration without CONFIG_FILE")
+	}
+}
+
+func TestLoadConfig_InvalidFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"malformed", "db: [unclosed"},
+		{"unknown key", "db:\n  hostname: db.internal\n"},
+		{"wrong type", "search:\n  result_limit: lots\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writeConfigFile(t, "config.yaml", tt.content)
+
+			_, err := LoadConfig()
+			if err == nil || !strings.Contains(err.Error(), "invalid config file") {
+				t.Errorf("Expected an invalid config file error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestLoadConfig_MissingFile(t *testing.T) {
+	t.Setenv("CONFIG_FILE", filepath.Join(t.TempDir(), "missing.yaml"))
+
+	if _, err := LoadConfig(); err == nil {
+		t.Error("Expected an error for a missing config file")
+	}
+}
@@ -0,0 +1,119 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// redactedValue replaces secrets when the configuration is logged
+const redactedValue = "***"
+
+// Field is a single configuration key/value pair
+type Field struct {
+	Key   string
+	Value string
+}
+
+// Fields returns the effective configuration as key/value pairs with secrets redacted
+func (c *Config) Fields() []Field {
+	return []Field{
+		{"app.env", c.Env},
+		{"db.driver", c.Database.Driver},
+		{"db.path", c.Database.Path},
+		{"db.host", c.Database.Host},
+		{"db.port", c.Database.Port},
+		{"db.user", c.Database.User},
+		{"db.password", redact(c.Database.Password)},
+		{"db.name", c.Database.DBName},
+		{"db.sslmode", c.Database.SSLMode},
+		{"db.max_open_conns", strconv.Itoa(c.Database.MaxOpenConns)},
+		{"db.max_idle_conns", strconv.Itoa(c.Database.MaxIdleConns)},
+		{"db.conn_max_lifetime", c.Database.ConnMaxLifetime.String()},
+		{"db.run_migrations", strconv.FormatBool(c.Database.RunMigrations)},
+		{"jwt.secret", redact(c.JWT.SecretKey)},
+		{"jwt.access_token_duration", c.JWT.AccessTokenDuration.String()},
+		{"jwt.refresh_token_duration", c.JWT.RefreshTokenDuration.String()},
+		{"jwt.private_key_path", c.JWT.PrivateKeyPath},
+		{"jwt.public_key_path", c.JWT.PublicKeyPath},
+		{"jwt.not_before_offset", c.JWT.NotBeforeOffset.String()},
+		{"jwt.leeway", c.JWT.Leeway.String()},
+		{"jwt.issuer", c.JWT.Issuer},
+		{"jwt.audience", c.JWT.Audience},
+		{"jwt.accept_legacy_tokens", strconv.FormatBool(c.JWT.AcceptLegacyTokens)},
+		{"jwt.cookie_name", c.JWT.CookieName},
+		{"jwt.refresh_cookie", strconv.FormatBool(c.JWT.RefreshCookie)},
+		{"server.port", c.Server.Port},
+		{"server.shutdown_timeout", c.Server.ShutdownTimeout.String()},
+		{"server.read_timeout", c.Server.ReadTimeout.String()},
+		{"server.write_timeout", c.Server.WriteTimeout.String()},
+		{"server.idle_timeout", c.Server.IdleTimeout.String()},
+		{"server.gin_mode", c.GinMode()},
+		{"server.compression", strconv.FormatBool(c.Server.Compression)},
+		{"server.compression_min_size", strconv.Itoa(c.Server.CompressionMinSize)},
+		{"server.response_time_header", strconv.FormatBool(c.Server.ResponseTimeHeader)},
+		{"server.access_log_skip_paths", strings.Join(c.Server.AccessLogSkipPaths, ",")},
+		{"server.tls_cert_file", c.Server.TLSCertFile},
+		{"server.tls_key_file", c.Server.TLSKeyFile},
+		{"upload.dir", c.Upload.Dir},
+		{"upload.max_size", strconv.FormatInt(c.Upload.MaxSize, 10)},
+		{"upload.max_sizes", formatSizeMap(c.Upload.MaxSizes)},
+		{"security.bcrypt_cost", strconv.Itoa(c.Security.BcryptCost)},
+		{"security.min_password_length", strconv.Itoa(c.Security.MinPasswordLength)},
+		{"security.require_email_verification", strconv.FormatBool(c.Security.RequireEmailVerification)},
+		{"smtp.host", c.SMTP.Host},
+		{"smtp.port", c.SMTP.Port},
+		{"smtp.username", c.SMTP.Username},
+		{"smtp.password", redact(c.SMTP.Password)},
+		{"smtp.from", c.SMTP.From},
+		{"webhook.item_url", c.Webhook.ItemURL},
+		{"webhook.secret", redact(c.Webhook.Secret)},
+		{"webhook.max_retries", strconv.Itoa(c.Webhook.MaxRetries)},
+		{"webhook.timeout", c.Webhook.Timeout.String()},
+		{"search.result_limit", strconv.Itoa(c.Search.ResultLimit)},
+		{"tag.merge_restore_window", c.Tag.MergeRestoreWindow.String()},
+		{"item.max_per_user", strconv.Itoa(c.Item.MaxPerUser)},
+		{"organization.invitation_ttl", c.Organization.InvitationTTL.String()},
+		{"reset.cleanup_interval", c.Reset.CleanupInterval.String()},
+		{"headers.enabled", strconv.FormatBool(c.Headers.Enabled)},
+		{"headers.hsts", strconv.FormatBool(c.Headers.HSTS)},
+		{"headers.hsts_max_age", c.Headers.HSTSMaxAge.String()},
+		{"headers.frame_options", c.Headers.FrameOptions},
+		{"headers.referrer_policy", c.Headers.ReferrerPolicy},
+		{"log.level", c.Log.Level},
+		{"cors.allowed_origins", strings.Join(c.CORS.AllowedOrigins, ",")},
+		{"cors.allow_credentials", strconv.FormatBool(c.CORS.AllowCredentials)},
+		{"upload_cors.allowed_origins", strings.Join(c.UploadCORS.AllowedOrigins, ",")},
+		{"upload_cors.allow_credentials", strconv.FormatBool(c.UploadCORS.AllowCredentials)},
+	}
+}
+
+// LogEffective logs the effective configuration, one attribute per field, with secrets redacted
+func (c *Config) LogEffective(logger *slog.Logger) {
+	fields := c.Fields()
+	args := make([]any, 0, 2*len(fields))
+	for _, field := range fields {
+		args = append(args, field.Key, field.Value)
+	}
+	logger.Info("effective configuration", args...)
+}
+
+// redact hides a secret, keeping empty values visible so unset secrets can be spotted
+func redact(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return redactedValue
+}
+
+// formatSizeMap formats per-type size limits as sorted type=bytes pairs
+func formatSizeMap(sizes map[string]int64) string {
+	entries := make([]string, 0, len(sizes))
+	for name, size := range sizes {
+		entries = append(entries, fmt.Sprintf("%s=%d", name, size))
+	}
+	sort.Strings(entries)
+	return strings.Join(entries, ",")
+}
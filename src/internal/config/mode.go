@@ -0,0 +1,29 @@
+package config
+
+// EnvTest is the APP_ENV value used by test suites
+const EnvTest = "test"
+
+// Gin modes, matching gin.DebugMode, gin.ReleaseMode and gin.TestMode
+const (
+	GinModeDebug   = "debug"
+	GinModeRelease = "release"
+	GinModeTest    = "test"
+)
+
+// GinMode returns the Gin mode to run in. A valid GIN_MODE is used as is; otherwise
+// production runs in release mode, tests in test mode and everything else in debug mode.
+func (c *Config) GinMode() string {
+	switch c.Server.GinMode {
+	case GinModeDebug, GinModeRelease, GinModeTest:
+		return c.Server.GinMode
+	}
+
+	switch c.Env {
+	case EnvProduction:
+		return GinModeRelease
+	case EnvTest:
+		return GinModeTest
+	default:
+		return GinModeDebug
+	}
+}
@@ -0,0 +1,30 @@
+package config
+
+import "testing"
+
+func TestGinMode(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     string
+		ginMode string
+		want    string
+	}{
+		{"development", "development", "", GinModeDebug},
+		{"unset", "", "", GinModeDebug},
+		{"production", EnvProduction, "", GinModeRelease},
+		{"test", EnvTest, "", GinModeTest},
+		{"explicit mode wins", EnvProduction, GinModeDebug, GinModeDebug},
+		{"invalid mode ignored", EnvProduction, "verbose", GinModeRelease},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("APP_ENV", tt.env)
+			t.Setenv("GIN_MODE", tt.ginMode)
+
+			if got := NewConfig().GinMode(); got != tt.want {
+				t.Errorf("Expected gin mode %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+// DefaultJWTSecret is the JWT secret used when JWT_SECRET is not set; it must not be used in production
+const DefaultJWTSecret = "secret"
+
+// MinJWTSecretLength is the shortest JWT secret accepted in production
+const MinJWTSecretLength = 32
+
+// EnvProduction is the APP_ENV value for production deployments
+const EnvProduction = "production"
+
+var (
+	// ErrInsecureJWTSecret is returned when the JWT secret is the default or too short
+	ErrInsecureJWTSecret = errors.New("insecure JWT secret")
+	// ErrMissingSMTPHost is returned in production when no SMTP host is set, since emails
+	// carrying tokens would otherwise only be logged
+	ErrMissingSMTPHost = errors.New("SMTP_HOST must be set in production")
+)
+
+// Validate checks the configuration for insecure settings. In production these are
+// returned as an error so startup fails; otherwise a warning is logged. Production
+// also requires an SMTP host. Warnings recorded while loading are logged first.
+func (c *Config) Validate(logger *slog.Logger) error {
+	for _, w := range c.warnings {
+		logger.Warn(w.msg, w.args...)
+	}
+
+	err := c.checkJWTSecret()
+	if c.Env == EnvProduction {
+		if c.SMTP.Host == "" {
+			err = errors.Join(err, ErrMissingSMTPHost)
+		}
+		return err
+	}
+	if err == nil {
+		return nil
+	}
+
+	logger.Warn("insecure configuration, only allowed outside production", "error", err)
+	return nil
+}
+
+// checkJWTSecret reports a JWT secret that is the default or shorter than MinJWTSecretLength
+func (c *Config) checkJWTSecret() error {
+	// RS256 keys replace the shared secret entirely
+	if c.JWT.PrivateKeyPath != "" {
+		return nil
+	}
+	if c.JWT.SecretKey == DefaultJWTSecret {
+		return fmt.Errorf("%w: JWT_SECRET is the default value", ErrInsecureJWTSecret)
+	}
+	if len(c.JWT.SecretKey) < MinJWTSecretLength {
+		return fmt.Errorf("%w: JWT_SECRET must be at least %d characters", ErrInsecureJWTSecret, MinJWTSecretLength)
+	}
+	return nil
+}
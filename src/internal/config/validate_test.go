@@ -0,0 +1,99 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+// discardLogger returns a logger that drops everything
+func discardLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func TestValidate_Production(t *testing.T) {
+	tests := []struct {
+		name    string
+		secret  string
+		wantErr bool
+	}{
+		{"default secret", DefaultJWTSecret, true},
+		{"short secret", "short-but-not-default", true},
+		{"strong secret", strings.Repeat("s", MinJWTSecretLength), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Env: EnvProduction, JWT: JWTConfig{SecretKey: tt.secret}, SMTP: SMTPConfig{Host: "smtp.example.com"}}
+			err := cfg.Validate(discardLogger())
+			if tt.wantErr && !errors.Is(err, ErrInsecureJWTSecret) {
+				t.Errorf("Expected ErrInsecureJWTSecret, got %v", err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestValidate_ProductionWithRSAKeys(t *testing.T) {
+	cfg := &Config{Env: EnvProduction, JWT: JWTConfig{SecretKey: DefaultJWTSecret, PrivateKeyPath: "/keys/private.pem"}, SMTP: SMTPConfig{Host: "smtp.example.com"}}
+	if err := cfg.Validate(discardLogger()); err != nil {
+		t.Errorf("Expected the JWT secret to be ignored with RS256 keys, got %v", err)
+	}
+}
+
+func TestValidate_ProductionRequiresSMTPHost(t *testing.T) {
+	cfg := &Config{Env: EnvProduction, JWT: JWTConfig{SecretKey: strings.Repeat("s", MinJWTSecretLength)}}
+	if err := cfg.Validate(discardLogger()); !errors.Is(err, ErrMissingSMTPHost) {
+		t.Errorf("Expected ErrMissingSMTPHost, got %v", err)
+	}
+
+	// Outside production emails may be logged instead
+	cfg.Env = "development"
+	if err := cfg.Validate(discardLogger()); err != nil {
+		t.Errorf("Expected no error outside production, got %v", err)
+	}
+}
+
+func TestValidate_DevelopmentWarns(t *testing.T) {
+	var buf bytes.Buffer
+	cfg := &Config{Env: "development", JWT: JWTConfig{SecretKey: DefaultJWTSecret}}
+	if err := cfg.Validate(slog.New(slog.NewTextHandler(&buf, nil))); err != nil {
+		t.Errorf("Expected no error outside production, got %v", err)
+	}
+	if !strings.Contains(buf.String(), "level=WARN") || !strings.Contains(buf.String(), "insecure JWT secret") {
+		t.Errorf("Expected a warning to be logged, got: %s", buf.String())
+	}
+}
+
+func TestValidate_LogsLoadWarnings(t *testing.T) {
+	t.Setenv("JWT_SECRET", strings.Repeat("s", MinJWTSecretLength))
+	t.Setenv("BCRYPT_COST", "99")
+	t.Setenv("UPLOAD_MAX_SIZES", "image/*=50, bogus")
+
+	var buf bytes.Buffer
+	if err := NewConfig().Validate(slog.New(slog.NewTextHandler(&buf, nil))); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	for _, expected := range []string{"invalid BCRYPT_COST", "value=99", "key=UPLOAD_MAX_SIZES entry=bogus"} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("Expected log to contain %s, got: %s", expected, buf.String())
+		}
+	}
+}
+
+func TestNewConfig_AppEnv(t *testing.T) {
+	if cfg := NewConfig(); cfg.Env != "development" {
+		t.Errorf("Expected default env 'development', got '%s'", cfg.Env)
+	}
+
+	t.Setenv("APP_ENV", "production")
+	t.Setenv("JWT_SECRET", "secret")
+	if err := NewConfig().Validate(discardLogger()); !errors.Is(err, ErrInsecureJWTSecret) {
+		t.Errorf("Expected ErrInsecureJWTSecret with APP_ENV=production, got %v", err)
+	}
+}
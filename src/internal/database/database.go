@@ -2,16 +2,27 @@ package database
 
 import (
 	"context"
-	"log"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
 	"time"
 
 	"backend/internal/config"
 
 	"go.uber.org/fx"
 	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
 
+const (
+	// DriverPostgres connects to PostgreSQL, schema changes come from the SQL migrations
+	DriverPostgres = "postgres"
+	// DriverSQLite opens a local SQLite file, creating the schema with AutoMigrate
+	DriverSQLite = "sqlite"
+)
+
 // Module provides database dependency injection
 var Module = fx.Module("database",
 	fx.Provide(NewDatabase),
@@ -19,16 +30,23 @@ var Module = fx.Module("database",
 
 // User represents a user in the database
 type User struct {
-	Email     string    `json:"email" gorm:"primaryKey"`
-	Password  string    `json:"password"`
-	Prefix    string    `json:"prefix" gorm:"size:10"`
-	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
-	
+	Email             string         `json:"email" gorm:"primaryKey"`
+	Password          string         `json:"-"`
+	Prefix            string         `json:"prefix" gorm:"size:10"`
+	Role              string         `json:"role" gorm:"size:20;default:user"`
+	DisplayName       string         `json:"display_name" gorm:"size:100"`
+	AvatarURL         string         `json:"avatar_url" gorm:"size:500"`
+	EmailVerified     bool           `json:"email_verified" gorm:"default:false"`
+	Active            bool           `json:"active" gorm:"not null;default:true"`
+	VerificationToken string         `json:"-" gorm:"size:64;index"`
+	CreatedAt         time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt         time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt         gorm.DeletedAt `json:"-" gorm:"index"`
+
 	// Relationships
-	ActiveOrganizationID uint `json:"active_organization_id"`
+	ActiveOrganizationID uint         `json:"active_organization_id"`
 	ActiveOrganization   Organization `json:"active_organization" gorm:"foreignKey:ActiveOrganizationID"`
-	Items                []Item `json:"items" gorm:"foreignKey:UserEmail"`
+	Items                []Item       `json:"items" gorm:"foreignKey:UserEmail"`
 }
 
 // Organization represents an organization in the database
@@ -37,90 +55,236 @@ type Organization struct {
 	Name      string    `json:"name"`
 	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
-	
+
 	// Relationships
 	Users []User `json:"users" gorm:"many2many:organization_users;"`
 	Tags  []Tag  `json:"tags" gorm:"foreignKey:OrganizationID"`
 }
 
+// OrganizationUser is a membership of a user in an organization
+type OrganizationUser struct {
+	OrganizationID uint   `json:"organization_id" gorm:"primaryKey"`
+	UserEmail      string `json:"user_email" gorm:"primaryKey"`
+	Role           string `json:"role" gorm:"size:20;not null;default:member"`
+}
+
+// OrgInvitation invites an email address to join an organization
+type OrgInvitation struct {
+	ID             uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	Token          string     `json:"-" gorm:"size:64;uniqueIndex"`
+	OrganizationID uint       `json:"organization_id" gorm:"index"`
+	Email          string     `json:"email" gorm:"size:255"`
+	Status         string     `json:"status" gorm:"size:20;not null;default:pending"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+	AcceptedAt     *time.Time `json:"accepted_at"`
+	CreatedAt      time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
+
 // Item represents an item in the database
 type Item struct {
-	ID          uint      `json:"id" gorm:"primaryKey;autoIncrement"`
-	Name        string    `json:"name" gorm:"size:200"`
-	BackpackID  string    `json:"backpack_id" gorm:"size:20"`
-	Description string    `json:"description" gorm:"size:1000"`
-	AddedAt     time.Time `json:"added_at"`
-	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt   time.Time `json:"updated_at" gorm:"autoUpdateTime"`
-	
+	ID          uint           `json:"id" gorm:"primaryKey;autoIncrement"`
+	Name        string         `json:"name" gorm:"size:200"`
+	BackpackID  string         `json:"backpack_id" gorm:"size:20"`
+	Description string         `json:"description" gorm:"size:1000"`
+	Quantity    int            `json:"quantity" gorm:"not null;default:1"`
+	Unit        string         `json:"unit" gorm:"size:20"`
+	Version     int            `json:"version" gorm:"not null;default:1"`
+	AddedAt     time.Time      `json:"added_at" gorm:"autoCreateTime"`
+	CreatedAt   time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
+
 	// Relationships
 	UserEmail string `json:"user_email"`
 	User      User   `json:"user" gorm:"foreignKey:UserEmail"`
-	
-	ParentID *uint `json:"parent_id"`
-	Parent   *Item `json:"parent" gorm:"foreignKey:ParentID"`
+
+	OrganizationID *uint `json:"organization_id"`
+
+	ParentID *uint  `json:"parent_id"`
+	Parent   *Item  `json:"parent" gorm:"foreignKey:ParentID"`
 	Children []Item `json:"children" gorm:"foreignKey:ParentID"`
-	
+
 	Tags []Tag `json:"tags" gorm:"many2many:item_tags;"`
+
+	Attachments []ItemAttachment `json:"attachments" gorm:"foreignKey:ItemID"`
+}
+
+// ItemAttachment is a file, such as a photo, attached to an item by URL
+type ItemAttachment struct {
+	ID          uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	ItemID      uint      `json:"item_id" gorm:"index"`
+	URL         string    `json:"url" gorm:"size:500"`
+	ContentType string    `json:"content_type" gorm:"size:100"`
+	UploadedAt  time.Time `json:"uploaded_at" gorm:"autoCreateTime"`
 }
 
 // Tag represents a tag in the database
 type Tag struct {
-	ID        uint      `json:"id" gorm:"primaryKey;autoIncrement"`
-	Name      string    `json:"name" gorm:"size:20"`
-	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
-	
+	ID        uint           `json:"id" gorm:"primaryKey;autoIncrement"`
+	Name      string         `json:"name" gorm:"size:20;uniqueIndex:idx_tags_organization_name,priority:2,where:deleted_at IS NULL"`
+	Color     string         `json:"color" gorm:"size:7"`
+	Icon      string         `json:"icon" gorm:"size:50"`
+	CreatedAt time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+
 	// Relationships
-	OrganizationID uint `json:"organization_id"`
+	OrganizationID uint         `json:"organization_id" gorm:"uniqueIndex:idx_tags_organization_name,priority:1,where:deleted_at IS NULL"`
 	Organization   Organization `json:"organization" gorm:"foreignKey:OrganizationID"`
-	
+
 	Items []Item `json:"items" gorm:"many2many:item_tags;"`
 }
 
+// TagMerge records a tag merged into another so the merge can be undone
+type TagMerge struct {
+	ID             uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	OrganizationID uint       `json:"organization_id" gorm:"index"`
+	SourceTagID    uint       `json:"source_tag_id"`
+	SourceName     string     `json:"source_name" gorm:"size:20"`
+	SourceColor    string     `json:"source_color" gorm:"size:7"`
+	SourceIcon     string     `json:"source_icon" gorm:"size:50"`
+	TargetTagID    uint       `json:"target_tag_id"`
+	RestoredAt     *time.Time `json:"restored_at"`
+	CreatedAt      time.Time  `json:"created_at" gorm:"autoCreateTime"`
+
+	// Relationships
+	Items []TagMergeItem `json:"items" gorm:"foreignKey:TagMergeID"`
+}
+
+// TagMergeItem records an item that carried the source tag of a merge
+type TagMergeItem struct {
+	TagMergeID uint `json:"-" gorm:"primaryKey"`
+	ItemID     uint `json:"item_id" gorm:"primaryKey;autoIncrement:false"`
+	// HadTarget reports whether the item already carried the target tag before the merge
+	HadTarget bool `json:"had_target"`
+}
+
+// AuditLog records a change made to an entity and who made it
+type AuditLog struct {
+	ID         uint   `json:"id" gorm:"primaryKey;autoIncrement"`
+	ActorEmail string `json:"actor_email" gorm:"size:255"`
+	EntityType string `json:"entity_type" gorm:"size:50;index:idx_audit_logs_entity"`
+	EntityID   uint   `json:"entity_id" gorm:"index:idx_audit_logs_entity"`
+	Action     string `json:"action" gorm:"size:20"`
+	// Diff holds action-specific details as JSON, such as the changed fields of an update
+	Diff      json.RawMessage `json:"diff,omitempty" gorm:"type:text"`
+	CreatedAt time.Time       `json:"created_at" gorm:"autoCreateTime"`
+}
+
 // BackPackIdNextNumber represents the next number for backpack ID generation
 type BackPackIdNextNumber struct {
 	ID         uint   `json:"id" gorm:"primaryKey;autoIncrement"`
-	BackpackID string `json:"backpack_id" gorm:"size:20"`
+	BackpackID string `json:"backpack_id" gorm:"size:20;uniqueIndex"`
 	Number     int    `json:"number"`
 }
 
 // ResetToken represents a password reset token
 type ResetToken struct {
 	ID        uint      `json:"id" gorm:"primaryKey;autoIncrement"`
-	Token     string    `json:"token" gorm:"size:30;uniqueIndex"`
+	Token     string    `json:"token" gorm:"size:64;uniqueIndex"`
 	ExpiredAt time.Time `json:"expired_at"`
 	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
-	
+
 	// Relationships
 	UserEmail string `json:"user_email"`
 	User      User   `json:"user" gorm:"foreignKey:UserEmail"`
 }
 
-// NewDatabase creates a new database connection
-func NewDatabase(lc fx.Lifecycle, cfg *config.Config) (*gorm.DB, error) {
-	db, err := gorm.Open(postgres.Open(cfg.Database.ConnectionString()), &gorm.Config{})
+// RefreshToken represents an issued refresh token, identified by its jti claim
+type RefreshToken struct {
+	ID        uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	JTI       string     `json:"jti" gorm:"column:jti;size:64;uniqueIndex"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	RevokedAt *time.Time `json:"revoked_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at" gorm:"autoCreateTime"`
+
+	// Relationships
+	UserEmail string `json:"user_email" gorm:"index"`
+	User      User   `json:"user" gorm:"foreignKey:UserEmail"`
+}
+
+// RevokedToken blacklists an issued token by its jti claim until the token expires
+type RevokedToken struct {
+	ID        uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	JTI       string    `json:"jti" gorm:"column:jti;size:64;uniqueIndex"`
+	ExpiresAt time.Time `json:"expires_at" gorm:"index"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// AllModels returns every model in dependency order; the item_tags and
+// organization_users join tables are created along with them
+func AllModels() []interface{} {
+	return []interface{}{
+		&Organization{}, &User{}, &OrganizationUser{}, &OrgInvitation{}, &Item{}, &ItemAttachment{}, &Tag{},
+		&TagMerge{}, &TagMergeItem{}, &BackPackIdNextNumber{}, &ResetToken{}, &RefreshToken{}, &RevokedToken{},
+		&AuditLog{},
+	}
+}
+
+// AutoMigrate creates or updates the tables for all models
+func AutoMigrate(db *gorm.DB) error {
+	return db.AutoMigrate(AllModels()...)
+}
+
+// NewDatabase creates a new database connection using the configured driver
+func NewDatabase(lc fx.Lifecycle, cfg *config.Config, logger *slog.Logger) (*gorm.DB, error) {
+	dialector, err := openDialector(cfg.Database)
 	if err != nil {
 		return nil, err
 	}
 
-	// Note: Migrations are now handled by the migrations package
-	// Auto-migration is disabled for production use
+	db, err := gorm.Open(dialector, &gorm.Config{})
+	if err != nil {
+		return nil, err
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	configurePool(sqlDB, cfg.Database)
+
+	// Postgres migrations are handled by the migrations package; they are
+	// Postgres-specific, so SQLite databases are created from the models
+	if cfg.Database.Driver == DriverSQLite {
+		// SQLite allows a single writer, and every :memory: connection is a separate database
+		sqlDB.SetMaxOpenConns(1)
+
+		if err := AutoMigrate(db); err != nil {
+			return nil, err
+		}
+	}
 
 	lc.Append(fx.Hook{
 		OnStart: func(context.Context) error {
-			log.Println("Database connection established")
+			logger.Info("database connection established", "driver", cfg.Database.Driver)
 			return nil
 		},
 		OnStop: func(ctx context.Context) error {
-			sqlDB, err := db.DB()
-			if err != nil {
-				return err
-			}
-			log.Println("Closing database connection")
+			logger.Info("closing database connection")
 			return sqlDB.Close()
 		},
 	})
 
 	return db, nil
 }
+
+// openDialector returns the GORM dialector for the configured driver
+func openDialector(cfg config.DatabaseConfig) (gorm.Dialector, error) {
+	switch cfg.Driver {
+	case DriverPostgres:
+		return postgres.Open(cfg.ConnectionString()), nil
+	case DriverSQLite:
+		return sqlite.Open(cfg.Path), nil
+	default:
+		return nil, fmt.Errorf("unsupported database driver %q", cfg.Driver)
+	}
+}
+
+// configurePool applies the configured connection pool limits
+func configurePool(sqlDB *sql.DB, cfg config.DatabaseConfig) {
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+}
@@ -0,0 +1,162 @@
+package database
+
+import (
+	"database/sql"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/logger"
+
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestModule_SQLite(t *testing.T) {
+	t.Setenv("DB_DRIVER", "sqlite")
+	t.Setenv("DB_PATH", filepath.Join(t.TempDir(), "test.db"))
+
+	var db *gorm.DB
+	app := fxtest.New(t,
+		fx.Provide(config.NewConfig),
+		fx.Supply(logger.Discard()),
+		Module,
+		fx.Populate(&db),
+	)
+	app.RequireStart()
+	defer app.RequireStop()
+
+	if name := db.Dialector.Name(); name != DriverSQLite {
+		t.Errorf("Expected the sqlite dialector, got %s", name)
+	}
+
+	// The schema is created on startup, so the database is usable right away
+	if err := db.Create(&Organization{Name: "Test"}).Error; err != nil {
+		t.Fatalf("Failed to create organization: %v", err)
+	}
+	if err := db.Create(&User{Email: "test@example.com", ActiveOrganizationID: 1}).Error; err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+	var count int64
+	db.Model(&User{}).Count(&count)
+	if count != 1 {
+		t.Errorf("Expected 1 user, got %d", count)
+	}
+}
+
+func TestNewDatabase_UnsupportedDriver(t *testing.T) {
+	cfg := &config.Config{Database: config.DatabaseConfig{Driver: "mysql"}}
+
+	if _, err := NewDatabase(fxtest.NewLifecycle(t), cfg, logger.Discard()); err == nil {
+		t.Error("Expected an error for an unsupported driver")
+	}
+}
+
+func TestConfigurePool(t *testing.T) {
+	sqlDB, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer sqlDB.Close()
+
+	configurePool(sqlDB, config.DatabaseConfig{MaxOpenConns: 25, MaxIdleConns: 5, ConnMaxLifetime: 30 * time.Minute})
+
+	if max := sqlDB.Stats().MaxOpenConnections; max != 25 {
+		t.Errorf("Expected 25 max open connections, got %d", max)
+	}
+	// sql.DB doesn't expose the idle and lifetime limits, so read them directly
+	pool := reflect.ValueOf(sqlDB).Elem()
+	if idle := pool.FieldByName("maxIdleCount").Int(); idle != 5 {
+		t.Errorf("Expected 5 max idle connections, got %d", idle)
+	}
+	if lifetime := time.Duration(pool.FieldByName("maxLifetime").Int()); lifetime != 30*time.Minute {
+		t.Errorf("Expected 30m connection lifetime, got %v", lifetime)
+	}
+}
+
+func TestAutoMigrate_CreatesAllTables(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+
+	if err := AutoMigrate(db); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+
+	tables := []string{
+		"organizations", "users", "organization_users", "org_invitations", "items", "item_tags", "tags",
+		"tag_merges", "tag_merge_items", "back_pack_id_next_numbers", "reset_tokens", "refresh_tokens", "revoked_tokens",
+	}
+	for _, table := range tables {
+		if !db.Migrator().HasTable(table) {
+			t.Errorf("Expected table %s to exist", table)
+		}
+	}
+}
+
+func TestRelations_Preload(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+	if err := AutoMigrate(db); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+
+	org := Organization{Name: "Camping Club"}
+	db.Create(&org)
+	db.Create(&User{Email: "test@example.com", ActiveOrganizationID: org.ID})
+	db.Create(&OrganizationUser{OrganizationID: org.ID, UserEmail: "test@example.com", Role: "owner"})
+	tag := Tag{Name: "outdoor", OrganizationID: org.ID}
+	db.Create(&tag)
+	parent := Item{Name: "Backpack", UserEmail: "test@example.com", OrganizationID: &org.ID}
+	db.Create(&parent)
+	child := Item{Name: "Tent", UserEmail: "test@example.com", OrganizationID: &org.ID, ParentID: &parent.ID, Tags: []Tag{tag}}
+	db.Create(&child)
+
+	var u User
+	if err := db.Preload("ActiveOrganization").Preload("Items").First(&u, "email = ?", "test@example.com").Error; err != nil {
+		t.Fatalf("Failed to load user: %v", err)
+	}
+	if u.ActiveOrganization.Name != "Camping Club" {
+		t.Errorf("Expected active organization 'Camping Club', got '%s'", u.ActiveOrganization.Name)
+	}
+	if len(u.Items) != 2 {
+		t.Errorf("Expected 2 items, got %d", len(u.Items))
+	}
+
+	var loadedParent Item
+	if err := db.Preload("Children.Tags").First(&loadedParent, parent.ID).Error; err != nil {
+		t.Fatalf("Failed to load item: %v", err)
+	}
+	if len(loadedParent.Children) != 1 || loadedParent.Children[0].Name != "Tent" {
+		t.Fatalf("Expected child 'Tent', got %+v", loadedParent.Children)
+	}
+	if tags := loadedParent.Children[0].Tags; len(tags) != 1 || tags[0].Name != "outdoor" {
+		t.Errorf("Expected the child to carry tag 'outdoor', got %+v", tags)
+	}
+
+	var loadedChild Item
+	if err := db.Preload("Parent").First(&loadedChild, child.ID).Error; err != nil {
+		t.Fatalf("Failed to load item: %v", err)
+	}
+	if loadedChild.Parent == nil || loadedChild.Parent.ID != parent.ID {
+		t.Errorf("Expected parent %d, got %+v", parent.ID, loadedChild.Parent)
+	}
+
+	var loadedOrg Organization
+	if err := db.Preload("Users").Preload("Tags.Items").First(&loadedOrg, org.ID).Error; err != nil {
+		t.Fatalf("Failed to load organization: %v", err)
+	}
+	if len(loadedOrg.Users) != 1 || loadedOrg.Users[0].Email != "test@example.com" {
+		t.Errorf("Expected member test@example.com, got %+v", loadedOrg.Users)
+	}
+	if len(loadedOrg.Tags) != 1 || len(loadedOrg.Tags[0].Items) != 1 {
+		t.Errorf("Expected one tag carried by one item, got %+v", loadedOrg.Tags)
+	}
+}
@@ -0,0 +1,12 @@
+package database
+
+import "strings"
+
+// likeEscaper escapes the LIKE wildcards and the escape character itself
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// ContainsPattern returns a LIKE pattern matching values that contain s literally.
+// Use it with an ESCAPE '\' clause so wildcards in user input match only themselves.
+func ContainsPattern(s string) string {
+	return "%" + likeEscaper.Replace(s) + "%"
+}
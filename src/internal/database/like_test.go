@@ -0,0 +1,21 @@
+package database
+
+import "testing"
+
+func TestContainsPattern(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"tent", "%tent%"},
+		{"100%", `%100\%%`},
+		{"snake_case", `%snake\_case%`},
+		{`C:\gear`, `%C:\\gear%`},
+	}
+
+	for _, tt := range tests {
+		if got := ContainsPattern(tt.input); got != tt.want {
+			t.Errorf("ContainsPattern(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,10 @@
+package database
+
+import "gorm.io/gorm"
+
+// WithTx runs fn in a transaction on db. The transaction is committed when fn returns nil
+// and rolled back when fn returns an error or panics, so multi-step writes are all or
+// nothing. Inside an existing transaction fn runs in a savepoint.
+func WithTx(db *gorm.DB, fn func(tx *gorm.DB) error) error {
+	return db.Transaction(fn)
+}
@@ -0,0 +1,83 @@
+package database
+
+import (
+	"errors"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupTxTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+	if err := db.AutoMigrate(&Organization{}); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+	return db
+}
+
+func countOrganizations(t *testing.T, db *gorm.DB) int64 {
+	var count int64
+	if err := db.Model(&Organization{}).Count(&count).Error; err != nil {
+		t.Fatalf("Failed to count organizations: %v", err)
+	}
+	return count
+}
+
+func TestWithTx_Commits(t *testing.T) {
+	db := setupTxTestDB(t)
+
+	err := WithTx(db, func(tx *gorm.DB) error {
+		if err := tx.Create(&Organization{Name: "First"}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&Organization{Name: "Second"}).Error
+	})
+	if err != nil {
+		t.Fatalf("Expected transaction to commit, got %v", err)
+	}
+	if count := countOrganizations(t, db); count != 2 {
+		t.Errorf("Expected 2 organizations, got %d", count)
+	}
+}
+
+func TestWithTx_RollsBackOnError(t *testing.T) {
+	db := setupTxTestDB(t)
+	injected := errors.New("injected failure")
+
+	err := WithTx(db, func(tx *gorm.DB) error {
+		if err := tx.Create(&Organization{Name: "First"}).Error; err != nil {
+			return err
+		}
+		return injected
+	})
+	if !errors.Is(err, injected) {
+		t.Errorf("Expected the injected error, got %v", err)
+	}
+	if count := countOrganizations(t, db); count != 0 {
+		t.Errorf("Expected nothing to be committed, got %d organizations", count)
+	}
+}
+
+func TestWithTx_RollsBackOnPanic(t *testing.T) {
+	db := setupTxTestDB(t)
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("Expected the panic to be re-raised")
+			}
+		}()
+		WithTx(db, func(tx *gorm.DB) error {
+			tx.Create(&Organization{Name: "First"})
+			panic("injected panic")
+		})
+	}()
+
+	if count := countOrganizations(t, db); count != 0 {
+		t.Errorf("Expected nothing to be committed, got %d organizations", count)
+	}
+}
@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"backend/internal/notify"
+	"backend/internal/user"
+
+	"github.com/gin-gonic/gin"
+)
+
+// setPasswordTokenTTL is how long imported users have to set their password
+const setPasswordTokenTTL = 24 * time.Hour
+
+// Default and largest page size of the user listing
+const (
+	defaultUserPageSize = 20
+	maxUserPageSize     = 100
+)
+
+// BatchUserEntry represents a single user in the batch import request body
+type BatchUserEntry struct {
+	Email string `json:"email" binding:"required,email"`
+	Role  string `json:"role" binding:"omitempty,oneof=user admin"`
+	// Password is optional; users without one are emailed a set-password token
+	Password string `json:"password"`
+}
+
+// BatchUsersRequest represents the batch user import request body
+type BatchUsersRequest struct {
+	Users []BatchUserEntry `json:"users" binding:"required,min=1,max=500,dive"`
+}
+
+// BatchUserResult represents the outcome of importing a single user
+type BatchUserResult struct {
+	Email   string `json:"email"`
+	Created bool   `json:"created"`
+	Error   string `json:"error,omitempty"`
+}
+
+// BatchCreateUsers handles importing several users at once; users without a password
+// get a random one and an email asking them to set their own
+func (h *Handlers) BatchCreateUsers(c *gin.Context) {
+	var req BatchUsersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondInvalidInput(c, err)
+		return
+	}
+
+	newUsers := make([]user.NewUser, len(req.Users))
+	for i, entry := range req.Users {
+		newUsers[i] = user.NewUser{Email: entry.Email, Role: entry.Role, Password: entry.Password}
+	}
+
+	results, err := h.userService.CreateUsers(newUsers)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to create users")
+		return
+	}
+
+	response := make([]BatchUserResult, len(results))
+	created := 0
+	for i, result := range results {
+		response[i] = BatchUserResult{Email: result.Email}
+		if result.Err != nil {
+			switch {
+			case errors.Is(result.Err, user.ErrUserAlreadyExists):
+				response[i].Error = "User already exists"
+			case errors.Is(result.Err, user.ErrPasswordTooShort):
+				response[i].Error = passwordTooShortMessage(h.userService.MinPasswordLength())
+			default:
+				response[i].Error = "Failed to create user"
+			}
+			continue
+		}
+
+		response[i].Created = true
+		created++
+		if req.Users[i].Password == "" {
+			h.sendSetPasswordEmail(result.Email)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"created": created,
+		"failed":  len(results) - created,
+		"results": response,
+	})
+}
+
+// ListUsers handles listing users a page at a time, optionally filtered by email
+func (h *Handlers) ListUsers(c *gin.Context) {
+	page := 1
+	if raw := c.Query("page"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 {
+			respondError(c, http.StatusBadRequest, CodeInvalidInput, "Invalid page: must be at least 1")
+			return
+		}
+		page = parsed
+	}
+
+	pageSize := defaultUserPageSize
+	if raw := c.Query("page_size"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > maxUserPageSize {
+			respondError(c, http.StatusBadRequest, CodeInvalidInput, "Invalid page_size: must be between 1 and "+strconv.Itoa(maxUserPageSize))
+			return
+		}
+		pageSize = parsed
+	}
+
+	users, total, err := h.userService.ListUsers(c.Query("email"), (page-1)*pageSize, pageSize)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to list users")
+		return
+	}
+
+	response := make([]UserResponse, len(users))
+	for i := range users {
+		response[i] = newUserResponse(&users[i])
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"users":     response,
+		"page":      page,
+		"page_size": pageSize,
+		"total":     total,
+	})
+}
+
+// ReactivateUser handles reactivating any deactivated user
+func (h *Handlers) ReactivateUser(c *gin.Context) {
+	h.reactivateUser(c, c.Param("email"))
+}
+
+// reactivateUser lets a deactivated user log in again and responds with the user
+func (h *Handlers) reactivateUser(c *gin.Context, email string) {
+	if err := h.userService.SetActive(email, true); err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			respondError(c, http.StatusNotFound, CodeNotFound, "User not found")
+			return
+		}
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to reactivate user")
+		return
+	}
+
+	reactivated, err := h.userService.GetUser(email)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to get user")
+		return
+	}
+
+	c.JSON(http.StatusOK, newUserResponse(reactivated))
+}
+
+// sendSetPasswordEmail asks an imported user to choose their own password
+func (h *Handlers) sendSetPasswordEmail(email string) {
+	token, err := h.resetService.CreateResetTokenWithExpiry(email, setPasswordTokenTTL)
+	if err != nil {
+		h.logger.Error("failed to create set-password token", "email", email, "error", err)
+		return
+	}
+
+	h.sendEmailAsync(notify.Message{
+		To:      email,
+		Subject: "Set your SchwiftyBox password",
+		Body:    "An account has been created for you. Set your password using this token: " + token,
+	})
+}
@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"backend/internal/database"
+	"backend/internal/user"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBatchCreateUsers_PartialSuccess(t *testing.T) {
+	handlers := setupTestHandlers(t)
+
+	// auth@example.com is registered by the authenticated request helper
+	body := []byte(`{"users":[
+		{"email":"alice@example.com","role":"admin"},
+		{"email":"auth@example.com"},
+		{"email":"bob@example.com","role":"user"}
+	]}`)
+	c, w := createAuthenticatedRequest(handlers, "POST", "/admin/users/batch", body)
+	// Ignore the verification email sent when the authenticated user registered
+	mailer := handlers.mailer.(*fakeMailer)
+	assert.NoError(t, handlers.WaitForEmails(context.Background()))
+	mailer.sent = nil
+	handlers.BatchCreateUsers(c)
+	assert.NoError(t, handlers.WaitForEmails(context.Background()))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Created int               `json:"created"`
+		Failed  int               `json:"failed"`
+		Results []BatchUserResult `json:"results"`
+	}
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, response.Created)
+	assert.Equal(t, 1, response.Failed)
+	assert.Equal(t, []BatchUserResult{
+		{Email: "alice@example.com", Created: true},
+		{Email: "auth@example.com", Error: "User already exists"},
+		{Email: "bob@example.com", Created: true},
+	}, response.Results)
+
+	// Created users are persisted with their roles
+	var alice database.User
+	assert.NoError(t, handlers.db.Where("email = ?", "alice@example.com").First(&alice).Error)
+	assert.Equal(t, "admin", alice.Role)
+	assert.NotEmpty(t, alice.Password)
+
+	// Each created user is asked to set a password; the emails go out in the background
+	var recipients []string
+	for _, msg := range mailer.sent {
+		recipients = append(recipients, msg.To)
+	}
+	assert.ElementsMatch(t, []string{"alice@example.com", "bob@example.com"}, recipients)
+}
+
+func TestBatchCreateUsers_ShortPasswordFailsItsRow(t *testing.T) {
+	handlers := setupTestHandlers(t)
+
+	body := []byte(`{"users":[
+		{"email":"alice@example.com","password":"short"},
+		{"email":"bob@example.com","password":"password123"}
+	]}`)
+	c, w := createAuthenticatedRequest(handlers, "POST", "/admin/users/batch", body)
+	handlers.BatchCreateUsers(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Created int               `json:"created"`
+		Results []BatchUserResult `json:"results"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 1, response.Created)
+	assert.Equal(t, []BatchUserResult{
+		{Email: "alice@example.com", Error: passwordTooShortMessage(handlers.userService.MinPasswordLength())},
+		{Email: "bob@example.com", Created: true},
+	}, response.Results)
+
+	_, err := handlers.userService.GetUser("alice@example.com")
+	assert.ErrorIs(t, err, user.ErrUserNotFound)
+}
+
+func TestBatchCreateUsers_DoesNotWaitForEmails(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	c, w := createAuthenticatedRequest(handlers, "POST", "/admin/users/batch", []byte(`{"users":[{"email":"alice@example.com"}]}`))
+
+	// The response is written while the mail server is still stuck
+	assert.NoError(t, handlers.WaitForEmails(context.Background()))
+	mailer := &blockingMailer{release: make(chan struct{})}
+	handlers.mailer = mailer
+	handlers.BatchCreateUsers(c)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	close(mailer.release)
+	assert.NoError(t, handlers.WaitForEmails(context.Background()))
+}
+
+func TestBatchCreateUsers_InvalidInput(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	c, w := createAuthenticatedRequest(handlers, "POST", "/admin/users/batch", []byte(`{"users":[{"email":"not-an-email"}]}`))
+
+	handlers.BatchCreateUsers(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestReactivateUser(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	assert.NoError(t, handlers.userService.CreateUser("rick@example.com", "password123"))
+	assert.NoError(t, handlers.userService.SetActive("rick@example.com", false))
+
+	c, w := createAuthenticatedRequest(handlers, "POST", "/admin/users/rick@example.com/reactivate", nil)
+	c.Params = gin.Params{{Key: "email", Value: "rick@example.com"}}
+	handlers.ReactivateUser(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response UserResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.True(t, response.Active)
+
+	assert.Equal(t, http.StatusOK, loginRequest(handlers, "rick@example.com", "password123").Code)
+
+	c, w = createAuthenticatedRequest(handlers, "POST", "/admin/users/nobody@example.com/reactivate", nil)
+	c.Params = gin.Params{{Key: "email", Value: "nobody@example.com"}}
+	handlers.ReactivateUser(c)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+// listUsersResponse is the decoded user listing
+type listUsersResponse struct {
+	Users    []UserResponse `json:"users"`
+	Page     int            `json:"page"`
+	PageSize int            `json:"page_size"`
+	Total    int64          `json:"total"`
+}
+
+func TestListUsers(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	for _, email := range []string{"alice@example.com", "bob@example.com", "carol@example.org"} {
+		assert.NoError(t, handlers.userService.CreateUser(email, "password123"))
+	}
+
+	// auth@example.com is registered by the authenticated request helper, so there are four users
+	c, w := createAuthenticatedRequest(handlers, "GET", "/admin/users?page=2&page_size=3", nil)
+	handlers.ListUsers(c)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "password")
+
+	var response listUsersResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, int64(4), response.Total)
+	assert.Equal(t, 2, response.Page)
+	assert.Equal(t, 3, response.PageSize)
+	if assert.Len(t, response.Users, 1) {
+		assert.Equal(t, "carol@example.org", response.Users[0].Email)
+		assert.True(t, response.Users[0].Active)
+		assert.False(t, response.Users[0].CreatedAt.IsZero())
+	}
+
+	// Pages past the end are empty
+	c, w = createAuthenticatedRequest(handlers, "GET", "/admin/users?page=5&page_size=3", nil)
+	handlers.ListUsers(c)
+	assert.Equal(t, http.StatusOK, w.Code)
+	response = listUsersResponse{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Empty(t, response.Users)
+	assert.Equal(t, int64(4), response.Total)
+
+	// The email filter ignores case
+	c, w = createAuthenticatedRequest(handlers, "GET", "/admin/users?email=EXAMPLE.COM", nil)
+	handlers.ListUsers(c)
+	assert.Equal(t, http.StatusOK, w.Code)
+	response = listUsersResponse{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, int64(3), response.Total)
+	assert.Equal(t, defaultUserPageSize, response.PageSize)
+}
+
+func TestListUsers_PaginationBounds(t *testing.T) {
+	handlers := setupTestHandlers(t)
+
+	for _, query := range []string{"page=0", "page=-1", "page=abc", "page_size=0", "page_size=101", "page_size=abc"} {
+		c, w := createAuthenticatedRequest(handlers, "GET", "/admin/users?"+query, nil)
+		handlers.ListUsers(c)
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+
+	c, w := createAuthenticatedRequest(handlers, "GET", "/admin/users?page_size=100", nil)
+	handlers.ListUsers(c)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"backend/internal/item"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ItemAttachmentRequest represents the item attachment request body; URL is either a
+// path returned by the upload endpoint or an absolute http(s) URL
+type ItemAttachmentRequest struct {
+	URL         string `json:"url" binding:"required,max=500"`
+	ContentType string `json:"content_type" binding:"max=100"`
+}
+
+// AddItemAttachment handles attaching a file URL to an item
+func (h *Handlers) AddItemAttachment(c *gin.Context) {
+	userEmail, exists := c.Get("user_email")
+	if !exists {
+		respondError(c, http.StatusUnauthorized, CodeUnauthenticated, "User not authenticated")
+		return
+	}
+
+	itemID, err := strconv.ParseUint(c.Param("item_id"), 10, 32)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, CodeInvalidInput, "Invalid item ID")
+		return
+	}
+
+	var req ItemAttachmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondInvalidInput(c, err)
+		return
+	}
+
+	attachment, err := h.itemService.AddAttachment(uint(itemID), userEmail.(string), req.URL, req.ContentType)
+	if err != nil {
+		if errors.Is(err, item.ErrInvalidAttachmentURL) {
+			respondError(c, http.StatusBadRequest, CodeInvalidInput, "URL must be an uploaded file path or an http(s) URL")
+			return
+		}
+		if errors.Is(err, item.ErrItemNotFound) {
+			respondError(c, http.StatusNotFound, CodeNotFound, "Item not found")
+			return
+		}
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to add attachment")
+		return
+	}
+
+	c.JSON(http.StatusCreated, attachment)
+}
+
+// DeleteItemAttachment handles removing an attachment from an item
+func (h *Handlers) DeleteItemAttachment(c *gin.Context) {
+	userEmail, exists := c.Get("user_email")
+	if !exists {
+		respondError(c, http.StatusUnauthorized, CodeUnauthenticated, "User not authenticated")
+		return
+	}
+
+	itemID, err := strconv.ParseUint(c.Param("item_id"), 10, 32)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, CodeInvalidInput, "Invalid item ID")
+		return
+	}
+	attachmentID, err := strconv.ParseUint(c.Param("attachment_id"), 10, 32)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, CodeInvalidInput, "Invalid attachment ID")
+		return
+	}
+
+	if err := h.itemService.DeleteAttachment(uint(itemID), uint(attachmentID), userEmail.(string)); err != nil {
+		if errors.Is(err, item.ErrItemNotFound) {
+			respondError(c, http.StatusNotFound, CodeNotFound, "Item not found")
+			return
+		}
+		if errors.Is(err, item.ErrAttachmentNotFound) {
+			respondError(c, http.StatusNotFound, CodeNotFound, "Attachment not found")
+			return
+		}
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to delete attachment")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
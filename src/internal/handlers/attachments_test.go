@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"backend/internal/database"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestItemAttachments_AddListDelete(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	created := createTestItem(t, handlers, "Tent")
+	itemID := fmt.Sprintf("%d", created.ID)
+
+	// Add
+	c, w := createAuthenticatedRequest(handlers, "POST", "/items/"+itemID+"/attachments", []byte(`{"url":"/uploads/abc123.png"}`))
+	c.Params = gin.Params{{Key: "item_id", Value: itemID}}
+	handlers.AddItemAttachment(c)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var attachment database.ItemAttachment
+	json.Unmarshal(w.Body.Bytes(), &attachment)
+	assert.Equal(t, created.ID, attachment.ItemID)
+	assert.Equal(t, "image/png", attachment.ContentType)
+
+	// List via GetItem
+	c, w = createAuthenticatedRequest(handlers, "GET", "/items/"+itemID, nil)
+	c.Params = gin.Params{{Key: "item_id", Value: itemID}}
+	handlers.GetItem(c)
+
+	var fetched database.Item
+	json.Unmarshal(w.Body.Bytes(), &fetched)
+	if assert.Len(t, fetched.Attachments, 1) {
+		assert.Equal(t, "/uploads/abc123.png", fetched.Attachments[0].URL)
+	}
+
+	// Delete
+	attachmentID := fmt.Sprintf("%d", attachment.ID)
+	c, w = createAuthenticatedRequest(handlers, "DELETE", "/items/"+itemID+"/attachments/"+attachmentID, nil)
+	c.Params = gin.Params{{Key: "item_id", Value: itemID}, {Key: "attachment_id", Value: attachmentID}}
+	handlers.DeleteItemAttachment(c)
+	assert.Equal(t, http.StatusNoContent, c.Writer.Status())
+
+	// Deleting again reports the attachment missing
+	c, w = createAuthenticatedRequest(handlers, "DELETE", "/items/"+itemID+"/attachments/"+attachmentID, nil)
+	c.Params = gin.Params{{Key: "item_id", Value: itemID}, {Key: "attachment_id", Value: attachmentID}}
+	handlers.DeleteItemAttachment(c)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestAddItemAttachment_InvalidURL(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	created := createTestItem(t, handlers, "Tent")
+	itemID := fmt.Sprintf("%d", created.ID)
+
+	for _, url := range []string{"javascript:alert(1)", "/etc/passwd", "/uploads/../config.yaml", "ftp://example.com/a.png"} {
+		body, _ := json.Marshal(ItemAttachmentRequest{URL: url})
+		c, w := createAuthenticatedRequest(handlers, "POST", "/items/"+itemID+"/attachments", body)
+		c.Params = gin.Params{{Key: "item_id", Value: itemID}}
+		handlers.AddItemAttachment(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, url)
+	}
+}
+
+func TestItemAttachments_NotOwned(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	other := database.Item{Name: "Other", UserEmail: "other@example.com"}
+	handlers.db.Create(&other)
+	attachment := database.ItemAttachment{ItemID: other.ID, URL: "https://example.com/photo.jpg"}
+	handlers.db.Create(&attachment)
+	itemID := fmt.Sprintf("%d", other.ID)
+	attachmentID := fmt.Sprintf("%d", attachment.ID)
+
+	c, w := createAuthenticatedRequest(handlers, "POST", "/items/"+itemID+"/attachments", []byte(`{"url":"https://example.com/a.jpg"}`))
+	c.Params = gin.Params{{Key: "item_id", Value: itemID}}
+	handlers.AddItemAttachment(c)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	c, w = createAuthenticatedRequest(handlers, "DELETE", "/items/"+itemID+"/attachments/"+attachmentID, nil)
+	c.Params = gin.Params{{Key: "item_id", Value: itemID}, {Key: "attachment_id", Value: attachmentID}}
+	handlers.DeleteItemAttachment(c)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	var count int64
+	handlers.db.Model(&database.ItemAttachment{}).Count(&count)
+	assert.Equal(t, int64(1), count)
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Error codes returned in APIError.Code; clients should switch on these rather than on messages
+const (
+	CodeInvalidInput      = "invalid_input"
+	CodeUnauthenticated   = "unauthenticated"
+	CodeForbidden         = "forbidden"
+	CodeNotFound          = "not_found"
+	CodeConflict          = "conflict"
+	CodeGone              = "gone"
+	CodeTooLarge          = "too_large"
+	CodeInternal          = "internal_error"
+	CodeUserExists        = "user_exists"
+	CodeInvalidCredential = "invalid_credentials"
+	CodeInvalidToken      = "invalid_token"
+	CodeEmailNotVerified  = "email_not_verified"
+	CodeAccountInactive   = "account_inactive"
+	CodePasswordTooShort  = "password_too_short"
+	CodeNotMember         = "not_member"
+	CodeInvalidPrefix     = "invalid_prefix"
+	CodeQuotaExceeded     = "quota_exceeded"
+	CodeParentCycle       = "parent_cycle"
+	CodeMergeRestored     = "merge_already_restored"
+	CodeMergeExpired      = "merge_expired"
+	CodeVersionConflict   = "version_conflict"
+)
+
+// APIError is the response body of every failed request
+type APIError struct {
+	Code    string      `json:"code"`
+	Message string      `json:"message"`
+	Details interface{} `json:"details,omitempty"`
+}
+
+// respondError writes an APIError with the given status
+func respondError(c *gin.Context, status int, code, message string) {
+	c.JSON(status, APIError{Code: code, Message: message})
+}
+
+// respondErrorDetails writes an APIError carrying additional details with the given status
+func respondErrorDetails(c *gin.Context, status int, code, message string, details interface{}) {
+	c.JSON(status, APIError{Code: code, Message: message, Details: details})
+}
+
+// respondInvalidInput reports a request body or query that failed to bind
+func respondInvalidInput(c *gin.Context, err error) {
+	respondErrorDetails(c, http.StatusBadRequest, CodeInvalidInput, "Invalid input", err.Error())
+}
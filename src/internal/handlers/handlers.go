@@ -1,17 +1,27 @@
 package handlers
 
 import (
-	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
-	"io"
-	"log"
+	"fmt"
+	"log/slog"
 	"net/http"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
+	"backend/internal/config"
 	"backend/internal/database"
+	"backend/internal/item"
 	"backend/internal/jwt"
+	"backend/internal/notify"
+	"backend/internal/organization"
+	"backend/internal/reset"
+	"backend/internal/tag"
 	"backend/internal/user"
+	"backend/internal/webhook"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/fx"
@@ -21,13 +31,30 @@ import (
 // Module provides handlers dependency injection
 var Module = fx.Module("handlers",
 	fx.Provide(NewHandlers),
+	fx.Invoke(registerShutdown),
 )
 
+// registerShutdown makes the app wait for emails still being sent when it stops
+func registerShutdown(lc fx.Lifecycle, h *Handlers) {
+	lc.Append(fx.Hook{
+		OnStop: h.WaitForEmails,
+	})
+}
+
 // Handlers contains all HTTP handlers
 type Handlers struct {
-	userService *user.Service
-	jwtService  *jwt.Service
-	db          *gorm.DB
+	userService         *user.Service
+	jwtService          *jwt.Service
+	itemService         *item.Service
+	tagService          *tag.Service
+	organizationService *organization.Service
+	resetService        *reset.Service
+	mailer              notify.Mailer
+	emails              sync.WaitGroup
+	webhooks            *webhook.Dispatcher
+	db                  *gorm.DB
+	config              *config.Config
+	logger              *slog.Logger
 }
 
 // LoginRequest represents the login request body
@@ -39,7 +66,7 @@ type LoginRequest struct {
 // RegisterRequest represents the registration request body
 type RegisterRequest struct {
 	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required,min=6"`
+	Password string `json:"password" binding:"required"`
 }
 
 // RefreshRequest represents the refresh token request body
@@ -56,19 +83,93 @@ type VerifyRequest struct {
 type ItemCreateRequest struct {
 	Name        string `json:"name" binding:"required"`
 	Description string `json:"description"`
+	Quantity    *int   `json:"quantity" binding:"omitempty,min=0"`
+	Unit        string `json:"unit" binding:"max=20"`
+}
+
+// ItemBulkCreateRequest represents the bulk item creation request body; entries are
+// validated by the item service so a failure can name its index
+type ItemBulkCreateRequest struct {
+	Items []ItemCreateRequest `json:"items" binding:"required,min=1,max=500"`
 }
 
 // ItemUpdateRequest represents the item update request body
 type ItemUpdateRequest struct {
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	ParentID    *uint  `json:"parent"`
-	Tags        []uint `json:"tags"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Quantity    *int     `json:"quantity" binding:"omitempty,min=0"`
+	Unit        *string  `json:"unit" binding:"omitempty,max=20"`
+	ParentID    *uint    `json:"parent"`
+	Tags        []TagRef `json:"tags"`
+	// Version is the item version the edit is based on; a stale version is rejected with 409
+	Version *int `json:"version" binding:"required"`
+}
+
+// ItemQuantityRequest represents the item quantity adjustment request body
+type ItemQuantityRequest struct {
+	Delta *int `json:"delta" binding:"required"`
+}
+
+// ItemMoveRequest represents the item move request body
+type ItemMoveRequest struct {
+	ParentID *uint `json:"parent"`
+}
+
+// TagRef references a tag either by ID or, when given as a string, by name
+type TagRef struct {
+	ID   uint
+	Name string
+}
+
+// UnmarshalJSON accepts either a numeric tag ID or a tag name
+func (r *TagRef) UnmarshalJSON(data []byte) error {
+	var id uint
+	if err := json.Unmarshal(data, &id); err == nil {
+		r.ID = id
+		return nil
+	}
+
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return errors.New("tag must be an ID or a name")
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return errors.New("tag name must not be empty")
+	}
+	r.Name = name
+	return nil
 }
 
+// maxTagNameLength matches the size of the tags.name column
+const maxTagNameLength = 20
+
+var (
+	// errUnknownTag is returned when a tag name does not exist and creation is not requested
+	errUnknownTag = errors.New("unknown tag")
+	// errInvalidTagName is returned when a tag to be created has an invalid name
+	errInvalidTagName = errors.New("invalid tag name")
+	// errVersionConflict is returned when an item changed after its version was checked
+	errVersionConflict = errors.New("version conflict")
+)
+
 // TagCreateRequest represents the tag creation request body
 type TagCreateRequest struct {
-	Name string `json:"name" binding:"required"`
+	Name  string `json:"name" binding:"required"`
+	Color string `json:"color"`
+	Icon  string `json:"icon" binding:"max=50"`
+}
+
+// TagUpdateRequest represents the tag update request body; omitted fields are left unchanged
+type TagUpdateRequest struct {
+	Name  *string `json:"name" binding:"omitempty,min=1,max=20"`
+	Color *string `json:"color"`
+	Icon  *string `json:"icon" binding:"omitempty,max=50"`
+}
+
+// TagMergeRequest represents the tag merge request body
+type TagMergeRequest struct {
+	Into uint `json:"into" binding:"required"`
 }
 
 // PasswordResetRequest represents the password reset request body
@@ -82,393 +183,675 @@ type NewPasswordRequest struct {
 	Token    string `json:"token" binding:"required"`
 }
 
+// ChangePasswordRequest represents the change password request body
+type ChangePasswordRequest struct {
+	OldPassword string `json:"old_password" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
+// AssignOrganizationRequest represents the item organization assignment request body
+type AssignOrganizationRequest struct {
+	OrganizationID uint `json:"organization_id" binding:"required"`
+}
+
 // UserUpdateRequest represents the user update request body
 type UserUpdateRequest struct {
-	Email string `json:"email" binding:"required,email"`
+	// Email must match the user's current email when set; the email is the user's key
+	// and can't be changed
+	Email       string  `json:"email" binding:"omitempty,email"`
+	DisplayName *string `json:"display_name" binding:"omitempty,max=100"`
+	AvatarURL   *string `json:"avatar_url" binding:"omitempty,max=500"`
 }
 
 // NewHandlers creates a new handlers instance
-func NewHandlers(userService *user.Service, jwtService *jwt.Service, db *gorm.DB) *Handlers {
+func NewHandlers(userService *user.Service, jwtService *jwt.Service, itemService *item.Service, tagService *tag.Service, organizationService *organization.Service, resetService *reset.Service, mailer notify.Mailer, webhooks *webhook.Dispatcher, db *gorm.DB, cfg *config.Config, logger *slog.Logger) *Handlers {
 	return &Handlers{
-		userService: userService,
-		jwtService:  jwtService,
-		db:          db,
+		userService:         userService,
+		jwtService:          jwtService,
+		itemService:         itemService,
+		tagService:          tagService,
+		organizationService: organizationService,
+		resetService:        resetService,
+		mailer:              mailer,
+		webhooks:            webhooks,
+		db:                  db,
+		config:              cfg,
+		logger:              logger,
+	}
+}
+
+// sendEmailAsync sends a message in the background, logging delivery failures
+func (h *Handlers) sendEmailAsync(msg notify.Message) {
+	h.emails.Add(1)
+	go func() {
+		defer h.emails.Done()
+		if err := h.mailer.Send(msg); err != nil {
+			h.logger.Error("failed to send email", "to", msg.To, "error", err)
+		}
+	}()
+}
+
+// WaitForEmails blocks until the emails sent in the background are delivered or ctx is done
+func (h *Handlers) WaitForEmails(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		h.emails.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
 	}
 }
 
+// checkPassword responds with 400 and returns false when the password does not meet the strength requirements
+func (h *Handlers) checkPassword(c *gin.Context, password string) bool {
+	if err := h.userService.ValidatePassword(password); err != nil {
+		respondError(c, http.StatusBadRequest, CodePasswordTooShort, passwordTooShortMessage(h.userService.MinPasswordLength()))
+		return false
+	}
+	return true
+}
+
+// passwordTooShortMessage describes the minimum password length to clients
+func passwordTooShortMessage(minLength int) string {
+	return fmt.Sprintf("Password must be at least %d characters", minLength)
+}
+
 // RegisterUser handles user registration
 func (h *Handlers) RegisterUser(c *gin.Context) {
 	var req RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input: " + err.Error()})
+		respondInvalidInput(c, err)
+		return
+	}
+	if !h.checkPassword(c, req.Password) {
 		return
 	}
 
 	if err := h.userService.CreateUser(req.Email, req.Password); err != nil {
 		if errors.Is(err, user.ErrUserAlreadyExists) {
-			c.JSON(http.StatusConflict, gin.H{"error": "User already exists"})
+			respondError(c, http.StatusConflict, CodeUserExists, "User already exists")
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to create user")
 		return
 	}
 
+	h.sendVerificationEmail(req.Email)
+
 	c.JSON(http.StatusCreated, gin.H{"message": "User created successfully"})
 }
 
 // Login handles user login
 func (h *Handlers) Login(c *gin.Context) {
-	log.Printf("Login attempt - Content-Type: %s", c.GetHeader("Content-Type"))
-
 	var req LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		log.Printf("Login validation error: %v", err)
-		log.Printf("Request body: %+v", req)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input: " + err.Error()})
+		h.logger.DebugContext(c.Request.Context(), "login request rejected", "error", err)
+		respondInvalidInput(c, err)
 		return
 	}
 
-	log.Printf("Login request for email: %s", req.Email)
-
 	if err := h.userService.ValidateUser(req.Email, req.Password); err != nil {
-		log.Printf("User validation error: %v", err)
 		if errors.Is(err, user.ErrInvalidCredentials) {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+			h.logger.InfoContext(c.Request.Context(), "login failed", "email", req.Email, "reason", "invalid credentials")
+			respondError(c, http.StatusUnauthorized, CodeInvalidCredential, "Invalid credentials")
+			return
+		}
+		if errors.Is(err, user.ErrUserInactive) {
+			h.logger.InfoContext(c.Request.Context(), "login failed", "email", req.Email, "reason", "inactive")
+			respondError(c, http.StatusForbidden, CodeAccountInactive, "Account is deactivated")
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Login failed"})
+		h.logger.ErrorContext(c.Request.Context(), "failed to validate user", "email", req.Email, "error", err)
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Login failed")
 		return
 	}
 
-	log.Printf("User validation successful for: %s", req.Email)
+	if h.config.Security.RequireEmailVerification {
+		account, err := h.userService.GetUser(req.Email)
+		if err != nil {
+			respondError(c, http.StatusInternalServerError, CodeInternal, "Login failed")
+			return
+		}
+		if !account.EmailVerified {
+			respondError(c, http.StatusForbidden, CodeEmailNotVerified, "Email address not verified. Check your inbox for the verification email.")
+			return
+		}
+	}
 
-	log.Printf("Calling GenerateTokenPair for email: %s", req.Email)
-	tokens, err := h.jwtService.GenerateTokenPair(req.Email)
+	tokens, err := h.jwtService.IssueTokenPair(req.Email)
 	if err != nil {
-		log.Printf("Token generation error: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate tokens"})
+		h.logger.ErrorContext(c.Request.Context(), "failed to issue tokens", "email", req.Email, "error", err)
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to generate tokens")
 		return
 	}
 
-	log.Printf("Login successful for: %s", req.Email)
-	log.Printf("Generated tokens: %+v", tokens)
+	h.logger.InfoContext(c.Request.Context(), "login succeeded", "email", req.Email)
+	h.setRefreshCookie(c, tokens.RefreshToken)
 	c.JSON(http.StatusOK, tokens)
 }
 
 // RefreshToken handles token refresh
 func (h *Handlers) RefreshToken(c *gin.Context) {
-	log.Printf("=== REFRESH TOKEN REQUEST START ===")
-	log.Printf("Method: %s", c.Request.Method)
-	log.Printf("URL: %s", c.Request.URL.String())
-	log.Printf("Content-Type: %s", c.GetHeader("Content-Type"))
-	log.Printf("User-Agent: %s", c.GetHeader("User-Agent"))
-
-	// Log raw body for debugging
-	body, err := c.GetRawData()
-	if err != nil {
-		log.Printf("Failed to read raw body: %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
-		return
-	}
-	log.Printf("Raw body: %s", string(body))
-
-	// Restore body for binding
-	c.Request.Body = io.NopCloser(bytes.NewBuffer(body))
-
+	// Only metadata is logged here: the body and the token itself are credentials
 	var req RefreshRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		log.Printf("JSON binding error: %v", err)
-		log.Printf("Request struct: %+v", req)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input: " + err.Error()})
+	if err := h.bindRefreshRequest(c, &req); err != nil {
+		h.logger.DebugContext(c.Request.Context(), "refresh request rejected", "error", err)
+		respondInvalidInput(c, err)
 		return
 	}
-
-	log.Printf("Parsed request: %+v", req)
-	log.Printf("Refresh token length: %d", len(req.RefreshToken))
-	if len(req.RefreshToken) > 20 {
-		log.Printf("Refresh token preview: %s...", req.RefreshToken[:20])
-	} else {
-		log.Printf("Refresh token: %s", req.RefreshToken)
-	}
+	h.logger.DebugContext(c.Request.Context(), "refresh token request", "token_length", len(req.RefreshToken))
 
 	// Validate refresh token
 	email, err := h.jwtService.ValidateRefreshToken(req.RefreshToken)
 	if err != nil {
-		log.Printf("Refresh token validation failed: %v", err)
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid refresh token"})
+		h.logger.InfoContext(c.Request.Context(), "refresh token rejected", "token_length", len(req.RefreshToken), "error", err)
+		h.clearRefreshCookie(c)
+		respondError(c, http.StatusUnauthorized, CodeInvalidToken, "Invalid refresh token")
 		return
 	}
 
-	log.Printf("Refresh token validated for user: %s", email)
-
-	// Verify user still exists
-	if _, err := h.userService.GetUser(email); err != nil {
+	// Verify user still exists and is active
+	account, err := h.userService.GetUser(email)
+	if err != nil {
 		if errors.Is(err, user.ErrUserNotFound) {
-			log.Printf("User not found during refresh: %s", email)
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+			h.logger.InfoContext(c.Request.Context(), "refresh for unknown user", "email", email)
+			h.clearRefreshCookie(c)
+			respondError(c, http.StatusUnauthorized, CodeUnauthenticated, "User not found")
 			return
 		}
-		log.Printf("Failed to validate user during refresh: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate user"})
+		h.logger.ErrorContext(c.Request.Context(), "failed to validate user during refresh", "email", email, "error", err)
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to validate user")
+		return
+	}
+	if !account.Active {
+		h.logger.InfoContext(c.Request.Context(), "refresh for inactive user", "email", email)
+		h.clearRefreshCookie(c)
+		respondError(c, http.StatusForbidden, CodeAccountInactive, "Account is deactivated")
 		return
 	}
 
-	log.Printf("User verified: %s", email)
-
-	// Generate new token pair (both access and refresh tokens)
-	tokens, err := h.jwtService.GenerateTokenPair(email)
+	// Rotate the refresh token: the presented one is used up and a new pair is issued
+	tokens, err := h.jwtService.RotateRefreshToken(req.RefreshToken)
 	if err != nil {
-		log.Printf("Failed to generate new tokens: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate tokens"})
+		if errors.Is(err, jwt.ErrRefreshTokenNotFound) || errors.Is(err, jwt.ErrRefreshTokenReused) {
+			h.logger.InfoContext(c.Request.Context(), "refresh token no longer active", "email", email, "error", err)
+			h.clearRefreshCookie(c)
+			respondError(c, http.StatusUnauthorized, CodeInvalidToken, "Invalid refresh token")
+			return
+		}
+		h.logger.ErrorContext(c.Request.Context(), "failed to rotate refresh token", "email", email, "error", err)
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to generate tokens")
 		return
 	}
 
-	log.Printf("New tokens generated successfully")
-	log.Printf("=== REFRESH TOKEN REQUEST END ===")
-
+	h.logger.InfoContext(c.Request.Context(), "tokens refreshed", "email", email)
+	h.setRefreshCookie(c, tokens.RefreshToken)
 	c.JSON(http.StatusOK, tokens)
 }
 
-// TokenPair handles generating a new token pair for a user
+// TokenPair handles an admin generating a new token pair for a user. The user must be
+// allowed to log in: deactivated and, when verification is required, unverified users
+// get no tokens.
 func (h *Handlers) TokenPair(c *gin.Context) {
 	var req struct {
 		Email string `json:"email" binding:"required,email"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input: " + err.Error()})
+		respondInvalidInput(c, err)
 		return
 	}
 
-	// Verify user exists
-	if _, err := h.userService.GetUser(req.Email); err != nil {
+	account, err := h.userService.GetUser(req.Email)
+	if err != nil {
 		if errors.Is(err, user.ErrUserNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			respondError(c, http.StatusNotFound, CodeNotFound, "User not found")
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate user"})
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to validate user")
+		return
+	}
+	if !account.Active {
+		respondError(c, http.StatusForbidden, CodeAccountInactive, "Account is deactivated")
+		return
+	}
+	if h.config.Security.RequireEmailVerification && !account.EmailVerified {
+		respondError(c, http.StatusForbidden, CodeEmailNotVerified, "Email address not verified")
 		return
 	}
 
-	tokens, err := h.jwtService.GenerateTokenPair(req.Email)
+	tokens, err := h.jwtService.IssueTokenPair(req.Email)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate tokens"})
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to generate tokens")
 		return
 	}
 
 	c.JSON(http.StatusOK, tokens)
 }
 
-// VerifyToken handles token verification
+// VerifyToken handles access token verification
 func (h *Handlers) VerifyToken(c *gin.Context) {
+	h.inspectToken(c, jwt.TokenTypeAccess)
+}
+
+// IntrospectToken handles verification of either an access or a refresh token
+func (h *Handlers) IntrospectToken(c *gin.Context) {
+	h.inspectToken(c, "")
+}
+
+// inspectToken validates the token in the request body as the given type, or as either type
+// when tokenType is empty, and responds with its claims
+func (h *Handlers) inspectToken(c *gin.Context, tokenType string) {
 	var req VerifyRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input: " + err.Error()})
+		respondInvalidInput(c, err)
 		return
 	}
 
-	email, err := h.jwtService.ValidateToken(req.Token)
+	info, err := h.jwtService.Inspect(req.Token, tokenType)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		respondError(c, http.StatusUnauthorized, CodeInvalidToken, "Invalid token")
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"valid": true, "email": email})
+	// Rotated or revoked refresh tokens still carry a valid signature
+	if info.TokenType == jwt.TokenTypeRefresh {
+		active, err := h.jwtService.IsRefreshTokenActive(req.Token)
+		if err != nil {
+			respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to verify token")
+			return
+		}
+		if !active {
+			respondError(c, http.StatusUnauthorized, CodeInvalidToken, "Invalid token")
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"valid":      true,
+		"email":      info.Email,
+		"token_type": info.TokenType,
+		"expires_at": info.ExpiresAt,
+	})
 }
 
 // GetUserStatistics handles getting user statistics
 func (h *Handlers) GetUserStatistics(c *gin.Context) {
 	var count int64
 	if err := h.db.Model(&database.User{}).Count(&count).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user statistics"})
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to get user statistics")
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"total": count})
 }
 
+// passwordResetSentMessage is the reset response for both known and unknown emails
+const passwordResetSentMessage = "If that account exists, a reset token was sent"
+
 // RequestPasswordReset handles password reset request
 func (h *Handlers) RequestPasswordReset(c *gin.Context) {
 	var req PasswordResetRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input: " + err.Error()})
+		respondInvalidInput(c, err)
 		return
 	}
 
-	// Check if user exists
+	// Unknown emails get the same response so accounts can't be enumerated
 	var user database.User
 	if err := h.db.Where("email = ?", req.Username).First(&user).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			c.JSON(http.StatusOK, gin.H{"message": passwordResetSentMessage})
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process request"})
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to process request")
 		return
 	}
 
-	// Generate reset token (simple random string for now)
-	token := "reset_" + strconv.FormatInt(time.Now().Unix(), 10)
-	expiredAt := time.Now().Add(5 * time.Minute)
-
-	resetToken := database.ResetToken{
-		Token:     token,
-		ExpiredAt: expiredAt,
-		UserEmail: user.Email,
-	}
-
-	// Save reset token
-	if err := h.db.Create(&resetToken).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create reset token"})
+	token, err := h.resetService.CreateResetToken(user.Email)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to create reset token")
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Password reset token sent"})
+	// Sent in the background so a slow mail server doesn't hold up the response
+	h.sendEmailAsync(notify.Message{
+		To:      user.Email,
+		Subject: "Reset your SchwiftyBox password",
+		Body:    "Reset your password using this token: " + token,
+	})
+
+	c.JSON(http.StatusOK, gin.H{"message": passwordResetSentMessage})
 }
 
 // SetNewPassword handles setting new password with reset token
 func (h *Handlers) SetNewPassword(c *gin.Context) {
 	var req NewPasswordRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input: " + err.Error()})
+		respondInvalidInput(c, err)
+		return
+	}
+	if !h.checkPassword(c, req.Password) {
 		return
 	}
 
-	// Find reset token
-	var resetToken database.ResetToken
-	if err := h.db.Where("token = ? AND expired_at > ?", req.Token, time.Now()).First(&resetToken).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired token"})
+	// Reject bad tokens before spending time on hashing
+	resetToken, err := h.resetService.ValidateResetToken(req.Token)
+	if err != nil {
+		if errors.Is(err, reset.ErrResetTokenNotFound) || errors.Is(err, reset.ErrResetTokenExpired) {
+			respondError(c, http.StatusBadRequest, CodeInvalidToken, "Invalid or expired token")
+			return
+		}
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to validate token")
+		return
+	}
+
+	hash, err := h.userService.HashPassword(req.Password)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to update password")
+		return
+	}
+
+	// Sessions opened with the old password end with it
+	err = database.WithTx(h.db, func(tx *gorm.DB) error {
+		if err := h.resetService.WithTx(tx).ResetPassword(req.Token, hash); err != nil {
+			return err
+		}
+		return h.jwtService.WithTx(tx).RevokeAllRefreshTokens(resetToken.UserEmail)
+	})
+	if err != nil {
+		if errors.Is(err, reset.ErrResetTokenNotFound) || errors.Is(err, reset.ErrResetTokenExpired) {
+			respondError(c, http.StatusBadRequest, CodeInvalidToken, "Invalid or expired token")
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate token"})
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to update password")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password updated successfully"})
+}
+
+// ChangePassword handles changing the authenticated user's password
+func (h *Handlers) ChangePassword(c *gin.Context) {
+	userEmail, exists := c.Get("user_email")
+	if !exists {
+		respondError(c, http.StatusUnauthorized, CodeUnauthenticated, "User not authenticated")
+		return
+	}
+
+	var req ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondInvalidInput(c, err)
+		return
+	}
+	if !h.checkPassword(c, req.NewPassword) {
 		return
 	}
 
-	// Update user password (for now, store as plain text - in production should be hashed)
-	if err := h.db.Model(&database.User{}).Where("email = ?", resetToken.UserEmail).Update("password", req.Password).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update password"})
+	if err := h.userService.ValidateUser(userEmail.(string), req.OldPassword); err != nil {
+		if errors.Is(err, user.ErrInvalidCredentials) {
+			respondError(c, http.StatusUnauthorized, CodeInvalidCredential, "Invalid old password")
+			return
+		}
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to validate password")
 		return
 	}
 
-	// Delete used reset token
-	h.db.Delete(&resetToken)
+	// Sessions opened with the old password end with it
+	err := database.WithTx(h.db, func(tx *gorm.DB) error {
+		if err := h.userService.WithTx(tx).UpdatePassword(userEmail.(string), req.NewPassword); err != nil {
+			return err
+		}
+		return h.jwtService.WithTx(tx).RevokeAllRefreshTokens(userEmail.(string))
+	})
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to update password")
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Password updated successfully"})
 }
 
-// DeactivateUser handles user deactivation
+// DeactivateUser handles deactivating the authenticated user, which blocks logging in
+// and ends their sessions
 func (h *Handlers) DeactivateUser(c *gin.Context) {
-	_, exists := c.Get("user_email")
+	userEmail, exists := c.Get("user_email")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		respondError(c, http.StatusUnauthorized, CodeUnauthenticated, "User not authenticated")
+		return
+	}
+
+	// Deactivating and revoking sessions succeed or fail together
+	err := database.WithTx(h.db, func(tx *gorm.DB) error {
+		if err := h.userService.WithTx(tx).SetActive(userEmail.(string), false); err != nil {
+			return err
+		}
+		return h.jwtService.WithTx(tx).RevokeAllRefreshTokens(userEmail.(string))
+	})
+	if err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			respondError(c, http.StatusNotFound, CodeNotFound, "User not found")
+			return
+		}
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to deactivate user")
 		return
 	}
 
-	// For now, we'll just return success since we don't have a deactivated field
-	// In a real implementation, you'd set a deactivated flag
 	c.JSON(http.StatusOK, gin.H{"message": "User deactivated successfully"})
 }
 
-// GetUserDetails handles getting user details
+// authorizeUserAccess reports whether the authenticated user may manage the user in the
+// email path parameter: their own account, or any account for admins. Otherwise it
+// responds with 403.
+func (h *Handlers) authorizeUserAccess(c *gin.Context) bool {
+	userEmail := c.GetString("user_email")
+	if userEmail != "" && userEmail == c.Param("email") {
+		return true
+	}
+
+	caller, err := h.userService.GetUser(userEmail)
+	if err == nil && caller.Role == user.RoleAdmin {
+		return true
+	}
+	if err != nil && !errors.Is(err, user.ErrUserNotFound) {
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to check permissions")
+		return false
+	}
+
+	respondError(c, http.StatusForbidden, CodeForbidden, "Not allowed to manage this user")
+	return false
+}
+
+// GetUserDetails handles getting the details of the user in the email path parameter
 func (h *Handlers) GetUserDetails(c *gin.Context) {
-	userIDStr := c.Param("user_id")
-	userID, err := strconv.ParseUint(userIDStr, 10, 32)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+	if !h.authorizeUserAccess(c) {
 		return
 	}
 
-	var user database.User
-	if err := h.db.First(&user, userID).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+	found, err := h.userService.GetUser(c.Param("email"))
+	if err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			respondError(c, http.StatusNotFound, CodeNotFound, "User not found")
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user"})
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to get user")
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"email": user.Email})
+	c.JSON(http.StatusOK, newUserResponse(found))
 }
 
-// UpdateUserDetails handles updating user details
+// UpdateUserDetails handles updating the profile of the user in the email path parameter
 func (h *Handlers) UpdateUserDetails(c *gin.Context) {
-	userIDStr := c.Param("user_id")
-	userID, err := strconv.ParseUint(userIDStr, 10, 32)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+	if !h.authorizeUserAccess(c) {
 		return
 	}
 
 	var req UserUpdateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input: " + err.Error()})
+		respondInvalidInput(c, err)
+		return
+	}
+	if req.AvatarURL != nil && !isValidAvatarURL(*req.AvatarURL) {
+		respondError(c, http.StatusBadRequest, CodeInvalidInput, "Avatar URL must be an http or https URL")
 		return
 	}
 
-	var user database.User
-	if err := h.db.First(&user, userID).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+	existing, err := h.userService.GetUser(c.Param("email"))
+	if err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			respondError(c, http.StatusNotFound, CodeNotFound, "User not found")
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user"})
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to get user")
+		return
+	}
+
+	// Items, memberships and tokens reference the email, so renaming would orphan them
+	if req.Email != "" && req.Email != existing.Email {
+		respondError(c, http.StatusBadRequest, CodeInvalidInput, "Email can't be changed")
 		return
 	}
 
-	// Update email
-	if err := h.db.Model(&user).Update("email", req.Email).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user"})
+	updated, err := h.userService.UpdateProfile(existing.Email, req.DisplayName, req.AvatarURL)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to update user")
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"email": req.Email})
+	c.JSON(http.StatusOK, newUserResponse(updated))
 }
 
-// DeleteUser handles user deletion
+// DeleteUser handles deleting the user in the email path parameter
 func (h *Handlers) DeleteUser(c *gin.Context) {
-	userIDStr := c.Param("user_id")
-	userID, err := strconv.ParseUint(userIDStr, 10, 32)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+	if !h.authorizeUserAccess(c) {
 		return
 	}
 
-	if err := h.db.Delete(&database.User{}, userID).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete user"})
+	db := h.db
+	if c.Query("hard") == "true" {
+		db = db.Unscoped()
+	}
+
+	result := db.Where("email = ?", c.Param("email")).Delete(&database.User{})
+	if result.Error != nil {
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to delete user")
+		return
+	}
+	if result.RowsAffected == 0 {
+		respondError(c, http.StatusNotFound, CodeNotFound, "User not found")
 		return
 	}
 
 	c.Status(http.StatusNoContent)
 }
 
+// quantityFilters maps the quantity threshold query parameters to their comparison operators
+var quantityFilters = map[string]string{
+	"quantity_lt": "<",
+	"quantity_gt": ">",
+}
+
+// itemSortOrders maps the supported item sort values to ORDER BY clauses
+var itemSortOrders = map[string]string{
+	"name":         "LOWER(name) ASC, id ASC",
+	"-name":        "LOWER(name) DESC, id DESC",
+	"added_at":     "added_at ASC, id ASC",
+	"-added_at":    "added_at DESC, id DESC",
+	"backpack_id":  "backpack_id ASC, id ASC",
+	"-backpack_id": "backpack_id DESC, id DESC",
+}
+
 // GetItems handles getting all items for the authenticated user
 func (h *Handlers) GetItems(c *gin.Context) {
 	userEmail, exists := c.Get("user_email")
-	log.Printf("GetItems called for user: %s", userEmail)
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		respondError(c, http.StatusUnauthorized, CodeUnauthenticated, "User not authenticated")
+		return
+	}
+
+	order, ok := itemSortOrders[c.DefaultQuery("sort", "-added_at")]
+	if !ok {
+		respondError(c, http.StatusBadRequest, CodeInvalidInput, "Invalid sort value")
 		return
 	}
 
 	nameFilter := c.Query("name")
-	log.Printf("Name filter: %s", nameFilter)
 
 	var items []database.Item
-	query := h.db.Where("user_email = ?", userEmail).Preload("Tags").Preload("Parent")
+	query := h.db.Preload("Tags").Preload("Parent").Order(order)
+
+	// Items are the user's own unless the organization's shared inventory is requested
+	switch c.DefaultQuery("scope", "user") {
+	case "user":
+		query = query.Where("user_email = ?", userEmail)
+	case "org":
+		u, err := h.userService.GetUser(userEmail.(string))
+		if err != nil {
+			respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to get user")
+			return
+		}
+		query = query.Where("organization_id = ?", u.ActiveOrganizationID)
+	default:
+		respondError(c, http.StatusBadRequest, CodeInvalidInput, "Invalid scope value")
+		return
+	}
 
 	if nameFilter != "" {
-		// Use LIKE for SQLite compatibility (case-insensitive search)
-		query = query.Where("name LIKE ?", "%"+nameFilter+"%")
+		// LOWER on both sides is case-insensitive on Postgres and SQLite alike, unlike ILIKE
+		query = query.Where(`LOWER(name) LIKE LOWER(?) ESCAPE '\'`, database.ContainsPattern(nameFilter))
+	}
+
+	for param, op := range quantityFilters {
+		raw, ok := c.GetQuery(param)
+		if !ok {
+			continue
+		}
+		threshold, err := strconv.Atoi(raw)
+		if err != nil || threshold < 0 {
+			respondError(c, http.StatusBadRequest, CodeInvalidInput, "Invalid "+param+" value")
+			return
+		}
+		query = query.Where("quantity "+op+" ?", threshold)
+	}
+
+	if rawTags := c.QueryArray("tags"); len(rawTags) > 0 {
+		tagIDs := make([]uint, 0, len(rawTags))
+		seen := make(map[uint]bool, len(rawTags))
+		for _, raw := range rawTags {
+			id, err := strconv.ParseUint(raw, 10, 32)
+			if err != nil {
+				respondError(c, http.StatusBadRequest, CodeInvalidInput, "Invalid tags value")
+				return
+			}
+			if !seen[uint(id)] {
+				seen[uint(id)] = true
+				tagIDs = append(tagIDs, uint(id))
+			}
+		}
+
+		switch c.DefaultQuery("match", "any") {
+		case "any":
+			query = query.Where("id IN (SELECT item_id FROM item_tags WHERE tag_id IN ?)", tagIDs)
+		case "all":
+			query = query.Where("id IN (SELECT item_id FROM item_tags WHERE tag_id IN ? GROUP BY item_id HAVING COUNT(DISTINCT tag_id) = ?)", tagIDs, len(tagIDs))
+		default:
+			respondError(c, http.StatusBadRequest, CodeInvalidInput, "Invalid match value")
+			return
+		}
 	}
 
 	if err := query.Find(&items).Error; err != nil {
-		log.Printf("Failed to get items: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get items"})
+		h.logger.ErrorContext(c.Request.Context(), "failed to get items", "email", userEmail, "error", err)
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to get items")
 		return
 	}
 
-	log.Printf("Found %d items 1", len(items))
-	log.Printf("Returning items in object with key 'items'")
+	h.logger.DebugContext(c.Request.Context(), "items listed", "email", userEmail, "count", len(items))
 	c.JSON(http.StatusOK, gin.H{"items": items})
 }
 
@@ -476,104 +859,189 @@ func (h *Handlers) GetItems(c *gin.Context) {
 func (h *Handlers) GetItem(c *gin.Context) {
 	userEmail, exists := c.Get("user_email")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		respondError(c, http.StatusUnauthorized, CodeUnauthenticated, "User not authenticated")
 		return
 	}
 
 	itemIDStr := c.Param("item_id")
 	itemID, err := strconv.ParseUint(itemIDStr, 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid item ID"})
+		respondError(c, http.StatusBadRequest, CodeInvalidInput, "Invalid item ID")
 		return
 	}
 
 	var item database.Item
-	if err := h.db.Where("id = ? AND user_email = ?", itemID, userEmail).Preload("Tags").Preload("Parent").First(&item).Error; err != nil {
+	if err := h.db.Where("id = ? AND user_email = ?", itemID, userEmail).Preload("Tags").Preload("Parent").Preload("Attachments").First(&item).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Item not found"})
+			respondError(c, http.StatusNotFound, CodeNotFound, "Item not found")
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get item"})
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to get item")
 		return
 	}
 
 	c.JSON(http.StatusOK, item)
 }
 
+// BackpackIDExists handles checking whether a backpack ID is already assigned to one of the user's items
+func (h *Handlers) BackpackIDExists(c *gin.Context) {
+	userEmail, exists := c.Get("user_email")
+	if !exists {
+		respondError(c, http.StatusUnauthorized, CodeUnauthenticated, "User not authenticated")
+		return
+	}
+
+	backpackID := c.Query("id")
+	if backpackID == "" {
+		respondError(c, http.StatusBadRequest, CodeInvalidInput, "Backpack ID is required")
+		return
+	}
+
+	var count int64
+	if err := h.db.Model(&database.Item{}).Where("backpack_id = ? AND user_email = ?", backpackID, userEmail).Count(&count).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to check backpack ID")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"exists": count > 0})
+}
+
 // CreateItem handles creating a new item
 func (h *Handlers) CreateItem(c *gin.Context) {
 	userEmail, exists := c.Get("user_email")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		respondError(c, http.StatusUnauthorized, CodeUnauthenticated, "User not authenticated")
 		return
 	}
 
 	var req ItemCreateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input: " + err.Error()})
+		respondInvalidInput(c, err)
 		return
 	}
 
-	// Get user to generate backpack_id
-	var user database.User
-	if err := h.db.Where("email = ?", userEmail).First(&user).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user"})
+	// The item service assigns the backpack_id from the user's prefix
+	created, err := h.itemService.Create(userEmail.(string), item.NewItem{
+		Name:        req.Name,
+		Description: req.Description,
+		Quantity:    req.Quantity,
+		Unit:        req.Unit,
+	})
+	if err != nil {
+		if errors.Is(err, item.ErrInvalidItem) {
+			respondError(c, http.StatusBadRequest, CodeInvalidInput, err.Error())
+			return
+		}
+		if errors.Is(err, item.ErrInvalidPrefix) {
+			respondError(c, http.StatusConflict, CodeInvalidPrefix, "User has an invalid backpack prefix")
+			return
+		}
+		if errors.Is(err, item.ErrQuotaExceeded) {
+			respondError(c, http.StatusForbidden, CodeQuotaExceeded, "Item quota exceeded")
+			return
+		}
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to create item")
 		return
 	}
 
-	// Generate backpack_id (simple implementation)
-	backpackID := user.Prefix + "_" + strconv.FormatInt(time.Now().Unix(), 10)
+	h.webhooks.Send(webhook.EventItemCreated, created)
 
-	item := database.Item{
-		Name:        req.Name,
-		Description: req.Description,
-		BackpackID:  backpackID,
-		AddedAt:     time.Now(),
-		UserEmail:   userEmail.(string),
+	c.Header("Location", "/api/items/"+strconv.FormatUint(uint64(created.ID), 10))
+	c.JSON(http.StatusCreated, created)
+}
+
+// CreateItems handles creating a batch of items in one transaction
+func (h *Handlers) CreateItems(c *gin.Context) {
+	userEmail, exists := c.Get("user_email")
+	if !exists {
+		respondError(c, http.StatusUnauthorized, CodeUnauthenticated, "User not authenticated")
+		return
 	}
 
-	if err := h.db.Create(&item).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create item"})
+	var req ItemBulkCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondInvalidInput(c, err)
+		return
+	}
+
+	newItems := make([]item.NewItem, len(req.Items))
+	for i, entry := range req.Items {
+		newItems[i] = item.NewItem{
+			Name:        entry.Name,
+			Description: entry.Description,
+			Quantity:    entry.Quantity,
+			Unit:        entry.Unit,
+		}
+	}
+
+	created, err := h.itemService.CreateItems(userEmail.(string), newItems)
+	if err != nil {
+		var batchErr *item.BatchError
+		if errors.As(err, &batchErr) {
+			respondErrorDetails(c, http.StatusBadRequest, CodeInvalidInput, "Invalid item in batch", gin.H{
+				"index": batchErr.Index,
+				"error": batchErr.Err.Error(),
+			})
+			return
+		}
+		if errors.Is(err, item.ErrInvalidPrefix) {
+			respondError(c, http.StatusConflict, CodeInvalidPrefix, "User has an invalid backpack prefix")
+			return
+		}
+		if errors.Is(err, item.ErrQuotaExceeded) {
+			respondError(c, http.StatusForbidden, CodeQuotaExceeded, "Item quota exceeded")
+			return
+		}
+		h.logger.ErrorContext(c.Request.Context(), "failed to create items", "email", userEmail, "count", len(newItems), "error", err)
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to create items")
 		return
 	}
 
-	// Load relationships
-	h.db.Preload("Tags").Preload("Parent").First(&item, item.ID)
+	for i := range created {
+		h.webhooks.Send(webhook.EventItemCreated, &created[i])
+	}
 
-	c.JSON(http.StatusCreated, item)
+	c.JSON(http.StatusCreated, gin.H{"items": created})
 }
 
 // UpdateItem handles updating an existing item
 func (h *Handlers) UpdateItem(c *gin.Context) {
 	userEmail, exists := c.Get("user_email")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		respondError(c, http.StatusUnauthorized, CodeUnauthenticated, "User not authenticated")
 		return
 	}
 
 	itemIDStr := c.Param("item_id")
 	itemID, err := strconv.ParseUint(itemIDStr, 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid item ID"})
+		respondError(c, http.StatusBadRequest, CodeInvalidInput, "Invalid item ID")
 		return
 	}
 
 	var req ItemUpdateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input: " + err.Error()})
+		respondInvalidInput(c, err)
 		return
 	}
 
-	var item database.Item
-	if err := h.db.Where("id = ? AND user_email = ?", itemID, userEmail).First(&item).Error; err != nil {
+	var existing database.Item
+	if err := h.db.Where("id = ? AND user_email = ?", itemID, userEmail).First(&existing).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Item not found"})
+			respondError(c, http.StatusNotFound, CodeNotFound, "Item not found")
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get item"})
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to get item")
 		return
 	}
 
+	if existing.Version != *req.Version {
+		respondError(c, http.StatusConflict, CodeVersionConflict, "Item was modified by another request")
+		return
+	}
+
+	createMissingTags := c.Query("create_missing_tags") == "true"
+
 	// Update fields
 	updates := make(map[string]interface{})
 	if req.Name != "" {
@@ -582,115 +1050,726 @@ func (h *Handlers) UpdateItem(c *gin.Context) {
 	if req.Description != "" {
 		updates["description"] = req.Description
 	}
+	if req.Quantity != nil {
+		updates["quantity"] = *req.Quantity
+	}
+	if req.Unit != nil {
+		updates["unit"] = *req.Unit
+	}
 	if req.ParentID != nil {
+		// Same ownership and cycle checks as MoveItem
+		if err := h.itemService.CheckParent(existing.ID, *req.ParentID, userEmail.(string)); err != nil {
+			switch {
+			case errors.Is(err, item.ErrParentNotFound):
+				respondError(c, http.StatusNotFound, CodeNotFound, "Parent item not found")
+			case errors.Is(err, item.ErrParentCycle):
+				respondError(c, http.StatusConflict, CodeParentCycle, "Item cannot be moved under itself or one of its descendants")
+			default:
+				respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to check parent item")
+			}
+			return
+		}
 		updates["parent_id"] = req.ParentID
 	}
 
-	if len(updates) > 0 {
-		if err := h.db.Model(&item).Updates(updates).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update item"})
-			return
+	err = database.WithTx(h.db, func(tx *gorm.DB) error {
+		// The audit diff holds the requested values, copied before Updates adds updated_at
+		diff := make(map[string]interface{}, len(updates)+1)
+		for field, value := range updates {
+			diff[field] = value
+		}
+
+		// The version check in the WHERE clause catches edits racing past the check above
+		updates["version"] = gorm.Expr("version + 1")
+		result := tx.Model(&existing).Where("version = ?", *req.Version).Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errVersionConflict
+		}
+
+		// Update tags if provided
+		if req.Tags != nil {
+			tags, err := resolveTags(tx, userEmail.(string), req.Tags, createMissingTags)
+			if err != nil {
+				return err
+			}
+			if err := tx.Model(&existing).Association("Tags").Replace(tags); err != nil {
+				return err
+			}
+
+			tagIDs := make([]uint, len(tags))
+			for i, tag := range tags {
+				tagIDs[i] = tag.ID
+			}
+			diff["tags"] = tagIDs
 		}
-	}
 
-	// Update tags if provided
-	if req.Tags != nil {
-		var tags []database.Tag
-		if err := h.db.Where("id IN ?", req.Tags).Find(&tags).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get tags"})
+		item.RecordAudit(tx, userEmail.(string), existing.ID, item.AuditUpdate, diff)
+		return nil
+	})
+	if err != nil {
+		var unknownIDs *unknownTagIDsError
+		if errors.As(err, &unknownIDs) {
+			respondErrorDetails(c, http.StatusBadRequest, CodeInvalidInput, err.Error(), gin.H{"unknown_tag_ids": unknownIDs.IDs})
 			return
 		}
-		h.db.Model(&item).Association("Tags").Replace(tags)
+		if errors.Is(err, errUnknownTag) || errors.Is(err, errInvalidTagName) {
+			respondError(c, http.StatusBadRequest, CodeInvalidInput, err.Error())
+			return
+		}
+		if errors.Is(err, errVersionConflict) {
+			respondError(c, http.StatusConflict, CodeVersionConflict, "Item was modified by another request")
+			return
+		}
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to update item")
+		return
 	}
 
 	// Load updated item with relationships
-	h.db.Preload("Tags").Preload("Parent").First(&item, item.ID)
+	h.db.Preload("Tags").Preload("Parent").First(&existing, existing.ID)
 
-	c.JSON(http.StatusOK, item)
+	h.webhooks.Send(webhook.EventItemUpdated, existing)
+
+	c.JSON(http.StatusOK, existing)
 }
 
-// DeleteItem handles deleting an item
+// AdjustItemQuantity handles atomically adding a delta to an item's quantity
+func (h *Handlers) AdjustItemQuantity(c *gin.Context) {
+	userEmail, exists := c.Get("user_email")
+	if !exists {
+		respondError(c, http.StatusUnauthorized, CodeUnauthenticated, "User not authenticated")
+		return
+	}
+
+	itemID, err := strconv.ParseUint(c.Param("item_id"), 10, 32)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, CodeInvalidInput, "Invalid item ID")
+		return
+	}
+
+	var req ItemQuantityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondInvalidInput(c, err)
+		return
+	}
+
+	adjusted, err := h.itemService.AdjustQuantity(uint(itemID), userEmail.(string), *req.Delta)
+	if err != nil {
+		if errors.Is(err, item.ErrItemNotFound) {
+			respondError(c, http.StatusNotFound, CodeNotFound, "Item not found")
+			return
+		}
+		if errors.Is(err, item.ErrQuantityBelowZero) {
+			respondError(c, http.StatusBadRequest, CodeInvalidInput, "Quantity cannot go below zero")
+			return
+		}
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to adjust quantity")
+		return
+	}
+
+	h.webhooks.Send(webhook.EventItemUpdated, adjusted)
+
+	c.JSON(http.StatusOK, adjusted)
+}
+
+// resolveTags looks up the referenced tags, optionally creating named tags missing from the user's active organization
+func resolveTags(tx *gorm.DB, userEmail string, refs []TagRef, createMissing bool) ([]database.Tag, error) {
+	var ids []uint
+	var names []string
+	for _, ref := range refs {
+		if ref.Name != "" {
+			names = append(names, ref.Name)
+		} else {
+			ids = append(ids, ref.ID)
+		}
+	}
+
+	var user database.User
+	if err := tx.Where("email = ?", userEmail).First(&user).Error; err != nil {
+		return nil, err
+	}
+
+	// Tags of other organizations are treated as unknown
+	tags := []database.Tag{}
+	if len(ids) > 0 {
+		if err := tx.Where("id IN ? AND organization_id = ?", ids, user.ActiveOrganizationID).Find(&tags).Error; err != nil {
+			return nil, err
+		}
+		if unknown := missingTagIDs(ids, tags); len(unknown) > 0 {
+			return nil, &unknownTagIDsError{IDs: unknown}
+		}
+	}
+
+	for _, name := range names {
+		var tag database.Tag
+		err := tx.Where("organization_id = ? AND name = ?", user.ActiveOrganizationID, name).First(&tag).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			if !createMissing {
+				return nil, fmt.Errorf("%w: %s", errUnknownTag, name)
+			}
+			if len(name) > maxTagNameLength {
+				return nil, fmt.Errorf("%w: %s exceeds %d characters", errInvalidTagName, name, maxTagNameLength)
+			}
+			tag = database.Tag{Name: name, OrganizationID: user.ActiveOrganizationID}
+			err = tx.Create(&tag).Error
+		}
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+
+	return tags, nil
+}
+
+// unknownTagIDsError is returned when referenced tag IDs don't exist in the user's active organization
+type unknownTagIDsError struct {
+	IDs []uint
+}
+
+func (e *unknownTagIDsError) Error() string {
+	ids := make([]string, len(e.IDs))
+	for i, id := range e.IDs {
+		ids[i] = strconv.FormatUint(uint64(id), 10)
+	}
+	return fmt.Sprintf("%v: %s", errUnknownTag, strings.Join(ids, ", "))
+}
+
+func (e *unknownTagIDsError) Unwrap() error {
+	return errUnknownTag
+}
+
+// missingTagIDs returns the IDs, in request order and without duplicates, that none of the found tags has
+func missingTagIDs(ids []uint, found []database.Tag) []uint {
+	seen := make(map[uint]bool, len(ids))
+	for _, tag := range found {
+		seen[tag.ID] = true
+	}
+
+	var missing []uint
+	for _, id := range ids {
+		if !seen[id] {
+			missing = append(missing, id)
+			seen[id] = true
+		}
+	}
+	return missing
+}
+
+// DeleteItem handles deleting an item
 func (h *Handlers) DeleteItem(c *gin.Context) {
 	userEmail, exists := c.Get("user_email")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		respondError(c, http.StatusUnauthorized, CodeUnauthenticated, "User not authenticated")
 		return
 	}
 
 	itemIDStr := c.Param("item_id")
 	itemID, err := strconv.ParseUint(itemIDStr, 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid item ID"})
+		respondError(c, http.StatusBadRequest, CodeInvalidInput, "Invalid item ID")
 		return
 	}
 
-	if err := h.db.Where("id = ? AND user_email = ?", itemID, userEmail).Delete(&database.Item{}).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete item"})
+	// Children move up to the deleted item's parent unless the whole subtree is deleted
+	opts := item.DeleteOptions{
+		Cascade: c.Query("cascade") == "true",
+		Hard:    c.Query("hard") == "true",
+	}
+
+	deleted, err := h.itemService.DeleteItem(uint(itemID), userEmail.(string), opts)
+	if err != nil && !errors.Is(err, item.ErrItemNotFound) {
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to delete item")
 		return
 	}
 
+	for _, id := range deleted {
+		h.webhooks.Send(webhook.EventItemDeleted, gin.H{"id": id})
+	}
+
 	c.Status(http.StatusNoContent)
 }
 
-// GetTags handles getting all tags for the user's organization
+// RestoreItem handles undeleting a soft-deleted item
+func (h *Handlers) RestoreItem(c *gin.Context) {
+	userEmail, exists := c.Get("user_email")
+	if !exists {
+		respondError(c, http.StatusUnauthorized, CodeUnauthenticated, "User not authenticated")
+		return
+	}
+
+	itemID, err := strconv.ParseUint(c.Param("item_id"), 10, 32)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, CodeInvalidInput, "Invalid item ID")
+		return
+	}
+
+	restored, err := h.itemService.RestoreItem(uint(itemID), userEmail.(string))
+	if err != nil {
+		if errors.Is(err, item.ErrItemNotFound) {
+			respondError(c, http.StatusNotFound, CodeNotFound, "Deleted item not found")
+			return
+		}
+		if errors.Is(err, item.ErrQuotaExceeded) {
+			respondError(c, http.StatusForbidden, CodeQuotaExceeded, "Item quota exceeded")
+			return
+		}
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to restore item")
+		return
+	}
+
+	h.webhooks.Send(webhook.EventItemUpdated, restored)
+
+	c.JSON(http.StatusOK, restored)
+}
+
+// GetDeletedItems handles getting tombstones for items deleted after the since cursor
+func (h *Handlers) GetDeletedItems(c *gin.Context) {
+	userEmail, exists := c.Get("user_email")
+	if !exists {
+		respondError(c, http.StatusUnauthorized, CodeUnauthenticated, "User not authenticated")
+		return
+	}
+
+	var since time.Time
+	if sinceStr := c.Query("since"); sinceStr != "" {
+		parsed, err := time.Parse(time.RFC3339, sinceStr)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, CodeInvalidInput, "Invalid since value, expected RFC 3339 timestamp")
+			return
+		}
+		since = parsed
+	}
+
+	deleted, err := h.itemService.GetDeletedSince(userEmail.(string), since)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to get deleted items")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": deleted})
+}
+
+// MoveItem handles moving an item under a new parent, or to the top level when parent is null
+func (h *Handlers) MoveItem(c *gin.Context) {
+	userEmail, exists := c.Get("user_email")
+	if !exists {
+		respondError(c, http.StatusUnauthorized, CodeUnauthenticated, "User not authenticated")
+		return
+	}
+
+	itemID, err := strconv.ParseUint(c.Param("item_id"), 10, 32)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, CodeInvalidInput, "Invalid item ID")
+		return
+	}
+
+	var req ItemMoveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondInvalidInput(c, err)
+		return
+	}
+
+	moved, err := h.itemService.MoveItem(uint(itemID), userEmail.(string), req.ParentID)
+	if err != nil {
+		switch {
+		case errors.Is(err, item.ErrItemNotFound):
+			respondError(c, http.StatusNotFound, CodeNotFound, "Item not found")
+		case errors.Is(err, item.ErrParentNotFound):
+			respondError(c, http.StatusNotFound, CodeNotFound, "Parent item not found")
+		case errors.Is(err, item.ErrParentCycle):
+			respondError(c, http.StatusConflict, CodeParentCycle, "Item cannot be moved under itself or one of its descendants")
+		default:
+			respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to move item")
+		}
+		return
+	}
+
+	h.webhooks.Send(webhook.EventItemUpdated, moved)
+
+	c.JSON(http.StatusOK, moved)
+}
+
+// GetItemTree handles getting the user's items as a nested tree
+func (h *Handlers) GetItemTree(c *gin.Context) {
+	userEmail, exists := c.Get("user_email")
+	if !exists {
+		respondError(c, http.StatusUnauthorized, CodeUnauthenticated, "User not authenticated")
+		return
+	}
+
+	tree, err := h.itemService.GetItemTree(userEmail.(string))
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to get item tree")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": tree})
+}
+
+// AssignItemsToOrganization handles assigning all of the user's items to an organization
+func (h *Handlers) AssignItemsToOrganization(c *gin.Context) {
+	userEmail, exists := c.Get("user_email")
+	if !exists {
+		respondError(c, http.StatusUnauthorized, CodeUnauthenticated, "User not authenticated")
+		return
+	}
+
+	var req AssignOrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondInvalidInput(c, err)
+		return
+	}
+
+	if _, err := h.organizationService.GetOrganization(req.OrganizationID); err != nil {
+		if errors.Is(err, organization.ErrOrganizationNotFound) {
+			respondError(c, http.StatusNotFound, CodeNotFound, "Organization not found")
+			return
+		}
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to get organization")
+		return
+	}
+
+	isMember, err := h.organizationService.IsMember(req.OrganizationID, userEmail.(string))
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to check membership")
+		return
+	}
+	if !isMember {
+		respondError(c, http.StatusForbidden, CodeNotMember, "Not a member of this organization")
+		return
+	}
+
+	var updated int64
+	err = database.WithTx(h.db, func(tx *gorm.DB) error {
+		result := tx.Model(&database.Item{}).Where("user_email = ?", userEmail).Update("organization_id", req.OrganizationID)
+		updated = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to assign items")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"organization_id": req.OrganizationID, "updated": updated})
+}
+
+// tagSortOrders maps the supported tag sort values to ORDER BY clauses
+var tagSortOrders = map[string]string{
+	"name":        "LOWER(tags.name) ASC, tags.id ASC",
+	"-name":       "LOWER(tags.name) DESC, tags.id DESC",
+	"created_at":  "tags.created_at ASC, tags.id ASC",
+	"-created_at": "tags.created_at DESC, tags.id DESC",
+}
+
+// GetTags handles getting all tags for the user's organization, with how many items use each
 func (h *Handlers) GetTags(c *gin.Context) {
 	userEmail, exists := c.Get("user_email")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		respondError(c, http.StatusUnauthorized, CodeUnauthenticated, "User not authenticated")
+		return
+	}
+
+	order, ok := tagSortOrders[c.DefaultQuery("sort", "name")]
+	if !ok {
+		respondError(c, http.StatusBadRequest, CodeInvalidInput, "Invalid sort value")
 		return
 	}
 
 	// Get user's organization
 	var user database.User
 	if err := h.db.Where("email = ?", userEmail).Preload("ActiveOrganization").First(&user).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user"})
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to get user")
 		return
 	}
 
-	var tags []database.Tag
-	if err := h.db.Where("organization_id = ?", user.ActiveOrganizationID).Find(&tags).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get tags"})
+	tags, err := h.tagService.GetTagsWithUsage(user.ActiveOrganizationID, order)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to get tags")
 		return
 	}
 
 	c.JSON(http.StatusOK, tags)
 }
 
+// GetTag handles getting a single tag of the user's organization
+func (h *Handlers) GetTag(c *gin.Context) {
+	userEmail, exists := c.Get("user_email")
+	if !exists {
+		respondError(c, http.StatusUnauthorized, CodeUnauthenticated, "User not authenticated")
+		return
+	}
+
+	tagID, err := strconv.ParseUint(c.Param("tag_id"), 10, 32)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, CodeInvalidInput, "Invalid tag ID")
+		return
+	}
+
+	// Get user's organization
+	var user database.User
+	if err := h.db.Where("email = ?", userEmail).First(&user).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to get user")
+		return
+	}
+
+	found, err := h.tagService.GetTag(uint(tagID))
+	if err != nil && !errors.Is(err, tag.ErrTagNotFound) {
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to get tag")
+		return
+	}
+	// Tags of other organizations are reported as missing
+	if err != nil || found.OrganizationID != user.ActiveOrganizationID {
+		respondError(c, http.StatusNotFound, CodeNotFound, "Tag not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, found)
+}
+
 // CreateTag handles creating a new tag
 func (h *Handlers) CreateTag(c *gin.Context) {
 	userEmail, exists := c.Get("user_email")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		respondError(c, http.StatusUnauthorized, CodeUnauthenticated, "User not authenticated")
 		return
 	}
 
 	var req TagCreateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input: " + err.Error()})
+		respondInvalidInput(c, err)
+		return
+	}
+	if err := tag.ValidateColor(req.Color); err != nil {
+		respondError(c, http.StatusBadRequest, CodeInvalidInput, "Color must be a hex value like #1A2B3C")
 		return
 	}
 
 	// Get user's organization
 	var user database.User
 	if err := h.db.Where("email = ?", userEmail).First(&user).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user"})
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to get user")
+		return
+	}
+
+	taken, err := h.tagService.IsNameTaken(user.ActiveOrganizationID, req.Name, 0)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to create tag")
+		return
+	}
+	if taken {
+		respondError(c, http.StatusConflict, CodeConflict, "Tag already exists")
 		return
 	}
 
 	tag := database.Tag{
 		Name:           req.Name,
+		Color:          req.Color,
+		Icon:           req.Icon,
 		OrganizationID: user.ActiveOrganizationID,
 	}
 
 	if err := h.db.Create(&tag).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create tag"})
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to create tag")
 		return
 	}
 
+	c.Header("Location", "/api/tags/"+strconv.FormatUint(uint64(tag.ID), 10))
 	c.JSON(http.StatusCreated, tag)
 }
 
+// UpdateTag handles changing the name, color or icon of a tag in the user's organization
+func (h *Handlers) UpdateTag(c *gin.Context) {
+	userEmail, exists := c.Get("user_email")
+	if !exists {
+		respondError(c, http.StatusUnauthorized, CodeUnauthenticated, "User not authenticated")
+		return
+	}
+
+	tagID, err := strconv.ParseUint(c.Param("tag_id"), 10, 32)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, CodeInvalidInput, "Invalid tag ID")
+		return
+	}
+
+	var req TagUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondInvalidInput(c, err)
+		return
+	}
+
+	// Get user's organization
+	var user database.User
+	if err := h.db.Where("email = ?", userEmail).First(&user).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to get user")
+		return
+	}
+
+	existing, err := h.tagService.GetTag(uint(tagID))
+	if err != nil && !errors.Is(err, tag.ErrTagNotFound) {
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to get tag")
+		return
+	}
+	if err != nil || existing.OrganizationID != user.ActiveOrganizationID {
+		respondError(c, http.StatusNotFound, CodeNotFound, "Tag not found")
+		return
+	}
+
+	updated, err := h.tagService.UpdateTag(existing.ID, req.Name, req.Color, req.Icon)
+	if err != nil {
+		if errors.Is(err, tag.ErrInvalidColor) {
+			respondError(c, http.StatusBadRequest, CodeInvalidInput, "Color must be a hex value like #1A2B3C")
+			return
+		}
+		if errors.Is(err, tag.ErrTagAlreadyExists) {
+			respondError(c, http.StatusConflict, CodeConflict, "Tag already exists")
+			return
+		}
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to update tag")
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+// DeleteTag handles deleting a tag from the user's organization
+func (h *Handlers) DeleteTag(c *gin.Context) {
+	userEmail, exists := c.Get("user_email")
+	if !exists {
+		respondError(c, http.StatusUnauthorized, CodeUnauthenticated, "User not authenticated")
+		return
+	}
+
+	tagID, err := strconv.ParseUint(c.Param("tag_id"), 10, 32)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, CodeInvalidInput, "Invalid tag ID")
+		return
+	}
+
+	// Get user's organization
+	var user database.User
+	if err := h.db.Where("email = ?", userEmail).First(&user).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to get user")
+		return
+	}
+
+	existing, err := h.tagService.GetTag(uint(tagID))
+	if err != nil && !errors.Is(err, tag.ErrTagNotFound) {
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to get tag")
+		return
+	}
+	if err != nil || existing.OrganizationID != user.ActiveOrganizationID {
+		respondError(c, http.StatusNotFound, CodeNotFound, "Tag not found")
+		return
+	}
+
+	if err := h.tagService.DeleteTag(existing.ID, c.Query("hard") == "true"); err != nil {
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to delete tag")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// MergeTag handles merging a tag into another tag of the user's organization
+func (h *Handlers) MergeTag(c *gin.Context) {
+	userEmail, exists := c.Get("user_email")
+	if !exists {
+		respondError(c, http.StatusUnauthorized, CodeUnauthenticated, "User not authenticated")
+		return
+	}
+
+	tagID, err := strconv.ParseUint(c.Param("tag_id"), 10, 32)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, CodeInvalidInput, "Invalid tag ID")
+		return
+	}
+
+	var req TagMergeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondInvalidInput(c, err)
+		return
+	}
+
+	// Get user's organization
+	var user database.User
+	if err := h.db.Where("email = ?", userEmail).First(&user).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to get user")
+		return
+	}
+
+	merge, err := h.tagService.MergeTags(uint(tagID), req.Into, user.ActiveOrganizationID)
+	if err != nil {
+		switch {
+		case errors.Is(err, tag.ErrMergeIntoSelf):
+			respondError(c, http.StatusBadRequest, CodeInvalidInput, "Cannot merge a tag into itself")
+		case errors.Is(err, tag.ErrTagNotFound):
+			respondError(c, http.StatusNotFound, CodeNotFound, "Tag not found")
+		case errors.Is(err, tag.ErrTagOutsideOrganization):
+			respondError(c, http.StatusForbidden, CodeForbidden, "Tag belongs to another organization")
+		default:
+			respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to merge tags")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, merge)
+}
+
+// RestoreTagMerge handles undoing a recent tag merge in the user's organization
+func (h *Handlers) RestoreTagMerge(c *gin.Context) {
+	userEmail, exists := c.Get("user_email")
+	if !exists {
+		respondError(c, http.StatusUnauthorized, CodeUnauthenticated, "User not authenticated")
+		return
+	}
+
+	mergeID, err := strconv.ParseUint(c.Param("merge_id"), 10, 32)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, CodeInvalidInput, "Invalid merge ID")
+		return
+	}
+
+	// Get user's organization
+	var user database.User
+	if err := h.db.Where("email = ?", userEmail).First(&user).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to get user")
+		return
+	}
+
+	restored, err := h.tagService.RestoreMerge(uint(mergeID), user.ActiveOrganizationID, h.config.Tag.MergeRestoreWindow)
+	if err != nil {
+		switch {
+		case errors.Is(err, tag.ErrMergeNotFound):
+			respondError(c, http.StatusNotFound, CodeNotFound, "Tag merge not found")
+		case errors.Is(err, tag.ErrMergeAlreadyRestored):
+			respondError(c, http.StatusConflict, CodeMergeRestored, "Tag merge already restored")
+		case errors.Is(err, tag.ErrMergeRestoreExpired):
+			respondError(c, http.StatusGone, CodeMergeExpired, "Tag merge is too old to restore")
+		case errors.Is(err, tag.ErrTagAlreadyExists):
+			respondError(c, http.StatusConflict, CodeConflict, "A tag with the merged tag's name already exists")
+		default:
+			respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to restore tag merge")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, restored)
+}
+
 // GetJWTService returns the JWT service for middleware
 func (h *Handlers) GetJWTService() *jwt.Service {
 	return h.jwtService
 }
+
+// GetUserService returns the user service for middleware
+func (h *Handlers) GetUserService() *user.Service {
+	return h.userService
+}
+
+// GetOrganizationService returns the organization service for middleware
+func (h *Handlers) GetOrganizationService() *organization.Service {
+	return h.organizationService
+}
@@ -2,20 +2,34 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"sync"
 	"testing"
 	"time"
 
 	"backend/internal/config"
 	"backend/internal/database"
+	"backend/internal/item"
 	"backend/internal/jwt"
+	"backend/internal/logger"
+	"backend/internal/notify"
+	"backend/internal/organization"
+	"backend/internal/reset"
+	"backend/internal/tag"
 	"backend/internal/user"
+	"backend/internal/webhook"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"go.uber.org/fx/fxtest"
+	"golang.org/x/crypto/bcrypt"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
@@ -28,7 +42,7 @@ func setupTestHandlers(t *testing.T) *Handlers {
 	}
 
 	// Auto migrate all models
-	err = db.AutoMigrate(&database.Organization{}, &database.User{}, &database.Item{}, &database.Tag{}, &database.ResetToken{}, &database.BackPackIdNextNumber{})
+	err = database.AutoMigrate(db)
 	if err != nil {
 		t.Fatalf("Failed to migrate test database: %v", err)
 	}
@@ -40,12 +54,60 @@ func setupTestHandlers(t *testing.T) *Handlers {
 			AccessTokenDuration:  time.Minute * 15,
 			RefreshTokenDuration: time.Hour * 24,
 		},
+		Upload: config.UploadConfig{
+			Dir: t.TempDir(),
+		},
+		Security: config.SecurityConfig{
+			BcryptCost: bcrypt.MinCost,
+		},
+		Search: config.SearchConfig{
+			ResultLimit: 20,
+		},
+		Tag: config.TagConfig{
+			MergeRestoreWindow: time.Hour,
+		},
+		Organization: config.OrganizationConfig{
+			InvitationTTL: time.Minute * 5,
+		},
+	}
+
+	userService := user.NewUserService(db, cfg)
+	jwtService, err := jwt.NewJWTService(cfg, db, logger.Discard())
+	if err != nil {
+		t.Fatalf("Failed to create JWT service: %v", err)
 	}
+	organizationService := organization.NewOrganizationService(db)
+	resetService := reset.NewResetService(db, logger.Discard())
 
-	userService := user.NewUserService(db)
-	jwtService := jwt.NewJWTService(cfg)
+	webhooks := webhook.NewDispatcher(fxtest.NewLifecycle(t), cfg, logger.Discard())
 
-	return NewHandlers(userService, jwtService, db)
+	return NewHandlers(userService, jwtService, item.NewItemService(db, cfg), tag.NewTagService(db), organizationService, resetService, &fakeMailer{}, webhooks, db, cfg, logger.Discard())
+}
+
+// fakeMailer captures sent messages instead of delivering them
+type fakeMailer struct {
+	mu   sync.Mutex
+	sent []notify.Message
+}
+
+func (m *fakeMailer) Send(msg notify.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+// sentTo returns the messages sent to the given address
+func (m *fakeMailer) sentTo(to string) []notify.Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var sent []notify.Message
+	for _, msg := range m.sent {
+		if msg.To == to {
+			sent = append(sent, msg)
+		}
+	}
+	return sent
 }
 
 func setupGinContext() (*gin.Context, *httptest.ResponseRecorder) {
@@ -105,7 +167,7 @@ func TestRegisterUser_InvalidEmail(t *testing.T) {
 	var response map[string]interface{}
 	err := json.Unmarshal(w.Body.Bytes(), &response)
 	assert.NoError(t, err)
-	assert.Contains(t, response["error"].(string), "Invalid input")
+	assert.Equal(t, CodeInvalidInput, response["code"])
 }
 
 func TestRegisterUser_ShortPassword(t *testing.T) {
@@ -128,7 +190,8 @@ func TestRegisterUser_ShortPassword(t *testing.T) {
 	var response map[string]interface{}
 	err := json.Unmarshal(w.Body.Bytes(), &response)
 	assert.NoError(t, err)
-	assert.Contains(t, response["error"].(string), "Invalid input")
+	assert.Equal(t, CodePasswordTooShort, response["code"])
+	assert.Equal(t, "Password must be at least 8 characters", response["message"])
 }
 
 func TestRegisterUser_DuplicateUser(t *testing.T) {
@@ -152,13 +215,12 @@ func TestRegisterUser_DuplicateUser(t *testing.T) {
 	c2.Request.Header.Set("Content-Type", "application/json")
 	handlers.RegisterUser(c2)
 
-	// SQLite returns 500 instead of 409 for duplicate key constraint
-	assert.Equal(t, http.StatusInternalServerError, w2.Code)
+	assert.Equal(t, http.StatusConflict, w2.Code)
 
 	var response map[string]interface{}
 	err := json.Unmarshal(w2.Body.Bytes(), &response)
 	assert.NoError(t, err)
-	assert.Equal(t, "Failed to create user", response["error"])
+	assert.Equal(t, CodeUserExists, response["code"])
 }
 
 func TestLogin_Success(t *testing.T) {
@@ -197,6 +259,30 @@ func TestLogin_Success(t *testing.T) {
 	assert.NotEmpty(t, response.RefreshToken)
 }
 
+func TestLogin_LogsWithoutSecrets(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	var logs bytes.Buffer
+	handlers.logger = logger.New(&logs, "debug", true)
+	assert.NoError(t, handlers.userService.CreateUser("test@example.com", "password123"))
+
+	c, w := setupGinContext()
+	body, _ := json.Marshal(LoginRequest{Email: "test@example.com", Password: "password123"})
+	c.Request = httptest.NewRequest("POST", "/login", bytes.NewBuffer(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	handlers.Login(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var tokens jwt.TokenResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &tokens))
+
+	output := logs.String()
+	assert.Contains(t, output, `"msg":"login succeeded"`)
+	assert.Contains(t, output, `"email":"test@example.com"`)
+	assert.NotContains(t, output, "password123")
+	assert.NotContains(t, output, tokens.Token)
+	assert.NotContains(t, output, tokens.RefreshToken)
+}
+
 func TestLogin_InvalidCredentials(t *testing.T) {
 	handlers := setupTestHandlers(t)
 	c, w := setupGinContext()
@@ -217,7 +303,7 @@ func TestLogin_InvalidCredentials(t *testing.T) {
 	var response map[string]interface{}
 	err := json.Unmarshal(w.Body.Bytes(), &response)
 	assert.NoError(t, err)
-	assert.Equal(t, "Invalid credentials", response["error"])
+	assert.Equal(t, CodeInvalidCredential, response["code"])
 }
 
 func TestLogin_InvalidEmail(t *testing.T) {
@@ -240,7 +326,7 @@ func TestLogin_InvalidEmail(t *testing.T) {
 	var response map[string]interface{}
 	err := json.Unmarshal(w.Body.Bytes(), &response)
 	assert.NoError(t, err)
-	assert.Contains(t, response["error"].(string), "Invalid input")
+	assert.Equal(t, CodeInvalidInput, response["code"])
 }
 
 func TestRefreshToken_Success(t *testing.T) {
@@ -294,6 +380,39 @@ func TestRefreshToken_Success(t *testing.T) {
 	}
 }
 
+func TestRefreshToken_LogsWithoutSecrets(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	var logs bytes.Buffer
+	handlers.logger = logger.New(&logs, "debug", false)
+	assert.NoError(t, handlers.userService.CreateUser("test@example.com", "password123"))
+	issued, err := handlers.jwtService.IssueTokenPair("test@example.com")
+	assert.NoError(t, err)
+
+	refresh := func(token string) *httptest.ResponseRecorder {
+		c, w := setupGinContext()
+		body, _ := json.Marshal(RefreshRequest{RefreshToken: token})
+		c.Request = httptest.NewRequest("POST", "/refresh", bytes.NewBuffer(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		handlers.RefreshToken(c)
+		return w
+	}
+
+	w := refresh(issued.RefreshToken)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var rotated jwt.TokenResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &rotated))
+
+	// A rejected token must not be logged either
+	assert.Equal(t, http.StatusUnauthorized, refresh("not-a-real-refresh-token").Code)
+
+	output := logs.String()
+	assert.Contains(t, output, "tokens refreshed")
+	assert.Contains(t, output, fmt.Sprintf("token_length=%d", len(issued.RefreshToken)))
+	for _, secret := range []string{issued.RefreshToken, rotated.Token, rotated.RefreshToken, "not-a-real-refresh-token", "password123"} {
+		assert.NotContains(t, output, secret)
+	}
+}
+
 func TestRefreshToken_InvalidToken(t *testing.T) {
 	handlers := setupTestHandlers(t)
 	c, w := setupGinContext()
@@ -313,7 +432,7 @@ func TestRefreshToken_InvalidToken(t *testing.T) {
 	var response map[string]interface{}
 	err := json.Unmarshal(w.Body.Bytes(), &response)
 	assert.NoError(t, err)
-	assert.Equal(t, "Invalid refresh token", response["error"])
+	assert.Equal(t, CodeInvalidToken, response["code"])
 }
 
 func TestRefreshToken_UserNotFound(t *testing.T) {
@@ -379,7 +498,7 @@ func TestRefreshToken_UserNotFound(t *testing.T) {
 	err := json.Unmarshal(w.Body.Bytes(), &response)
 	assert.NoError(t, err)
 	// Accept either error message
-	assert.Contains(t, []string{"User not found", "Invalid refresh token"}, response["error"])
+	assert.Contains(t, []string{CodeUnauthenticated, CodeInvalidToken}, response["code"])
 }
 
 // New tests for additional endpoints
@@ -408,6 +527,78 @@ func TestVerifyToken_Success(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, true, response["valid"])
 	assert.Equal(t, "test@example.com", response["email"])
+	assert.Equal(t, "access", response["token_type"])
+	assert.NotEmpty(t, response["expires_at"])
+}
+
+// verifyTokenRequest posts a token to the given verification handler
+func verifyTokenRequest(handler gin.HandlerFunc, token string) *httptest.ResponseRecorder {
+	c, w := setupGinContext()
+	jsonBody, _ := json.Marshal(VerifyRequest{Token: token})
+	c.Request = httptest.NewRequest("POST", "/verify", bytes.NewBuffer(jsonBody))
+	c.Request.Header.Set("Content-Type", "application/json")
+	handler(c)
+	return w
+}
+
+func TestVerifyToken_AccessToken(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	tokens, err := handlers.jwtService.IssueTokenPair("test@example.com")
+	assert.NoError(t, err)
+
+	for _, handler := range []gin.HandlerFunc{handlers.VerifyToken, handlers.IntrospectToken} {
+		w := verifyTokenRequest(handler, tokens.Token)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "access", response["token_type"])
+		assert.Equal(t, "test@example.com", response["email"])
+
+		expiresAt, err := time.Parse(time.RFC3339, response["expires_at"].(string))
+		assert.NoError(t, err)
+		assert.WithinDuration(t, time.Now().Add(handlers.jwtService.GetAccessTokenDuration()), expiresAt, time.Minute)
+	}
+}
+
+func TestVerifyToken_RefreshToken(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	tokens, err := handlers.jwtService.IssueTokenPair("test@example.com")
+	assert.NoError(t, err)
+
+	// The access token endpoint rejects refresh tokens
+	w := verifyTokenRequest(handlers.VerifyToken, tokens.RefreshToken)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = verifyTokenRequest(handlers.IntrospectToken, tokens.RefreshToken)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, true, response["valid"])
+	assert.Equal(t, "refresh", response["token_type"])
+	assert.Equal(t, "test@example.com", response["email"])
+
+	// A rotated refresh token is no longer valid
+	_, err = handlers.jwtService.RotateRefreshToken(tokens.RefreshToken)
+	assert.NoError(t, err)
+	w = verifyTokenRequest(handlers.IntrospectToken, tokens.RefreshToken)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestVerifyToken_ExpiredToken(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	token, err := handlers.jwtService.GenerateToken("test@example.com", -time.Hour)
+	assert.NoError(t, err)
+
+	for _, handler := range []gin.HandlerFunc{handlers.VerifyToken, handlers.IntrospectToken} {
+		w := verifyTokenRequest(handler, token)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+		var response map[string]interface{}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, CodeInvalidToken, response["code"])
+	}
 }
 
 func TestVerifyToken_InvalidToken(t *testing.T) {
@@ -428,7 +619,7 @@ func TestVerifyToken_InvalidToken(t *testing.T) {
 	var response map[string]interface{}
 	err := json.Unmarshal(w.Body.Bytes(), &response)
 	assert.NoError(t, err)
-	assert.Equal(t, "Invalid token", response["error"])
+	assert.Equal(t, CodeInvalidToken, response["code"])
 }
 
 func TestGetUserStatistics_Success(t *testing.T) {
@@ -480,6 +671,8 @@ func TestRequestPasswordReset_Success(t *testing.T) {
 	c.Request = httptest.NewRequest("POST", "/register", bytes.NewBuffer(jsonBody))
 	c.Request.Header.Set("Content-Type", "application/json")
 	handlers.RegisterUser(c)
+	// Let the verification email go out first
+	handlers.emails.Wait()
 
 	// Request password reset
 	w = httptest.NewRecorder()
@@ -498,28 +691,70 @@ func TestRequestPasswordReset_Success(t *testing.T) {
 	var response map[string]interface{}
 	err := json.Unmarshal(w.Body.Bytes(), &response)
 	assert.NoError(t, err)
-	assert.Equal(t, "Password reset token sent", response["message"])
+	assert.Equal(t, "If that account exists, a reset token was sent", response["message"])
+
+	// The stored token is emailed to the user
+	var resetToken database.ResetToken
+	assert.NoError(t, handlers.db.Where("user_email = ?", "test@example.com").First(&resetToken).Error)
+	// The email is sent in the background
+	handlers.emails.Wait()
+	mailer := handlers.mailer.(*fakeMailer)
+	last := mailer.sent[len(mailer.sent)-1]
+	assert.Equal(t, "test@example.com", last.To)
+	assert.Contains(t, last.Body, resetToken.Token)
+}
+
+// blockingMailer holds every send until release is closed
+type blockingMailer struct {
+	release chan struct{}
+}
+
+func (m *blockingMailer) Send(msg notify.Message) error {
+	<-m.release
+	return nil
 }
 
-func TestRequestPasswordReset_UserNotFound(t *testing.T) {
+func TestWaitForEmails(t *testing.T) {
 	handlers := setupTestHandlers(t)
-	c, w := setupGinContext()
+	mailer := &blockingMailer{release: make(chan struct{})}
+	handlers.mailer = mailer
+	handlers.sendEmailAsync(notify.Message{To: "test@example.com", Subject: "Hello"})
 
-	resetReq := PasswordResetRequest{
-		Username: "nonexistent@test.com",
+	// The stop deadline bounds the wait
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, handlers.WaitForEmails(ctx), context.DeadlineExceeded)
+
+	close(mailer.release)
+	assert.NoError(t, handlers.WaitForEmails(context.Background()))
+}
+
+func TestRequestPasswordReset_SameResponseForUnknownEmail(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	assert.NoError(t, handlers.userService.CreateUser("test@example.com", "password123"))
+
+	request := func(email string) *httptest.ResponseRecorder {
+		c, w := setupGinContext()
+		body, _ := json.Marshal(PasswordResetRequest{Username: email})
+		c.Request = httptest.NewRequest("POST", "/reset-password", bytes.NewBuffer(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		handlers.RequestPasswordReset(c)
+		return w
 	}
-	resetJson, _ := json.Marshal(resetReq)
-	c.Request = httptest.NewRequest("POST", "/reset-password", bytes.NewBuffer(resetJson))
-	c.Request.Header.Set("Content-Type", "application/json")
 
-	handlers.RequestPasswordReset(c)
+	existing := request("test@example.com")
+	unknown := request("nonexistent@test.com")
 
-	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, http.StatusOK, existing.Code)
+	assert.Equal(t, existing.Code, unknown.Code)
+	assert.Equal(t, existing.Body.String(), unknown.Body.String())
 
-	var response map[string]interface{}
-	err := json.Unmarshal(w.Body.Bytes(), &response)
-	assert.NoError(t, err)
-	assert.Equal(t, "User not found", response["error"])
+	// Only the real user gets a token
+	var count int64
+	handlers.db.Model(&database.ResetToken{}).Where("user_email = ?", "nonexistent@test.com").Count(&count)
+	assert.Zero(t, count)
+	handlers.db.Model(&database.ResetToken{}).Where("user_email = ?", "test@example.com").Count(&count)
+	assert.Equal(t, int64(1), count)
 }
 
 func TestSetNewPassword_Success(t *testing.T) {
@@ -571,6 +806,45 @@ func TestSetNewPassword_Success(t *testing.T) {
 	err = json.Unmarshal(w.Body.Bytes(), &response)
 	assert.NoError(t, err)
 	assert.Equal(t, "Password updated successfully", response["message"])
+	assert.NoError(t, handlers.userService.ValidateUser("test@example.com", "newpassword123"))
+
+	// The token is used up
+	_, err = handlers.resetService.ValidateResetToken(resetToken.Token)
+	assert.ErrorIs(t, err, reset.ErrResetTokenNotFound)
+}
+
+func TestSetNewPassword_RevokesRefreshTokens(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	tokens := loginTestUser(t, handlers, "test@example.com")
+	token, err := handlers.resetService.CreateResetToken("test@example.com")
+	assert.NoError(t, err)
+
+	c, w := setupGinContext()
+	body, _ := json.Marshal(NewPasswordRequest{Password: "newpassword123", Token: token})
+	c.Request = httptest.NewRequest("POST", "/send-password", bytes.NewBuffer(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	handlers.SetNewPassword(c)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	assert.Equal(t, http.StatusUnauthorized, refreshTestTokens(handlers, tokens.RefreshToken).Code)
+}
+
+func TestSetNewPassword_ExpiredToken(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	createAuthenticatedRequest(handlers, "GET", "/", nil)
+	token, err := handlers.resetService.CreateResetTokenWithExpiry("auth@example.com", -time.Minute)
+	assert.NoError(t, err)
+
+	c, w := setupGinContext()
+	body, _ := json.Marshal(NewPasswordRequest{Password: "newpassword123", Token: token})
+	c.Request = httptest.NewRequest("POST", "/send-password", bytes.NewBuffer(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	handlers.SetNewPassword(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), CodeInvalidToken)
+	assert.NoError(t, handlers.userService.ValidateUser("auth@example.com", "password123"))
 }
 
 func TestSetNewPassword_InvalidToken(t *testing.T) {
@@ -592,7 +866,99 @@ func TestSetNewPassword_InvalidToken(t *testing.T) {
 	var response map[string]interface{}
 	err := json.Unmarshal(w.Body.Bytes(), &response)
 	assert.NoError(t, err)
-	assert.Equal(t, "Invalid or expired token", response["error"])
+	assert.Equal(t, CodeInvalidToken, response["code"])
+}
+
+func TestChangePassword_Success(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	body, _ := json.Marshal(ChangePasswordRequest{OldPassword: "password123", NewPassword: "newpassword123"})
+	c, w := createAuthenticatedRequest(handlers, "POST", "/users/password", body)
+
+	handlers.ChangePassword(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, handlers.userService.ValidateUser("auth@example.com", "newpassword123"))
+	assert.ErrorIs(t, handlers.userService.ValidateUser("auth@example.com", "password123"), user.ErrInvalidCredentials)
+}
+
+func TestChangePassword_RevokesRefreshTokens(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	tokens := loginTestUser(t, handlers, "auth@example.com")
+
+	body, _ := json.Marshal(ChangePasswordRequest{OldPassword: "password123", NewPassword: "newpassword123"})
+	c, w := createAuthenticatedRequest(handlers, "POST", "/users/password", body)
+	handlers.ChangePassword(c)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	assert.Equal(t, http.StatusUnauthorized, refreshTestTokens(handlers, tokens.RefreshToken).Code)
+}
+
+func TestChangePassword_WrongOldPassword(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	body, _ := json.Marshal(ChangePasswordRequest{OldPassword: "wrongpassword", NewPassword: "newpassword123"})
+	c, w := createAuthenticatedRequest(handlers, "POST", "/users/password", body)
+
+	handlers.ChangePassword(c)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.NoError(t, handlers.userService.ValidateUser("auth@example.com", "password123"))
+}
+
+func TestChangePassword_ShortNewPassword(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	body, _ := json.Marshal(ChangePasswordRequest{OldPassword: "password123", NewPassword: "123"})
+	c, w := createAuthenticatedRequest(handlers, "POST", "/users/password", body)
+
+	handlers.ChangePassword(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.NoError(t, handlers.userService.ValidateUser("auth@example.com", "password123"))
+}
+
+func TestPasswordFlows_RejectShortPassword(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	// Register the authenticated user so a reset token can be issued for them
+	createAuthenticatedRequest(handlers, "GET", "/", nil)
+	token, err := handlers.resetService.CreateResetTokenWithExpiry("auth@example.com", time.Hour)
+	assert.NoError(t, err)
+
+	flows := map[string]func() *httptest.ResponseRecorder{
+		"register": func() *httptest.ResponseRecorder {
+			c, w := setupGinContext()
+			c.Request = httptest.NewRequest("POST", "/users", bytes.NewBufferString(`{"email":"short@example.com","password":"1234567"}`))
+			c.Request.Header.Set("Content-Type", "application/json")
+			handlers.RegisterUser(c)
+			return w
+		},
+		"reset": func() *httptest.ResponseRecorder {
+			c, w := setupGinContext()
+			body, _ := json.Marshal(NewPasswordRequest{Password: "1234567", Token: token})
+			c.Request = httptest.NewRequest("POST", "/send-password", bytes.NewBuffer(body))
+			c.Request.Header.Set("Content-Type", "application/json")
+			handlers.SetNewPassword(c)
+			return w
+		},
+		"change": func() *httptest.ResponseRecorder {
+			body, _ := json.Marshal(ChangePasswordRequest{OldPassword: "password123", NewPassword: "1234567"})
+			c, w := createAuthenticatedRequest(handlers, "POST", "/users/password", body)
+			handlers.ChangePassword(c)
+			return w
+		},
+	}
+
+	for name, flow := range flows {
+		w := flow()
+		assert.Equal(t, http.StatusBadRequest, w.Code, name)
+		var response map[string]interface{}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response), name)
+		assert.Equal(t, CodePasswordTooShort, response["code"], name)
+		assert.Equal(t, "Password must be at least 8 characters", response["message"], name)
+	}
+
+	// Nothing was changed or created
+	assert.NoError(t, handlers.userService.ValidateUser("auth@example.com", "password123"))
+	_, err = handlers.userService.GetUser("short@example.com")
+	assert.ErrorIs(t, err, user.ErrUserNotFound)
 }
 
 // Helper function to create authenticated request
@@ -632,6 +998,33 @@ func createAuthenticatedRequest(handlers *Handlers, method, path string, body []
 	return c, w
 }
 
+// createTestItem creates an item for the authenticated test user
+func createTestItem(t *testing.T, handlers *Handlers, name string) database.Item {
+	c, w := createAuthenticatedRequest(handlers, "POST", "/items", []byte(`{"name":"`+name+`"}`))
+	handlers.CreateItem(c)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Failed to create item: %s", w.Body.String())
+	}
+
+	var item database.Item
+	json.Unmarshal(w.Body.Bytes(), &item)
+	return item
+}
+
+// createTestTag creates a tag in the authenticated test user's organization
+func createTestTag(t *testing.T, handlers *Handlers, name, color string) database.Tag {
+	body, _ := json.Marshal(TagCreateRequest{Name: name, Color: color})
+	c, w := createAuthenticatedRequest(handlers, "POST", "/tags", body)
+	handlers.CreateTag(c)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Failed to create tag: %s", w.Body.String())
+	}
+
+	var tag database.Tag
+	json.Unmarshal(w.Body.Bytes(), &tag)
+	return tag
+}
+
 // Items tests
 func TestGetItems_Success(t *testing.T) {
 	handlers := setupTestHandlers(t)
@@ -641,10 +1034,10 @@ func TestGetItems_Success(t *testing.T) {
 
 	assert.Equal(t, http.StatusOK, w.Code)
 
-	var response []database.Item
+	var response map[string][]database.Item
 	err := json.Unmarshal(w.Body.Bytes(), &response)
 	assert.NoError(t, err)
-	assert.Len(t, response, 0) // No items initially
+	assert.Len(t, response["items"], 0) // No items initially
 }
 
 func TestGetItems_WithNameFilter(t *testing.T) {
@@ -656,117 +1049,648 @@ func TestGetItems_WithNameFilter(t *testing.T) {
 	assert.Equal(t, http.StatusCreated, w.Code)
 
 	// Get items with name filter
-	c, w = createAuthenticatedRequest(handlers, "GET", "/items?name=Test", nil)
+	c, w = createAuthenticatedRequest(handlers, "GET", "/items?name=tEST", nil)
 	handlers.GetItems(c)
 
 	assert.Equal(t, http.StatusOK, w.Code)
 
-	var response []database.Item
+	var response map[string][]database.Item
 	err := json.Unmarshal(w.Body.Bytes(), &response)
 	assert.NoError(t, err)
-	assert.Len(t, response, 1)
-	assert.Equal(t, "Test Item", response[0].Name)
+	assert.Len(t, response["items"], 1)
+	assert.Equal(t, "Test Item", response["items"][0].Name)
 }
 
-func TestCreateItem_Success(t *testing.T) {
+func TestGetItems_NameFilterWildcardsMatchLiterally(t *testing.T) {
 	handlers := setupTestHandlers(t)
-	c, w := createAuthenticatedRequest(handlers, "POST", "/items", []byte(`{"name":"Test Item","description":"Test Description"}`))
+	for _, name := range []string{"100% wool socks", "1000 matches", "gear_box", "gear box"} {
+		createTestItem(t, handlers, name)
+	}
 
-	handlers.CreateItem(c)
+	for filter, want := range map[string][]string{
+		"100%":  {"100% wool socks"},
+		"gear_": {"gear_box"},
+	} {
+		c, w := createAuthenticatedRequest(handlers, "GET", "/items?name="+url.QueryEscape(filter), nil)
+		handlers.GetItems(c)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, want, itemNames(t, w), filter)
+	}
+}
 
-	assert.Equal(t, http.StatusCreated, w.Code)
+func TestGetItems_QuantityBelowThreshold(t *testing.T) {
+	handlers := setupTestHandlers(t)
 
-	var response database.Item
-	err := json.Unmarshal(w.Body.Bytes(), &response)
-	assert.NoError(t, err)
-	assert.Equal(t, "Test Item", response.Name)
-	assert.Equal(t, "Test Description", response.Description)
-	assert.NotEmpty(t, response.BackpackID)
+	for name, quantity := range map[string]int{"Screws": 2, "Nails": 50} {
+		c, w := createAuthenticatedRequest(handlers, "POST", "/items", []byte(fmt.Sprintf(`{"name":%q}`, name)))
+		handlers.CreateItem(c)
+		assert.Equal(t, http.StatusCreated, w.Code)
+		var created database.Item
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+		assert.NoError(t, handlers.db.Model(&created).Update("quantity", quantity).Error)
+	}
+
+	c, w := createAuthenticatedRequest(handlers, "GET", "/items?quantity_lt=5", nil)
+	handlers.GetItems(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response map[string][]database.Item
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Len(t, response["items"], 1)
+	assert.Equal(t, "Screws", response["items"][0].Name)
+
+	c, w = createAuthenticatedRequest(handlers, "GET", "/items?quantity_gt=2&name=Screws", nil)
+	handlers.GetItems(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Len(t, response["items"], 0)
 }
 
-func TestCreateItem_InvalidInput(t *testing.T) {
+func TestGetItems_InvalidQuantityThreshold(t *testing.T) {
 	handlers := setupTestHandlers(t)
-	c, w := createAuthenticatedRequest(handlers, "POST", "/items", []byte(`{"description":"Test Description"}`))
 
-	handlers.CreateItem(c)
+	for _, query := range []string{"quantity_lt=-1", "quantity_gt=abc", "quantity_lt="} {
+		c, w := createAuthenticatedRequest(handlers, "GET", "/items?"+query, nil)
+		handlers.GetItems(c)
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
 
-	assert.Equal(t, http.StatusBadRequest, w.Code)
+// createSortableItems creates items whose names and added_at order disagree
+func createSortableItems(t *testing.T, handlers *Handlers) {
+	base := time.Now().Add(-time.Hour)
+	for i, name := range []string{"banana", "Apple", "cherry"} {
+		c, w := createAuthenticatedRequest(handlers, "POST", "/items", []byte(fmt.Sprintf(`{"name":%q}`, name)))
+		handlers.CreateItem(c)
+		assert.Equal(t, http.StatusCreated, w.Code)
+		var created database.Item
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+		assert.NoError(t, handlers.db.Model(&created).Update("added_at", base.Add(time.Duration(i)*time.Minute)).Error)
+	}
+}
 
-	var response map[string]interface{}
-	err := json.Unmarshal(w.Body.Bytes(), &response)
-	assert.NoError(t, err)
-	assert.Contains(t, response["error"].(string), "Invalid input")
+func itemNames(t *testing.T, w *httptest.ResponseRecorder) []string {
+	var response map[string][]database.Item
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	names := make([]string, 0, len(response["items"]))
+	for _, i := range response["items"] {
+		names = append(names, i.Name)
+	}
+	return names
 }
 
-func TestGetItem_Success(t *testing.T) {
+func TestGetItems_DefaultNewestFirst(t *testing.T) {
 	handlers := setupTestHandlers(t)
+	createSortableItems(t, handlers)
 
-	// Create an item first
-	c, w := createAuthenticatedRequest(handlers, "POST", "/items", []byte(`{"name":"Test Item","description":"Test Description"}`))
-	handlers.CreateItem(c)
+	c, w := createAuthenticatedRequest(handlers, "GET", "/items", nil)
+	handlers.GetItems(c)
 
-	var createdItem database.Item
-	json.Unmarshal(w.Body.Bytes(), &createdItem)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{"cherry", "Apple", "banana"}, itemNames(t, w))
+}
 
-	// Get the item
-	c, w = createAuthenticatedRequest(handlers, "GET", "/items/"+fmt.Sprintf("%d", createdItem.ID), nil)
-	c.Params = gin.Params{{Key: "item_id", Value: fmt.Sprintf("%d", createdItem.ID)}}
-	handlers.GetItem(c)
+func TestGetItems_Sort(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	createSortableItems(t, handlers)
 
-	assert.Equal(t, http.StatusOK, w.Code)
+	tests := map[string][]string{
+		"name":     {"Apple", "banana", "cherry"},
+		"-name":    {"cherry", "banana", "Apple"},
+		"added_at": {"banana", "Apple", "cherry"},
+	}
+	for sort, expected := range tests {
+		c, w := createAuthenticatedRequest(handlers, "GET", "/items?sort="+sort, nil)
+		handlers.GetItems(c)
 
-	var response database.Item
-	err := json.Unmarshal(w.Body.Bytes(), &response)
-	assert.NoError(t, err)
-	assert.Equal(t, "Test Item", response.Name)
-	assert.Equal(t, createdItem.ID, response.ID)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, expected, itemNames(t, w), sort)
+	}
 }
 
-func TestGetItem_NotFound(t *testing.T) {
+func TestGetItems_InvalidSort(t *testing.T) {
 	handlers := setupTestHandlers(t)
-	c, w := createAuthenticatedRequest(handlers, "GET", "/items/999", nil)
-	c.Params = gin.Params{{Key: "item_id", Value: "999"}}
-
-	handlers.GetItem(c)
 
-	assert.Equal(t, http.StatusNotFound, w.Code)
+	c, w := createAuthenticatedRequest(handlers, "GET", "/items?sort=password", nil)
+	handlers.GetItems(c)
 
-	var response map[string]interface{}
-	err := json.Unmarshal(w.Body.Bytes(), &response)
-	assert.NoError(t, err)
-	assert.Equal(t, "Item not found", response["error"])
+	assert.Equal(t, http.StatusBadRequest, w.Code)
 }
 
-func TestUpdateItem_Success(t *testing.T) {
+func TestGetItems_FilterByTags(t *testing.T) {
 	handlers := setupTestHandlers(t)
+	camping := createTestTag(t, handlers, "camping", "")
+	cooking := createTestTag(t, handlers, "cooking", "")
+	tent := createTestItem(t, handlers, "Tent")
+	stove := createTestItem(t, handlers, "Stove")
+	pan := createTestItem(t, handlers, "Pan")
+	createTestItem(t, handlers, "Kayak")
+	handlers.db.Model(&tent).Association("Tags").Append(&camping)
+	handlers.db.Model(&stove).Association("Tags").Append(&camping, &cooking)
+	handlers.db.Model(&pan).Association("Tags").Append(&cooking)
+
+	tests := map[string][]string{
+		fmt.Sprintf("tags=%d", camping.ID):                               {"Tent", "Stove"},
+		fmt.Sprintf("tags=%d&tags=%d", camping.ID, cooking.ID):           {"Tent", "Stove", "Pan"},
+		fmt.Sprintf("tags=%d&tags=%d&match=any", camping.ID, cooking.ID): {"Tent", "Stove", "Pan"},
+		fmt.Sprintf("tags=%d&tags=%d&match=all", camping.ID, cooking.ID): {"Stove"},
+		fmt.Sprintf("tags=%d&tags=%d&match=all", camping.ID, camping.ID): {"Tent", "Stove"},
+	}
+	for query, expected := range tests {
+		c, w := createAuthenticatedRequest(handlers, "GET", "/items?"+query, nil)
+		handlers.GetItems(c)
 
-	// Create an item first
-	c, w := createAuthenticatedRequest(handlers, "POST", "/items", []byte(`{"name":"Original Name","description":"Original Description"}`))
-	handlers.CreateItem(c)
-
-	var createdItem database.Item
-	json.Unmarshal(w.Body.Bytes(), &createdItem)
+		assert.Equal(t, http.StatusOK, w.Code, query)
+		assert.ElementsMatch(t, expected, itemNames(t, w), query)
+	}
+}
 
-	// Update the item
-	c, w = createAuthenticatedRequest(handlers, "PATCH", "/items/"+fmt.Sprintf("%d", createdItem.ID), []byte(`{"name":"Updated Name","description":"Updated Description"}`))
-	c.Params = gin.Params{{Key: "item_id", Value: fmt.Sprintf("%d", createdItem.ID)}}
-	handlers.UpdateItem(c)
+func TestGetItems_InvalidTagFilter(t *testing.T) {
+	handlers := setupTestHandlers(t)
 
-	assert.Equal(t, http.StatusOK, w.Code)
+	for _, query := range []string{"tags=abc", "tags=1&match=some"} {
+		c, w := createAuthenticatedRequest(handlers, "GET", "/items?"+query, nil)
+		handlers.GetItems(c)
 
-	var response database.Item
-	err := json.Unmarshal(w.Body.Bytes(), &response)
-	assert.NoError(t, err)
-	assert.Equal(t, "Updated Name", response.Name)
-	assert.Equal(t, "Updated Description", response.Description)
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
 }
 
-func TestDeleteItem_Success(t *testing.T) {
+func TestGetItems_OrganizationScope(t *testing.T) {
 	handlers := setupTestHandlers(t)
+	c, w := createAuthenticatedRequest(handlers, "GET", "/items?scope=org", nil)
 
-	// Create an item first
-	c, w := createAuthenticatedRequest(handlers, "POST", "/items", []byte(`{"name":"Test Item","description":"Test Description"}`))
-	handlers.CreateItem(c)
+	// auth@example.com and a teammate share a team organization
+	team, err := handlers.organizationService.CreateOrganizationForUser("Team", "auth@example.com")
+	assert.NoError(t, err)
+	assert.NoError(t, handlers.userService.CreateUser("teammate@example.com", "password123"))
+	assert.NoError(t, handlers.organizationService.AddUserToOrganization(team.ID, "teammate@example.com"))
+	assert.NoError(t, handlers.organizationService.SetUserActiveOrganization("teammate@example.com", team.ID))
+	assert.NoError(t, handlers.userService.CreateUser("outsider@example.com", "password123"))
+
+	for email, name := range map[string]string{"auth@example.com": "Tent", "teammate@example.com": "Stove", "outsider@example.com": "Kayak"} {
+		_, err := handlers.itemService.CreateItem(name, "", email, nil)
+		assert.NoError(t, err)
+	}
+
+	handlers.GetItems(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.ElementsMatch(t, []string{"Tent", "Stove"}, itemNames(t, w))
+
+	// The user scope stays the default
+	c, w = createAuthenticatedRequest(handlers, "GET", "/items", nil)
+	handlers.GetItems(c)
+
+	assert.Equal(t, []string{"Tent"}, itemNames(t, w))
+}
+
+func TestGetItems_InvalidScope(t *testing.T) {
+	handlers := setupTestHandlers(t)
+
+	c, w := createAuthenticatedRequest(handlers, "GET", "/items?scope=everyone", nil)
+	handlers.GetItems(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestCreateItem_Success(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	c, w := createAuthenticatedRequest(handlers, "POST", "/items", []byte(`{"name":"Test Item","description":"Test Description"}`))
+
+	handlers.CreateItem(c)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var response database.Item
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, "Test Item", response.Name)
+	assert.Equal(t, "Test Description", response.Description)
+	assert.NotEmpty(t, response.BackpackID)
+	assert.Equal(t, fmt.Sprintf("/api/items/%d", response.ID), w.Header().Get("Location"))
+}
+
+func TestCreateItem_InvalidInput(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	c, w := createAuthenticatedRequest(handlers, "POST", "/items", []byte(`{"description":"Test Description"}`))
+
+	handlers.CreateItem(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var response map[string]interface{}
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, CodeInvalidInput, response["code"])
+}
+
+func TestCreateItem_QuotaExceeded(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	handlers.config.Item.MaxPerUser = 1
+	handlers.itemService = item.NewItemService(handlers.db, handlers.config)
+
+	createTestItem(t, handlers, "Tent")
+
+	c, w := createAuthenticatedRequest(handlers, "POST", "/items", []byte(`{"name":"Stove"}`))
+	handlers.CreateItem(c)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestRestoreItem_QuotaExceeded(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	handlers.config.Item.MaxPerUser = 1
+	handlers.itemService = item.NewItemService(handlers.db, handlers.config)
+
+	created := createTestItem(t, handlers, "Tent")
+	itemID := fmt.Sprintf("%d", created.ID)
+
+	c, _ := createAuthenticatedRequest(handlers, "DELETE", "/items/"+itemID, nil)
+	c.Params = gin.Params{{Key: "item_id", Value: itemID}}
+	handlers.DeleteItem(c)
+	createTestItem(t, handlers, "Stove")
+
+	c, w := createAuthenticatedRequest(handlers, "POST", "/items/"+itemID+"/restore", nil)
+	c.Params = gin.Params{{Key: "item_id", Value: itemID}}
+	handlers.RestoreItem(c)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	var response APIError
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, CodeQuotaExceeded, response.Code)
+}
+
+func TestCreateItems_Success(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	c, w := createAuthenticatedRequest(handlers, "POST", "/items/bulk", []byte(`{"items":[{"name":"Tent"},{"name":"Stove","description":"Gas"}]}`))
+
+	handlers.CreateItems(c)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var response struct {
+		Items []database.Item `json:"items"`
+	}
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	if assert.Len(t, response.Items, 2) {
+		assert.Equal(t, "Tent", response.Items[0].Name)
+		assert.Equal(t, "Gas", response.Items[1].Description)
+		assert.NotEmpty(t, response.Items[0].BackpackID)
+		assert.NotEqual(t, response.Items[0].BackpackID, response.Items[1].BackpackID)
+	}
+}
+
+func TestCreateItems_InvalidEntry(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	c, w := createAuthenticatedRequest(handlers, "POST", "/items/bulk", []byte(`{"items":[{"name":"Tent"},{"description":"No name"}]}`))
+
+	handlers.CreateItems(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var response struct {
+		Code    string `json:"code"`
+		Details struct {
+			Index int `json:"index"`
+		} `json:"details"`
+	}
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, CodeInvalidInput, response.Code)
+	assert.Equal(t, 1, response.Details.Index)
+
+	// The valid entry is rolled back with the invalid one
+	var count int64
+	handlers.db.Model(&database.Item{}).Count(&count)
+	assert.Zero(t, count)
+}
+
+func TestCreateItems_TooMany(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	body, _ := json.Marshal(ItemBulkCreateRequest{Items: make([]ItemCreateRequest, 501)})
+	c, w := createAuthenticatedRequest(handlers, "POST", "/items/bulk", body)
+
+	handlers.CreateItems(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestCreateItem_QuantityAndUnit(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	c, w := createAuthenticatedRequest(handlers, "POST", "/items", []byte(`{"name":"Rice","quantity":3,"unit":"kg"}`))
+
+	handlers.CreateItem(c)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var response database.Item
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, response.Quantity)
+	assert.Equal(t, "kg", response.Unit)
+}
+
+func TestCreateItem_NegativeQuantity(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	c, w := createAuthenticatedRequest(handlers, "POST", "/items", []byte(`{"name":"Rice","quantity":-1}`))
+
+	handlers.CreateItem(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestAdjustItemQuantity(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	created := createTestItem(t, handlers, "Batteries")
+	id := fmt.Sprintf("%d", created.ID)
+
+	adjust := func(body string) *httptest.ResponseRecorder {
+		c, w := createAuthenticatedRequest(handlers, "PATCH", "/items/"+id+"/quantity", []byte(body))
+		c.Params = gin.Params{{Key: "item_id", Value: id}}
+		handlers.AdjustItemQuantity(c)
+		return w
+	}
+
+	// Increment
+	w := adjust(`{"delta":2}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response database.Item
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, 3, response.Quantity)
+
+	// Decrement
+	w = adjust(`{"delta":-3}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, 0, response.Quantity)
+
+	// Below zero
+	w = adjust(`{"delta":-1}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Missing delta
+	w = adjust(`{}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	c, w := createAuthenticatedRequest(handlers, "PATCH", "/items/9999/quantity", []byte(`{"delta":1}`))
+	c.Params = gin.Params{{Key: "item_id", Value: "9999"}}
+	handlers.AdjustItemQuantity(c)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestGetItem_Success(t *testing.T) {
+	handlers := setupTestHandlers(t)
+
+	// Create an item first
+	c, w := createAuthenticatedRequest(handlers, "POST", "/items", []byte(`{"name":"Test Item","description":"Test Description"}`))
+	handlers.CreateItem(c)
+
+	var createdItem database.Item
+	json.Unmarshal(w.Body.Bytes(), &createdItem)
+
+	// Get the item
+	c, w = createAuthenticatedRequest(handlers, "GET", "/items/"+fmt.Sprintf("%d", createdItem.ID), nil)
+	c.Params = gin.Params{{Key: "item_id", Value: fmt.Sprintf("%d", createdItem.ID)}}
+	handlers.GetItem(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response database.Item
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, "Test Item", response.Name)
+	assert.Equal(t, createdItem.ID, response.ID)
+}
+
+func TestGetItem_NotFound(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	c, w := createAuthenticatedRequest(handlers, "GET", "/items/999", nil)
+	c.Params = gin.Params{{Key: "item_id", Value: "999"}}
+
+	handlers.GetItem(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	var response map[string]interface{}
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, CodeNotFound, response["code"])
+}
+
+func TestUpdateItem_Success(t *testing.T) {
+	handlers := setupTestHandlers(t)
+
+	// Create an item first
+	c, w := createAuthenticatedRequest(handlers, "POST", "/items", []byte(`{"name":"Original Name","description":"Original Description"}`))
+	handlers.CreateItem(c)
+
+	var createdItem database.Item
+	json.Unmarshal(w.Body.Bytes(), &createdItem)
+
+	// Update the item
+	c, w = createAuthenticatedRequest(handlers, "PATCH", "/items/"+fmt.Sprintf("%d", createdItem.ID), []byte(`{"name":"Updated Name","description":"Updated Description","version":1}`))
+	c.Params = gin.Params{{Key: "item_id", Value: fmt.Sprintf("%d", createdItem.ID)}}
+	handlers.UpdateItem(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response database.Item
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, "Updated Name", response.Name)
+	assert.Equal(t, "Updated Description", response.Description)
+}
+
+func TestUpdateItem_IncrementsVersion(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	item := createTestItem(t, handlers, "Tent")
+	itemID := fmt.Sprintf("%d", item.ID)
+	assert.Equal(t, 1, item.Version)
+
+	for version := 1; version <= 2; version++ {
+		body := fmt.Sprintf(`{"quantity":%d,"version":%d}`, version+1, version)
+		c, w := createAuthenticatedRequest(handlers, "PATCH", "/items/"+itemID, []byte(body))
+		c.Params = gin.Params{{Key: "item_id", Value: itemID}}
+		handlers.UpdateItem(c)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response database.Item
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, version+1, response.Version)
+		assert.Equal(t, version+1, response.Quantity)
+	}
+}
+
+func TestUpdateItem_StaleVersion(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	item := createTestItem(t, handlers, "Tent")
+	itemID := fmt.Sprintf("%d", item.ID)
+
+	c, w := createAuthenticatedRequest(handlers, "PATCH", "/items/"+itemID, []byte(`{"name":"Big Tent","version":1}`))
+	c.Params = gin.Params{{Key: "item_id", Value: itemID}}
+	handlers.UpdateItem(c)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// A second edit based on the same version loses
+	c, w = createAuthenticatedRequest(handlers, "PATCH", "/items/"+itemID, []byte(`{"name":"Small Tent","version":1}`))
+	c.Params = gin.Params{{Key: "item_id", Value: itemID}}
+	handlers.UpdateItem(c)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	var response map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, CodeVersionConflict, response["code"])
+
+	var stored database.Item
+	assert.NoError(t, handlers.db.First(&stored, item.ID).Error)
+	assert.Equal(t, "Big Tent", stored.Name)
+	assert.Equal(t, 2, stored.Version)
+}
+
+func TestUpdateItem_MissingVersion(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	item := createTestItem(t, handlers, "Tent")
+	itemID := fmt.Sprintf("%d", item.ID)
+
+	c, w := createAuthenticatedRequest(handlers, "PATCH", "/items/"+itemID, []byte(`{"name":"Big Tent"}`))
+	c.Params = gin.Params{{Key: "item_id", Value: itemID}}
+	handlers.UpdateItem(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestUpdateItem_CreateMissingTags(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	item := createTestItem(t, handlers, "Backpack")
+	existing := createTestTag(t, handlers, "camping", "#00ff00")
+
+	path := fmt.Sprintf("/items/%d?create_missing_tags=true", item.ID)
+	body := fmt.Sprintf(`{"tags":[%d,"hiking","camping"],"version":1}`, existing.ID)
+	c, w := createAuthenticatedRequest(handlers, "PATCH", path, []byte(body))
+	c.Params = gin.Params{{Key: "item_id", Value: fmt.Sprintf("%d", item.ID)}}
+	handlers.UpdateItem(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response database.Item
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+
+	names := map[string]uint{}
+	for _, tag := range response.Tags {
+		names[tag.Name] = tag.ID
+	}
+	assert.Len(t, names, 2)
+	assert.Equal(t, existing.ID, names["camping"])
+	assert.NotZero(t, names["hiking"])
+
+	// The new tag belongs to the caller's active organization
+	var created database.Tag
+	assert.NoError(t, handlers.db.Where("name = ?", "hiking").First(&created).Error)
+	assert.Equal(t, existing.OrganizationID, created.OrganizationID)
+}
+
+func TestUpdateItem_UnknownTagNameWithoutFlag(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	item := createTestItem(t, handlers, "Backpack")
+	existing := createTestTag(t, handlers, "camping", "#00ff00")
+
+	body := fmt.Sprintf(`{"name":"Renamed","tags":[%d,"hiking"],"version":1}`, existing.ID)
+	c, w := createAuthenticatedRequest(handlers, "PATCH", fmt.Sprintf("/items/%d", item.ID), []byte(body))
+	c.Params = gin.Params{{Key: "item_id", Value: fmt.Sprintf("%d", item.ID)}}
+	handlers.UpdateItem(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// The whole update is rolled back
+	var count int64
+	handlers.db.Model(&database.Tag{}).Where("name = ?", "hiking").Count(&count)
+	assert.Equal(t, int64(0), count)
+
+	var stored database.Item
+	handlers.db.First(&stored, item.ID)
+	assert.Equal(t, "Backpack", stored.Name)
+}
+
+func TestUpdateItem_ParentChecks(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	box := createTestItem(t, handlers, "Box")
+	bag := createTestItem(t, handlers, "Bag")
+
+	foreign := database.Item{Name: "Safe", UserEmail: "other@example.com", BackpackID: "OTH0001"}
+	assert.NoError(t, handlers.db.Create(&foreign).Error)
+
+	update := func(id, parentID uint) *httptest.ResponseRecorder {
+		var current database.Item
+		handlers.db.First(&current, id)
+		body := fmt.Sprintf(`{"parent":%d,"version":%d}`, parentID, current.Version)
+		c, w := createAuthenticatedRequest(handlers, "PUT", fmt.Sprintf("/items/%d", id), []byte(body))
+		c.Params = gin.Params{{Key: "item_id", Value: fmt.Sprintf("%d", id)}}
+		handlers.UpdateItem(c)
+		return w
+	}
+
+	// An item can't be its own parent
+	w := update(box.ID, box.ID)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), CodeParentCycle)
+
+	// Nor the child of one of its descendants
+	assert.Equal(t, http.StatusOK, update(bag.ID, box.ID).Code)
+	w = update(box.ID, bag.ID)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), CodeParentCycle)
+
+	// Nor the child of another user's item
+	w = update(box.ID, foreign.ID)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	var stored database.Item
+	assert.NoError(t, handlers.db.First(&stored, box.ID).Error)
+	assert.Nil(t, stored.ParentID)
+}
+
+func TestUpdateItem_UnknownTagIDs(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	item := createTestItem(t, handlers, "Backpack")
+	existing := createTestTag(t, handlers, "camping", "#00ff00")
+
+	// A tag of another organization is as unknown as a missing one
+	foreign := database.Tag{Name: "secret", OrganizationID: existing.OrganizationID + 100}
+	assert.NoError(t, handlers.db.Create(&foreign).Error)
+
+	body := fmt.Sprintf(`{"tags":[%d,%d,9999],"version":1}`, existing.ID, foreign.ID)
+	c, w := createAuthenticatedRequest(handlers, "PATCH", fmt.Sprintf("/items/%d", item.ID), []byte(body))
+	c.Params = gin.Params{{Key: "item_id", Value: fmt.Sprintf("%d", item.ID)}}
+	handlers.UpdateItem(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var response struct {
+		Code    string `json:"code"`
+		Details struct {
+			UnknownTagIDs []uint `json:"unknown_tag_ids"`
+		} `json:"details"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, CodeInvalidInput, response.Code)
+	assert.Equal(t, []uint{foreign.ID, 9999}, response.Details.UnknownTagIDs)
+
+	// The item keeps its tags
+	var stored database.Item
+	assert.NoError(t, handlers.db.Preload("Tags").First(&stored, item.ID).Error)
+	assert.Empty(t, stored.Tags)
+}
+
+func TestDeleteItem_Success(t *testing.T) {
+	handlers := setupTestHandlers(t)
+
+	// Create an item first
+	c, w := createAuthenticatedRequest(handlers, "POST", "/items", []byte(`{"name":"Test Item","description":"Test Description"}`))
+	handlers.CreateItem(c)
 
 	var createdItem database.Item
 	json.Unmarshal(w.Body.Bytes(), &createdItem)
@@ -776,25 +1700,313 @@ func TestDeleteItem_Success(t *testing.T) {
 	c.Params = gin.Params{{Key: "item_id", Value: fmt.Sprintf("%d", createdItem.ID)}}
 	handlers.DeleteItem(c)
 
-	// Check if we got a successful response (204 or 200)
-	if w.Code != http.StatusNoContent && w.Code != http.StatusOK {
-		t.Errorf("Expected 204 or 200, got %d", w.Code)
-	}
+	// Check if we got a successful response (204 or 200)
+	if w.Code != http.StatusNoContent && w.Code != http.StatusOK {
+		t.Errorf("Expected 204 or 200, got %d", w.Code)
+	}
+}
+
+func TestDeleteItem_SoftDeleteThenRestore(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	created := createTestItem(t, handlers, "Tent")
+	itemID := fmt.Sprintf("%d", created.ID)
+
+	c, _ := createAuthenticatedRequest(handlers, "DELETE", "/items/"+itemID, nil)
+	c.Params = gin.Params{{Key: "item_id", Value: itemID}}
+	handlers.DeleteItem(c)
+
+	c, w := createAuthenticatedRequest(handlers, "GET", "/items", nil)
+	handlers.GetItems(c)
+	assert.Empty(t, itemNames(t, w))
+
+	c, w = createAuthenticatedRequest(handlers, "POST", "/items/"+itemID+"/restore", nil)
+	c.Params = gin.Params{{Key: "item_id", Value: itemID}}
+	handlers.RestoreItem(c)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	c, w = createAuthenticatedRequest(handlers, "GET", "/items", nil)
+	handlers.GetItems(c)
+	assert.Equal(t, []string{"Tent"}, itemNames(t, w))
+
+	// Restoring an item that isn't deleted is a 404
+	c, w = createAuthenticatedRequest(handlers, "POST", "/items/"+itemID+"/restore", nil)
+	c.Params = gin.Params{{Key: "item_id", Value: itemID}}
+	handlers.RestoreItem(c)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestDeleteItem_HardDeleteCannotBeRestored(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	created := createTestItem(t, handlers, "Tent")
+	itemID := fmt.Sprintf("%d", created.ID)
+
+	c, _ := createAuthenticatedRequest(handlers, "DELETE", "/items/"+itemID+"?hard=true", nil)
+	c.Params = gin.Params{{Key: "item_id", Value: itemID}}
+	handlers.DeleteItem(c)
+
+	var count int64
+	handlers.db.Unscoped().Model(&database.Item{}).Where("id = ?", created.ID).Count(&count)
+	assert.Equal(t, int64(0), count)
+
+	c, w := createAuthenticatedRequest(handlers, "POST", "/items/"+itemID+"/restore", nil)
+	c.Params = gin.Params{{Key: "item_id", Value: itemID}}
+	handlers.RestoreItem(c)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestBackpackIDExists_Assigned(t *testing.T) {
+	handlers := setupTestHandlers(t)
+
+	// Create an item first
+	c, w := createAuthenticatedRequest(handlers, "POST", "/items", []byte(`{"name":"Test Item"}`))
+	handlers.CreateItem(c)
+
+	var createdItem database.Item
+	json.Unmarshal(w.Body.Bytes(), &createdItem)
+
+	// Check the assigned backpack ID
+	c, w = createAuthenticatedRequest(handlers, "GET", "/items/backpack-id-exists?id="+createdItem.BackpackID, nil)
+	handlers.BackpackIDExists(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string]interface{}
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, true, response["exists"])
+}
+
+func TestBackpackIDExists_Unassigned(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	c, w := createAuthenticatedRequest(handlers, "GET", "/items/backpack-id-exists?id=ABC0042", nil)
+
+	handlers.BackpackIDExists(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string]interface{}
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, false, response["exists"])
+}
+
+func TestBackpackIDExists_MissingID(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	c, w := createAuthenticatedRequest(handlers, "GET", "/items/backpack-id-exists", nil)
+
+	handlers.BackpackIDExists(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGetItemTree(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	c, w := createAuthenticatedRequest(handlers, "GET", "/items/tree", nil)
+
+	box, _ := handlers.itemService.CreateItem("Box", "", "auth@example.com", nil)
+	bag, _ := handlers.itemService.CreateItem("Bag", "", "auth@example.com", &box.ID)
+	handlers.itemService.CreateItem("Pouch", "", "auth@example.com", &bag.ID)
+	handlers.itemService.CreateItem("Lamp", "", "auth@example.com", nil)
+
+	handlers.GetItemTree(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string][]*item.TreeNode
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	roots := response["items"]
+	assert.Len(t, roots, 2)
+	assert.Equal(t, "Box", roots[0].Name)
+	assert.Empty(t, roots[1].Children)
+	if assert.Len(t, roots[0].Children, 1) {
+		assert.Equal(t, "Bag", roots[0].Children[0].Name)
+		if assert.Len(t, roots[0].Children[0].Children, 1) {
+			assert.Equal(t, "Pouch", roots[0].Children[0].Children[0].Name)
+			assert.Empty(t, roots[0].Children[0].Children[0].Children)
+		}
+	}
+}
+
+func TestMoveItem(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	c, w := createAuthenticatedRequest(handlers, "PATCH", "/items/1/move", []byte(`{"parent":2}`))
+
+	box, _ := handlers.itemService.CreateItem("Box", "", "auth@example.com", nil)
+	bag, _ := handlers.itemService.CreateItem("Bag", "", "auth@example.com", nil)
+	c.Params = gin.Params{{Key: "item_id", Value: fmt.Sprintf("%d", box.ID)}}
+
+	handlers.MoveItem(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var moved database.Item
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &moved))
+	if assert.NotNil(t, moved.ParentID) {
+		assert.Equal(t, bag.ID, *moved.ParentID)
+	}
+
+	// Moving the parent under its own child is a cycle
+	c, w = createAuthenticatedRequest(handlers, "PATCH", "/items/2/move", []byte(fmt.Sprintf(`{"parent":%d}`, box.ID)))
+	c.Params = gin.Params{{Key: "item_id", Value: fmt.Sprintf("%d", bag.ID)}}
+	handlers.MoveItem(c)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	c, w = createAuthenticatedRequest(handlers, "PATCH", "/items/2/move", []byte(`{"parent":999}`))
+	c.Params = gin.Params{{Key: "item_id", Value: fmt.Sprintf("%d", bag.ID)}}
+	handlers.MoveItem(c)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestAssignItemsToOrganization_Success(t *testing.T) {
+	handlers := setupTestHandlers(t)
+
+	// Create two items
+	for _, name := range []string{"First Item", "Second Item"} {
+		c, w := createAuthenticatedRequest(handlers, "POST", "/items", []byte(`{"name":"`+name+`"}`))
+		handlers.CreateItem(c)
+		assert.Equal(t, http.StatusCreated, w.Code)
+	}
+
+	// Create a shared organization the user belongs to
+	org, err := handlers.organizationService.CreateOrganization("Shared")
+	assert.NoError(t, err)
+	assert.NoError(t, handlers.organizationService.AddUserToOrganization(org.ID, "auth@example.com"))
+
+	body := []byte(fmt.Sprintf(`{"organization_id":%d}`, org.ID))
+	c, w := createAuthenticatedRequest(handlers, "POST", "/users/me/items/assign-org", body)
+	handlers.AssignItemsToOrganization(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string]interface{}
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(2), response["updated"])
+
+	var items []database.Item
+	assert.NoError(t, handlers.db.Where("user_email = ?", "auth@example.com").Find(&items).Error)
+	assert.Len(t, items, 2)
+	for _, item := range items {
+		if assert.NotNil(t, item.OrganizationID) {
+			assert.Equal(t, org.ID, *item.OrganizationID)
+		}
+	}
+}
+
+func TestAssignItemsToOrganization_NotMember(t *testing.T) {
+	handlers := setupTestHandlers(t)
+
+	c, w := createAuthenticatedRequest(handlers, "POST", "/items", []byte(`{"name":"Test Item"}`))
+	handlers.CreateItem(c)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	// Create an organization the user does not belong to
+	org, err := handlers.organizationService.CreateOrganization("Other")
+	assert.NoError(t, err)
+
+	body := []byte(fmt.Sprintf(`{"organization_id":%d}`, org.ID))
+	c, w = createAuthenticatedRequest(handlers, "POST", "/users/me/items/assign-org", body)
+	handlers.AssignItemsToOrganization(c)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// The item stays in the organization it was created in
+	var item database.Item
+	assert.NoError(t, handlers.db.Where("user_email = ?", "auth@example.com").First(&item).Error)
+	if assert.NotNil(t, item.OrganizationID) {
+		assert.NotEqual(t, org.ID, *item.OrganizationID)
+	}
+}
+
+// Tags tests
+func TestGetTags_Success(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	c, w := createAuthenticatedRequest(handlers, "GET", "/tags", nil)
+
+	handlers.GetTags(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response []database.Tag
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Len(t, response, 0) // No tags initially
+}
+
+// tagNames returns the tag names from a GetTags response
+func tagNames(t *testing.T, w *httptest.ResponseRecorder) []string {
+	var response []database.Tag
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to parse tags: %v", err)
+	}
+
+	names := make([]string, len(response))
+	for i, tag := range response {
+		names[i] = tag.Name
+	}
+	return names
+}
+
+func TestGetTags_DefaultAlphabeticalOrder(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	createTestTag(t, handlers, "garden", "")
+	createTestTag(t, handlers, "Bikes", "")
+	createTestTag(t, handlers, "attic", "")
+
+	c, w := createAuthenticatedRequest(handlers, "GET", "/tags", nil)
+	handlers.GetTags(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{"attic", "Bikes", "garden"}, tagNames(t, w))
+}
+
+func TestGetTags_SortOverrides(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	createTestTag(t, handlers, "garden", "")
+	createTestTag(t, handlers, "Bikes", "")
+	createTestTag(t, handlers, "attic", "")
+
+	c, w := createAuthenticatedRequest(handlers, "GET", "/tags?sort=-name", nil)
+	handlers.GetTags(c)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{"garden", "Bikes", "attic"}, tagNames(t, w))
+
+	c, w = createAuthenticatedRequest(handlers, "GET", "/tags?sort=created_at", nil)
+	handlers.GetTags(c)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{"garden", "Bikes", "attic"}, tagNames(t, w))
+}
+
+func TestGetTags_InvalidSort(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	c, w := createAuthenticatedRequest(handlers, "GET", "/tags?sort=organization_id", nil)
+
+	handlers.GetTags(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
 }
 
-// Tags tests
-func TestGetTags_Success(t *testing.T) {
+func TestGetTags_UsageCounts(t *testing.T) {
 	handlers := setupTestHandlers(t)
-	c, w := createAuthenticatedRequest(handlers, "GET", "/tags", nil)
+	camping := createTestTag(t, handlers, "camping", "")
+	tools := createTestTag(t, handlers, "tools", "")
+	createTestTag(t, handlers, "unused", "")
+	tent := createTestItem(t, handlers, "Tent")
+	stove := createTestItem(t, handlers, "Stove")
+	handlers.db.Model(&tent).Association("Tags").Append(&camping)
+	handlers.db.Model(&stove).Association("Tags").Append(&camping, &tools)
 
+	c, w := createAuthenticatedRequest(handlers, "GET", "/tags", nil)
 	handlers.GetTags(c)
 
 	assert.Equal(t, http.StatusOK, w.Code)
 
-	var response []database.Tag
-	err := json.Unmarshal(w.Body.Bytes(), &response)
-	assert.NoError(t, err)
-	assert.Len(t, response, 0) // No tags initially
+	var response []map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	counts := map[string]float64{}
+	for _, tag := range response {
+		counts[tag["name"].(string)] = tag["usage_count"].(float64)
+	}
+	assert.Equal(t, map[string]float64{"camping": 2, "tools": 1, "unused": 0}, counts)
 }
 
 func TestCreateTag_Success(t *testing.T) {
@@ -809,6 +2021,32 @@ func TestCreateTag_Success(t *testing.T) {
 	err := json.Unmarshal(w.Body.Bytes(), &response)
 	assert.NoError(t, err)
 	assert.Equal(t, "Test Tag", response.Name)
+	assert.Equal(t, fmt.Sprintf("/api/tags/%d", response.ID), w.Header().Get("Location"))
+}
+
+func TestGetTag(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	created := createTestTag(t, handlers, "camping", "#00ff00")
+	foreign := database.Tag{Name: "secret", OrganizationID: created.OrganizationID + 100}
+	assert.NoError(t, handlers.db.Create(&foreign).Error)
+
+	get := func(id string) *httptest.ResponseRecorder {
+		c, w := createAuthenticatedRequest(handlers, "GET", "/tags/"+id, nil)
+		c.Params = gin.Params{{Key: "tag_id", Value: id}}
+		handlers.GetTag(c)
+		return w
+	}
+
+	w := get(fmt.Sprintf("%d", created.ID))
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response database.Tag
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "camping", response.Name)
+
+	// Tags of other organizations and unknown tags are not found
+	assert.Equal(t, http.StatusNotFound, get(fmt.Sprintf("%d", foreign.ID)).Code)
+	assert.Equal(t, http.StatusNotFound, get("9999").Code)
+	assert.Equal(t, http.StatusBadRequest, get("abc").Code)
 }
 
 func TestCreateTag_InvalidInput(t *testing.T) {
@@ -822,5 +2060,558 @@ func TestCreateTag_InvalidInput(t *testing.T) {
 	var response map[string]interface{}
 	err := json.Unmarshal(w.Body.Bytes(), &response)
 	assert.NoError(t, err)
-	assert.Contains(t, response["error"].(string), "Invalid input")
+	assert.Equal(t, CodeInvalidInput, response["code"])
+}
+
+func TestCreateTag_ColorAndIcon(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	c, w := createAuthenticatedRequest(handlers, "POST", "/tags", []byte(`{"name":"camping","color":"#1a2B3c","icon":"tent"}`))
+
+	handlers.CreateTag(c)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	c, w = createAuthenticatedRequest(handlers, "GET", "/tags", nil)
+	handlers.GetTags(c)
+
+	var tags []database.Tag
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &tags))
+	if assert.Len(t, tags, 1) {
+		assert.Equal(t, "#1a2B3c", tags[0].Color)
+		assert.Equal(t, "tent", tags[0].Icon)
+	}
+}
+
+func TestCreateTag_InvalidColor(t *testing.T) {
+	handlers := setupTestHandlers(t)
+
+	for _, color := range []string{"red", "#fff", "#12345g", "1a2b3c", "#1a2b3c4d"} {
+		body, _ := json.Marshal(TagCreateRequest{Name: "camping", Color: color})
+		c, w := createAuthenticatedRequest(handlers, "POST", "/tags", body)
+		handlers.CreateTag(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, color)
+	}
+}
+
+func TestUpdateTag(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	created := createTestTag(t, handlers, "camping", "#ff0000")
+
+	c, w := createAuthenticatedRequest(handlers, "PUT", "/tags", []byte(`{"name":"outdoors","color":"#00FF00"}`))
+	c.Params = gin.Params{{Key: "tag_id", Value: fmt.Sprintf("%d", created.ID)}}
+	handlers.UpdateTag(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var updated database.Tag
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &updated))
+	assert.Equal(t, "outdoors", updated.Name)
+	assert.Equal(t, "#00FF00", updated.Color)
+
+	c, w = createAuthenticatedRequest(handlers, "PUT", "/tags", []byte(`{"color":"green"}`))
+	c.Params = gin.Params{{Key: "tag_id", Value: fmt.Sprintf("%d", created.ID)}}
+	handlers.UpdateTag(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestUpdateTag_DuplicateName(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	created := createTestTag(t, handlers, "camping", "#ff0000")
+	createTestTag(t, handlers, "hiking", "#00ff00")
+
+	c, w := createAuthenticatedRequest(handlers, "PUT", "/tags", []byte(`{"name":"hiking"}`))
+	c.Params = gin.Params{{Key: "tag_id", Value: fmt.Sprintf("%d", created.ID)}}
+	handlers.UpdateTag(c)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	var stored database.Tag
+	assert.NoError(t, handlers.db.First(&stored, created.ID).Error)
+	assert.Equal(t, "camping", stored.Name)
+}
+
+func TestCreateTag_DuplicateName(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	createTestTag(t, handlers, "camping", "#ff0000")
+
+	body, _ := json.Marshal(TagCreateRequest{Name: "camping", Color: "#00ff00"})
+	c, w := createAuthenticatedRequest(handlers, "POST", "/tags", body)
+	handlers.CreateTag(c)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestUpdateTag_OtherOrganization(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	other, err := handlers.organizationService.CreateOrganization("Other")
+	assert.NoError(t, err)
+	foreign, err := handlers.tagService.CreateTag("foreign", other.ID)
+	assert.NoError(t, err)
+
+	c, w := createAuthenticatedRequest(handlers, "PUT", "/tags", []byte(`{"name":"mine"}`))
+	c.Params = gin.Params{{Key: "tag_id", Value: fmt.Sprintf("%d", foreign.ID)}}
+	handlers.UpdateTag(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestMergeTag_Success(t *testing.T) {
+	handlers := setupTestHandlers(t)
+
+	source := createTestTag(t, handlers, "tools", "#ff0000")
+	target := createTestTag(t, handlers, "Tools", "#00ff00")
+	hammer := createTestItem(t, handlers, "Hammer")
+	saw := createTestItem(t, handlers, "Saw")
+	handlers.db.Model(&hammer).Association("Tags").Append(&source)
+	handlers.db.Model(&saw).Association("Tags").Append(&source, &target)
+
+	c, w := createAuthenticatedRequest(handlers, "POST", "/tags/merge", []byte(fmt.Sprintf(`{"into":%d}`, target.ID)))
+	c.Params = gin.Params{{Key: "tag_id", Value: fmt.Sprintf("%d", source.ID)}}
+	handlers.MergeTag(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Both items end up with the target tag, once
+	for _, i := range []database.Item{hammer, saw} {
+		var tags []database.Tag
+		assert.NoError(t, handlers.db.Model(&i).Association("Tags").Find(&tags))
+		if assert.Len(t, tags, 1, i.Name) {
+			assert.Equal(t, target.ID, tags[0].ID)
+		}
+	}
+
+	_, err := handlers.tagService.GetTag(source.ID)
+	assert.ErrorIs(t, err, tag.ErrTagNotFound)
+}
+
+func TestMergeTag_OtherOrganization(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	source := createTestTag(t, handlers, "tools", "#ff0000")
+	other, err := handlers.organizationService.CreateOrganization("Other")
+	assert.NoError(t, err)
+	foreign, err := handlers.tagService.CreateTag("Tools", other.ID)
+	assert.NoError(t, err)
+
+	c, w := createAuthenticatedRequest(handlers, "POST", "/tags/merge", []byte(fmt.Sprintf(`{"into":%d}`, foreign.ID)))
+	c.Params = gin.Params{{Key: "tag_id", Value: fmt.Sprintf("%d", source.ID)}}
+	handlers.MergeTag(c)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	_, err = handlers.tagService.GetTag(source.ID)
+	assert.NoError(t, err)
+}
+
+func TestRestoreTagMerge_Success(t *testing.T) {
+	handlers := setupTestHandlers(t)
+
+	source := createTestTag(t, handlers, "tools", "#ff0000")
+	target := createTestTag(t, handlers, "Tools", "#ff0000")
+	hammer := createTestItem(t, handlers, "Hammer")
+	handlers.db.Model(&hammer).Association("Tags").Append(&source)
+
+	merge, err := handlers.tagService.MergeTags(source.ID, target.ID, source.OrganizationID)
+	assert.NoError(t, err)
+
+	mergeID := fmt.Sprintf("%d", merge.ID)
+	c, w := createAuthenticatedRequest(handlers, "POST", "/tags/restore-merge/"+mergeID, nil)
+	c.Params = gin.Params{{Key: "merge_id", Value: mergeID}}
+	handlers.RestoreTagMerge(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var restored database.Tag
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &restored))
+	assert.Equal(t, source.ID, restored.ID)
+
+	tags, err := handlers.tagService.GetTagsByItem(hammer.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, tags, 1) {
+		assert.Equal(t, source.ID, tags[0].ID)
+	}
+
+	// A merge can only be restored once
+	c, w = createAuthenticatedRequest(handlers, "POST", "/tags/restore-merge/"+mergeID, nil)
+	c.Params = gin.Params{{Key: "merge_id", Value: mergeID}}
+	handlers.RestoreTagMerge(c)
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestRestoreTagMerge_NameReused(t *testing.T) {
+	handlers := setupTestHandlers(t)
+
+	source := createTestTag(t, handlers, "tools", "#ff0000")
+	target := createTestTag(t, handlers, "Tools", "#ff0000")
+
+	merge, err := handlers.tagService.MergeTags(source.ID, target.ID, source.OrganizationID)
+	assert.NoError(t, err)
+	createTestTag(t, handlers, "tools", "#00ff00")
+
+	mergeID := fmt.Sprintf("%d", merge.ID)
+	c, w := createAuthenticatedRequest(handlers, "POST", "/tags/restore-merge/"+mergeID, nil)
+	c.Params = gin.Params{{Key: "merge_id", Value: mergeID}}
+	handlers.RestoreTagMerge(c)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	var response APIError
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, CodeConflict, response.Code)
+}
+
+func TestRestoreTagMerge_NotFound(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	c, w := createAuthenticatedRequest(handlers, "POST", "/tags/restore-merge/999", nil)
+	c.Params = gin.Params{{Key: "merge_id", Value: "999"}}
+
+	handlers.RestoreTagMerge(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestItemWebhooks(t *testing.T) {
+	var mu sync.Mutex
+	var events []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, "sha256="+webhook.Sign("shared-secret", body), r.Header.Get(webhook.SignatureHeader))
+
+		var event webhook.Event
+		json.Unmarshal(body, &event)
+		mu.Lock()
+		events = append(events, event.Type)
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	handlers := setupTestHandlers(t)
+	handlers.config.Webhook = config.WebhookConfig{ItemURL: srv.URL, Secret: "shared-secret", Timeout: time.Second}
+	handlers.webhooks = webhook.NewDispatcher(fxtest.NewLifecycle(t), handlers.config, logger.Discard())
+
+	created := createTestItem(t, handlers, "Tent")
+	assert.NoError(t, handlers.webhooks.Wait(context.Background()))
+
+	itemID := fmt.Sprintf("%d", created.ID)
+	c, w := createAuthenticatedRequest(handlers, "PATCH", "/items/"+itemID, []byte(`{"name":"Big Tent","version":1}`))
+	c.Params = gin.Params{{Key: "item_id", Value: itemID}}
+	handlers.UpdateItem(c)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, handlers.webhooks.Wait(context.Background()))
+
+	c, _ = createAuthenticatedRequest(handlers, "DELETE", "/items/"+itemID, nil)
+	c.Params = gin.Params{{Key: "item_id", Value: itemID}}
+	handlers.DeleteItem(c)
+	assert.NoError(t, handlers.webhooks.Wait(context.Background()))
+
+	assert.Equal(t, []string{webhook.EventItemCreated, webhook.EventItemUpdated, webhook.EventItemDeleted}, events)
+}
+
+func TestGetDeletedItems(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	deletedItem := createTestItem(t, handlers, "Old Tent")
+	createTestItem(t, handlers, "New Tent")
+
+	itemID := fmt.Sprintf("%d", deletedItem.ID)
+	c, _ := createAuthenticatedRequest(handlers, "DELETE", "/items/"+itemID, nil)
+	c.Params = gin.Params{{Key: "item_id", Value: itemID}}
+	handlers.DeleteItem(c)
+
+	since := url.QueryEscape(time.Now().Add(-time.Hour).Format(time.RFC3339))
+	c, w := createAuthenticatedRequest(handlers, "GET", "/items/deleted?since="+since, nil)
+	handlers.GetDeletedItems(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Items []item.DeletedItem `json:"items"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	if assert.Len(t, response.Items, 1) {
+		assert.Equal(t, deletedItem.ID, response.Items[0].ID)
+		assert.Equal(t, deletedItem.BackpackID, response.Items[0].BackpackID)
+	}
+
+	// Nothing was deleted after now
+	since = url.QueryEscape(time.Now().Add(time.Hour).Format(time.RFC3339))
+	c, w = createAuthenticatedRequest(handlers, "GET", "/items/deleted?since="+since, nil)
+	handlers.GetDeletedItems(c)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Empty(t, response.Items)
+}
+
+func TestGetDeletedItems_InvalidSince(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	c, w := createAuthenticatedRequest(handlers, "GET", "/items/deleted?since=yesterday", nil)
+
+	handlers.GetDeletedItems(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+// makeAdmin gives the user the admin role
+func makeAdmin(t *testing.T, handlers *Handlers, email string) {
+	assert.NoError(t, handlers.db.Model(&database.User{}).Where("email = ?", email).Update("role", user.RoleAdmin).Error)
+}
+
+func TestGetUserDetails(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	assert.NoError(t, handlers.userService.CreateUser("rick@example.com", "password123"))
+
+	c, w := createAuthenticatedRequest(handlers, "GET", "/users/rick@example.com", nil)
+	makeAdmin(t, handlers, "auth@example.com")
+	c.Params = gin.Params{{Key: "email", Value: "rick@example.com"}}
+	handlers.GetUserDetails(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response UserResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "rick@example.com", response.Email)
+
+	c, w = createAuthenticatedRequest(handlers, "GET", "/users/nobody@example.com", nil)
+	c.Params = gin.Params{{Key: "email", Value: "nobody@example.com"}}
+	handlers.GetUserDetails(c)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestGetUserDetails_Self(t *testing.T) {
+	handlers := setupTestHandlers(t)
+
+	c, w := createAuthenticatedRequest(handlers, "GET", "/users/auth@example.com", nil)
+	c.Params = gin.Params{{Key: "email", Value: "auth@example.com"}}
+	handlers.GetUserDetails(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestUserDetails_OtherUserForbidden(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	assert.NoError(t, handlers.userService.CreateUser("rick@example.com", "password123"))
+
+	requests := []struct {
+		method  string
+		body    []byte
+		handler gin.HandlerFunc
+	}{
+		{"GET", nil, handlers.GetUserDetails},
+		{"PUT", []byte(`{"display_name":"Not Rick"}`), handlers.UpdateUserDetails},
+		{"DELETE", nil, handlers.DeleteUser},
+	}
+	for _, r := range requests {
+		c, w := createAuthenticatedRequest(handlers, r.method, "/users/rick@example.com?hard=true", r.body)
+		c.Params = gin.Params{{Key: "email", Value: "rick@example.com"}}
+		r.handler(c)
+
+		assert.Equal(t, http.StatusForbidden, w.Code, r.method)
+		var response APIError
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, CodeForbidden, response.Code)
+	}
+
+	// The account is untouched
+	found, err := handlers.userService.GetUser("rick@example.com")
+	assert.NoError(t, err)
+	assert.Empty(t, found.DisplayName)
+}
+
+func TestUpdateUserDetails(t *testing.T) {
+	handlers := setupTestHandlers(t)
+
+	c, w := createAuthenticatedRequest(handlers, "PUT", "/users/auth@example.com", []byte(`{"email":"auth@example.com","display_name":"Rick"}`))
+	c.Params = gin.Params{{Key: "email", Value: "auth@example.com"}}
+	handlers.UpdateUserDetails(c)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response UserResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "auth@example.com", response.Email)
+	assert.Equal(t, "Rick", response.DisplayName)
+
+	// The email may be left out
+	c, w = createAuthenticatedRequest(handlers, "PUT", "/users/auth@example.com", []byte(`{"display_name":"Morty"}`))
+	c.Params = gin.Params{{Key: "email", Value: "auth@example.com"}}
+	handlers.UpdateUserDetails(c)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "Morty", response.DisplayName)
+
+	makeAdmin(t, handlers, "auth@example.com")
+	c, w = createAuthenticatedRequest(handlers, "PUT", "/users/nobody@example.com", []byte(`{"email":"nobody@example.com"}`))
+	c.Params = gin.Params{{Key: "email", Value: "nobody@example.com"}}
+	handlers.UpdateUserDetails(c)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestUpdateUserDetails_EmailChangeRejected(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	c, w := createAuthenticatedRequest(handlers, "PUT", "/users/auth@example.com", []byte(`{"email":"sanchez@example.com","display_name":"Rick"}`))
+	created := createTestItem(t, handlers, "Portal Gun")
+
+	c.Params = gin.Params{{Key: "email", Value: "auth@example.com"}}
+	handlers.UpdateUserDetails(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// The user keeps their email and the rows referencing it
+	_, err := handlers.userService.GetUser("sanchez@example.com")
+	assert.ErrorIs(t, err, user.ErrUserNotFound)
+
+	var stored database.Item
+	assert.NoError(t, handlers.db.First(&stored, created.ID).Error)
+	assert.Equal(t, "auth@example.com", stored.UserEmail)
+
+	count, err := handlers.jwtService.CountActiveSessions("auth@example.com")
+	assert.NoError(t, err)
+	assert.NotZero(t, count)
+}
+
+func TestDeleteUser(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	assert.NoError(t, handlers.userService.CreateUser("rick@example.com", "password123"))
+
+	c, _ := createAuthenticatedRequest(handlers, "DELETE", "/users/rick@example.com", nil)
+	makeAdmin(t, handlers, "auth@example.com")
+	c.Params = gin.Params{{Key: "email", Value: "rick@example.com"}}
+	handlers.DeleteUser(c)
+	assert.Equal(t, http.StatusNoContent, c.Writer.Status())
+
+	_, err := handlers.userService.GetUser("rick@example.com")
+	assert.ErrorIs(t, err, user.ErrUserNotFound)
+
+	// The user is already gone
+	c, w := createAuthenticatedRequest(handlers, "DELETE", "/users/rick@example.com", nil)
+	c.Params = gin.Params{{Key: "email", Value: "rick@example.com"}}
+	handlers.DeleteUser(c)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestDeleteUser_Self(t *testing.T) {
+	handlers := setupTestHandlers(t)
+
+	c, _ := createAuthenticatedRequest(handlers, "DELETE", "/users/auth@example.com", nil)
+	c.Params = gin.Params{{Key: "email", Value: "auth@example.com"}}
+	handlers.DeleteUser(c)
+	assert.Equal(t, http.StatusNoContent, c.Writer.Status())
+}
+
+// loginRequest logs in with the given credentials
+func loginRequest(handlers *Handlers, email, password string) *httptest.ResponseRecorder {
+	c, w := setupGinContext()
+	body, _ := json.Marshal(LoginRequest{Email: email, Password: password})
+	c.Request = httptest.NewRequest("POST", "/token", bytes.NewBuffer(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	handlers.Login(c)
+	return w
+}
+
+func TestDeactivateUser(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	c, w := createAuthenticatedRequest(handlers, "POST", "/users/deactivate", nil)
+	tokens, err := handlers.jwtService.IssueTokenPair("auth@example.com")
+	assert.NoError(t, err)
+
+	handlers.DeactivateUser(c)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// The flag is persisted
+	var stored database.User
+	assert.NoError(t, handlers.db.Where("email = ?", "auth@example.com").First(&stored).Error)
+	assert.False(t, stored.Active)
+
+	// Logging in is refused
+	w = loginRequest(handlers, "auth@example.com", "password123")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	var response map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, CodeAccountInactive, response["code"])
+
+	// Wrong passwords still get the usual answer
+	w = loginRequest(handlers, "auth@example.com", "wrongpassword")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	// Existing sessions can't be refreshed
+	c, w = setupGinContext()
+	c.Request = httptest.NewRequest("POST", "/token/refresh", bytes.NewBufferString(`{"refresh_token":"`+tokens.RefreshToken+`"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	handlers.RefreshToken(c)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	count, err := handlers.jwtService.CountActiveSessions("auth@example.com")
+	assert.NoError(t, err)
+	assert.Zero(t, count)
+}
+
+// tokenPairRequest asks for a token pair for the given email
+func tokenPairRequest(handlers *Handlers, email string) *httptest.ResponseRecorder {
+	c, w := setupGinContext()
+	c.Request = httptest.NewRequest("POST", "/token/pair", bytes.NewBufferString(`{"email":"`+email+`"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	handlers.TokenPair(c)
+	return w
+}
+
+func TestTokenPair(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	assert.NoError(t, handlers.userService.CreateUser("rick@example.com", "password123"))
+
+	w := tokenPairRequest(handlers, "rick@example.com")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = tokenPairRequest(handlers, "nobody@example.com")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestTokenPair_InactiveUser(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	assert.NoError(t, handlers.userService.CreateUser("rick@example.com", "password123"))
+	assert.NoError(t, handlers.userService.SetActive("rick@example.com", false))
+
+	w := tokenPairRequest(handlers, "rick@example.com")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	var response APIError
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, CodeAccountInactive, response.Code)
+}
+
+func TestTokenPair_UnverifiedUser(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	handlers.config.Security.RequireEmailVerification = true
+	assert.NoError(t, handlers.userService.CreateUser("rick@example.com", "password123"))
+
+	w := tokenPairRequest(handlers, "rick@example.com")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	var response APIError
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, CodeEmailNotVerified, response.Code)
+}
+
+func TestDeactivateUser_RollsBackOnFailure(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	c, w := createAuthenticatedRequest(handlers, "POST", "/users/deactivate", nil)
+	_, err := handlers.jwtService.IssueTokenPair("auth@example.com")
+	assert.NoError(t, err)
+	sessions, err := handlers.jwtService.CountActiveSessions("auth@example.com")
+	assert.NoError(t, err)
+
+	// Fail revoking the sessions, which runs after the user was deactivated
+	err = handlers.db.Callback().Update().Before("gorm:update").Register("test:fail_revoke", func(tx *gorm.DB) {
+		if tx.Statement.Table == "refresh_tokens" {
+			tx.AddError(errors.New("injected failure"))
+		}
+	})
+	assert.NoError(t, err)
+
+	handlers.DeactivateUser(c)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+
+	// Neither write was committed
+	var stored database.User
+	assert.NoError(t, handlers.db.Where("email = ?", "auth@example.com").First(&stored).Error)
+	assert.True(t, stored.Active)
+
+	count, err := handlers.jwtService.CountActiveSessions("auth@example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, sessions, count)
 }
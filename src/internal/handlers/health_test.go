@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealth(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	c, w := setupGinContext()
+	c.Request = httptest.NewRequest("GET", "/health", nil)
+
+	handlers.Health(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response map[string]string
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "ok", response["status"])
+}
+
+func TestReady_DatabaseReachable(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	c, w := setupGinContext()
+	c.Request = httptest.NewRequest("GET", "/ready", nil)
+
+	handlers.Ready(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestReady_DatabaseClosed(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	sqlDB, err := handlers.db.DB()
+	assert.NoError(t, err)
+	assert.NoError(t, sqlDB.Close())
+
+	c, w := setupGinContext()
+	c.Request = httptest.NewRequest("GET", "/ready", nil)
+	handlers.Ready(c)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	// Liveness doesn't depend on the database
+	c, w = setupGinContext()
+	c.Request = httptest.NewRequest("GET", "/health", nil)
+	handlers.Health(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
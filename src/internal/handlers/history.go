@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"backend/internal/item"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetItemHistory handles listing the audit log of one of the user's items
+func (h *Handlers) GetItemHistory(c *gin.Context) {
+	userEmail, exists := c.Get("user_email")
+	if !exists {
+		respondError(c, http.StatusUnauthorized, CodeUnauthenticated, "User not authenticated")
+		return
+	}
+
+	itemID, err := strconv.ParseUint(c.Param("item_id"), 10, 32)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, CodeInvalidInput, "Invalid item ID")
+		return
+	}
+
+	history, err := h.itemService.GetHistory(uint(itemID), userEmail.(string))
+	if err != nil {
+		if errors.Is(err, item.ErrItemNotFound) {
+			respondError(c, http.StatusNotFound, CodeNotFound, "Item not found")
+			return
+		}
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to get item history")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"history": history})
+}
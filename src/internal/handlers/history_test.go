@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"backend/internal/database"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetItemHistory(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	created := createTestItem(t, handlers, "Tent")
+	itemID := fmt.Sprintf("%d", created.ID)
+
+	c, w := createAuthenticatedRequest(handlers, "PUT", "/items/"+itemID, []byte(`{"name":"Big Tent","quantity":2,"version":1}`))
+	c.Params = gin.Params{{Key: "item_id", Value: itemID}}
+	handlers.UpdateItem(c)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	c, w = createAuthenticatedRequest(handlers, "GET", "/items/"+itemID+"/history", nil)
+	c.Params = gin.Params{{Key: "item_id", Value: itemID}}
+	handlers.GetItemHistory(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		History []struct {
+			ActorEmail string                 `json:"actor_email"`
+			Action     string                 `json:"action"`
+			Diff       map[string]interface{} `json:"diff"`
+		} `json:"history"`
+	}
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	if assert.Len(t, response.History, 2) {
+		assert.Equal(t, "create", response.History[0].Action)
+		assert.Equal(t, "update", response.History[1].Action)
+		assert.Equal(t, "auth@example.com", response.History[1].ActorEmail)
+		assert.Equal(t, "Big Tent", response.History[1].Diff["name"])
+		assert.Equal(t, float64(2), response.History[1].Diff["quantity"])
+	}
+}
+
+func TestGetItemHistory_NotOwned(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	other := database.Item{Name: "Other", UserEmail: "other@example.com"}
+	handlers.db.Create(&other)
+	itemID := fmt.Sprintf("%d", other.ID)
+
+	c, w := createAuthenticatedRequest(handlers, "GET", "/items/"+itemID+"/history", nil)
+	c.Params = gin.Params{{Key: "item_id", Value: itemID}}
+	handlers.GetItemHistory(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
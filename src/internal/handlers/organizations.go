@@ -0,0 +1,303 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"backend/internal/database"
+	"backend/internal/notify"
+	"backend/internal/organization"
+	"backend/internal/user"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OrganizationCreateRequest represents the organization creation request body
+type OrganizationCreateRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+// OrganizationMemberRequest represents the add member request body
+type OrganizationMemberRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// OrganizationInvitationRequest represents the invitation request body
+type OrganizationInvitationRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// newProfileOrganizations lists organizations, flagging the active one
+func newProfileOrganizations(organizations []database.Organization, activeID uint) []ProfileOrganization {
+	result := make([]ProfileOrganization, 0, len(organizations))
+	for _, org := range organizations {
+		result = append(result, ProfileOrganization{
+			ID:     org.ID,
+			Name:   org.Name,
+			Active: org.ID == activeID,
+		})
+	}
+	return result
+}
+
+// parseOrganizationID reads the org_id path parameter, responding with 400 when it is invalid
+func parseOrganizationID(c *gin.Context) (uint, bool) {
+	organizationID, err := strconv.ParseUint(c.Param("org_id"), 10, 32)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, CodeInvalidInput, "Invalid organization ID")
+		return 0, false
+	}
+	return uint(organizationID), true
+}
+
+// CreateOrganization handles creating an organization, with the current user as its
+// first member; the new organization becomes the user's active one
+func (h *Handlers) CreateOrganization(c *gin.Context) {
+	userEmail, exists := c.Get("user_email")
+	if !exists {
+		respondError(c, http.StatusUnauthorized, CodeUnauthenticated, "User not authenticated")
+		return
+	}
+
+	var req OrganizationCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondInvalidInput(c, err)
+		return
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		respondError(c, http.StatusBadRequest, CodeInvalidInput, "Organization name is required")
+		return
+	}
+
+	created, err := h.organizationService.CreateOrganizationForUser(name, userEmail.(string))
+	if err != nil {
+		if errors.Is(err, organization.ErrOrganizationAlreadyExists) {
+			respondError(c, http.StatusConflict, CodeConflict, "Organization already exists")
+			return
+		}
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to create organization")
+		return
+	}
+
+	c.JSON(http.StatusCreated, ProfileOrganization{ID: created.ID, Name: created.Name, Active: true})
+}
+
+// GetOrganizations handles listing the organizations the current user belongs to
+func (h *Handlers) GetOrganizations(c *gin.Context) {
+	userEmail, exists := c.Get("user_email")
+	if !exists {
+		respondError(c, http.StatusUnauthorized, CodeUnauthenticated, "User not authenticated")
+		return
+	}
+
+	u, err := h.userService.GetUser(userEmail.(string))
+	if err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			respondError(c, http.StatusNotFound, CodeNotFound, "User not found")
+			return
+		}
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to get user")
+		return
+	}
+
+	organizations, err := h.organizationService.GetOrganizationsByUser(u.Email)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to get organizations")
+		return
+	}
+
+	c.JSON(http.StatusOK, newProfileOrganizations(organizations, u.ActiveOrganizationID))
+}
+
+// AddOrganizationMember handles adding an existing user to an organization the current user belongs to
+func (h *Handlers) AddOrganizationMember(c *gin.Context) {
+	userEmail, exists := c.Get("user_email")
+	if !exists {
+		respondError(c, http.StatusUnauthorized, CodeUnauthenticated, "User not authenticated")
+		return
+	}
+
+	organizationID, ok := parseOrganizationID(c)
+	if !ok {
+		return
+	}
+
+	var req OrganizationMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondInvalidInput(c, err)
+		return
+	}
+
+	if _, err := h.organizationService.GetOrganization(organizationID); err != nil {
+		if errors.Is(err, organization.ErrOrganizationNotFound) {
+			respondError(c, http.StatusNotFound, CodeNotFound, "Organization not found")
+			return
+		}
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to get organization")
+		return
+	}
+
+	isMember, err := h.organizationService.IsMember(organizationID, userEmail.(string))
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to check membership")
+		return
+	}
+	if !isMember {
+		respondError(c, http.StatusForbidden, CodeNotMember, "Not a member of this organization")
+		return
+	}
+
+	if _, err := h.userService.GetUser(req.Email); err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			respondError(c, http.StatusNotFound, CodeNotFound, "User not found")
+			return
+		}
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to get user")
+		return
+	}
+
+	if err := h.organizationService.AddUserToOrganization(organizationID, req.Email); err != nil {
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to add member")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"organization_id": organizationID, "email": req.Email})
+}
+
+// SetActiveOrganization handles switching the current user's active organization
+func (h *Handlers) SetActiveOrganization(c *gin.Context) {
+	userEmail, exists := c.Get("user_email")
+	if !exists {
+		respondError(c, http.StatusUnauthorized, CodeUnauthenticated, "User not authenticated")
+		return
+	}
+
+	organizationID, ok := parseOrganizationID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.organizationService.SetUserActiveOrganization(userEmail.(string), organizationID); err != nil {
+		switch {
+		case errors.Is(err, organization.ErrOrganizationNotFound):
+			respondError(c, http.StatusNotFound, CodeNotFound, "Organization not found")
+		case errors.Is(err, organization.ErrNotMember):
+			respondError(c, http.StatusForbidden, CodeNotMember, "Not a member of this organization")
+		default:
+			respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to set active organization")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"active_organization_id": organizationID})
+}
+
+// DeleteOrganization handles deleting an organization along with its tags;
+// the route is restricted to owners
+func (h *Handlers) DeleteOrganization(c *gin.Context) {
+	organizationID, ok := parseOrganizationID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.organizationService.DeleteOrganization(organizationID); err != nil {
+		switch {
+		case errors.Is(err, organization.ErrOrganizationNotFound):
+			respondError(c, http.StatusNotFound, CodeNotFound, "Organization not found")
+		case errors.Is(err, organization.ErrLastOrganization):
+			respondError(c, http.StatusConflict, CodeConflict, "Organization is the only one of a member")
+		default:
+			respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to delete organization")
+		}
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// CreateOrganizationInvitation handles inviting an email address to join an
+// organization; the route is restricted to owners and admins
+func (h *Handlers) CreateOrganizationInvitation(c *gin.Context) {
+	organizationID, ok := parseOrganizationID(c)
+	if !ok {
+		return
+	}
+
+	var req OrganizationInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondInvalidInput(c, err)
+		return
+	}
+
+	invitation, err := h.organizationService.CreateInvitation(organizationID, req.Email, h.config.Organization.InvitationTTL)
+	if err != nil {
+		if errors.Is(err, organization.ErrOrganizationNotFound) {
+			respondError(c, http.StatusNotFound, CodeNotFound, "Organization not found")
+			return
+		}
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to create invitation")
+		return
+	}
+
+	h.sendEmailAsync(notify.Message{
+		To:      invitation.Email,
+		Subject: "You're invited to a SchwiftyBox organization",
+		Body:    "You have been invited to join an organization on SchwiftyBox. Accept the invitation using this token: " + invitation.Token,
+	})
+
+	c.JSON(http.StatusCreated, invitation)
+}
+
+// AcceptOrganizationInvitation handles the invited user joining an organization
+func (h *Handlers) AcceptOrganizationInvitation(c *gin.Context) {
+	userEmail, exists := c.Get("user_email")
+	if !exists {
+		respondError(c, http.StatusUnauthorized, CodeUnauthenticated, "User not authenticated")
+		return
+	}
+
+	invitation, err := h.organizationService.AcceptInvitation(c.Param("token"), userEmail.(string))
+	if err != nil {
+		switch {
+		case errors.Is(err, organization.ErrInvitationNotFound):
+			respondError(c, http.StatusNotFound, CodeNotFound, "Invitation not found")
+		case errors.Is(err, organization.ErrInvitationExpired):
+			respondError(c, http.StatusGone, CodeGone, "Invitation has expired")
+		case errors.Is(err, organization.ErrInvitationUsed):
+			respondError(c, http.StatusConflict, CodeConflict, "Invitation has already been accepted")
+		case errors.Is(err, organization.ErrInvitationEmailMismatch):
+			respondError(c, http.StatusForbidden, CodeForbidden, "Invitation was sent to a different email")
+		default:
+			respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to accept invitation")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, invitation)
+}
+
+// ReactivateOrganizationMember handles an admin reactivating a deactivated member of
+// an organization
+func (h *Handlers) ReactivateOrganizationMember(c *gin.Context) {
+	organizationID, ok := parseOrganizationID(c)
+	if !ok {
+		return
+	}
+
+	email := c.Param("email")
+	isMember, err := h.organizationService.IsMember(organizationID, email)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to check membership")
+		return
+	}
+	if !isMember {
+		respondError(c, http.StatusNotFound, CodeNotFound, "Member not found")
+		return
+	}
+
+	h.reactivateUser(c, email)
+}
@@ -0,0 +1,247 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"backend/internal/organization"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// createTestOrganization creates an organization for auth@example.com through the handler
+func createTestOrganization(t *testing.T, handlers *Handlers, name string) ProfileOrganization {
+	c, w := createAuthenticatedRequest(handlers, "POST", "/organizations", []byte(fmt.Sprintf(`{"name":%q}`, name)))
+	handlers.CreateOrganization(c)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var created ProfileOrganization
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	return created
+}
+
+func TestCreateOrganization_ThenList(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	created := createTestOrganization(t, handlers, "Camping Club")
+
+	assert.Equal(t, "Camping Club", created.Name)
+	assert.True(t, created.Active)
+
+	c, w := createAuthenticatedRequest(handlers, "GET", "/organizations", nil)
+	handlers.GetOrganizations(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var organizations []ProfileOrganization
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &organizations))
+	assert.Len(t, organizations, 2)
+	for _, org := range organizations {
+		assert.Equal(t, org.ID == created.ID, org.Active, "organization %d", org.ID)
+	}
+}
+
+func TestCreateOrganization_InvalidInput(t *testing.T) {
+	handlers := setupTestHandlers(t)
+
+	for _, body := range []string{`{}`, `{"name":"   "}`} {
+		c, w := createAuthenticatedRequest(handlers, "POST", "/organizations", []byte(body))
+		handlers.CreateOrganization(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+	}
+}
+
+func TestSetActiveOrganization(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	created := createTestOrganization(t, handlers, "Camping Club")
+
+	u, err := handlers.userService.GetUser("auth@example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, created.ID, u.ActiveOrganizationID)
+
+	// Switch back to the personal organization
+	organizations, err := handlers.organizationService.GetOrganizationsByUser("auth@example.com")
+	assert.NoError(t, err)
+	var personalID uint
+	for _, org := range organizations {
+		if org.ID != created.ID {
+			personalID = org.ID
+		}
+	}
+
+	c, w := createAuthenticatedRequest(handlers, "PUT", "/organizations/active", nil)
+	c.Params = gin.Params{{Key: "org_id", Value: fmt.Sprintf("%d", personalID)}}
+	handlers.SetActiveOrganization(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	u, err = handlers.userService.GetUser("auth@example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, personalID, u.ActiveOrganizationID)
+}
+
+func TestSetActiveOrganization_NotMember(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	other, err := handlers.organizationService.CreateOrganization("Other")
+	assert.NoError(t, err)
+
+	c, w := createAuthenticatedRequest(handlers, "PUT", "/organizations/active", nil)
+	c.Params = gin.Params{{Key: "org_id", Value: fmt.Sprintf("%d", other.ID)}}
+	handlers.SetActiveOrganization(c)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), CodeNotMember)
+
+	c, w = createAuthenticatedRequest(handlers, "PUT", "/organizations/active", nil)
+	c.Params = gin.Params{{Key: "org_id", Value: "9999"}}
+	handlers.SetActiveOrganization(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestAddOrganizationMember(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	created := createTestOrganization(t, handlers, "Camping Club")
+	assert.NoError(t, handlers.userService.CreateUser("friend@example.com", "password123"))
+
+	c, w := createAuthenticatedRequest(handlers, "POST", "/organizations/members", []byte(`{"email":"friend@example.com"}`))
+	c.Params = gin.Params{{Key: "org_id", Value: fmt.Sprintf("%d", created.ID)}}
+	handlers.AddOrganizationMember(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	isMember, err := handlers.organizationService.IsMember(created.ID, "friend@example.com")
+	assert.NoError(t, err)
+	assert.True(t, isMember)
+
+	// Unknown users can't be added
+	c, w = createAuthenticatedRequest(handlers, "POST", "/organizations/members", []byte(`{"email":"nobody@example.com"}`))
+	c.Params = gin.Params{{Key: "org_id", Value: fmt.Sprintf("%d", created.ID)}}
+	handlers.AddOrganizationMember(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestAddOrganizationMember_NotMember(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	other, err := handlers.organizationService.CreateOrganization("Other")
+	assert.NoError(t, err)
+
+	c, w := createAuthenticatedRequest(handlers, "POST", "/organizations/members", []byte(`{"email":"auth@example.com"}`))
+	c.Params = gin.Params{{Key: "org_id", Value: fmt.Sprintf("%d", other.ID)}}
+	handlers.AddOrganizationMember(c)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestCreateOrganizationInvitation(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	created := createTestOrganization(t, handlers, "Camping Club")
+
+	c, w := createAuthenticatedRequest(handlers, "POST", "/organizations/invitations", []byte(`{"email":"friend@example.com"}`))
+	c.Params = gin.Params{{Key: "org_id", Value: fmt.Sprintf("%d", created.ID)}}
+	handlers.CreateOrganizationInvitation(c)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var response map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "friend@example.com", response["email"])
+	assert.Equal(t, organization.InvitationPending, response["status"])
+	assert.NotContains(t, response, "token")
+
+	// The token is only sent to the invited address
+	handlers.emails.Wait()
+	sent := handlers.mailer.(*fakeMailer).sentTo("friend@example.com")
+	if assert.Len(t, sent, 1) {
+		assert.Contains(t, sent[0].Subject, "invited")
+	}
+}
+
+func TestAcceptOrganizationInvitation(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	other, err := handlers.organizationService.CreateOrganization("Other")
+	assert.NoError(t, err)
+	invitation, err := handlers.organizationService.CreateInvitation(other.ID, "auth@example.com", time.Minute)
+	assert.NoError(t, err)
+
+	c, w := createAuthenticatedRequest(handlers, "POST", "/invitations/accept", nil)
+	c.Params = gin.Params{{Key: "token", Value: invitation.Token}}
+	handlers.AcceptOrganizationInvitation(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	isMember, err := handlers.organizationService.IsMember(other.ID, "auth@example.com")
+	assert.NoError(t, err)
+	assert.True(t, isMember)
+
+	// Invitations are single-use
+	c, w = createAuthenticatedRequest(handlers, "POST", "/invitations/accept", nil)
+	c.Params = gin.Params{{Key: "token", Value: invitation.Token}}
+	handlers.AcceptOrganizationInvitation(c)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestAcceptOrganizationInvitation_Expired(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	other, err := handlers.organizationService.CreateOrganization("Other")
+	assert.NoError(t, err)
+	invitation, err := handlers.organizationService.CreateInvitation(other.ID, "auth@example.com", -time.Minute)
+	assert.NoError(t, err)
+
+	c, w := createAuthenticatedRequest(handlers, "POST", "/invitations/accept", nil)
+	c.Params = gin.Params{{Key: "token", Value: invitation.Token}}
+	handlers.AcceptOrganizationInvitation(c)
+
+	assert.Equal(t, http.StatusGone, w.Code)
+	assert.Contains(t, w.Body.String(), CodeGone)
+}
+
+func TestCreateOrganizationInvitation_DoesNotWaitForEmail(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	created := createTestOrganization(t, handlers, "Camping Club")
+	c, w := createAuthenticatedRequest(handlers, "POST", "/organizations/invitations", []byte(`{"email":"friend@example.com"}`))
+	c.Params = gin.Params{{Key: "org_id", Value: fmt.Sprintf("%d", created.ID)}}
+
+	// The response is written while the mail server is still stuck
+	handlers.emails.Wait()
+	mailer := &blockingMailer{release: make(chan struct{})}
+	handlers.mailer = mailer
+	handlers.CreateOrganizationInvitation(c)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	close(mailer.release)
+	handlers.emails.Wait()
+}
+
+func TestReactivateOrganizationMember(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	created := createTestOrganization(t, handlers, "Camping Club")
+	assert.NoError(t, handlers.userService.CreateUser("friend@example.com", "password123"))
+	assert.NoError(t, handlers.userService.CreateUser("stranger@example.com", "password123"))
+	assert.NoError(t, handlers.organizationService.AddUserToOrganization(created.ID, "friend@example.com"))
+	assert.NoError(t, handlers.userService.SetActive("friend@example.com", false))
+	assert.NoError(t, handlers.userService.SetActive("stranger@example.com", false))
+
+	orgID := fmt.Sprintf("%d", created.ID)
+	c, w := createAuthenticatedRequest(handlers, "POST", "/organizations/"+orgID+"/members/friend@example.com/reactivate", nil)
+	c.Params = gin.Params{{Key: "org_id", Value: orgID}, {Key: "email", Value: "friend@example.com"}}
+	handlers.ReactivateOrganizationMember(c)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	reactivated, err := handlers.userService.GetUser("friend@example.com")
+	assert.NoError(t, err)
+	assert.True(t, reactivated.Active)
+
+	// Users outside the organization are left alone
+	c, w = createAuthenticatedRequest(handlers, "POST", "/organizations/"+orgID+"/members/stranger@example.com/reactivate", nil)
+	c.Params = gin.Params{{Key: "org_id", Value: orgID}, {Key: "email", Value: "stranger@example.com"}}
+	handlers.ReactivateOrganizationMember(c)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	stranger, err := handlers.userService.GetUser("stranger@example.com")
+	assert.NoError(t, err)
+	assert.False(t, stranger.Active)
+}
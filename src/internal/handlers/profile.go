@@ -0,0 +1,229 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+
+	"backend/internal/database"
+	"backend/internal/item"
+	"backend/internal/user"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// UserResponse is the public view of a user, without credentials
+type UserResponse struct {
+	Email       string    `json:"email"`
+	DisplayName string    `json:"display_name"`
+	AvatarURL   string    `json:"avatar_url"`
+	Prefix      string    `json:"prefix"`
+	Role        string    `json:"role"`
+	Active      bool      `json:"active"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// CurrentUserResponse represents the authenticated user's own record
+type CurrentUserResponse struct {
+	UserResponse
+	ActiveOrganizationID uint `json:"active_organization_id"`
+}
+
+// ProfileUpdateRequest represents the current user's profile update request body
+type ProfileUpdateRequest struct {
+	DisplayName *string `json:"display_name" binding:"omitempty,max=100"`
+	AvatarURL   *string `json:"avatar_url" binding:"omitempty,max=500"`
+}
+
+// ProfileOrganization is an organization the user belongs to
+type ProfileOrganization struct {
+	ID     uint   `json:"id"`
+	Name   string `json:"name"`
+	Active bool   `json:"active"`
+}
+
+// FullProfileResponse represents the user profile with organizations
+type FullProfileResponse struct {
+	User                 UserResponse          `json:"user"`
+	Organizations        []ProfileOrganization `json:"organizations"`
+	ActiveOrganizationID uint                  `json:"active_organization_id"`
+}
+
+// newUserResponse strips credentials from a user
+func newUserResponse(u *database.User) UserResponse {
+	return UserResponse{
+		Email:       u.Email,
+		DisplayName: u.DisplayName,
+		AvatarURL:   u.AvatarURL,
+		Prefix:      u.Prefix,
+		Role:        u.Role,
+		Active:      u.Active,
+		CreatedAt:   u.CreatedAt,
+	}
+}
+
+// isValidAvatarURL reports whether an avatar URL is empty (clearing it) or an absolute http(s) URL
+func isValidAvatarURL(raw string) bool {
+	if raw == "" {
+		return true
+	}
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+	return (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
+
+// GetCurrentUser handles getting the authenticated user's profile, returning 404 if the
+// user was deleted after the token was issued
+func (h *Handlers) GetCurrentUser(c *gin.Context) {
+	userEmail, exists := c.Get("user_email")
+	if !exists {
+		respondError(c, http.StatusUnauthorized, CodeUnauthenticated, "User not authenticated")
+		return
+	}
+
+	u, err := h.userService.GetUser(userEmail.(string))
+	if err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			respondError(c, http.StatusNotFound, CodeNotFound, "User not found")
+			return
+		}
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to get user")
+		return
+	}
+
+	c.JSON(http.StatusOK, CurrentUserResponse{
+		UserResponse:         newUserResponse(u),
+		ActiveOrganizationID: u.ActiveOrganizationID,
+	})
+}
+
+// UpdateCurrentUser handles updating the authenticated user's display name and avatar
+func (h *Handlers) UpdateCurrentUser(c *gin.Context) {
+	userEmail, exists := c.Get("user_email")
+	if !exists {
+		respondError(c, http.StatusUnauthorized, CodeUnauthenticated, "User not authenticated")
+		return
+	}
+
+	var req ProfileUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondInvalidInput(c, err)
+		return
+	}
+	if req.AvatarURL != nil && !isValidAvatarURL(*req.AvatarURL) {
+		respondError(c, http.StatusBadRequest, CodeInvalidInput, "Avatar URL must be an http or https URL")
+		return
+	}
+
+	u, err := h.userService.UpdateProfile(userEmail.(string), req.DisplayName, req.AvatarURL)
+	if err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			respondError(c, http.StatusNotFound, CodeNotFound, "User not found")
+			return
+		}
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to update user")
+		return
+	}
+
+	c.JSON(http.StatusOK, newUserResponse(u))
+}
+
+// GetFullProfile handles getting the current user with all of their organizations
+func (h *Handlers) GetFullProfile(c *gin.Context) {
+	userEmail, exists := c.Get("user_email")
+	if !exists {
+		respondError(c, http.StatusUnauthorized, CodeUnauthenticated, "User not authenticated")
+		return
+	}
+
+	u, err := h.userService.GetUser(userEmail.(string))
+	if err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			respondError(c, http.StatusNotFound, CodeNotFound, "User not found")
+			return
+		}
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to get user")
+		return
+	}
+
+	organizations, err := h.organizationService.GetOrganizationsByUser(u.Email)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to get organizations")
+		return
+	}
+
+	response := FullProfileResponse{
+		User:                 newUserResponse(u),
+		Organizations:        newProfileOrganizations(organizations, u.ActiveOrganizationID),
+		ActiveOrganizationID: u.ActiveOrganizationID,
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// PrefixResponse is the current user's backpack prefix and its counter
+type PrefixResponse struct {
+	Prefix         string `json:"prefix"`
+	LastNumber     int    `json:"last_number"`
+	NextBackpackID string `json:"next_backpack_id"`
+}
+
+// newPrefixResponse builds the response for a prefix sequence
+func newPrefixResponse(sequence *item.PrefixSequence) PrefixResponse {
+	return PrefixResponse{
+		Prefix:         sequence.Prefix,
+		LastNumber:     sequence.LastNumber,
+		NextBackpackID: sequence.NextBackpackID(),
+	}
+}
+
+// GetPrefix handles getting the current user's backpack prefix and the last number issued for it
+func (h *Handlers) GetPrefix(c *gin.Context) {
+	userEmail, exists := c.Get("user_email")
+	if !exists {
+		respondError(c, http.StatusUnauthorized, CodeUnauthenticated, "User not authenticated")
+		return
+	}
+
+	sequence, err := h.itemService.GetPrefixSequence(userEmail.(string))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, http.StatusNotFound, CodeNotFound, "User not found")
+			return
+		}
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to get prefix")
+		return
+	}
+
+	c.JSON(http.StatusOK, newPrefixResponse(sequence))
+}
+
+// RegeneratePrefix handles replacing the current user's backpack prefix with a new unused
+// one; numbering starts over and existing items keep their backpack IDs
+func (h *Handlers) RegeneratePrefix(c *gin.Context) {
+	userEmail, exists := c.Get("user_email")
+	if !exists {
+		respondError(c, http.StatusUnauthorized, CodeUnauthenticated, "User not authenticated")
+		return
+	}
+
+	sequence, err := h.itemService.RegeneratePrefix(userEmail.(string))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, http.StatusNotFound, CodeNotFound, "User not found")
+			return
+		}
+		if errors.Is(err, item.ErrPrefixUnavailable) {
+			respondError(c, http.StatusConflict, CodeConflict, "No unused prefix available")
+			return
+		}
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to regenerate prefix")
+		return
+	}
+
+	c.JSON(http.StatusOK, newPrefixResponse(sequence))
+}
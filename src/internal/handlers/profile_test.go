@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"backend/internal/database"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetFullProfile_Success(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	c, w := createAuthenticatedRequest(handlers, "GET", "/users/me/full", nil)
+
+	// Add the user to a second organization
+	second, err := handlers.organizationService.CreateOrganization("Second Org")
+	assert.NoError(t, err)
+	assert.NoError(t, handlers.organizationService.AddUserToOrganization(second.ID, "auth@example.com"))
+
+	handlers.GetFullProfile(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "password")
+
+	var response FullProfileResponse
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, "auth@example.com", response.User.Email)
+	assert.Len(t, response.Organizations, 2)
+	assert.NotEqual(t, second.ID, response.ActiveOrganizationID)
+
+	for _, org := range response.Organizations {
+		assert.Equal(t, org.ID == response.ActiveOrganizationID, org.Active, "organization %d", org.ID)
+	}
+}
+
+func TestGetFullProfile_Unauthenticated(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	c, w := setupGinContext()
+
+	handlers.GetFullProfile(c)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestUpdateCurrentUser_ThenGetCurrentUser(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	c, w := createAuthenticatedRequest(handlers, "PATCH", "/users/me", []byte(`{"display_name":" Rick ","avatar_url":"https://example.com/rick.png"}`))
+
+	handlers.UpdateCurrentUser(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	c, w = createAuthenticatedRequest(handlers, "GET", "/users/me", nil)
+	handlers.GetCurrentUser(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "password")
+
+	var current CurrentUserResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &current))
+	assert.Equal(t, "auth@example.com", current.Email)
+	assert.NotZero(t, current.ActiveOrganizationID)
+	assert.Equal(t, "Rick", current.DisplayName)
+	assert.Equal(t, "https://example.com/rick.png", current.AvatarURL)
+
+	// Fields left out of the request are unchanged
+	c, w = createAuthenticatedRequest(handlers, "PATCH", "/users/me", []byte(`{"display_name":"Morty"}`))
+	handlers.UpdateCurrentUser(c)
+	var response UserResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "Morty", response.DisplayName)
+	assert.Equal(t, "https://example.com/rick.png", response.AvatarURL)
+}
+
+func TestUpdateCurrentUser_InvalidAvatarURL(t *testing.T) {
+	handlers := setupTestHandlers(t)
+
+	for _, avatar := range []string{"not a url", "ftp://example.com/a.png", "https://", "/relative.png"} {
+		body, _ := json.Marshal(ProfileUpdateRequest{AvatarURL: &avatar})
+		c, w := createAuthenticatedRequest(handlers, "PATCH", "/users/me", body)
+		handlers.UpdateCurrentUser(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, avatar)
+	}
+}
+
+func TestGetCurrentUser_Unauthenticated(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	c, w := setupGinContext()
+
+	handlers.GetCurrentUser(c)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestGetCurrentUser_DeletedAfterLogin(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	c, w := createAuthenticatedRequest(handlers, "GET", "/users/me", nil)
+	assert.NoError(t, handlers.db.Where("email = ?", "auth@example.com").Delete(&database.User{}).Error)
+
+	handlers.GetCurrentUser(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestGetPrefix(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	item := createTestItem(t, handlers, "Tent")
+
+	c, w := createAuthenticatedRequest(handlers, "GET", "/users/me/prefix", nil)
+	handlers.GetPrefix(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response PrefixResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "AUT", response.Prefix)
+	assert.Equal(t, 1, response.LastNumber)
+	assert.Equal(t, "AUT0002", response.NextBackpackID)
+	assert.Equal(t, "AUT0001", item.BackpackID)
+}
+
+func TestRegeneratePrefix(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	createTestItem(t, handlers, "Tent")
+
+	c, w := createAuthenticatedRequest(handlers, "POST", "/users/me/prefix", nil)
+	handlers.RegeneratePrefix(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response PrefixResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.NotEqual(t, "AUT", response.Prefix)
+	assert.Equal(t, 0, response.LastNumber)
+	assert.Equal(t, response.Prefix+"0001", response.NextBackpackID)
+
+	item := createTestItem(t, handlers, "Stove")
+	assert.Equal(t, response.Prefix+"0001", item.BackpackID)
+}
+
+func TestGetPrefix_Unauthenticated(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	c, w := setupGinContext()
+
+	handlers.GetPrefix(c)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"backend/internal/item"
+
+	"github.com/gin-gonic/gin"
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// Bounds and default of the QR code size query parameter, in pixels
+const (
+	minQRSize     = 64
+	maxQRSize     = 1024
+	defaultQRSize = 256
+)
+
+// GetItemQRCode handles rendering an item's backpack ID as a PNG QR code for labeling
+func (h *Handlers) GetItemQRCode(c *gin.Context) {
+	userEmail, exists := c.Get("user_email")
+	if !exists {
+		respondError(c, http.StatusUnauthorized, CodeUnauthenticated, "User not authenticated")
+		return
+	}
+
+	itemID, err := strconv.ParseUint(c.Param("item_id"), 10, 32)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, CodeInvalidInput, "Invalid item ID")
+		return
+	}
+
+	size := defaultQRSize
+	if raw, ok := c.GetQuery("size"); ok {
+		size, err = strconv.Atoi(raw)
+		if err != nil || size < minQRSize || size > maxQRSize {
+			respondError(c, http.StatusBadRequest, CodeInvalidInput,
+				fmt.Sprintf("Invalid size value, expected %d to %d", minQRSize, maxQRSize))
+			return
+		}
+	}
+
+	found, err := h.itemService.GetItem(uint(itemID), userEmail.(string))
+	if err != nil {
+		if errors.Is(err, item.ErrItemNotFound) {
+			respondError(c, http.StatusNotFound, CodeNotFound, "Item not found")
+			return
+		}
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to get item")
+		return
+	}
+
+	png, err := qrcode.Encode(found.BackpackID, qrcode.Medium, size)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to generate QR code")
+		return
+	}
+
+	c.Data(http.StatusOK, "image/png", png)
+}
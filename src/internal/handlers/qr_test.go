@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"image/png"
+	"net/http"
+	"testing"
+
+	"backend/internal/database"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetItemQRCode_Success(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	created := createTestItem(t, handlers, "Tent")
+	itemID := fmt.Sprintf("%d", created.ID)
+
+	c, w := createAuthenticatedRequest(handlers, "GET", "/items/"+itemID+"/qr?size=128", nil)
+	c.Params = gin.Params{{Key: "item_id", Value: itemID}}
+	handlers.GetItemQRCode(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "image/png", w.Header().Get("Content-Type"))
+	assert.NotEmpty(t, w.Body.Bytes())
+
+	img, err := png.Decode(bytes.NewReader(w.Body.Bytes()))
+	if assert.NoError(t, err) {
+		assert.Equal(t, 128, img.Bounds().Dx())
+	}
+}
+
+func TestGetItemQRCode_InvalidSize(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	created := createTestItem(t, handlers, "Tent")
+	itemID := fmt.Sprintf("%d", created.ID)
+
+	for _, size := range []string{"abc", "10", "5000"} {
+		c, w := createAuthenticatedRequest(handlers, "GET", "/items/"+itemID+"/qr?size="+size, nil)
+		c.Params = gin.Params{{Key: "item_id", Value: itemID}}
+		handlers.GetItemQRCode(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, size)
+	}
+}
+
+func TestGetItemQRCode_NotOwned(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	other := database.Item{Name: "Other", BackpackID: "OTH0001", UserEmail: "other@example.com"}
+	handlers.db.Create(&other)
+	itemID := fmt.Sprintf("%d", other.ID)
+
+	c, w := createAuthenticatedRequest(handlers, "GET", "/items/"+itemID+"/qr", nil)
+	c.Params = gin.Params{{Key: "item_id", Value: itemID}}
+	handlers.GetItemQRCode(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"backend/internal/database"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Search handles searching the user's items and organization tags together
+func (h *Handlers) Search(c *gin.Context) {
+	userEmail, exists := c.Get("user_email")
+	if !exists {
+		respondError(c, http.StatusUnauthorized, CodeUnauthenticated, "User not authenticated")
+		return
+	}
+
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		respondError(c, http.StatusBadRequest, CodeInvalidInput, "Search query is required")
+		return
+	}
+
+	// Get user's organization
+	var user database.User
+	if err := h.db.Where("email = ?", userEmail).First(&user).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to get user")
+		return
+	}
+
+	limit := h.config.Search.ResultLimit
+
+	items, err := h.itemService.SearchItems(user.Email, query, limit)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to search items")
+		return
+	}
+
+	tags, err := h.tagService.SearchTags(user.ActiveOrganizationID, query, limit)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to search tags")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": items, "tags": tags})
+}
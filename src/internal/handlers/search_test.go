@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"backend/internal/database"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// search runs a search request and decodes the response
+func search(t *testing.T, handlers *Handlers, query string) (int, []string, []string) {
+	c, w := createAuthenticatedRequest(handlers, "GET", "/search?q="+url.QueryEscape(query), nil)
+	handlers.Search(c)
+
+	var response struct {
+		Items []database.Item `json:"items"`
+		Tags  []database.Tag  `json:"tags"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &response)
+
+	var items, tags []string
+	for _, item := range response.Items {
+		items = append(items, item.Name)
+	}
+	for _, tag := range response.Tags {
+		tags = append(tags, tag.Name)
+	}
+	return w.Code, items, tags
+}
+
+func TestSearch_MatchesItemsAndTags(t *testing.T) {
+	handlers := setupTestHandlers(t)
+
+	createTestItem(t, handlers, "Camping Stove")
+	c, _ := createAuthenticatedRequest(handlers, "POST", "/items", []byte(`{"name":"Tent","description":"For camping trips"}`))
+	handlers.CreateItem(c)
+	createTestItem(t, handlers, "Hammer")
+	createTestTag(t, handlers, "camping", "#00ff00")
+	createTestTag(t, handlers, "tools", "#ff0000")
+
+	code, items, tags := search(t, handlers, "CAMP")
+
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []string{"Camping Stove", "Tent"}, items)
+	assert.Equal(t, []string{"camping"}, tags)
+}
+
+func TestSearch_RespectsLimit(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	handlers.config.Search.ResultLimit = 2
+
+	createTestItem(t, handlers, "Box A")
+	createTestItem(t, handlers, "Box B")
+	createTestItem(t, handlers, "Box C")
+
+	_, items, tags := search(t, handlers, "box")
+
+	assert.Equal(t, []string{"Box A", "Box B"}, items)
+	assert.Empty(t, tags)
+}
+
+func TestSearch_WildcardsMatchLiterally(t *testing.T) {
+	handlers := setupTestHandlers(t)
+
+	createTestItem(t, handlers, "100% wool socks")
+	createTestItem(t, handlers, "1000 matches")
+	createTestItem(t, handlers, "gear_box")
+	createTestItem(t, handlers, "gear box")
+	createTestTag(t, handlers, "50%_off", "#00ff00")
+	createTestTag(t, handlers, "50 cents off", "#ff0000")
+
+	_, items, _ := search(t, handlers, "100%")
+	assert.Equal(t, []string{"100% wool socks"}, items)
+
+	_, items, _ = search(t, handlers, "gear_")
+	assert.Equal(t, []string{"gear_box"}, items)
+
+	_, _, tags := search(t, handlers, "%_")
+	assert.Equal(t, []string{"50%_off"}, tags)
+}
+
+func TestSearch_MissingQuery(t *testing.T) {
+	handlers := setupTestHandlers(t)
+
+	code, _, _ := search(t, handlers, "")
+
+	assert.Equal(t, http.StatusBadRequest, code)
+}
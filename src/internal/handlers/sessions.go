@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"backend/internal/jwt"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Logout handles revoking a refresh token, along with the access token
+// from the Authorization header when one is sent
+func (h *Handlers) Logout(c *gin.Context) {
+	var req RefreshRequest
+	if err := h.bindRefreshRequest(c, &req); err != nil {
+		respondInvalidInput(c, err)
+		return
+	}
+
+	if _, err := h.jwtService.ValidateRefreshToken(req.RefreshToken); err != nil {
+		respondError(c, http.StatusUnauthorized, CodeInvalidToken, "Invalid refresh token")
+		return
+	}
+
+	if err := h.jwtService.RevokeRefreshToken(req.RefreshToken); err != nil {
+		if errors.Is(err, jwt.ErrRefreshTokenNotFound) {
+			respondError(c, http.StatusUnauthorized, CodeInvalidToken, "Invalid refresh token")
+			return
+		}
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to log out")
+		return
+	}
+
+	if err := h.jwtService.Revoke(req.RefreshToken); err != nil {
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to log out")
+		return
+	}
+
+	// An expired or already invalid access token needs no revoking
+	if accessToken, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer "); ok {
+		if _, err := h.jwtService.ValidateToken(accessToken); err == nil {
+			if err := h.jwtService.Revoke(accessToken); err != nil && !errors.Is(err, jwt.ErrTokenNotRevocable) {
+				respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to log out")
+				return
+			}
+		}
+	}
+
+	h.clearRefreshCookie(c)
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
+}
+
+// refreshCookieName and refreshCookiePath scope the refresh token cookie to the API,
+// which covers the refresh and logout endpoints
+const (
+	refreshCookieName = "refresh_token"
+	refreshCookiePath = "/api"
+)
+
+// bindRefreshRequest binds the request body, falling back to the refresh token cookie
+// when enabled and the body carries no token
+func (h *Handlers) bindRefreshRequest(c *gin.Context, req *RefreshRequest) error {
+	err := c.ShouldBindJSON(req)
+	if err == nil || !h.config.JWT.RefreshCookie {
+		return err
+	}
+	token, cookieErr := c.Cookie(refreshCookieName)
+	if cookieErr != nil || token == "" {
+		return err
+	}
+	req.RefreshToken = token
+	return nil
+}
+
+// setRefreshCookie sets the refresh token cookie when enabled, expiring with the token
+func (h *Handlers) setRefreshCookie(c *gin.Context, refreshToken string) {
+	if !h.config.JWT.RefreshCookie {
+		return
+	}
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     refreshCookieName,
+		Value:    refreshToken,
+		Path:     refreshCookiePath,
+		MaxAge:   int(h.config.JWT.RefreshTokenDuration.Seconds()),
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteStrictMode,
+	})
+}
+
+// clearRefreshCookie tells the browser to drop the refresh token cookie when enabled
+func (h *Handlers) clearRefreshCookie(c *gin.Context) {
+	if !h.config.JWT.RefreshCookie {
+		return
+	}
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     refreshCookieName,
+		Value:    "",
+		Path:     refreshCookiePath,
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteStrictMode,
+	})
+}
+
+// GetSessionCount handles getting the number of active sessions for the current user
+func (h *Handlers) GetSessionCount(c *gin.Context) {
+	userEmail, exists := c.Get("user_email")
+	if !exists {
+		respondError(c, http.StatusUnauthorized, CodeUnauthenticated, "User not authenticated")
+		return
+	}
+
+	count, err := h.jwtService.CountActiveSessions(userEmail.(string))
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to count sessions")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"count": count})
+}
@@ -0,0 +1,310 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"backend/internal/jwt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// loginTestUser logs in as the given user, registering them first if needed
+func loginTestUser(t *testing.T, handlers *Handlers, email string) jwt.TokenResponse {
+	c, _ := setupGinContext()
+	c.Request = httptest.NewRequest("POST", "/users", bytes.NewBufferString(`{"email":"`+email+`","password":"password123"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	handlers.RegisterUser(c)
+
+	c, w := setupGinContext()
+	c.Request = httptest.NewRequest("POST", "/token", bytes.NewBufferString(`{"email":"`+email+`","password":"password123"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	handlers.Login(c)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Failed to log in: %d %s", w.Code, w.Body.String())
+	}
+
+	var tokens jwt.TokenResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &tokens); err != nil {
+		t.Fatalf("Failed to decode tokens: %v", err)
+	}
+	return tokens
+}
+
+// getSessionCount returns the session count reported for the given user
+func getSessionCount(t *testing.T, handlers *Handlers, email string) int64 {
+	c, w := setupGinContext()
+	c.Request = httptest.NewRequest("GET", "/users/me/sessions/count", nil)
+	c.Set("user_email", email)
+	handlers.GetSessionCount(c)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Failed to get session count: %d %s", w.Code, w.Body.String())
+	}
+
+	var response struct {
+		Count int64 `json:"count"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to decode session count: %v", err)
+	}
+	return response.Count
+}
+
+func TestGetSessionCount_MultipleLogins(t *testing.T) {
+	handlers := setupTestHandlers(t)
+
+	loginTestUser(t, handlers, "sessions@example.com")
+	loginTestUser(t, handlers, "sessions@example.com")
+	loginTestUser(t, handlers, "sessions@example.com")
+	loginTestUser(t, handlers, "other@example.com")
+
+	assert.Equal(t, int64(3), getSessionCount(t, handlers, "sessions@example.com"))
+	assert.Equal(t, int64(1), getSessionCount(t, handlers, "other@example.com"))
+}
+
+func TestGetSessionCount_AfterLogout(t *testing.T) {
+	handlers := setupTestHandlers(t)
+
+	first := loginTestUser(t, handlers, "sessions@example.com")
+	loginTestUser(t, handlers, "sessions@example.com")
+
+	c, w := setupGinContext()
+	c.Request = httptest.NewRequest("POST", "/logout", bytes.NewBufferString(`{"refresh_token":"`+first.RefreshToken+`"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	handlers.Logout(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, int64(1), getSessionCount(t, handlers, "sessions@example.com"))
+}
+
+func TestGetSessionCount_Unauthenticated(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	c, w := setupGinContext()
+	c.Request = httptest.NewRequest("GET", "/users/me/sessions/count", nil)
+
+	handlers.GetSessionCount(c)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestLogout_RevokedTokenCannotRefresh(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	tokens := loginTestUser(t, handlers, "sessions@example.com")
+	body := `{"refresh_token":"` + tokens.RefreshToken + `"}`
+
+	c, w := setupGinContext()
+	c.Request = httptest.NewRequest("POST", "/logout", bytes.NewBufferString(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	handlers.Logout(c)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// A second logout with the same token is rejected
+	c, w = setupGinContext()
+	c.Request = httptest.NewRequest("POST", "/logout", bytes.NewBufferString(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	handlers.Logout(c)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	c, w = setupGinContext()
+	c.Request = httptest.NewRequest("POST", "/token/refresh", bytes.NewBufferString(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	handlers.RefreshToken(c)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestLogout_RevokesAccessToken(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	tokens := loginTestUser(t, handlers, "sessions@example.com")
+
+	c, w := setupGinContext()
+	c.Request = httptest.NewRequest("POST", "/logout", bytes.NewBufferString(`{"refresh_token":"`+tokens.RefreshToken+`"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Request.Header.Set("Authorization", "Bearer "+tokens.Token)
+	handlers.Logout(c)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	_, err := handlers.GetJWTService().ValidateToken(tokens.Token)
+	assert.ErrorIs(t, err, jwt.ErrTokenRevoked)
+	_, err = handlers.GetJWTService().ValidateRefreshToken(tokens.RefreshToken)
+	assert.ErrorIs(t, err, jwt.ErrTokenRevoked)
+}
+
+// refreshTestTokens exchanges a refresh token, returning the response recorder
+func refreshTestTokens(handlers *Handlers, refreshToken string) *httptest.ResponseRecorder {
+	c, w := setupGinContext()
+	c.Request = httptest.NewRequest("POST", "/token/refresh", bytes.NewBufferString(`{"refresh_token":"`+refreshToken+`"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	handlers.RefreshToken(c)
+	return w
+}
+
+func TestRefreshToken_RotationWorksOnce(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	tokens := loginTestUser(t, handlers, "sessions@example.com")
+
+	w := refreshTestTokens(handlers, tokens.RefreshToken)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var rotated jwt.TokenResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &rotated))
+
+	w = refreshTestTokens(handlers, rotated.RefreshToken)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestRefreshToken_ReplayAfterRotation(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	tokens := loginTestUser(t, handlers, "sessions@example.com")
+
+	w := refreshTestTokens(handlers, tokens.RefreshToken)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var rotated jwt.TokenResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &rotated))
+
+	// Replaying the old refresh token is rejected and revokes the new one too
+	w = refreshTestTokens(handlers, tokens.RefreshToken)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = refreshTestTokens(handlers, rotated.RefreshToken)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+// findCookie returns the named cookie set by a response, or nil
+func findCookie(w *httptest.ResponseRecorder, name string) *http.Cookie {
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.Name == name {
+			return cookie
+		}
+	}
+	return nil
+}
+
+func TestRefreshCookie_Enabled(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	handlers.config.JWT.RefreshCookie = true
+	tokens := loginTestUser(t, handlers, "cookie@example.com")
+
+	// Log in again to inspect the login response
+	c, w := setupGinContext()
+	c.Request = httptest.NewRequest("POST", "/token", bytes.NewBufferString(`{"email":"cookie@example.com","password":"password123"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	handlers.Login(c)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var loginTokens jwt.TokenResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &loginTokens))
+
+	cookie := findCookie(w, refreshCookieName)
+	if assert.NotNil(t, cookie) {
+		assert.Equal(t, loginTokens.RefreshToken, cookie.Value)
+		assert.True(t, cookie.HttpOnly)
+		assert.True(t, cookie.Secure)
+		assert.Equal(t, http.SameSiteStrictMode, cookie.SameSite)
+		assert.Equal(t, int(handlers.config.JWT.RefreshTokenDuration.Seconds()), cookie.MaxAge)
+		assert.Equal(t, refreshCookiePath, cookie.Path)
+	}
+	setCookie := w.Header().Get("Set-Cookie")
+	assert.Contains(t, setCookie, "HttpOnly")
+	assert.Contains(t, setCookie, "Secure")
+	assert.Contains(t, setCookie, "SameSite=Strict")
+
+	// Refreshing sets the rotated refresh token
+	w = refreshTestTokens(handlers, tokens.RefreshToken)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var rotated jwt.TokenResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &rotated))
+	cookie = findCookie(w, refreshCookieName)
+	if assert.NotNil(t, cookie) {
+		assert.Equal(t, rotated.RefreshToken, cookie.Value)
+		assert.True(t, cookie.HttpOnly)
+	}
+
+	// Logging out clears the cookie
+	c, w = setupGinContext()
+	c.Request = httptest.NewRequest("POST", "/logout", bytes.NewBufferString(`{"refresh_token":"`+rotated.RefreshToken+`"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	handlers.Logout(c)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	cookie = findCookie(w, refreshCookieName)
+	if assert.NotNil(t, cookie) {
+		assert.Empty(t, cookie.Value)
+		assert.Less(t, cookie.MaxAge, 0)
+	}
+}
+
+func TestRefreshCookie_OnlyCookie(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	handlers.config.JWT.RefreshCookie = true
+	tokens := loginTestUser(t, handlers, "cookie@example.com")
+
+	// cookieRequest sends a request with no body, carrying the refresh token only as a cookie
+	cookieRequest := func(path, refreshToken string, handle gin.HandlerFunc) *httptest.ResponseRecorder {
+		c, w := setupGinContext()
+		c.Request = httptest.NewRequest("POST", path, nil)
+		c.Request.AddCookie(&http.Cookie{Name: refreshCookieName, Value: refreshToken})
+		handle(c)
+		return w
+	}
+
+	w := cookieRequest("/refresh", tokens.RefreshToken, handlers.RefreshToken)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var rotated jwt.TokenResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &rotated))
+	cookie := findCookie(w, refreshCookieName)
+	if assert.NotNil(t, cookie) {
+		assert.Equal(t, rotated.RefreshToken, cookie.Value)
+	}
+
+	// A used-up token is rejected and its cookie cleared
+	w = cookieRequest("/refresh", tokens.RefreshToken, handlers.RefreshToken)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	cookie = findCookie(w, refreshCookieName)
+	if assert.NotNil(t, cookie) {
+		assert.Empty(t, cookie.Value)
+		assert.Less(t, cookie.MaxAge, 0)
+	}
+
+	// Reuse revoked the whole chain, so log in again to test logging out with the cookie
+	tokens = loginTestUser(t, handlers, "cookie2@example.com")
+	w = cookieRequest("/logout", tokens.RefreshToken, handlers.Logout)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = refreshTestTokens(handlers, tokens.RefreshToken)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestRefreshCookie_IgnoredWhenDisabled(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	tokens := loginTestUser(t, handlers, "cookie@example.com")
+
+	c, w := setupGinContext()
+	c.Request = httptest.NewRequest("POST", "/refresh", nil)
+	c.Request.AddCookie(&http.Cookie{Name: refreshCookieName, Value: tokens.RefreshToken})
+	handlers.RefreshToken(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestRefreshCookie_Disabled(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	tokens := loginTestUser(t, handlers, "cookie@example.com")
+
+	c, w := setupGinContext()
+	c.Request = httptest.NewRequest("POST", "/token", bytes.NewBufferString(`{"email":"cookie@example.com","password":"password123"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	handlers.Login(c)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("Set-Cookie"))
+
+	w = refreshTestTokens(handlers, tokens.RefreshToken)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("Set-Cookie"))
+}
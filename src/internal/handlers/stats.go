@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"backend/internal/database"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TagColorCount represents the number of tagged items for a tag color
+type TagColorCount struct {
+	Color     string `json:"color"`
+	ItemCount int64  `json:"item_count"`
+}
+
+// ContainerCount represents the number of items directly inside a parent item
+type ContainerCount struct {
+	ID         uint   `json:"id"`
+	Name       string `json:"name"`
+	BackpackID string `json:"backpack_id"`
+	ChildCount int64  `json:"child_count"`
+}
+
+const (
+	defaultContainerLimit = 10
+	maxContainerLimit     = 100
+)
+
+// GetItemCountsByTagColor handles getting item counts grouped by tag color for the user's organization
+func (h *Handlers) GetItemCountsByTagColor(c *gin.Context) {
+	userEmail, exists := c.Get("user_email")
+	if !exists {
+		respondError(c, http.StatusUnauthorized, CodeUnauthenticated, "User not authenticated")
+		return
+	}
+
+	// Get user's organization
+	var user database.User
+	if err := h.db.Where("email = ?", userEmail).First(&user).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to get user")
+		return
+	}
+
+	counts := []TagColorCount{}
+	if err := h.db.Model(&database.Tag{}).
+		// An item with several tags of one color counts once for that color
+		Select("tags.color AS color, COUNT(DISTINCT items.id) AS item_count").
+		Joins("LEFT JOIN item_tags ON item_tags.tag_id = tags.id").
+		Joins("LEFT JOIN items ON items.id = item_tags.item_id AND items.deleted_at IS NULL").
+		Where("tags.organization_id = ?", user.ActiveOrganizationID).
+		Group("tags.color").
+		Order("item_count DESC, color").
+		Scan(&counts).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to get tag statistics")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"colors": counts})
+}
+
+// GetContainerCounts handles ranking the user's items by how many items they directly contain
+func (h *Handlers) GetContainerCounts(c *gin.Context) {
+	userEmail, exists := c.Get("user_email")
+	if !exists {
+		respondError(c, http.StatusUnauthorized, CodeUnauthenticated, "User not authenticated")
+		return
+	}
+
+	limit := defaultContainerLimit
+	if limitStr := c.Query("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed < 1 || parsed > maxContainerLimit {
+			respondError(c, http.StatusBadRequest, CodeInvalidInput, "Invalid limit: must be between 1 and "+strconv.Itoa(maxContainerLimit))
+			return
+		}
+		limit = parsed
+	}
+
+	counts := []ContainerCount{}
+	if err := h.db.Table("items AS children").
+		Select("parents.id AS id, parents.name AS name, parents.backpack_id AS backpack_id, COUNT(children.id) AS child_count").
+		Joins("JOIN items AS parents ON parents.id = children.parent_id AND parents.deleted_at IS NULL").
+		Where("children.deleted_at IS NULL AND parents.user_email = ?", userEmail).
+		Group("children.parent_id, parents.id, parents.name, parents.backpack_id").
+		Order("child_count DESC, parents.id").
+		Limit(limit).
+		Scan(&counts).Error; err != nil {
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to get container statistics")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"containers": counts})
+}
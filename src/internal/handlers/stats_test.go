@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetItemCountsByTagColor_Success(t *testing.T) {
+	handlers := setupTestHandlers(t)
+
+	red := createTestTag(t, handlers, "Tools", "#ff0000")
+	redToo := createTestTag(t, handlers, "Urgent", "#ff0000")
+	blue := createTestTag(t, handlers, "Kitchen", "#0000ff")
+
+	hammer := createTestItem(t, handlers, "Hammer")
+	saw := createTestItem(t, handlers, "Saw")
+	pan := createTestItem(t, handlers, "Pan")
+
+	handlers.db.Model(&hammer).Association("Tags").Append(&red, &redToo)
+	handlers.db.Model(&saw).Association("Tags").Append(&red)
+	handlers.db.Model(&pan).Association("Tags").Append(&blue)
+
+	c, w := createAuthenticatedRequest(handlers, "GET", "/stats/tags/by-color", nil)
+	handlers.GetItemCountsByTagColor(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string][]TagColorCount
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, []TagColorCount{
+		{Color: "#ff0000", ItemCount: 2},
+		{Color: "#0000ff", ItemCount: 1},
+	}, response["colors"])
+}
+
+func TestGetItemCountsByTagColor_ItemWithTwoTagsOfOneColor(t *testing.T) {
+	handlers := setupTestHandlers(t)
+
+	red := createTestTag(t, handlers, "Tools", "#ff0000")
+	redToo := createTestTag(t, handlers, "Urgent", "#ff0000")
+	hammer := createTestItem(t, handlers, "Hammer")
+	handlers.db.Model(&hammer).Association("Tags").Append(&red, &redToo)
+
+	c, w := createAuthenticatedRequest(handlers, "GET", "/stats/tags/by-color", nil)
+	handlers.GetItemCountsByTagColor(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string][]TagColorCount
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, []TagColorCount{{Color: "#ff0000", ItemCount: 1}}, response["colors"])
+}
+
+func TestGetItemCountsByTagColor_NoTags(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	c, w := createAuthenticatedRequest(handlers, "GET", "/stats/tags/by-color", nil)
+
+	handlers.GetItemCountsByTagColor(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string][]TagColorCount
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Len(t, response["colors"], 0)
+}
+
+func TestGetContainerCounts_RanksByChildCount(t *testing.T) {
+	handlers := setupTestHandlers(t)
+
+	garage := createTestItem(t, handlers, "Garage")
+	toolbox := createTestItem(t, handlers, "Toolbox")
+	drawer := createTestItem(t, handlers, "Drawer")
+	createTestItem(t, handlers, "Loose")
+
+	for _, name := range []string{"Hammer", "Saw", "Drill"} {
+		child := createTestItem(t, handlers, name)
+		handlers.db.Model(&child).Update("parent_id", toolbox.ID)
+	}
+	handlers.db.Model(&toolbox).Update("parent_id", garage.ID)
+	bike := createTestItem(t, handlers, "Bike")
+	handlers.db.Model(&bike).Update("parent_id", garage.ID)
+	spoon := createTestItem(t, handlers, "Spoon")
+	handlers.db.Model(&spoon).Update("parent_id", drawer.ID)
+
+	// Deleted children don't count
+	fork := createTestItem(t, handlers, "Fork")
+	handlers.db.Model(&fork).Update("parent_id", drawer.ID)
+	handlers.db.Delete(&fork)
+
+	c, w := createAuthenticatedRequest(handlers, "GET", "/stats/containers", nil)
+	handlers.GetContainerCounts(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string][]ContainerCount
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	if assert.Len(t, response["containers"], 3) {
+		assert.Equal(t, "Toolbox", response["containers"][0].Name)
+		assert.Equal(t, int64(3), response["containers"][0].ChildCount)
+		assert.Equal(t, "Garage", response["containers"][1].Name)
+		assert.Equal(t, int64(2), response["containers"][1].ChildCount)
+		assert.Equal(t, "Drawer", response["containers"][2].Name)
+		assert.Equal(t, int64(1), response["containers"][2].ChildCount)
+	}
+}
+
+func TestGetContainerCounts_Limit(t *testing.T) {
+	handlers := setupTestHandlers(t)
+
+	for _, name := range []string{"Box A", "Box B"} {
+		parent := createTestItem(t, handlers, name)
+		child := createTestItem(t, handlers, name+" content")
+		handlers.db.Model(&child).Update("parent_id", parent.ID)
+	}
+
+	c, w := createAuthenticatedRequest(handlers, "GET", "/stats/containers?limit=1", nil)
+	handlers.GetContainerCounts(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string][]ContainerCount
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Len(t, response["containers"], 1)
+}
+
+func TestGetContainerCounts_InvalidLimit(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	c, w := createAuthenticatedRequest(handlers, "GET", "/stats/containers?limit=abc", nil)
+
+	handlers.GetContainerCounts(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
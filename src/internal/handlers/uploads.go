@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// uploadExtensions maps the sniffed content types of uploads to the extension they are
+// stored with; anything else is stored as .bin so it can't be served as markup
+var uploadExtensions = map[string]string{
+	"image/png":       ".png",
+	"image/jpeg":      ".jpg",
+	"image/gif":       ".gif",
+	"image/webp":      ".webp",
+	"image/bmp":       ".bmp",
+	"application/pdf": ".pdf",
+	"text/plain":      ".txt",
+}
+
+// multipartOverhead allows for the multipart boundaries and part headers around a file
+const multipartOverhead = 64 << 10
+
+// UploadFile handles uploading a file, e.g. an item photo
+func (h *Handlers) UploadFile(c *gin.Context) {
+	if _, exists := c.Get("user_email"); !exists {
+		respondError(c, http.StatusUnauthorized, CodeUnauthenticated, "User not authenticated")
+		return
+	}
+
+	// Stop reading the body once it can't fit under any limit, before it is buffered
+	maxRequestSize := h.config.Upload.MaxRequestSize()
+	if maxRequestSize > 0 {
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxRequestSize+multipartOverhead)
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			respondErrorDetails(c, http.StatusRequestEntityTooLarge, CodeTooLarge,
+				fmt.Sprintf("File too large: maximum size is %d bytes", maxRequestSize),
+				gin.H{"max_size": maxRequestSize})
+			return
+		}
+		respondInvalidInput(c, err)
+		return
+	}
+
+	// The client's Content-Type header can't be trusted, so the type is sniffed
+	contentType, err := sniffContentType(file)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to read file")
+		return
+	}
+	if limit := h.config.Upload.MaxSizeFor(contentType); limit > 0 && file.Size > limit {
+		respondErrorDetails(c, http.StatusRequestEntityTooLarge, CodeTooLarge,
+			fmt.Sprintf("File too large: maximum size for %s is %d bytes", contentType, limit),
+			gin.H{"content_type": contentType, "max_size": limit})
+		return
+	}
+
+	ext, ok := uploadExtensions[contentType]
+	if !ok {
+		ext = ".bin"
+	}
+	fileName, err := randomFileName(ext)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to save file")
+		return
+	}
+
+	if err := os.MkdirAll(h.config.Upload.Dir, 0o755); err != nil {
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to save file")
+		return
+	}
+
+	if err := c.SaveUploadedFile(file, filepath.Join(h.config.Upload.Dir, fileName)); err != nil {
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to save file")
+		return
+	}
+
+	url := "/uploads/" + fileName
+	c.Header("Location", url)
+	c.JSON(http.StatusCreated, gin.H{
+		"url":          url,
+		"content_type": contentType,
+		"size":         file.Size,
+	})
+}
+
+// sniffContentType detects the content type of an uploaded file from its first 512 bytes
+func sniffContentType(file *multipart.FileHeader) (string, error) {
+	f, err := file.Open()
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(f, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	return normalizeContentType(http.DetectContentType(head[:n])), nil
+}
+
+// normalizeContentType strips parameters from a content type and lowercases it
+func normalizeContentType(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return strings.ToLower(contentType)
+	}
+	return mediaType
+}
+
+// randomFileName generates a random file name with the given extension
+func randomFileName(ext string) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b) + ext, nil
+}
@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// File signatures that content sniffing recognizes
+const (
+	pngSignature  = "\x89PNG\r\n\x1a\n"
+	jpegSignature = "\xff\xd8\xff"
+	pdfSignature  = "%PDF-"
+)
+
+// createMultipartBody builds a multipart form body containing a single file field
+func createMultipartBody(t *testing.T, fileName string, content []byte) ([]byte, string) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", fileName)
+	if err != nil {
+		t.Fatalf("Failed to create form file: %v", err)
+	}
+	part.Write(content)
+	writer.Close()
+	return body.Bytes(), writer.FormDataContentType()
+}
+
+func TestUploadFile_Success(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	body, contentType := createMultipartBody(t, "photo.png", []byte(pngSignature+"fake image data"))
+
+	c, w := createAuthenticatedRequest(handlers, "POST", "/uploads", body)
+	c.Request.Header.Set("Content-Type", contentType)
+
+	handlers.UploadFile(c)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var response map[string]interface{}
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	url := response["url"].(string)
+	assert.True(t, strings.HasPrefix(url, "/uploads/"))
+	assert.True(t, strings.HasSuffix(url, ".png"))
+	assert.Equal(t, url, w.Header().Get("Location"))
+	assert.Equal(t, "image/png", response["content_type"])
+
+	// The file should be stored in the upload directory
+	content, err := os.ReadFile(filepath.Join(handlers.config.Upload.Dir, strings.TrimPrefix(url, "/uploads/")))
+	assert.NoError(t, err)
+	assert.Equal(t, pngSignature+"fake image data", string(content))
+}
+
+func TestUploadFile_SniffsContentType(t *testing.T) {
+	tests := []struct {
+		name        string
+		fileName    string
+		fileType    string
+		content     string
+		contentType string
+		ext         string
+	}{
+		{"html claiming to be an image", "photo.png", "image/png", "<html><script>alert(1)</script></html>", "text/html", ".bin"},
+		{"svg keeps no svg extension", "logo.svg", "image/svg+xml", `<?xml version="1.0"?><svg xmlns="http://www.w3.org/2000/svg"><script>alert(1)</script></svg>`, "text/xml", ".bin"},
+		{"image claiming to be text", "notes.html", "text/html", pngSignature + "pixels", "image/png", ".png"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handlers := setupTestHandlers(t)
+			body, contentType := createTypedMultipartBody(t, tt.fileName, tt.fileType, []byte(tt.content))
+			c, w := createAuthenticatedRequest(handlers, "POST", "/uploads", body)
+			c.Request.Header.Set("Content-Type", contentType)
+
+			handlers.UploadFile(c)
+
+			assert.Equal(t, http.StatusCreated, w.Code)
+			var response map[string]interface{}
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.contentType, response["content_type"])
+			assert.True(t, strings.HasSuffix(response["url"].(string), tt.ext), response["url"])
+		})
+	}
+}
+
+func TestUploadFile_MissingFile(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	c, w := createAuthenticatedRequest(handlers, "POST", "/uploads", nil)
+
+	handlers.UploadFile(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+// createTypedMultipartBody builds a multipart form body with a file of the given content type
+func createTypedMultipartBody(t *testing.T, fileName, fileType string, content []byte) ([]byte, string) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Disposition", `form-data; name="file"; filename="`+fileName+`"`)
+	header.Set("Content-Type", fileType)
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		t.Fatalf("Failed to create form file: %v", err)
+	}
+	part.Write(content)
+	writer.Close()
+	return body.Bytes(), writer.FormDataContentType()
+}
+
+func TestUploadFile_SizeLimitPerType(t *testing.T) {
+	tests := []struct {
+		name      string
+		fileType  string
+		signature string
+		size      int
+		expected  int
+	}{
+		{"image at limit", "image/png", pngSignature, 16, http.StatusCreated},
+		{"oversized image", "image/jpeg", jpegSignature, 17, http.StatusRequestEntityTooLarge},
+		{"document above image limit", "application/pdf", pdfSignature, 64, http.StatusCreated},
+		{"oversized document", "application/pdf", pdfSignature, 65, http.StatusRequestEntityTooLarge},
+		{"image sent as a document", "application/pdf", pngSignature, 17, http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handlers := setupTestHandlers(t)
+			handlers.config.Upload.MaxSize = 8
+			handlers.config.Upload.MaxSizes = map[string]int64{"image/*": 16, "application/pdf": 64}
+
+			content := append([]byte(tt.signature), bytes.Repeat([]byte("x"), tt.size-len(tt.signature))...)
+			body, contentType := createTypedMultipartBody(t, "file.bin", tt.fileType, content)
+			c, w := createAuthenticatedRequest(handlers, "POST", "/uploads", body)
+			c.Request.Header.Set("Content-Type", contentType)
+
+			handlers.UploadFile(c)
+
+			assert.Equal(t, tt.expected, w.Code)
+			if tt.expected == http.StatusRequestEntityTooLarge {
+				var response map[string]interface{}
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, CodeTooLarge, response["code"])
+				limit := handlers.config.Upload.MaxSizeFor(http.DetectContentType(content))
+				assert.Contains(t, response["message"], fmt.Sprintf("%d bytes", limit))
+			}
+		})
+	}
+}
+
+func TestUploadFile_DefaultSizeLimit(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	handlers.config.Upload.MaxSize = 8
+
+	body, contentType := createTypedMultipartBody(t, "notes.txt", "text/plain", []byte("more than eight bytes"))
+	c, w := createAuthenticatedRequest(handlers, "POST", "/uploads", body)
+	c.Request.Header.Set("Content-Type", contentType)
+
+	handlers.UploadFile(c)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Contains(t, w.Body.String(), "8 bytes")
+}
+
+func TestUploadFile_RequestBodyLimited(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	handlers.config.Upload.MaxSize = 8
+
+	body, contentType := createTypedMultipartBody(t, "big.txt", "text/plain", bytes.Repeat([]byte("x"), multipartOverhead+1024))
+	c, w := createAuthenticatedRequest(handlers, "POST", "/uploads", body)
+	c.Request.Header.Set("Content-Type", contentType)
+
+	handlers.UploadFile(c)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	var response map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, CodeTooLarge, response["code"])
+	// Rejected while reading the body, before the file was sniffed
+	assert.Equal(t, "File too large: maximum size is 8 bytes", response["message"])
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"backend/internal/notify"
+	"backend/internal/user"
+
+	"github.com/gin-gonic/gin"
+)
+
+// VerifyEmail handles confirming a user's email address with a verification token
+func (h *Handlers) VerifyEmail(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		respondError(c, http.StatusBadRequest, CodeInvalidToken, "Missing verification token")
+		return
+	}
+
+	if err := h.userService.VerifyEmail(token); err != nil {
+		if errors.Is(err, user.ErrInvalidVerificationToken) {
+			respondError(c, http.StatusBadRequest, CodeInvalidToken, "Invalid or already used verification token")
+			return
+		}
+		respondError(c, http.StatusInternalServerError, CodeInternal, "Failed to verify email")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Email verified successfully"})
+}
+
+// sendVerificationEmail sends a newly registered user their email verification token
+func (h *Handlers) sendVerificationEmail(email string) {
+	token, err := h.userService.CreateVerificationToken(email)
+	if err != nil {
+		h.logger.Error("failed to create verification token", "email", email, "error", err)
+		return
+	}
+
+	h.sendEmailAsync(notify.Message{
+		To:      email,
+		Subject: "Verify your SchwiftyBox email address",
+		Body:    "Welcome to SchwiftyBox! Verify your email address using this token: " + token,
+	})
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"backend/internal/database"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// registerTestUser registers a user and returns their stored verification token
+func registerTestUser(t *testing.T, handlers *Handlers, email string) string {
+	body, _ := json.Marshal(RegisterRequest{Email: email, Password: "password123"})
+	c, w := setupGinContext()
+	c.Request = httptest.NewRequest("POST", "/users", bytes.NewBuffer(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	handlers.RegisterUser(c)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Failed to register user: %s", w.Body.String())
+	}
+
+	var account database.User
+	if err := handlers.db.First(&account, "email = ?", email).Error; err != nil {
+		t.Fatalf("Failed to find registered user: %v", err)
+	}
+	return account.VerificationToken
+}
+
+func verifyTestEmail(handlers *Handlers, token string) *httptest.ResponseRecorder {
+	c, w := setupGinContext()
+	c.Request = httptest.NewRequest("GET", "/users/verify?token="+token, nil)
+	handlers.VerifyEmail(c)
+	return w
+}
+
+func TestRegisterUser_SendsVerificationEmail(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	token := registerTestUser(t, handlers, "verify@example.com")
+
+	assert.NotEmpty(t, token)
+	// The email is sent in the background
+	handlers.emails.Wait()
+	mailer := handlers.mailer.(*fakeMailer)
+	if assert.Len(t, mailer.sent, 1) {
+		assert.Equal(t, "verify@example.com", mailer.sent[0].To)
+		assert.Contains(t, mailer.sent[0].Body, token)
+	}
+}
+
+func TestVerifyEmail_ValidToken(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	token := registerTestUser(t, handlers, "verify@example.com")
+
+	w := verifyTestEmail(handlers, token)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var account database.User
+	assert.NoError(t, handlers.db.First(&account, "email = ?", "verify@example.com").Error)
+	assert.True(t, account.EmailVerified)
+	assert.Empty(t, account.VerificationToken)
+}
+
+func TestVerifyEmail_InvalidToken(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	registerTestUser(t, handlers, "verify@example.com")
+
+	w := verifyTestEmail(handlers, "not-a-token")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = verifyTestEmail(handlers, "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestVerifyEmail_AlreadyUsedToken(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	token := registerTestUser(t, handlers, "verify@example.com")
+
+	w := verifyTestEmail(handlers, token)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = verifyTestEmail(handlers, token)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestLogin_RequiresEmailVerification(t *testing.T) {
+	handlers := setupTestHandlers(t)
+	handlers.config.Security.RequireEmailVerification = true
+	token := registerTestUser(t, handlers, "verify@example.com")
+
+	login := func() int {
+		body, _ := json.Marshal(LoginRequest{Email: "verify@example.com", Password: "password123"})
+		c, w := setupGinContext()
+		c.Request = httptest.NewRequest("POST", "/token", bytes.NewBuffer(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		handlers.Login(c)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusForbidden, login())
+
+	verifyTestEmail(handlers, token)
+	assert.Equal(t, http.StatusOK, login())
+}
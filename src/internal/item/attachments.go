@@ -0,0 +1,99 @@
+package item
+
+import (
+	"errors"
+	"mime"
+	"net/url"
+	"path"
+	"strings"
+
+	"backend/internal/database"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrAttachmentNotFound is returned when an attachment does not exist on the given item
+	ErrAttachmentNotFound = errors.New("attachment not found")
+	// ErrInvalidAttachmentURL is returned when an attachment URL is neither an upload path nor an http(s) URL
+	ErrInvalidAttachmentURL = errors.New("invalid attachment URL")
+)
+
+// uploadsPrefix is where files stored by the upload endpoint are served from
+const uploadsPrefix = "/uploads/"
+
+// validateAttachmentURL accepts paths returned by the upload endpoint and absolute http(s) URLs
+func validateAttachmentURL(raw string) (*url.URL, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, ErrInvalidAttachmentURL
+	}
+
+	if u.Scheme == "" && u.Host == "" {
+		if !strings.HasPrefix(u.Path, uploadsPrefix) || len(u.Path) == len(uploadsPrefix) || strings.Contains(u.Path, "..") {
+			return nil, ErrInvalidAttachmentURL
+		}
+		return u, nil
+	}
+
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, ErrInvalidAttachmentURL
+	}
+	return u, nil
+}
+
+// AddAttachment attaches a URL to one of the user's items. Without a content type one is
+// guessed from the URL's file extension.
+func (s *Service) AddAttachment(itemID uint, userEmail, rawURL, contentType string) (*database.ItemAttachment, error) {
+	u, err := validateAttachmentURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkOwner(itemID, userEmail); err != nil {
+		return nil, err
+	}
+
+	if contentType == "" {
+		contentType = mime.TypeByExtension(path.Ext(u.Path))
+	}
+
+	attachment := &database.ItemAttachment{
+		ItemID:      itemID,
+		URL:         rawURL,
+		ContentType: contentType,
+	}
+	if err := s.db.Create(attachment).Error; err != nil {
+		return nil, err
+	}
+
+	return attachment, nil
+}
+
+// DeleteAttachment removes an attachment from one of the user's items
+func (s *Service) DeleteAttachment(itemID, attachmentID uint, userEmail string) error {
+	if err := s.checkOwner(itemID, userEmail); err != nil {
+		return err
+	}
+
+	result := s.db.Where("id = ? AND item_id = ?", attachmentID, itemID).Delete(&database.ItemAttachment{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrAttachmentNotFound
+	}
+	return nil
+}
+
+// checkOwner returns ErrItemNotFound unless the item exists and belongs to the user
+func (s *Service) checkOwner(itemID uint, userEmail string) error {
+	var item database.Item
+	if err := s.db.Select("id").Where("id = ? AND user_email = ?", itemID, userEmail).First(&item).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrItemNotFound
+		}
+		return err
+	}
+	return nil
+}
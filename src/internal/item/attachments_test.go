@@ -0,0 +1,79 @@
+package item
+
+import (
+	"errors"
+	"testing"
+
+	"backend/internal/config"
+	"backend/internal/database"
+)
+
+func TestValidateAttachmentURL(t *testing.T) {
+	tests := []struct {
+		url   string
+		valid bool
+	}{
+		{"/uploads/abc.png", true},
+		{"https://example.com/photo.jpg", true},
+		{"http://example.com/photo.jpg", true},
+		{"/uploads/", false},
+		{"/uploads/../secret", false},
+		{"/static/abc.png", false},
+		{"javascript:alert(1)", false},
+		{"ftp://example.com/a.png", false},
+		{"https:///nohost.png", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		_, err := validateAttachmentURL(tt.url)
+		if tt.valid && err != nil {
+			t.Errorf("Expected %q to be valid, got %v", tt.url, err)
+		}
+		if !tt.valid && !errors.Is(err, ErrInvalidAttachmentURL) {
+			t.Errorf("Expected %q to be rejected, got %v", tt.url, err)
+		}
+	}
+}
+
+func TestAttachments(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewItemService(db, &config.Config{})
+	db.Create(&database.User{Email: "test@example.com", Prefix: "TES"})
+	db.Create(&database.User{Email: "other@example.com", Prefix: "OTH"})
+
+	created, err := service.CreateItem("Tent", "", "test@example.com", nil)
+	if err != nil {
+		t.Fatalf("Failed to create item: %v", err)
+	}
+
+	attachment, err := service.AddAttachment(created.ID, "test@example.com", "https://example.com/tent.jpg", "")
+	if err != nil {
+		t.Fatalf("Failed to add attachment: %v", err)
+	}
+	if attachment.ContentType != "image/jpeg" {
+		t.Errorf("Expected content type guessed from the extension, got %q", attachment.ContentType)
+	}
+
+	fetched, err := service.GetItem(created.ID, "test@example.com")
+	if err != nil {
+		t.Fatalf("Failed to get item: %v", err)
+	}
+	if len(fetched.Attachments) != 1 || fetched.Attachments[0].ID != attachment.ID {
+		t.Errorf("Expected the attachment to be preloaded, got %+v", fetched.Attachments)
+	}
+
+	if _, err := service.AddAttachment(created.ID, "other@example.com", "https://example.com/a.jpg", ""); !errors.Is(err, ErrItemNotFound) {
+		t.Errorf("Expected ErrItemNotFound for another user's item, got %v", err)
+	}
+	if err := service.DeleteAttachment(created.ID, attachment.ID, "other@example.com"); !errors.Is(err, ErrItemNotFound) {
+		t.Errorf("Expected ErrItemNotFound for another user's item, got %v", err)
+	}
+
+	if err := service.DeleteAttachment(created.ID, attachment.ID, "test@example.com"); err != nil {
+		t.Fatalf("Failed to delete attachment: %v", err)
+	}
+	if err := service.DeleteAttachment(created.ID, attachment.ID, "test@example.com"); !errors.Is(err, ErrAttachmentNotFound) {
+		t.Errorf("Expected ErrAttachmentNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+package item
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+
+	"backend/internal/database"
+
+	"gorm.io/gorm"
+)
+
+// AuditEntity is the entity type of item audit entries
+const AuditEntity = "item"
+
+// Audit actions recorded for items
+const (
+	AuditCreate  = "create"
+	AuditUpdate  = "update"
+	AuditDelete  = "delete"
+	AuditRestore = "restore"
+)
+
+// RecordAudit writes an audit entry for an item change made in tx, so the entry commits or
+// rolls back with the change. The write runs in a nested transaction (a savepoint) and a
+// failure is logged rather than returned, so auditing never blocks the change itself.
+func RecordAudit(tx *gorm.DB, actor string, itemID uint, action string, diff interface{}) {
+	entry := database.AuditLog{
+		ActorEmail: actor,
+		EntityType: AuditEntity,
+		EntityID:   itemID,
+		Action:     action,
+	}
+	if diff != nil {
+		data, err := json.Marshal(diff)
+		if err != nil {
+			slog.Warn("failed to encode audit diff", "item_id", itemID, "action", action, "error", err)
+		} else {
+			entry.Diff = data
+		}
+	}
+
+	if err := tx.Transaction(func(sp *gorm.DB) error {
+		return sp.Create(&entry).Error
+	}); err != nil {
+		slog.Warn("failed to write audit log", "item_id", itemID, "action", action, "error", err)
+	}
+}
+
+// GetHistory returns the audit entries of one of the user's items, oldest first. Soft-deleted
+// items keep their history.
+func (s *Service) GetHistory(id uint, userEmail string) ([]database.AuditLog, error) {
+	var item database.Item
+	if err := s.db.Unscoped().Select("id").Where("id = ? AND user_email = ?", id, userEmail).First(&item).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrItemNotFound
+		}
+		return nil, err
+	}
+
+	entries := []database.AuditLog{}
+	if err := s.db.Where("entity_type = ? AND entity_id = ?", AuditEntity, id).Order("id").Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
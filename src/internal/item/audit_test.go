@@ -0,0 +1,99 @@
+package item
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"backend/internal/config"
+	"backend/internal/database"
+)
+
+func TestAudit_CreateThenUpdate(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewItemService(db, &config.Config{})
+	db.Create(&database.User{Email: "test@example.com", Prefix: "TES"})
+
+	created, err := service.CreateItem("Tent", "", "test@example.com", nil)
+	if err != nil {
+		t.Fatalf("Failed to create item: %v", err)
+	}
+	if _, err := service.UpdateItem(created.ID, "test@example.com", "Big Tent", "", nil, nil); err != nil {
+		t.Fatalf("Failed to update item: %v", err)
+	}
+
+	var count int64
+	db.Model(&database.AuditLog{}).Where("entity_type = ? AND entity_id = ?", AuditEntity, created.ID).Count(&count)
+	if count != 2 {
+		t.Fatalf("Expected 2 audit rows, got %d", count)
+	}
+
+	history, err := service.GetHistory(created.ID, "test@example.com")
+	if err != nil {
+		t.Fatalf("Failed to get history: %v", err)
+	}
+	if history[0].Action != AuditCreate || history[1].Action != AuditUpdate {
+		t.Errorf("Expected create then update, got %s then %s", history[0].Action, history[1].Action)
+	}
+	if history[1].ActorEmail != "test@example.com" {
+		t.Errorf("Expected actor test@example.com, got %s", history[1].ActorEmail)
+	}
+
+	var diff map[string]interface{}
+	if err := json.Unmarshal(history[1].Diff, &diff); err != nil {
+		t.Fatalf("Expected a JSON diff, got %q: %v", history[1].Diff, err)
+	}
+	if diff["name"] != "Big Tent" {
+		t.Errorf("Expected the diff to hold the new name, got %v", diff)
+	}
+}
+
+func TestAudit_DeleteKeepsHistory(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewItemService(db, &config.Config{})
+	db.Create(&database.User{Email: "test@example.com", Prefix: "TES"})
+	db.Create(&database.User{Email: "other@example.com", Prefix: "OTH"})
+
+	created, err := service.CreateItem("Tent", "", "test@example.com", nil)
+	if err != nil {
+		t.Fatalf("Failed to create item: %v", err)
+	}
+	if _, err := service.AdjustQuantity(created.ID, "test@example.com", 2); err != nil {
+		t.Fatalf("Failed to adjust quantity: %v", err)
+	}
+	if _, err := service.DeleteItem(created.ID, "test@example.com", DeleteOptions{}); err != nil {
+		t.Fatalf("Failed to delete item: %v", err)
+	}
+
+	history, err := service.GetHistory(created.ID, "test@example.com")
+	if err != nil {
+		t.Fatalf("Failed to get history of a deleted item: %v", err)
+	}
+	var actions []string
+	for _, entry := range history {
+		actions = append(actions, entry.Action)
+	}
+	if len(actions) != 3 || actions[2] != AuditDelete {
+		t.Errorf("Expected create, update and delete entries, got %v", actions)
+	}
+
+	if _, err := service.GetHistory(created.ID, "other@example.com"); !errors.Is(err, ErrItemNotFound) {
+		t.Errorf("Expected ErrItemNotFound for another user's item, got %v", err)
+	}
+}
+
+func TestAudit_FailedChangeLeavesNoEntry(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewItemService(db, &config.Config{})
+	db.Create(&database.User{Email: "test@example.com", Prefix: "TES"})
+
+	if _, err := service.CreateItems("test@example.com", []NewItem{{Name: "Tent"}, {Name: ""}}); err == nil {
+		t.Fatal("Expected the batch to fail")
+	}
+
+	var count int64
+	db.Model(&database.AuditLog{}).Count(&count)
+	if count != 0 {
+		t.Errorf("Expected no audit rows, got %d", count)
+	}
+}
@@ -4,12 +4,15 @@ import (
 	"errors"
 	"fmt"
 	"math/rand"
+	"strings"
 	"time"
 
+	"backend/internal/config"
 	"backend/internal/database"
 
 	"go.uber.org/fx"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // Module provides item service dependency injection
@@ -19,7 +22,8 @@ var Module = fx.Module("item",
 
 // Service handles item operations
 type Service struct {
-	db *gorm.DB
+	db         *gorm.DB
+	maxPerUser int
 }
 
 var (
@@ -27,119 +31,363 @@ var (
 	ErrItemNotFound = errors.New("item not found")
 	// ErrItemAlreadyExists is returned when trying to create an item that already exists
 	ErrItemAlreadyExists = errors.New("item already exists")
+	// ErrInvalidPrefix is returned when a backpack prefix is not exactly 3 letters A-Z
+	ErrInvalidPrefix = errors.New("invalid backpack prefix")
+	// ErrQuotaExceeded is returned when a user already owns the maximum number of items
+	ErrQuotaExceeded = errors.New("item quota exceeded")
+	// ErrParentNotFound is returned when a target parent item does not exist or belongs to another user
+	ErrParentNotFound = errors.New("parent item not found")
+	// ErrParentCycle is returned when a move would make an item a descendant of itself
+	ErrParentCycle = errors.New("item cannot be moved under itself")
+	// ErrInvalidItem is returned when a new item is missing a name or exceeds a column limit
+	ErrInvalidItem = errors.New("invalid item")
+	// ErrQuantityBelowZero is returned when a quantity adjustment would leave a negative quantity
+	ErrQuantityBelowZero = errors.New("quantity cannot go below zero")
+	// ErrPrefixUnavailable is returned when no unused backpack prefix could be found
+	ErrPrefixUnavailable = errors.New("no unused backpack prefix available")
+)
+
+// prefixLength is the number of letters in a backpack prefix
+const prefixLength = 3
+
+// Column limits of the item name and description
+const (
+	maxNameLength        = 200
+	maxDescriptionLength = 1000
+	maxUnitLength        = 20
 )
 
 // NewItemService creates a new item service
-func NewItemService(db *gorm.DB) *Service {
+func NewItemService(db *gorm.DB, cfg *config.Config) *Service {
 	return &Service{
-		db: db,
+		db:         db,
+		maxPerUser: cfg.Item.MaxPerUser,
 	}
 }
 
-// generatePrefix generates a random 3-letter prefix
-func (s *Service) generatePrefix() string {
+// GeneratePrefix generates a random 3-letter prefix
+func GeneratePrefix() string {
 	const letters = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
-	result := make([]byte, 3)
+	result := make([]byte, prefixLength)
 	for i := range result {
 		result[i] = letters[rand.Intn(len(letters))]
 	}
 	return string(result)
 }
 
-// getNextID gets the next ID for a backpack prefix
-func (s *Service) getNextID(prefix string) (string, error) {
-	var nextNumber database.BackPackIdNextNumber
-
-	// Use transaction to ensure atomicity
-	tx := s.db.Begin()
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
+// ValidatePrefix checks that a backpack prefix is exactly 3 uppercase letters A-Z
+func ValidatePrefix(prefix string) error {
+	if len(prefix) != prefixLength {
+		return ErrInvalidPrefix
+	}
+	for i := 0; i < len(prefix); i++ {
+		if prefix[i] < 'A' || prefix[i] > 'Z' {
+			return ErrInvalidPrefix
 		}
-	}()
+	}
+	return nil
+}
 
-	// Try to find existing record
-	err := tx.Where("backpack_id = ?", prefix).First(&nextNumber).Error
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			// Create new record
-			nextNumber = database.BackPackIdNextNumber{
-				BackpackID: prefix,
-				Number:     1,
-			}
-			if err := tx.Create(&nextNumber).Error; err != nil {
-				tx.Rollback()
-				return "", err
-			}
-		} else {
-			tx.Rollback()
-			return "", err
-		}
-	} else {
-		// Increment existing record
-		nextNumber.Number++
-		if err := tx.Save(&nextNumber).Error; err != nil {
-			tx.Rollback()
-			return "", err
-		}
+// NormalizePrefix trims and uppercases a backpack prefix, then validates it
+func NormalizePrefix(prefix string) (string, error) {
+	prefix = strings.ToUpper(strings.TrimSpace(prefix))
+	if err := ValidatePrefix(prefix); err != nil {
+		return "", err
 	}
+	return prefix, nil
+}
 
-	if err := tx.Commit().Error; err != nil {
+// getNextID gets the next ID for a backpack prefix
+func getNextID(tx *gorm.DB, prefix string) (string, error) {
+	number, err := reserveNumbers(tx, prefix, 1)
+	if err != nil {
 		return "", err
 	}
+	return formatNumber(number), nil
+}
 
-	// Format as 4-digit string with leading zeros
-	return fmt.Sprintf("%04d", nextNumber.Number), nil
+// formatNumber formats a backpack ID number as a 4-digit string with leading zeros
+func formatNumber(number int) string {
+	return fmt.Sprintf("%04d", number)
 }
 
-// CreateItem creates a new item
+// CreateItem creates a new item with the default quantity
 func (s *Service) CreateItem(name, description, userEmail string, parentID *uint) (*database.Item, error) {
-	// Get user to check prefix
-	var user database.User
-	if err := s.db.Where("email = ?", userEmail).First(&user).Error; err != nil {
+	return s.Create(userEmail, NewItem{Name: name, Description: description, ParentID: parentID})
+}
+
+// Create creates a new item from n
+func (s *Service) Create(userEmail string, n NewItem) (*database.Item, error) {
+	if err := n.validate(); err != nil {
 		return nil, err
 	}
 
-	// Generate prefix if not exists
-	if user.Prefix == "" {
-		user.Prefix = s.generatePrefix()
-		if err := s.db.Save(&user).Error; err != nil {
-			return nil, err
+	var item *database.Item
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		user, err := lockUser(tx, userEmail)
+		if err != nil {
+			return err
+		}
+
+		if err := s.checkQuota(tx, userEmail, 1); err != nil {
+			return err
 		}
-	}
 
-	// Get next ID
-	nextID, err := s.getNextID(user.Prefix)
+		if err := ensurePrefix(tx, user); err != nil {
+			return err
+		}
+
+		nextID, err := getNextID(tx, user.Prefix)
+		if err != nil {
+			return err
+		}
+
+		item = n.build(user, user.Prefix+nextID)
+		if err := tx.Create(item).Error; err != nil {
+			return err
+		}
+		if err := storeZeroQuantity(tx, item, n); err != nil {
+			return err
+		}
+		RecordAudit(tx, userEmail, item.ID, AuditCreate, createdDiff(item, n))
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	backpackID := user.Prefix + nextID
+	// Load relationships
+	s.db.Preload("Parent").Preload("Tags").First(item, item.ID)
+
+	return item, nil
+}
+
+// ensurePrefix generates the user's prefix if missing, otherwise makes sure it is well-formed
+func ensurePrefix(tx *gorm.DB, user *database.User) error {
+	prefix := GeneratePrefix()
+	if user.Prefix != "" {
+		normalized, err := NormalizePrefix(user.Prefix)
+		if err != nil {
+			return err
+		}
+		prefix = normalized
+	}
+	if prefix != user.Prefix {
+		user.Prefix = prefix
+		if err := tx.Model(user).Update("prefix", prefix).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NewItem holds the fields of an item to create
+type NewItem struct {
+	Name        string
+	Description string
+	// Quantity defaults to 1 when nil
+	Quantity *int
+	Unit     string
+	ParentID *uint
+}
+
+// BatchError reports which entry of a batch failed
+type BatchError struct {
+	Index int
+	Err   error
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("item %d: %v", e.Index, e.Err)
+}
+
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
+
+// validate checks n against the item column limits
+func (n NewItem) validate() error {
+	if strings.TrimSpace(n.Name) == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidItem)
+	}
+	if len(n.Name) > maxNameLength {
+		return fmt.Errorf("%w: name must be at most %d characters", ErrInvalidItem, maxNameLength)
+	}
+	if len(n.Description) > maxDescriptionLength {
+		return fmt.Errorf("%w: description must be at most %d characters", ErrInvalidItem, maxDescriptionLength)
+	}
+	if n.Quantity != nil && *n.Quantity < 0 {
+		return fmt.Errorf("%w: quantity must not be negative", ErrInvalidItem)
+	}
+	if len(n.Unit) > maxUnitLength {
+		return fmt.Errorf("%w: unit must be at most %d characters", ErrInvalidItem, maxUnitLength)
+	}
+	return nil
+}
 
+// build returns the item row for n, shared with the user's active organization
+func (n NewItem) build(user *database.User, backpackID string) *database.Item {
 	item := &database.Item{
-		Name:        name,
+		Name:        n.Name,
 		BackpackID:  backpackID,
-		Description: description,
-		AddedAt:     time.Now(),
-		UserEmail:   userEmail,
-		ParentID:    parentID,
+		Description: n.Description,
+		Quantity:    1,
+		Unit:        n.Unit,
+		Version:     1,
+		UserEmail:   user.Email,
+		ParentID:    n.ParentID,
+	}
+	if n.Quantity != nil {
+		item.Quantity = *n.Quantity
+	}
+
+	if user.ActiveOrganizationID != 0 {
+		organizationID := user.ActiveOrganizationID
+		item.OrganizationID = &organizationID
+	}
+	return item
+}
+
+// createdDiff describes the fields an item was created with
+func createdDiff(item *database.Item, n NewItem) map[string]interface{} {
+	quantity := item.Quantity
+	if n.Quantity != nil {
+		quantity = *n.Quantity
+	}
+	return map[string]interface{}{
+		"name":        item.Name,
+		"description": item.Description,
+		"backpack_id": item.BackpackID,
+		"quantity":    quantity,
+		"unit":        item.Unit,
+	}
+}
+
+// storeZeroQuantity writes an explicit zero quantity, which Create replaces with the column default
+func storeZeroQuantity(tx *gorm.DB, item *database.Item, n NewItem) error {
+	if n.Quantity == nil || *n.Quantity != 0 {
+		return nil
+	}
+	return tx.Model(item).Update("quantity", 0).Error
+}
+
+// CreateItems creates a batch of items in one transaction. Nothing is created if any entry
+// is invalid; the returned *BatchError names the failing index.
+func (s *Service) CreateItems(userEmail string, items []NewItem) ([]database.Item, error) {
+	for i, n := range items {
+		if err := n.validate(); err != nil {
+			return nil, &BatchError{Index: i, Err: err}
+		}
 	}
 
-	if err := s.db.Create(item).Error; err != nil {
+	created := make([]database.Item, len(items))
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		user, err := lockUser(tx, userEmail)
+		if err != nil {
+			return err
+		}
+
+		if err := s.checkQuota(tx, userEmail, len(items)); err != nil {
+			return err
+		}
+
+		if err := ensurePrefix(tx, user); err != nil {
+			return err
+		}
+
+		first, err := reserveNumbers(tx, user.Prefix, len(items))
+		if err != nil {
+			return err
+		}
+
+		for i, n := range items {
+			created[i] = *n.build(user, user.Prefix+formatNumber(first+i))
+		}
+
+		if err := tx.Create(&created).Error; err != nil {
+			return err
+		}
+		for i, n := range items {
+			if err := storeZeroQuantity(tx, &created[i], n); err != nil {
+				return err
+			}
+			RecordAudit(tx, userEmail, created[i].ID, AuditCreate, createdDiff(&created[i], n))
+		}
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
 
 	// Load relationships
-	s.db.Preload("Parent").Preload("Tags").First(item, item.ID)
+	ids := make([]uint, len(created))
+	for i := range created {
+		ids[i] = created[i].ID
+	}
+	if err := s.db.Preload("Tags").Where("id IN ?", ids).Order("id").Find(&created).Error; err != nil {
+		return nil, err
+	}
 
-	return item, nil
+	return created, nil
+}
+
+// reserveNumbers advances the prefix's counter by count and returns the first reserved number.
+// The counter is incremented and read back in a single UPDATE, so concurrent creates never
+// reserve the same numbers.
+func reserveNumbers(tx *gorm.DB, prefix string, count int) (int, error) {
+	for attempt := 0; attempt < 2; attempt++ {
+		var nextNumber database.BackPackIdNextNumber
+		result := tx.Model(&nextNumber).
+			Clauses(clause.Returning{Columns: []clause.Column{{Name: "number"}}}).
+			Where("backpack_id = ?", prefix).
+			Update("number", gorm.Expr("number + ?", count))
+		if result.Error != nil {
+			return 0, result.Error
+		}
+		if result.RowsAffected > 0 {
+			return nextNumber.Number - count + 1, nil
+		}
+
+		// First ID of the prefix; a concurrent create may have inserted the counter already
+		if err := claimSequence(tx, prefix); err != nil && !errors.Is(err, errPrefixClaimed) {
+			return 0, err
+		}
+	}
+	return 0, fmt.Errorf("backpack ID counter for %q not found", prefix)
+}
+
+// lockUser loads the user and locks their row until tx ends, so concurrent quota checks
+// for the same user run one after the other
+func lockUser(tx *gorm.DB, userEmail string) (*database.User, error) {
+	var user database.User
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("email = ?", userEmail).First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// checkQuota returns ErrQuotaExceeded when adding items would take the user past the
+// maximum number of items. Call it inside the transaction that adds them, after lockUser.
+func (s *Service) checkQuota(tx *gorm.DB, userEmail string, adding int) error {
+	if s.maxPerUser <= 0 {
+		return nil
+	}
+
+	var count int64
+	if err := tx.Model(&database.Item{}).Where("user_email = ?", userEmail).Count(&count).Error; err != nil {
+		return err
+	}
+	if count+int64(adding) > int64(s.maxPerUser) {
+		return ErrQuotaExceeded
+	}
+	return nil
 }
 
 // GetItem retrieves an item by ID
 func (s *Service) GetItem(id uint, userEmail string) (*database.Item, error) {
 	var item database.Item
 
-	if err := s.db.Preload("Parent").Preload("Tags").Preload("Children").
+	if err := s.db.Preload("Parent").Preload("Tags").Preload("Children").Preload("Attachments").
 		Where("id = ? AND user_email = ?", id, userEmail).First(&item).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, ErrItemNotFound
@@ -158,7 +406,8 @@ func (s *Service) GetItems(userEmail string, nameFilter string) ([]database.Item
 		Where("user_email = ?", userEmail)
 
 	if nameFilter != "" {
-		query = query.Where("name ILIKE ?", "%"+nameFilter+"%")
+		// LOWER on both sides is case-insensitive on Postgres and SQLite alike, unlike ILIKE
+		query = query.Where(`LOWER(name) LIKE LOWER(?) ESCAPE '\'`, database.ContainsPattern(nameFilter))
 	}
 
 	if err := query.Find(&items).Error; err != nil {
@@ -168,6 +417,23 @@ func (s *Service) GetItems(userEmail string, nameFilter string) ([]database.Item
 	return items, nil
 }
 
+// SearchItems retrieves up to limit of the user's items whose name or description contains query
+func (s *Service) SearchItems(userEmail, query string, limit int) ([]database.Item, error) {
+	items := []database.Item{}
+	pattern := database.ContainsPattern(strings.ToLower(query))
+
+	if err := s.db.Preload("Tags").
+		Where("user_email = ?", userEmail).
+		Where(`LOWER(name) LIKE ? ESCAPE '\' OR LOWER(description) LIKE ? ESCAPE '\'`, pattern, pattern).
+		Order("name, id").
+		Limit(limit).
+		Find(&items).Error; err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
 // UpdateItem updates an item
 func (s *Service) UpdateItem(id uint, userEmail string, name, description string, parentID *uint, tagIDs []uint) (*database.Item, error) {
 	item, err := s.GetItem(id, userEmail)
@@ -179,20 +445,38 @@ func (s *Service) UpdateItem(id uint, userEmail string, name, description string
 	item.Name = name
 	item.Description = description
 	item.ParentID = parentID
+	item.Version++
 
-	if err := s.db.Save(item).Error; err != nil {
-		return nil, err
+	diff := map[string]interface{}{
+		"name":        name,
+		"description": description,
+		"parent_id":   parentID,
 	}
-
-	// Update tags if provided
 	if tagIDs != nil {
-		// Clear existing tags
-		s.db.Exec("DELETE FROM item_tags WHERE item_id = ?", id)
+		diff["tags"] = tagIDs
+	}
 
-		// Add new tags
-		for _, tagID := range tagIDs {
-			s.db.Exec("INSERT INTO item_tags (item_id, tag_id) VALUES (?, ?)", id, tagID)
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(item).Error; err != nil {
+			return err
 		}
+
+		// Update tags if provided
+		if tagIDs != nil {
+			// Clear existing tags
+			tx.Exec("DELETE FROM item_tags WHERE item_id = ?", id)
+
+			// Add new tags
+			for _, tagID := range tagIDs {
+				tx.Exec("INSERT INTO item_tags (item_id, tag_id) VALUES (?, ?)", id, tagID)
+			}
+		}
+
+		RecordAudit(tx, userEmail, id, AuditUpdate, diff)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	// Reload with relationships
@@ -201,18 +485,244 @@ func (s *Service) UpdateItem(id uint, userEmail string, name, description string
 	return item, nil
 }
 
-// DeleteItem deletes an item
-func (s *Service) DeleteItem(id uint, userEmail string) error {
-	result := s.db.Where("id = ? AND user_email = ?", id, userEmail).Delete(&database.Item{})
-	if result.Error != nil {
-		return result.Error
+// MoveItem reparents an item, or makes it a root item when parentID is nil
+func (s *Service) MoveItem(id uint, userEmail string, parentID *uint) (*database.Item, error) {
+	if _, err := s.GetItem(id, userEmail); err != nil {
+		return nil, err
+	}
+
+	if parentID != nil {
+		if err := s.CheckParent(id, *parentID, userEmail); err != nil {
+			return nil, err
+		}
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&database.Item{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"parent_id": parentID,
+			"version":   gorm.Expr("version + 1"),
+		}).Error; err != nil {
+			return err
+		}
+		RecordAudit(tx, userEmail, id, AuditUpdate, map[string]interface{}{"parent_id": parentID})
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	if result.RowsAffected == 0 {
-		return ErrItemNotFound
+
+	return s.GetItem(id, userEmail)
+}
+
+// AdjustQuantity atomically adds delta to an item's quantity, refusing to go below zero
+func (s *Service) AdjustQuantity(id uint, userEmail string, delta int) (*database.Item, error) {
+	// The guard lives in the UPDATE so concurrent adjustments cannot race past zero
+	var adjusted int64
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&database.Item{}).
+			Where("id = ? AND user_email = ? AND quantity + ? >= 0", id, userEmail, delta).
+			Updates(map[string]interface{}{
+				"quantity": gorm.Expr("quantity + ?", delta),
+				"version":  gorm.Expr("version + 1"),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		adjusted = result.RowsAffected
+		if adjusted > 0 {
+			RecordAudit(tx, userEmail, id, AuditUpdate, map[string]interface{}{"quantity_delta": delta})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if adjusted == 0 {
+		if _, err := s.GetItem(id, userEmail); err != nil {
+			return nil, err
+		}
+		return nil, ErrQuantityBelowZero
+	}
+
+	return s.GetItem(id, userEmail)
+}
+
+// CheckParent verifies parentID is one of the user's items and not id itself or one of its descendants
+func (s *Service) CheckParent(id, parentID uint, userEmail string) error {
+	visited := make(map[uint]bool)
+	current := &parentID
+	for current != nil {
+		if *current == id {
+			return ErrParentCycle
+		}
+		// An existing loop above the target can't lead back to the item
+		if visited[*current] {
+			return nil
+		}
+		visited[*current] = true
+
+		var ancestor database.Item
+		if err := s.db.Select("id, parent_id").
+			Where("id = ? AND user_email = ?", *current, userEmail).First(&ancestor).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				if *current == parentID {
+					return ErrParentNotFound
+				}
+				return nil
+			}
+			return err
+		}
+		current = ancestor.ParentID
 	}
 	return nil
 }
 
+// DeleteOptions controls how DeleteItem removes an item
+type DeleteOptions struct {
+	// Cascade deletes the item's whole subtree instead of moving its children up to its parent
+	Cascade bool
+	// Hard removes the items permanently instead of soft-deleting them
+	Hard bool
+}
+
+// DeleteItem deletes an item and returns the IDs of all deleted items
+func (s *Service) DeleteItem(id uint, userEmail string, opts DeleteOptions) ([]uint, error) {
+	var deleted []uint
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var item database.Item
+		if err := tx.Select("id, parent_id").Where("id = ? AND user_email = ?", id, userEmail).First(&item).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrItemNotFound
+			}
+			return err
+		}
+
+		deleted = []uint{id}
+		if opts.Cascade {
+			descendants, err := descendantIDs(tx, id, userEmail)
+			if err != nil {
+				return err
+			}
+			deleted = append(deleted, descendants...)
+		} else if err := tx.Model(&database.Item{}).
+			Where("parent_id = ? AND user_email = ?", id, userEmail).
+			Update("parent_id", item.ParentID).Error; err != nil {
+			return err
+		}
+
+		for _, deletedID := range deleted {
+			RecordAudit(tx, userEmail, deletedID, AuditDelete, map[string]interface{}{"hard": opts.Hard, "cascade": opts.Cascade})
+		}
+
+		if opts.Hard {
+			tx = tx.Unscoped()
+		}
+		return tx.Where("id IN ? AND user_email = ?", deleted, userEmail).Delete(&database.Item{}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return deleted, nil
+}
+
+// RestoreItem undeletes a soft-deleted item. If its parent is gone it becomes a top-level item.
+func (s *Service) RestoreItem(id uint, userEmail string) (*database.Item, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var item database.Item
+		if err := tx.Unscoped().Select("id, parent_id").
+			Where("id = ? AND user_email = ? AND deleted_at IS NOT NULL", id, userEmail).
+			First(&item).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrItemNotFound
+			}
+			return err
+		}
+
+		// A restored item counts towards the quota again
+		if _, err := lockUser(tx, userEmail); err != nil {
+			return err
+		}
+		if err := s.checkQuota(tx, userEmail, 1); err != nil {
+			return err
+		}
+
+		updates := map[string]interface{}{"deleted_at": nil}
+		if item.ParentID != nil {
+			var count int64
+			if err := tx.Model(&database.Item{}).Where("id = ?", *item.ParentID).Count(&count).Error; err != nil {
+				return err
+			}
+			if count == 0 {
+				updates["parent_id"] = nil
+			}
+		}
+
+		if err := tx.Unscoped().Model(&database.Item{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+			return err
+		}
+		RecordAudit(tx, userEmail, id, AuditRestore, nil)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetItem(id, userEmail)
+}
+
+// descendantIDs collects the IDs of every item below id, one tree level per query
+func descendantIDs(tx *gorm.DB, id uint, userEmail string) ([]uint, error) {
+	visited := map[uint]bool{id: true}
+	var descendants []uint
+
+	level := []uint{id}
+	for len(level) > 0 {
+		var children []uint
+		if err := tx.Model(&database.Item{}).
+			Where("parent_id IN ? AND user_email = ?", level, userEmail).
+			Pluck("id", &children).Error; err != nil {
+			return nil, err
+		}
+
+		level = level[:0]
+		for _, child := range children {
+			// Guard against parent cycles
+			if visited[child] {
+				continue
+			}
+			visited[child] = true
+			descendants = append(descendants, child)
+			level = append(level, child)
+		}
+	}
+
+	return descendants, nil
+}
+
+// DeletedItem is a tombstone for a soft-deleted item
+type DeletedItem struct {
+	ID         uint      `json:"id"`
+	BackpackID string    `json:"backpack_id"`
+	DeletedAt  time.Time `json:"deleted_at"`
+}
+
+// GetDeletedSince retrieves tombstones for the user's items soft-deleted after since
+func (s *Service) GetDeletedSince(userEmail string, since time.Time) ([]DeletedItem, error) {
+	deleted := []DeletedItem{}
+
+	if err := s.db.Unscoped().Model(&database.Item{}).
+		Select("id, backpack_id, deleted_at").
+		Where("user_email = ? AND deleted_at > ?", userEmail, since).
+		Order("deleted_at, id").
+		Scan(&deleted).Error; err != nil {
+		return nil, err
+	}
+
+	return deleted, nil
+}
+
 // GetItemsByTag retrieves all items with a specific tag
 func (s *Service) GetItemsByTag(tagID uint, userEmail string) ([]database.Item, error) {
 	var items []database.Item
@@ -226,3 +736,80 @@ func (s *Service) GetItemsByTag(tagID uint, userEmail string) ([]database.Item,
 
 	return items, nil
 }
+
+// TreeNode is an item in the nested item hierarchy
+type TreeNode struct {
+	ID          uint        `json:"id"`
+	Name        string      `json:"name"`
+	BackpackID  string      `json:"backpack_id"`
+	Description string      `json:"description"`
+	Quantity    int         `json:"quantity"`
+	Unit        string      `json:"unit"`
+	ParentID    *uint       `json:"parent_id"`
+	Children    []*TreeNode `json:"children"`
+}
+
+// GetItemTree retrieves the user's items nested under their parents
+func (s *Service) GetItemTree(userEmail string) ([]*TreeNode, error) {
+	var items []database.Item
+	if err := s.db.Where("user_email = ?", userEmail).Order("id").Find(&items).Error; err != nil {
+		return nil, err
+	}
+
+	return buildTree(items), nil
+}
+
+// buildTree assembles a flat item list into trees. Items whose parent is missing
+// become roots, and an item on a parent cycle becomes a root to break the loop.
+func buildTree(items []database.Item) []*TreeNode {
+	nodes := make(map[uint]*TreeNode, len(items))
+	children := make(map[uint][]*TreeNode)
+	for _, i := range items {
+		nodes[i.ID] = &TreeNode{
+			ID:          i.ID,
+			Name:        i.Name,
+			BackpackID:  i.BackpackID,
+			Description: i.Description,
+			Quantity:    i.Quantity,
+			Unit:        i.Unit,
+			ParentID:    i.ParentID,
+			Children:    []*TreeNode{},
+		}
+	}
+
+	roots := []*TreeNode{}
+	for _, i := range items {
+		node := nodes[i.ID]
+		if i.ParentID == nil || nodes[*i.ParentID] == nil {
+			roots = append(roots, node)
+			continue
+		}
+		children[*i.ParentID] = append(children[*i.ParentID], node)
+	}
+
+	visited := make(map[uint]bool, len(items))
+	var attach func(node *TreeNode)
+	attach = func(node *TreeNode) {
+		visited[node.ID] = true
+		for _, child := range children[node.ID] {
+			if visited[child.ID] {
+				continue
+			}
+			node.Children = append(node.Children, child)
+			attach(child)
+		}
+	}
+	for _, root := range roots {
+		attach(root)
+	}
+
+	// Anything not reached from a root sits on a cycle
+	for _, i := range items {
+		if node := nodes[i.ID]; !visited[node.ID] {
+			roots = append(roots, node)
+			attach(node)
+		}
+	}
+
+	return roots
+}
@@ -0,0 +1,833 @@
+package item
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/database"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+
+	// Auto migrate all models
+	err = database.AutoMigrate(db)
+	if err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+
+	return db
+}
+
+func TestValidatePrefix(t *testing.T) {
+	tests := []struct {
+		prefix string
+		valid  bool
+	}{
+		{"ABC", true},
+		{"ZZZ", true},
+		{"abc", false},
+		{"AbC", false},
+		{"AB", false},
+		{"ABCD", false},
+		{"", false},
+		{"A1C", false},
+		{"ÄBC", false},
+	}
+
+	for _, tt := range tests {
+		err := ValidatePrefix(tt.prefix)
+		if tt.valid && err != nil {
+			t.Errorf("Expected prefix %q to be valid, got %v", tt.prefix, err)
+		}
+		if !tt.valid && !errors.Is(err, ErrInvalidPrefix) {
+			t.Errorf("Expected prefix %q to be rejected, got %v", tt.prefix, err)
+		}
+	}
+}
+
+func TestNormalizePrefix(t *testing.T) {
+	normalized, err := NormalizePrefix(" abc ")
+	if err != nil {
+		t.Fatalf("Expected lowercase prefix to be normalized, got %v", err)
+	}
+	if normalized != "ABC" {
+		t.Errorf("Expected normalized prefix 'ABC', got '%s'", normalized)
+	}
+
+	for _, prefix := range []string{"ab", "abcd", "a-c"} {
+		if _, err := NormalizePrefix(prefix); !errors.Is(err, ErrInvalidPrefix) {
+			t.Errorf("Expected prefix %q to be rejected, got %v", prefix, err)
+		}
+	}
+}
+
+func TestGeneratePrefix(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		if prefix := GeneratePrefix(); ValidatePrefix(prefix) != nil {
+			t.Fatalf("Generated prefix %q is not valid", prefix)
+		}
+	}
+}
+
+func TestCreateItem_NormalizesStoredPrefix(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewItemService(db, &config.Config{})
+	db.Create(&database.User{Email: "test@example.com", Prefix: "abc"})
+
+	item, err := service.CreateItem("Tent", "", "test@example.com", nil)
+	if err != nil {
+		t.Fatalf("Failed to create item: %v", err)
+	}
+	if item.BackpackID != "ABC0001" {
+		t.Errorf("Expected backpack ID 'ABC0001', got '%s'", item.BackpackID)
+	}
+
+	var user database.User
+	db.First(&user, "email = ?", "test@example.com")
+	if user.Prefix != "ABC" {
+		t.Errorf("Expected stored prefix 'ABC', got '%s'", user.Prefix)
+	}
+}
+
+func TestCreateItem_UsesActiveOrganization(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewItemService(db, &config.Config{})
+	organization := database.Organization{Name: "Team"}
+	db.Create(&organization)
+	db.Create(&database.User{Email: "test@example.com", ActiveOrganizationID: organization.ID})
+
+	item, err := service.CreateItem("Tent", "", "test@example.com", nil)
+	if err != nil {
+		t.Fatalf("Failed to create item: %v", err)
+	}
+	if item.OrganizationID == nil || *item.OrganizationID != organization.ID {
+		t.Errorf("Expected item in organization %d, got %v", organization.ID, item.OrganizationID)
+	}
+}
+
+func TestCreateItem_RejectsMalformedPrefix(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewItemService(db, &config.Config{})
+	db.Create(&database.User{Email: "test@example.com", Prefix: "AB1"})
+
+	if _, err := service.CreateItem("Tent", "", "test@example.com", nil); !errors.Is(err, ErrInvalidPrefix) {
+		t.Errorf("Expected ErrInvalidPrefix, got %v", err)
+	}
+}
+
+func TestCreateItem_GeneratesMissingPrefix(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewItemService(db, &config.Config{})
+	db.Create(&database.User{Email: "test@example.com"})
+
+	item, err := service.CreateItem("Tent", "", "test@example.com", nil)
+	if err != nil {
+		t.Fatalf("Failed to create item: %v", err)
+	}
+
+	var user database.User
+	db.First(&user, "email = ?", "test@example.com")
+	if err := ValidatePrefix(user.Prefix); err != nil {
+		t.Errorf("Expected a valid generated prefix, got '%s'", user.Prefix)
+	}
+	if item.BackpackID != user.Prefix+"0001" {
+		t.Errorf("Expected backpack ID '%s0001', got '%s'", user.Prefix, item.BackpackID)
+	}
+}
+
+func TestCreateItem_PerUserQuota(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewItemService(db, &config.Config{Item: config.ItemConfig{MaxPerUser: 2}})
+	db.Create(&database.User{Email: "test@example.com", Prefix: "TES"})
+	db.Create(&database.User{Email: "other@example.com", Prefix: "OTH"})
+
+	// Under the cap
+	for _, name := range []string{"Tent", "Stove"} {
+		if _, err := service.CreateItem(name, "", "test@example.com", nil); err != nil {
+			t.Fatalf("Failed to create item under quota: %v", err)
+		}
+	}
+
+	// At the cap
+	if _, err := service.CreateItem("Lamp", "", "test@example.com", nil); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("Expected ErrQuotaExceeded, got %v", err)
+	}
+
+	// Other users have their own quota
+	if _, err := service.CreateItem("Lamp", "", "other@example.com", nil); err != nil {
+		t.Errorf("Failed to create item for another user: %v", err)
+	}
+}
+
+func TestGetItems_NameFilter(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewItemService(db, &config.Config{})
+	db.Create(&database.User{Email: "test@example.com", Prefix: "TES"})
+	db.Create(&database.User{Email: "other@example.com", Prefix: "OTH"})
+
+	for _, name := range []string{"Camping Tent", "Tent Pegs", "Stove"} {
+		if _, err := service.CreateItem(name, "", "test@example.com", nil); err != nil {
+			t.Fatalf("Failed to create item: %v", err)
+		}
+	}
+	if _, err := service.CreateItem("Other Tent", "", "other@example.com", nil); err != nil {
+		t.Fatalf("Failed to create item: %v", err)
+	}
+
+	// The match is case-insensitive in both directions and limited to the user's items
+	for _, filter := range []string{"tent", "TENT", "Tent"} {
+		items, err := service.GetItems("test@example.com", filter)
+		if err != nil {
+			t.Fatalf("Failed to get items for %q: %v", filter, err)
+		}
+		if len(items) != 2 {
+			t.Errorf("Expected 2 items matching %q, got %d", filter, len(items))
+		}
+	}
+
+	items, err := service.GetItems("test@example.com", "")
+	if err != nil {
+		t.Fatalf("Failed to get items: %v", err)
+	}
+	if len(items) != 3 {
+		t.Errorf("Expected all 3 items without a filter, got %d", len(items))
+	}
+
+	// Wildcards in the filter only match themselves
+	if _, err := service.CreateItem("100% Tent", "", "test@example.com", nil); err != nil {
+		t.Fatalf("Failed to create item: %v", err)
+	}
+	for filter, want := range map[string]int{"0%": 1, "_": 0} {
+		items, err := service.GetItems("test@example.com", filter)
+		if err != nil {
+			t.Fatalf("Failed to get items for %q: %v", filter, err)
+		}
+		if len(items) != want {
+			t.Errorf("Expected %d items matching %q, got %d", want, filter, len(items))
+		}
+	}
+}
+
+func TestGetDeletedSince(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewItemService(db, &config.Config{})
+	db.Create(&database.User{Email: "test@example.com", Prefix: "TES"})
+	db.Create(&database.User{Email: "other@example.com", Prefix: "OTH"})
+
+	before, _ := service.CreateItem("Before", "", "test@example.com", nil)
+	after, _ := service.CreateItem("After", "", "test@example.com", nil)
+	kept, _ := service.CreateItem("Kept", "", "test@example.com", nil)
+	other, _ := service.CreateItem("Other", "", "other@example.com", nil)
+
+	cursor := time.Now()
+	db.Unscoped().Model(&database.Item{}).Where("id = ?", before.ID).Update("deleted_at", cursor.Add(-time.Minute))
+	if _, err := service.DeleteItem(after.ID, "test@example.com", DeleteOptions{}); err != nil {
+		t.Fatalf("Failed to delete item: %v", err)
+	}
+	if _, err := service.DeleteItem(other.ID, "other@example.com", DeleteOptions{}); err != nil {
+		t.Fatalf("Failed to delete item: %v", err)
+	}
+
+	deleted, err := service.GetDeletedSince("test@example.com", cursor)
+	if err != nil {
+		t.Fatalf("Failed to get deleted items: %v", err)
+	}
+	if len(deleted) != 1 {
+		t.Fatalf("Expected 1 deleted item, got %d", len(deleted))
+	}
+	if deleted[0].ID != after.ID || deleted[0].BackpackID != after.BackpackID {
+		t.Errorf("Expected tombstone for item %d (%s), got %+v", after.ID, after.BackpackID, deleted[0])
+	}
+
+	// Soft-deleted items are hidden from normal reads
+	if _, err := service.GetItem(after.ID, "test@example.com"); !errors.Is(err, ErrItemNotFound) {
+		t.Errorf("Expected deleted item to be hidden, got %v", err)
+	}
+	if _, err := service.GetItem(kept.ID, "test@example.com"); err != nil {
+		t.Errorf("Expected kept item to be readable, got %v", err)
+	}
+}
+
+func TestGetItemTree_BreaksCycles(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewItemService(db, &config.Config{})
+	db.Create(&database.User{Email: "test@example.com", Prefix: "TES"})
+
+	first, _ := service.CreateItem("First", "", "test@example.com", nil)
+	second, _ := service.CreateItem("Second", "", "test@example.com", &first.ID)
+	db.Model(first).Update("parent_id", second.ID)
+
+	tree, err := service.GetItemTree("test@example.com")
+	if err != nil {
+		t.Fatalf("Failed to get item tree: %v", err)
+	}
+	if len(tree) != 1 {
+		t.Fatalf("Expected the cycle to be broken into 1 root, got %d", len(tree))
+	}
+	if tree[0].ID != first.ID || len(tree[0].Children) != 1 || tree[0].Children[0].ID != second.ID {
+		t.Errorf("Expected First > Second, got %+v", tree[0])
+	}
+	if len(tree[0].Children[0].Children) != 0 {
+		t.Errorf("Expected Second to have no children, got %d", len(tree[0].Children[0].Children))
+	}
+}
+
+func TestMoveItem(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewItemService(db, &config.Config{})
+	db.Create(&database.User{Email: "test@example.com", Prefix: "TES"})
+	db.Create(&database.User{Email: "other@example.com", Prefix: "OTH"})
+
+	box, _ := service.CreateItem("Box", "", "test@example.com", nil)
+	bag, _ := service.CreateItem("Bag", "", "test@example.com", &box.ID)
+	pouch, _ := service.CreateItem("Pouch", "", "test@example.com", &bag.ID)
+	lamp, _ := service.CreateItem("Lamp", "", "test@example.com", nil)
+	foreign, _ := service.CreateItem("Foreign", "", "other@example.com", nil)
+
+	moved, err := service.MoveItem(lamp.ID, "test@example.com", &pouch.ID)
+	if err != nil {
+		t.Fatalf("Failed to move item: %v", err)
+	}
+	if moved.ParentID == nil || *moved.ParentID != pouch.ID {
+		t.Errorf("Expected parent %d, got %v", pouch.ID, moved.ParentID)
+	}
+
+	moved, err = service.MoveItem(lamp.ID, "test@example.com", nil)
+	if err != nil {
+		t.Fatalf("Failed to move item to the top level: %v", err)
+	}
+	if moved.ParentID != nil {
+		t.Errorf("Expected no parent, got %d", *moved.ParentID)
+	}
+
+	if _, err := service.MoveItem(foreign.ID, "test@example.com", &box.ID); !errors.Is(err, ErrItemNotFound) {
+		t.Errorf("Expected ErrItemNotFound, got %v", err)
+	}
+	if _, err := service.MoveItem(lamp.ID, "test@example.com", &foreign.ID); !errors.Is(err, ErrParentNotFound) {
+		t.Errorf("Expected ErrParentNotFound, got %v", err)
+	}
+}
+
+func TestMoveItem_RejectsCycles(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewItemService(db, &config.Config{})
+	db.Create(&database.User{Email: "test@example.com", Prefix: "TES"})
+
+	box, _ := service.CreateItem("Box", "", "test@example.com", nil)
+	bag, _ := service.CreateItem("Bag", "", "test@example.com", &box.ID)
+	pouch, _ := service.CreateItem("Pouch", "", "test@example.com", &bag.ID)
+
+	tests := []struct {
+		name   string
+		parent uint
+	}{
+		{"self", box.ID},
+		{"child", bag.ID},
+		{"grandchild", pouch.ID},
+	}
+	for _, tt := range tests {
+		if _, err := service.MoveItem(box.ID, "test@example.com", &tt.parent); !errors.Is(err, ErrParentCycle) {
+			t.Errorf("%s: expected ErrParentCycle, got %v", tt.name, err)
+		}
+	}
+
+	reloaded, _ := service.GetItem(box.ID, "test@example.com")
+	if reloaded.ParentID != nil {
+		t.Errorf("Expected rejected moves to leave the parent unset, got %d", *reloaded.ParentID)
+	}
+}
+
+// createHierarchy creates Box > Bag > Pouch plus a second child Bag > Tin
+func createHierarchy(t *testing.T, service *Service) (box, bag, pouch, tin *database.Item) {
+	box, _ = service.CreateItem("Box", "", "test@example.com", nil)
+	bag, _ = service.CreateItem("Bag", "", "test@example.com", &box.ID)
+	pouch, _ = service.CreateItem("Pouch", "", "test@example.com", &bag.ID)
+	tin, _ = service.CreateItem("Tin", "", "test@example.com", &bag.ID)
+	if box == nil || bag == nil || pouch == nil || tin == nil {
+		t.Fatalf("Failed to create item hierarchy")
+	}
+	return box, bag, pouch, tin
+}
+
+func TestDeleteItem_ReparentsChildren(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewItemService(db, &config.Config{})
+	db.Create(&database.User{Email: "test@example.com", Prefix: "TES"})
+	box, bag, pouch, tin := createHierarchy(t, service)
+
+	deleted, err := service.DeleteItem(bag.ID, "test@example.com", DeleteOptions{})
+	if err != nil {
+		t.Fatalf("Failed to delete item: %v", err)
+	}
+	if len(deleted) != 1 || deleted[0] != bag.ID {
+		t.Errorf("Expected only item %d to be deleted, got %v", bag.ID, deleted)
+	}
+
+	for _, child := range []*database.Item{pouch, tin} {
+		reloaded, err := service.GetItem(child.ID, "test@example.com")
+		if err != nil {
+			t.Fatalf("Expected child %s to survive, got %v", child.Name, err)
+		}
+		if reloaded.ParentID == nil || *reloaded.ParentID != box.ID {
+			t.Errorf("Expected %s to move under Box, got parent %v", child.Name, reloaded.ParentID)
+		}
+	}
+
+	// Deleting a root item makes its children roots
+	if _, err := service.DeleteItem(box.ID, "test@example.com", DeleteOptions{}); err != nil {
+		t.Fatalf("Failed to delete item: %v", err)
+	}
+	reloaded, _ := service.GetItem(pouch.ID, "test@example.com")
+	if reloaded.ParentID != nil {
+		t.Errorf("Expected Pouch to become a root item, got parent %d", *reloaded.ParentID)
+	}
+}
+
+func TestDeleteItem_Cascade(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewItemService(db, &config.Config{})
+	db.Create(&database.User{Email: "test@example.com", Prefix: "TES"})
+	box, bag, pouch, tin := createHierarchy(t, service)
+	lamp, _ := service.CreateItem("Lamp", "", "test@example.com", nil)
+
+	deleted, err := service.DeleteItem(box.ID, "test@example.com", DeleteOptions{Cascade: true})
+	if err != nil {
+		t.Fatalf("Failed to delete item: %v", err)
+	}
+	if len(deleted) != 4 {
+		t.Errorf("Expected 4 deleted items, got %v", deleted)
+	}
+
+	for _, gone := range []*database.Item{box, bag, pouch, tin} {
+		if _, err := service.GetItem(gone.ID, "test@example.com"); !errors.Is(err, ErrItemNotFound) {
+			t.Errorf("Expected %s to be deleted, got %v", gone.Name, err)
+		}
+	}
+	if _, err := service.GetItem(lamp.ID, "test@example.com"); err != nil {
+		t.Errorf("Expected unrelated item to survive, got %v", err)
+	}
+
+	if _, err := service.DeleteItem(box.ID, "test@example.com", DeleteOptions{Cascade: true}); !errors.Is(err, ErrItemNotFound) {
+		t.Errorf("Expected ErrItemNotFound, got %v", err)
+	}
+}
+
+func TestRestoreItem_DetachesFromDeletedParent(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewItemService(db, &config.Config{})
+	db.Create(&database.User{Email: "test@example.com", Prefix: "TES"})
+	box, bag, _, _ := createHierarchy(t, service)
+
+	if _, err := service.DeleteItem(bag.ID, "test@example.com", DeleteOptions{}); err != nil {
+		t.Fatalf("Failed to delete item: %v", err)
+	}
+	if _, err := service.DeleteItem(box.ID, "test@example.com", DeleteOptions{Hard: true}); err != nil {
+		t.Fatalf("Failed to delete item: %v", err)
+	}
+
+	restored, err := service.RestoreItem(bag.ID, "test@example.com")
+	if err != nil {
+		t.Fatalf("Failed to restore item: %v", err)
+	}
+	if restored.ParentID != nil {
+		t.Errorf("Expected restored item to become a root item, got parent %d", *restored.ParentID)
+	}
+
+	if _, err := service.RestoreItem(box.ID, "test@example.com"); !errors.Is(err, ErrItemNotFound) {
+		t.Errorf("Expected hard-deleted item to be unrecoverable, got %v", err)
+	}
+}
+
+func TestRestoreItem_PerUserQuota(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewItemService(db, &config.Config{Item: config.ItemConfig{MaxPerUser: 1}})
+	db.Create(&database.User{Email: "test@example.com", Prefix: "TES"})
+
+	tent, err := service.CreateItem("Tent", "", "test@example.com", nil)
+	if err != nil {
+		t.Fatalf("Failed to create item: %v", err)
+	}
+	if _, err := service.DeleteItem(tent.ID, "test@example.com", DeleteOptions{}); err != nil {
+		t.Fatalf("Failed to delete item: %v", err)
+	}
+
+	// Trashed items don't count, so the slot can be reused
+	if _, err := service.CreateItem("Stove", "", "test@example.com", nil); err != nil {
+		t.Fatalf("Failed to create item: %v", err)
+	}
+
+	if _, err := service.RestoreItem(tent.ID, "test@example.com"); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("Expected ErrQuotaExceeded, got %v", err)
+	}
+
+	var count int64
+	db.Model(&database.Item{}).Where("user_email = ?", "test@example.com").Count(&count)
+	if count != 1 {
+		t.Errorf("Expected the item to stay in the trash, got %d live items", count)
+	}
+}
+
+func TestCreateItem_Timestamps(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewItemService(db, &config.Config{})
+	db.Create(&database.User{Email: "test@example.com", Prefix: "TES"})
+
+	created, err := service.CreateItem("Tent", "", "test@example.com", nil)
+	if err != nil {
+		t.Fatalf("Failed to create item: %v", err)
+	}
+	if created.AddedAt.IsZero() || created.CreatedAt.IsZero() || created.UpdatedAt.IsZero() {
+		t.Fatalf("Expected timestamps to be set, got %+v", created)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	updated, err := service.UpdateItem(created.ID, "test@example.com", "Big Tent", "", nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to update item: %v", err)
+	}
+	if !updated.UpdatedAt.After(created.UpdatedAt) {
+		t.Errorf("Expected updated_at to advance past %v, got %v", created.UpdatedAt, updated.UpdatedAt)
+	}
+	if !updated.AddedAt.Equal(created.AddedAt) {
+		t.Errorf("Expected added_at to stay %v, got %v", created.AddedAt, updated.AddedAt)
+	}
+}
+
+func TestCreateItems(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewItemService(db, &config.Config{})
+	db.Create(&database.User{Email: "test@example.com", Prefix: "TES"})
+
+	if _, err := service.CreateItem("Backpack", "", "test@example.com", nil); err != nil {
+		t.Fatalf("Failed to create item: %v", err)
+	}
+
+	created, err := service.CreateItems("test@example.com", []NewItem{
+		{Name: "Tent", Description: "Two person"},
+		{Name: "Stove"},
+		{Name: "Lamp"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create items: %v", err)
+	}
+
+	// Backpack IDs continue from the single-item counter
+	want := []string{"TES0002", "TES0003", "TES0004"}
+	if len(created) != len(want) {
+		t.Fatalf("Expected %d items, got %d", len(want), len(created))
+	}
+	for i, it := range created {
+		if it.ID == 0 || it.BackpackID != want[i] {
+			t.Errorf("Expected item %d to be stored with backpack ID %s, got ID %d and %q", i, want[i], it.ID, it.BackpackID)
+		}
+	}
+	if created[0].Name != "Tent" || created[0].Description != "Two person" {
+		t.Errorf("Expected first item to keep its fields, got %+v", created[0])
+	}
+
+	next, err := service.CreateItem("Mat", "", "test@example.com", nil)
+	if err != nil {
+		t.Fatalf("Failed to create item: %v", err)
+	}
+	if next.BackpackID != "TES0005" {
+		t.Errorf("Expected counter to advance past the batch, got %s", next.BackpackID)
+	}
+}
+
+func TestCreateItems_RollsBackInvalidBatch(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewItemService(db, &config.Config{})
+	db.Create(&database.User{Email: "test@example.com", Prefix: "TES"})
+
+	_, err := service.CreateItems("test@example.com", []NewItem{
+		{Name: "Tent"},
+		{Name: "  "},
+		{Name: "Lamp"},
+	})
+
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) || batchErr.Index != 1 || !errors.Is(err, ErrInvalidItem) {
+		t.Fatalf("Expected invalid item at index 1, got %v", err)
+	}
+
+	var count int64
+	db.Model(&database.Item{}).Count(&count)
+	if count != 0 {
+		t.Errorf("Expected no items to be created, got %d", count)
+	}
+	db.Model(&database.BackPackIdNextNumber{}).Count(&count)
+	if count != 0 {
+		t.Errorf("Expected no backpack numbers to be reserved, got %d", count)
+	}
+}
+
+func TestCreateItems_PerUserQuota(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewItemService(db, &config.Config{Item: config.ItemConfig{MaxPerUser: 2}})
+	db.Create(&database.User{Email: "test@example.com", Prefix: "TES"})
+
+	_, err := service.CreateItems("test@example.com", []NewItem{{Name: "Tent"}, {Name: "Stove"}, {Name: "Lamp"}})
+	if !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("Expected ErrQuotaExceeded, got %v", err)
+	}
+
+	var count int64
+	db.Model(&database.Item{}).Count(&count)
+	if count != 0 {
+		t.Errorf("Expected no items to be created, got %d", count)
+	}
+}
+
+func TestCreate_QuantityAndUnit(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewItemService(db, &config.Config{})
+	db.Create(&database.User{Email: "test@example.com", Prefix: "TES"})
+
+	defaulted, err := service.Create("test@example.com", NewItem{Name: "Tent"})
+	if err != nil {
+		t.Fatalf("Failed to create item: %v", err)
+	}
+	if defaulted.Quantity != 1 {
+		t.Errorf("Expected default quantity 1, got %d", defaulted.Quantity)
+	}
+
+	quantity := 3
+	rice, err := service.Create("test@example.com", NewItem{Name: "Rice", Quantity: &quantity, Unit: "kg"})
+	if err != nil {
+		t.Fatalf("Failed to create item: %v", err)
+	}
+	if rice.Quantity != 3 || rice.Unit != "kg" {
+		t.Errorf("Expected 3 kg, got %d %q", rice.Quantity, rice.Unit)
+	}
+
+	// An explicit zero is kept rather than replaced by the column default
+	zero := 0
+	empty, err := service.Create("test@example.com", NewItem{Name: "Gas", Quantity: &zero})
+	if err != nil {
+		t.Fatalf("Failed to create item: %v", err)
+	}
+	if empty.Quantity != 0 {
+		t.Errorf("Expected quantity 0, got %d", empty.Quantity)
+	}
+
+	negative := -1
+	if _, err := service.Create("test@example.com", NewItem{Name: "Lamp", Quantity: &negative}); !errors.Is(err, ErrInvalidItem) {
+		t.Errorf("Expected ErrInvalidItem for a negative quantity, got %v", err)
+	}
+}
+
+func TestAdjustQuantity(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewItemService(db, &config.Config{})
+	db.Create(&database.User{Email: "test@example.com", Prefix: "TES"})
+	db.Create(&database.User{Email: "other@example.com", Prefix: "OTH"})
+
+	created, err := service.CreateItem("Batteries", "", "test@example.com", nil)
+	if err != nil {
+		t.Fatalf("Failed to create item: %v", err)
+	}
+
+	// Increment
+	adjusted, err := service.AdjustQuantity(created.ID, "test@example.com", 4)
+	if err != nil {
+		t.Fatalf("Failed to increment quantity: %v", err)
+	}
+	if adjusted.Quantity != 5 {
+		t.Errorf("Expected quantity 5, got %d", adjusted.Quantity)
+	}
+	if adjusted.Version != 2 {
+		t.Errorf("Expected version 2, got %d", adjusted.Version)
+	}
+
+	// Decrement down to zero
+	adjusted, err = service.AdjustQuantity(created.ID, "test@example.com", -5)
+	if err != nil {
+		t.Fatalf("Failed to decrement quantity: %v", err)
+	}
+	if adjusted.Quantity != 0 {
+		t.Errorf("Expected quantity 0, got %d", adjusted.Quantity)
+	}
+
+	// Below zero is rejected and leaves the quantity alone
+	if _, err := service.AdjustQuantity(created.ID, "test@example.com", -1); !errors.Is(err, ErrQuantityBelowZero) {
+		t.Errorf("Expected ErrQuantityBelowZero, got %v", err)
+	}
+	stored, _ := service.GetItem(created.ID, "test@example.com")
+	if stored.Quantity != 0 {
+		t.Errorf("Expected quantity to stay 0, got %d", stored.Quantity)
+	}
+
+	// Other users' items are not found
+	if _, err := service.AdjustQuantity(created.ID, "other@example.com", 1); !errors.Is(err, ErrItemNotFound) {
+		t.Errorf("Expected ErrItemNotFound, got %v", err)
+	}
+}
+
+// setupConcurrentTestDB opens a file database so goroutines use separate connections;
+// immediate transactions and the busy timeout make SQLite queue the writers instead of failing
+func setupConcurrentTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "items.db")+"?_busy_timeout=10000&_txlock=immediate"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+	if err := database.AutoMigrate(db); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+	return db
+}
+
+func TestCreateItem_ConcurrentBackpackIDsAreUnique(t *testing.T) {
+	db := setupConcurrentTestDB(t)
+	service := NewItemService(db, &config.Config{})
+	db.Create(&database.User{Email: "test@example.com", Prefix: "CON"})
+
+	const workers, perWorker = 8, 10
+	ids := make(chan string, workers*perWorker)
+	errs := make(chan error, workers*perWorker)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				item, err := service.CreateItem("Item", "", "test@example.com", nil)
+				if err != nil {
+					errs <- err
+					continue
+				}
+				ids <- item.BackpackID
+			}
+		}()
+	}
+	wg.Wait()
+	close(ids)
+	close(errs)
+
+	for err := range errs {
+		t.Errorf("Failed to create item: %v", err)
+	}
+	seen := make(map[string]bool)
+	for id := range ids {
+		if seen[id] {
+			t.Errorf("Backpack ID %s was issued twice", id)
+		}
+		seen[id] = true
+	}
+	if len(seen) != workers*perWorker {
+		t.Errorf("Expected %d distinct backpack IDs, got %d", workers*perWorker, len(seen))
+	}
+	if !seen["CON0001"] || !seen[fmt.Sprintf("CON%04d", workers*perWorker)] {
+		t.Errorf("Expected backpack IDs CON0001 to CON%04d", workers*perWorker)
+	}
+}
+
+func TestCreateItems_ConcurrentWithSingleCreates(t *testing.T) {
+	db := setupConcurrentTestDB(t)
+	service := NewItemService(db, &config.Config{})
+	db.Create(&database.User{Email: "test@example.com", Prefix: "MIX"})
+
+	// Half the goroutines reserve numbers in batches of 3, the other half one at a time
+	const workers, batchSize = 10, 3
+	ids := make(chan string, workers*batchSize)
+	errs := make(chan error, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(batch bool) {
+			defer wg.Done()
+			if batch {
+				items, err := service.CreateItems("test@example.com", []NewItem{{Name: "A"}, {Name: "B"}, {Name: "C"}})
+				if err != nil {
+					errs <- err
+					return
+				}
+				for _, it := range items {
+					ids <- it.BackpackID
+				}
+				return
+			}
+			item, err := service.CreateItem("Item", "", "test@example.com", nil)
+			if err != nil {
+				errs <- err
+				return
+			}
+			ids <- item.BackpackID
+		}(w%2 == 0)
+	}
+	wg.Wait()
+	close(ids)
+	close(errs)
+
+	for err := range errs {
+		t.Errorf("Failed to create items: %v", err)
+	}
+	want := workers/2*batchSize + workers/2
+	seen := make(map[string]bool)
+	for id := range ids {
+		if seen[id] {
+			t.Errorf("Backpack ID %s was issued twice", id)
+		}
+		seen[id] = true
+	}
+	// The numbers are contiguous and keep the 4-digit format
+	for n := 1; n <= want; n++ {
+		if id := fmt.Sprintf("MIX%04d", n); !seen[id] {
+			t.Errorf("Expected backpack ID %s to be issued", id)
+		}
+	}
+	if len(seen) != want {
+		t.Errorf("Expected %d distinct backpack IDs, got %d", want, len(seen))
+	}
+}
+
+func TestGetNextID_ConcurrentFirstNumber(t *testing.T) {
+	db := setupConcurrentTestDB(t)
+
+	// Every goroutine starts on a prefix without a counter, racing to create it
+	const workers = 20
+	numbers := make(chan string, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			number, err := getNextID(db, "NEW")
+			if err != nil {
+				t.Errorf("Failed to get next ID: %v", err)
+				return
+			}
+			numbers <- number
+		}()
+	}
+	wg.Wait()
+	close(numbers)
+
+	seen := make(map[string]bool)
+	for number := range numbers {
+		if seen[number] {
+			t.Errorf("Number %s was issued twice", number)
+		}
+		seen[number] = true
+	}
+	if !seen["0001"] || !seen[fmt.Sprintf("%04d", workers)] {
+		t.Errorf("Expected numbers 0001 to %04d, got %v", workers, seen)
+	}
+
+	var count int64
+	db.Model(&database.BackPackIdNextNumber{}).Where("backpack_id = ?", "NEW").Count(&count)
+	if count != 1 {
+		t.Errorf("Expected a single counter row for the prefix, got %d", count)
+	}
+}
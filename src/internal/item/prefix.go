@@ -0,0 +1,96 @@
+package item
+
+import (
+	"errors"
+
+	"backend/internal/database"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxPrefixAttempts is how many random prefixes RegeneratePrefix tries before giving up
+const maxPrefixAttempts = 20
+
+// errPrefixClaimed is returned by claimSequence when the prefix already has a sequence
+var errPrefixClaimed = errors.New("backpack prefix already claimed")
+
+// PrefixSequence is a user's backpack prefix and the last number issued for it
+type PrefixSequence struct {
+	Prefix string
+	// LastNumber is 0 when no backpack ID has been issued for the prefix yet
+	LastNumber int
+}
+
+// NextBackpackID returns the backpack ID the next created item will get
+func (p *PrefixSequence) NextBackpackID() string {
+	return p.Prefix + formatNumber(p.LastNumber+1)
+}
+
+// claimSequence creates the backpack ID sequence of a prefix, starting at 0. The unique
+// index on the prefix makes this atomic: errPrefixClaimed means it already existed.
+func claimSequence(tx *gorm.DB, prefix string) error {
+	result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&database.BackPackIdNextNumber{
+		BackpackID: prefix,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errPrefixClaimed
+	}
+	return nil
+}
+
+// GetPrefixSequence returns the backpack prefix of a user and the last number issued for it
+func (s *Service) GetPrefixSequence(userEmail string) (*PrefixSequence, error) {
+	var user database.User
+	if err := s.db.Where("email = ?", userEmail).First(&user).Error; err != nil {
+		return nil, err
+	}
+
+	sequence := &PrefixSequence{Prefix: user.Prefix}
+	var nextNumber database.BackPackIdNextNumber
+	err := s.db.Where("backpack_id = ?", user.Prefix).First(&nextNumber).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	sequence.LastNumber = nextNumber.Number
+	return sequence, nil
+}
+
+// RegeneratePrefix gives a user a new random backpack prefix that no other user has and
+// that has never issued a backpack ID, so their numbering starts over at 0001. Existing
+// items keep their backpack IDs.
+func (s *Service) RegeneratePrefix(userEmail string) (*PrefixSequence, error) {
+	var user database.User
+	if err := s.db.Where("email = ?", userEmail).First(&user).Error; err != nil {
+		return nil, err
+	}
+
+	for attempt := 0; attempt < maxPrefixAttempts; attempt++ {
+		prefix := GeneratePrefix()
+		err := s.db.Transaction(func(tx *gorm.DB) error {
+			var taken int64
+			if err := tx.Model(&database.User{}).Where("prefix = ?", prefix).Count(&taken).Error; err != nil {
+				return err
+			}
+			if taken > 0 {
+				return errPrefixClaimed
+			}
+			// Claiming the sequence reserves the prefix against concurrent regenerations
+			if err := claimSequence(tx, prefix); err != nil {
+				return err
+			}
+			return tx.Model(&user).Update("prefix", prefix).Error
+		})
+		if errors.Is(err, errPrefixClaimed) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &PrefixSequence{Prefix: prefix}, nil
+	}
+	return nil, ErrPrefixUnavailable
+}
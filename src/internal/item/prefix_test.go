@@ -0,0 +1,100 @@
+package item
+
+import (
+	"errors"
+	"testing"
+
+	"backend/internal/config"
+	"backend/internal/database"
+)
+
+func TestGetPrefixSequence(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewItemService(db, &config.Config{})
+	db.Create(&database.User{Email: "test@example.com", Prefix: "ABC"})
+
+	sequence, err := service.GetPrefixSequence("test@example.com")
+	if err != nil {
+		t.Fatalf("Failed to get prefix sequence: %v", err)
+	}
+	if sequence.Prefix != "ABC" || sequence.LastNumber != 0 || sequence.NextBackpackID() != "ABC0001" {
+		t.Errorf("Expected unused prefix ABC, got %+v", sequence)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := service.CreateItem("Item", "", "test@example.com", nil); err != nil {
+			t.Fatalf("Failed to create item: %v", err)
+		}
+	}
+
+	sequence, err = service.GetPrefixSequence("test@example.com")
+	if err != nil {
+		t.Fatalf("Failed to get prefix sequence: %v", err)
+	}
+	if sequence.LastNumber != 2 || sequence.NextBackpackID() != "ABC0003" {
+		t.Errorf("Expected last number 2, got %+v", sequence)
+	}
+}
+
+func TestRegeneratePrefix(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewItemService(db, &config.Config{})
+	db.Create(&database.User{Email: "test@example.com", Prefix: "ABC"})
+	db.Create(&database.User{Email: "other@example.com", Prefix: "XYZ"})
+
+	old, err := service.CreateItem("Old", "", "test@example.com", nil)
+	if err != nil {
+		t.Fatalf("Failed to create item: %v", err)
+	}
+
+	sequence, err := service.RegeneratePrefix("test@example.com")
+	if err != nil {
+		t.Fatalf("Failed to regenerate prefix: %v", err)
+	}
+	if ValidatePrefix(sequence.Prefix) != nil || sequence.Prefix == "ABC" || sequence.Prefix == "XYZ" {
+		t.Errorf("Expected a new unused prefix, got %q", sequence.Prefix)
+	}
+
+	item, err := service.CreateItem("New", "", "test@example.com", nil)
+	if err != nil {
+		t.Fatalf("Failed to create item: %v", err)
+	}
+	if item.BackpackID != sequence.Prefix+"0001" {
+		t.Errorf("Expected numbering to restart at %s0001, got %s", sequence.Prefix, item.BackpackID)
+	}
+
+	var reloaded database.Item
+	db.First(&reloaded, old.ID)
+	if reloaded.BackpackID != "ABC0001" {
+		t.Errorf("Expected existing item to keep backpack ID ABC0001, got %s", reloaded.BackpackID)
+	}
+}
+
+func TestRegeneratePrefix_AllPrefixesTaken(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewItemService(db, &config.Config{})
+	db.Create(&database.User{Email: "test@example.com", Prefix: "ABC"})
+
+	// Every possible prefix already has a counter, so each attempt collides
+	var counters []database.BackPackIdNextNumber
+	for a := 'A'; a <= 'Z'; a++ {
+		for b := 'A'; b <= 'Z'; b++ {
+			for c := 'A'; c <= 'Z'; c++ {
+				counters = append(counters, database.BackPackIdNextNumber{BackpackID: string([]rune{a, b, c})})
+			}
+		}
+	}
+	if err := db.CreateInBatches(counters, 500).Error; err != nil {
+		t.Fatalf("Failed to create counters: %v", err)
+	}
+
+	if _, err := service.RegeneratePrefix("test@example.com"); !errors.Is(err, ErrPrefixUnavailable) {
+		t.Errorf("Expected ErrPrefixUnavailable, got %v", err)
+	}
+
+	var user database.User
+	db.First(&user, "email = ?", "test@example.com")
+	if user.Prefix != "ABC" {
+		t.Errorf("Expected prefix to stay ABC, got %s", user.Prefix)
+	}
+}
@@ -1,23 +1,71 @@
 package jwt
 
 import (
-	"log"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
 	"time"
 
 	"backend/internal/config"
+	"backend/internal/database"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"go.uber.org/fx"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // Module provides JWT service dependency injection
 var Module = fx.Module("jwt",
 	fx.Provide(NewJWTService),
+	fx.Invoke(registerCleanup),
 )
 
+// cleanupInterval is how often expired blacklist entries are purged
+const cleanupInterval = time.Hour
+
 // Service handles JWT operations
 type Service struct {
 	config *config.JWTConfig
+	db     *gorm.DB
+	logger *slog.Logger
+
+	// Tokens are signed with RS256 when an RSA key is configured, otherwise HS256
+	method    jwt.SigningMethod
+	signKey   interface{}
+	verifyKey interface{}
+}
+
+var (
+	// ErrRefreshTokenNotFound is returned when a refresh token is not in the store
+	ErrRefreshTokenNotFound = errors.New("refresh token not found")
+	// ErrRefreshTokenReused is returned when an already rotated refresh token is presented again
+	ErrRefreshTokenReused = errors.New("refresh token reused")
+	// ErrUnexpectedSigningMethod is returned when a token is signed with a different algorithm than configured
+	ErrUnexpectedSigningMethod = errors.New("unexpected signing method")
+	// ErrTokenRevoked is returned when a token has been revoked
+	ErrTokenRevoked = errors.New("token revoked")
+	// ErrTokenNotRevocable is returned when a token has no jti claim to revoke it by
+	ErrTokenNotRevocable = errors.New("token has no id")
+)
+
+// Token types carried in the token_type claim
+const (
+	TokenTypeAccess  = "access"
+	TokenTypeRefresh = "refresh"
+)
+
+// TokenInfo describes the claims of a validated token
+type TokenInfo struct {
+	Email     string    `json:"email"`
+	TokenType string    `json:"token_type"`
+	ExpiresAt time.Time `json:"expires_at"`
+	// Roles are the user's roles in their active organization when the token was issued
+	Roles []string `json:"roles,omitempty"`
 }
 
 // TokenResponse represents the response containing tokens
@@ -27,58 +75,209 @@ type TokenResponse struct {
 }
 
 // NewJWTService creates a new JWT service
-func NewJWTService(cfg *config.Config) *Service {
-	return &Service{
-		config: &cfg.JWT,
+func NewJWTService(cfg *config.Config, db *gorm.DB, logger *slog.Logger) (*Service, error) {
+	s := &Service{
+		config:    &cfg.JWT,
+		db:        db,
+		logger:    logger,
+		method:    jwt.SigningMethodHS256,
+		signKey:   []byte(cfg.JWT.SecretKey),
+		verifyKey: []byte(cfg.JWT.SecretKey),
+	}
+
+	if cfg.JWT.PrivateKeyPath != "" {
+		if err := s.loadRSAKeys(cfg.JWT.PrivateKeyPath, cfg.JWT.PublicKeyPath); err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+// WithTx returns a copy of the service that runs its queries in tx
+func (s *Service) WithTx(tx *gorm.DB) *Service {
+	clone := *s
+	clone.db = tx
+	return &clone
+}
+
+// loadRSAKeys switches the service to RS256 using the PEM keys at the given paths;
+// the public key is derived from the private key when no public key path is set
+func (s *Service) loadRSAKeys(privateKeyPath, publicKeyPath string) error {
+	privatePEM, err := os.ReadFile(privateKeyPath)
+	if err != nil {
+		return fmt.Errorf("failed to read JWT private key: %w", err)
+	}
+	privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(privatePEM)
+	if err != nil {
+		return fmt.Errorf("failed to parse JWT private key: %w", err)
+	}
+
+	publicKey := &privateKey.PublicKey
+	if publicKeyPath != "" {
+		publicPEM, err := os.ReadFile(publicKeyPath)
+		if err != nil {
+			return fmt.Errorf("failed to read JWT public key: %w", err)
+		}
+		if publicKey, err = jwt.ParseRSAPublicKeyFromPEM(publicPEM); err != nil {
+			return fmt.Errorf("failed to parse JWT public key: %w", err)
+		}
+	}
+
+	s.method = jwt.SigningMethodRS256
+	s.signKey = privateKey
+	s.verifyKey = publicKey
+	return nil
+}
+
+// sign signs the claims with the configured signing method, adding the nbf claim, the
+// configured iss and aud claims and a jti claim unless the caller already set one
+func (s *Service) sign(claims jwt.MapClaims) (string, error) {
+	claims["nbf"] = time.Now().Add(s.config.NotBeforeOffset).Unix()
+	if s.config.Issuer != "" {
+		claims["iss"] = s.config.Issuer
+	}
+	if s.config.Audience != "" {
+		claims["aud"] = s.config.Audience
+	}
+	if _, ok := claims["jti"]; !ok {
+		claims["jti"] = generateTokenID()
+	}
+	return jwt.NewWithClaims(s.method, claims).SignedString(s.signKey)
+}
+
+// parse verifies a token's signature, its exp and nbf claims within the configured leeway
+// and its iss and aud claims when configured
+func (s *Service) parse(tokenString string) (*jwt.Token, error) {
+	options := []jwt.ParserOption{jwt.WithLeeway(s.config.Leeway)}
+	if !s.config.AcceptLegacyTokens {
+		if s.config.Issuer != "" {
+			options = append(options, jwt.WithIssuer(s.config.Issuer))
+		}
+		if s.config.Audience != "" {
+			options = append(options, jwt.WithAudience(s.config.Audience))
+		}
+	}
+
+	token, err := jwt.Parse(tokenString, s.keyFunc, options...)
+	if err != nil {
+		return nil, err
+	}
+	if s.config.AcceptLegacyTokens {
+		if err := s.verifyLegacyClaims(token); err != nil {
+			return nil, err
+		}
+	}
+	return token, nil
+}
+
+// verifyLegacyClaims checks the iss and aud claims where the parser can't: tokens issued
+// before the claims were added lack them and are accepted, but present claims must match
+func (s *Service) verifyLegacyClaims(token *jwt.Token) error {
+	issuer, err := token.Claims.GetIssuer()
+	if err != nil {
+		return err
+	}
+	if s.config.Issuer != "" && issuer != "" && issuer != s.config.Issuer {
+		return jwt.ErrTokenInvalidIssuer
+	}
+
+	audience, err := token.Claims.GetAudience()
+	if err != nil {
+		return err
+	}
+	if s.config.Audience != "" && len(audience) > 0 && !slices.Contains(audience, s.config.Audience) {
+		return jwt.ErrTokenInvalidAudience
 	}
+	return nil
+}
+
+// keyFunc returns the verification key, rejecting tokens signed with any other algorithm
+func (s *Service) keyFunc(token *jwt.Token) (interface{}, error) {
+	if token.Method.Alg() != s.method.Alg() {
+		return nil, ErrUnexpectedSigningMethod
+	}
+	return s.verifyKey, nil
 }
 
 // GenerateToken generates a JWT token with the specified email and expiry
 func (s *Service) GenerateToken(email string, expiry time.Duration) (string, error) {
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+	return s.sign(jwt.MapClaims{
 		"email": email,
 		"exp":   time.Now().Add(expiry).Unix(),
 	})
-	return token.SignedString([]byte(s.config.SecretKey))
 }
 
-// GenerateAccessToken generates an access token
+// GenerateAccessToken generates an access token carrying the user's roles
 func (s *Service) GenerateAccessToken(email string) (string, error) {
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+	claims := jwt.MapClaims{
 		"email":      email,
-		"token_type": "access",
+		"token_type": TokenTypeAccess,
 		"exp":        time.Now().Add(s.config.AccessTokenDuration).Unix(),
-	})
-	return token.SignedString([]byte(s.config.SecretKey))
+	}
+
+	roles, err := s.userRoles(email)
+	if err != nil {
+		return "", err
+	}
+	if len(roles) > 0 {
+		claims["roles"] = roles
+	}
+
+	return s.sign(claims)
+}
+
+// userRoles returns the user's role in their active organization. Services without a
+// store and users without an active membership get no roles.
+func (s *Service) userRoles(email string) ([]string, error) {
+	if s.db == nil {
+		return nil, nil
+	}
+
+	var roles []string
+	err := s.db.Model(&database.OrganizationUser{}).
+		Joins("JOIN users ON users.email = organization_users.user_email AND users.active_organization_id = organization_users.organization_id").
+		Where("organization_users.user_email = ?", email).
+		Pluck("organization_users.role", &roles).Error
+	return roles, err
 }
 
 // GenerateRefreshToken generates a refresh token
 func (s *Service) GenerateRefreshToken(email string) (string, error) {
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+	token, _, _, err := s.generateRefreshToken(email)
+	return token, err
+}
+
+// generateRefreshToken generates a refresh token and returns it along with its ID and expiry
+func (s *Service) generateRefreshToken(email string) (string, string, time.Time, error) {
+	jti := generateTokenID()
+	expiresAt := time.Now().Add(s.config.RefreshTokenDuration)
+	signed, err := s.sign(jwt.MapClaims{
 		"email":      email,
-		"token_type": "refresh",
-		"exp":        time.Now().Add(s.config.RefreshTokenDuration).Unix(),
+		"token_type": TokenTypeRefresh,
+		"jti":        jti,
+		"exp":        expiresAt.Unix(),
 	})
-	return token.SignedString([]byte(s.config.SecretKey))
+	if err != nil {
+		return "", "", time.Time{}, err
+	}
+	return signed, jti, expiresAt, nil
 }
 
 // GenerateTokenPair generates both access and refresh tokens
 func (s *Service) GenerateTokenPair(email string) (*TokenResponse, error) {
-	log.Printf("GenerateTokenPair called for email: %s", email)
-
 	accessToken, err := s.GenerateAccessToken(email)
 	if err != nil {
-		log.Printf("Failed to generate access token: %v", err)
+		s.logger.Error("failed to generate access token", "email", email, "error", err)
 		return nil, err
 	}
-	log.Printf("Access token generated successfully")
 
 	refreshToken, err := s.GenerateRefreshToken(email)
 	if err != nil {
-		log.Printf("Failed to generate refresh token: %v", err)
+		s.logger.Error("failed to generate refresh token", "email", email, "error", err)
 		return nil, err
 	}
-	log.Printf("Refresh token generated successfully")
+	s.logger.Debug("token pair generated", "email", email)
 
 	return &TokenResponse{
 		Token:        accessToken,
@@ -86,62 +285,335 @@ func (s *Service) GenerateTokenPair(email string) (*TokenResponse, error) {
 	}, nil
 }
 
-// ValidateToken validates a JWT token and returns the email claim
-func (s *Service) ValidateToken(tokenString string) (string, error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		return []byte(s.config.SecretKey), nil
+// IssueTokenPair generates both access and refresh tokens and records the refresh token in the store
+func (s *Service) IssueTokenPair(email string) (*TokenResponse, error) {
+	accessToken, err := s.GenerateAccessToken(email)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, jti, expiresAt, err := s.generateRefreshToken(email)
+	if err != nil {
+		return nil, err
+	}
+
+	record := &database.RefreshToken{
+		JTI:       jti,
+		UserEmail: email,
+		ExpiresAt: expiresAt,
+	}
+	if err := s.db.Create(record).Error; err != nil {
+		return nil, err
+	}
+
+	return &TokenResponse{
+		Token:        accessToken,
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+// IsRefreshTokenActive reports whether a refresh token is in the store and has not been used, revoked or expired
+func (s *Service) IsRefreshTokenActive(tokenString string) (bool, error) {
+	jti, err := s.refreshTokenID(tokenString)
+	if errors.Is(err, ErrRefreshTokenNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	var count int64
+	if err := s.db.Model(&database.RefreshToken{}).
+		Where("jti = ? AND used_at IS NULL AND revoked_at IS NULL AND expires_at > ?", jti, time.Now()).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// RevokeRefreshToken marks a refresh token as revoked
+func (s *Service) RevokeRefreshToken(tokenString string) error {
+	jti, err := s.refreshTokenID(tokenString)
+	if err != nil {
+		return err
+	}
+
+	result := s.db.Model(&database.RefreshToken{}).
+		Where("jti = ? AND revoked_at IS NULL", jti).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrRefreshTokenNotFound
+	}
+	return nil
+}
+
+// RotateRefreshToken exchanges a refresh token for a new token pair, marking it as used.
+// Presenting a used refresh token again means it has leaked, so all of the user's refresh tokens are revoked
+func (s *Service) RotateRefreshToken(tokenString string) (*TokenResponse, error) {
+	jti, err := s.refreshTokenID(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	var record database.RefreshToken
+	if err := s.db.Where("jti = ?", jti).First(&record).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRefreshTokenNotFound
+		}
+		return nil, err
+	}
+
+	if record.UsedAt != nil {
+		return nil, s.revokeReusedChain(record.UserEmail)
+	}
+	if record.RevokedAt != nil || !record.ExpiresAt.After(time.Now()) {
+		return nil, ErrRefreshTokenNotFound
+	}
+
+	// Marking the token used and issuing its successor happen together, so a failed
+	// issue leaves the old token usable
+	var tokens *TokenResponse
+	err = database.WithTx(s.db, func(tx *gorm.DB) error {
+		// Only one of several concurrent refreshes with the same token may win
+		result := tx.Model(&database.RefreshToken{}).
+			Where("id = ? AND used_at IS NULL", record.ID).
+			Update("used_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrRefreshTokenReused
+		}
+
+		issued, err := s.WithTx(tx).IssueTokenPair(record.UserEmail)
+		tokens = issued
+		return err
 	})
+	if errors.Is(err, ErrRefreshTokenReused) {
+		// Revoked outside the transaction so the revocation isn't rolled back
+		return nil, s.revokeReusedChain(record.UserEmail)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// revokeReusedChain revokes all of a user's refresh tokens after reuse was detected
+func (s *Service) revokeReusedChain(email string) error {
+	s.logger.Warn("refresh token reuse detected, revoking all refresh tokens", "email", email)
+	if err := s.RevokeAllRefreshTokens(email); err != nil {
+		return err
+	}
+	return ErrRefreshTokenReused
+}
+
+// RevokeAllRefreshTokens revokes every active refresh token of a user
+func (s *Service) RevokeAllRefreshTokens(email string) error {
+	return s.db.Model(&database.RefreshToken{}).
+		Where("user_email = ? AND revoked_at IS NULL", email).
+		Update("revoked_at", time.Now()).Error
+}
+
+// CountActiveSessions returns the number of unused, non-revoked, non-expired refresh
+// tokens for a user; rotated tokens are used, so each session counts once
+func (s *Service) CountActiveSessions(email string) (int64, error) {
+	var count int64
+	err := s.db.Model(&database.RefreshToken{}).
+		Where("user_email = ? AND used_at IS NULL AND revoked_at IS NULL AND expires_at > ?", email, time.Now()).
+		Count(&count).Error
+	return count, err
+}
+
+// refreshTokenID validates a refresh token and returns its jti claim
+func (s *Service) refreshTokenID(tokenString string) (string, error) {
+	if _, err := s.ValidateRefreshToken(tokenString); err != nil {
+		return "", err
+	}
 
-	if err != nil || !token.Valid {
+	claims := jwt.MapClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(tokenString, claims); err != nil {
 		return "", err
 	}
 
+	jti, ok := claims["jti"].(string)
+	if !ok || jti == "" {
+		return "", ErrRefreshTokenNotFound
+	}
+	return jti, nil
+}
+
+// generateTokenID generates a random token ID for the jti claim
+func generateTokenID() string {
+	return uuid.NewString()
+}
+
+// Revoke blacklists a token until it expires, so it no longer validates
+func (s *Service) Revoke(tokenString string) error {
+	token, err := s.parse(tokenString)
+	if err != nil {
+		return err
+	}
+
 	claims, ok := token.Claims.(jwt.MapClaims)
 	if !ok {
-		return "", jwt.ErrInvalidKey
+		return jwt.ErrInvalidKey
+	}
+	jti, ok := claims["jti"].(string)
+	if !ok || jti == "" {
+		return ErrTokenNotRevocable
+	}
+	exp, err := claims.GetExpirationTime()
+	if err != nil || exp == nil {
+		return jwt.ErrTokenInvalidClaims
 	}
 
-	// Check if this is an access token (for backward compatibility, also accept tokens without token_type)
-	tokenType, ok := claims["token_type"].(string)
-	if ok && tokenType != "access" {
-		return "", jwt.ErrInvalidKey
+	// Revoking an already revoked token is a no-op
+	return s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&database.RevokedToken{
+		JTI:       jti,
+		ExpiresAt: exp.Time,
+	}).Error
+}
+
+// isRevoked reports whether a token's jti is on the blacklist
+func (s *Service) isRevoked(claims jwt.MapClaims) (bool, error) {
+	jti, ok := claims["jti"].(string)
+	// Tokens without an id can't be revoked; services without a store have no blacklist
+	if !ok || jti == "" || s.db == nil {
+		return false, nil
 	}
 
-	email, ok := claims["email"].(string)
-	if !ok {
-		return "", jwt.ErrInvalidKey
+	var count int64
+	if err := s.db.Model(&database.RevokedToken{}).Where("jti = ?", jti).Count(&count).Error; err != nil {
+		return false, err
 	}
+	return count > 0, nil
+}
 
-	return email, nil
+// CleanupRevokedTokens removes blacklist entries for tokens that have expired anyway
+func (s *Service) CleanupRevokedTokens() error {
+	return s.db.Where("expires_at < ?", time.Now()).Delete(&database.RevokedToken{}).Error
 }
 
-// ValidateRefreshToken validates a refresh token specifically
-func (s *Service) ValidateRefreshToken(tokenString string) (string, error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		return []byte(s.config.SecretKey), nil
+// registerCleanup periodically purges expired blacklist entries while the app runs
+func registerCleanup(lc fx.Lifecycle, s *Service) {
+	done := make(chan struct{})
+
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go func() {
+				ticker := time.NewTicker(cleanupInterval)
+				defer ticker.Stop()
+				for {
+					select {
+					case <-ticker.C:
+						if err := s.CleanupRevokedTokens(); err != nil {
+							s.logger.Error("failed to clean up revoked tokens", "error", err)
+						}
+					case <-done:
+						return
+					}
+				}
+			}()
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			close(done)
+			return nil
+		},
 	})
+}
 
-	if err != nil || !token.Valid {
+// ValidateToken validates a JWT token and returns the email claim
+func (s *Service) ValidateToken(tokenString string) (string, error) {
+	info, err := s.Inspect(tokenString, TokenTypeAccess)
+	if err != nil {
+		return "", err
+	}
+	return info.Email, nil
+}
+
+// ValidateRefreshToken validates a refresh token specifically
+func (s *Service) ValidateRefreshToken(tokenString string) (string, error) {
+	info, err := s.Inspect(tokenString, TokenTypeRefresh)
+	if err != nil {
 		return "", err
 	}
+	return info.Email, nil
+}
+
+// Inspect validates a token and returns its claims. An empty tokenType accepts both access
+// and refresh tokens; blacklisted tokens are rejected either way.
+func (s *Service) Inspect(tokenString, tokenType string) (*TokenInfo, error) {
+	token, err := s.parse(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid {
+		return nil, jwt.ErrTokenUnverifiable
+	}
 
 	claims, ok := token.Claims.(jwt.MapClaims)
 	if !ok {
-		return "", jwt.ErrInvalidKey
+		return nil, jwt.ErrInvalidKey
 	}
 
-	// Check if this is a refresh token (for backward compatibility, also accept tokens without token_type)
-	tokenType, ok := claims["token_type"].(string)
-	if ok && tokenType != "refresh" {
-		return "", jwt.ErrInvalidKey
+	// For backward compatibility tokens without token_type are accepted as either type
+	// and reported as the requested one, or as access tokens
+	actualType, ok := claims["token_type"].(string)
+	if ok && tokenType != "" && actualType != tokenType {
+		return nil, jwt.ErrInvalidKey
+	}
+	if !ok {
+		actualType = tokenType
+		if actualType == "" {
+			actualType = TokenTypeAccess
+		}
+	}
+
+	revoked, err := s.isRevoked(claims)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, ErrTokenRevoked
 	}
 
 	email, ok := claims["email"].(string)
 	if !ok {
-		return "", jwt.ErrInvalidKey
+		return nil, jwt.ErrInvalidKey
+	}
+
+	exp, err := claims.GetExpirationTime()
+	if err != nil || exp == nil {
+		return nil, jwt.ErrTokenInvalidClaims
 	}
 
-	return email, nil
+	return &TokenInfo{
+		Email:     email,
+		TokenType: actualType,
+		ExpiresAt: exp.Time,
+		Roles:     rolesClaim(claims),
+	}, nil
+}
+
+// rolesClaim returns the roles claim; tokens issued before roles were added have none
+func rolesClaim(claims jwt.MapClaims) []string {
+	values, ok := claims["roles"].([]interface{})
+	if !ok {
+		return nil
+	}
+
+	roles := make([]string, 0, len(values))
+	for _, value := range values {
+		if role, ok := value.(string); ok {
+			roles = append(roles, role)
+		}
+	}
+	return roles
 }
 
 // GetAccessTokenDuration returns the access token duration
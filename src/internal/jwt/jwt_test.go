@@ -1,12 +1,23 @@
 package jwt
 
 import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"backend/internal/config"
+	"backend/internal/database"
+	"backend/internal/logger"
 
 	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
 )
 
 func createTestConfig() *config.Config {
@@ -19,9 +30,19 @@ func createTestConfig() *config.Config {
 	}
 }
 
+// newTestService creates a JWT service, failing the test if the configuration is invalid
+func newTestService(t *testing.T, cfg *config.Config, db *gorm.DB) *Service {
+	t.Helper()
+	service, err := NewJWTService(cfg, db, logger.Discard())
+	if err != nil {
+		t.Fatalf("Failed to create JWT service: %v", err)
+	}
+	return service
+}
+
 func TestNewJWTService(t *testing.T) {
 	cfg := createTestConfig()
-	service := NewJWTService(cfg)
+	service := newTestService(t, cfg, nil)
 
 	if service.config == nil {
 		t.Error("JWT service config should not be nil")
@@ -33,7 +54,7 @@ func TestNewJWTService(t *testing.T) {
 
 func TestGenerateToken(t *testing.T) {
 	cfg := createTestConfig()
-	service := NewJWTService(cfg)
+	service := newTestService(t, cfg, nil)
 
 	email := "test@example.com"
 	expiry := time.Minute * 10
@@ -83,7 +104,7 @@ func TestGenerateToken(t *testing.T) {
 
 func TestGenerateTokenPair(t *testing.T) {
 	cfg := createTestConfig()
-	service := NewJWTService(cfg)
+	service := newTestService(t, cfg, nil)
 
 	email := "test@example.com"
 
@@ -122,7 +143,7 @@ func TestGenerateTokenPair(t *testing.T) {
 
 func TestValidateToken(t *testing.T) {
 	cfg := createTestConfig()
-	service := NewJWTService(cfg)
+	service := newTestService(t, cfg, nil)
 
 	email := "test@example.com"
 	token, err := service.GenerateToken(email, time.Minute*10)
@@ -142,7 +163,7 @@ func TestValidateToken(t *testing.T) {
 
 func TestValidateToken_InvalidToken(t *testing.T) {
 	cfg := createTestConfig()
-	service := NewJWTService(cfg)
+	service := newTestService(t, cfg, nil)
 
 	// Test invalid token
 	_, err := service.ValidateToken("invalid-token")
@@ -153,7 +174,7 @@ func TestValidateToken_InvalidToken(t *testing.T) {
 
 func TestValidateToken_ExpiredToken(t *testing.T) {
 	cfg := createTestConfig()
-	service := NewJWTService(cfg)
+	service := newTestService(t, cfg, nil)
 
 	email := "test@example.com"
 	// Generate token with very short expiration
@@ -171,9 +192,145 @@ func TestValidateToken_ExpiredToken(t *testing.T) {
 	}
 }
 
+func TestInspect(t *testing.T) {
+	cfg := createTestConfig()
+	service := newTestService(t, cfg, nil)
+
+	tokens, err := service.GenerateTokenPair("test@example.com")
+	if err != nil {
+		t.Fatalf("Failed to generate token pair: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		token     string
+		tokenType string
+		wantType  string
+		wantErr   bool
+	}{
+		{"access as access", tokens.Token, TokenTypeAccess, TokenTypeAccess, false},
+		{"access as any", tokens.Token, "", TokenTypeAccess, false},
+		{"refresh as any", tokens.RefreshToken, "", TokenTypeRefresh, false},
+		{"refresh as access", tokens.RefreshToken, TokenTypeAccess, "", true},
+		{"access as refresh", tokens.Token, TokenTypeRefresh, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := service.Inspect(tt.token, tt.tokenType)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("Expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to inspect token: %v", err)
+			}
+			if info.TokenType != tt.wantType {
+				t.Errorf("Expected token type %q, got %q", tt.wantType, info.TokenType)
+			}
+			if info.Email != "test@example.com" {
+				t.Errorf("Expected email test@example.com, got %q", info.Email)
+			}
+			if info.ExpiresAt.Before(time.Now()) {
+				t.Errorf("Expected expiry in the future, got %v", info.ExpiresAt)
+			}
+		})
+	}
+}
+
+func TestInspect_ExpiredToken(t *testing.T) {
+	cfg := createTestConfig()
+	service := newTestService(t, cfg, nil)
+
+	token, err := service.GenerateToken("test@example.com", -time.Minute)
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+
+	if _, err := service.Inspect(token, ""); !errors.Is(err, jwt.ErrTokenExpired) {
+		t.Errorf("Expected ErrTokenExpired, got %v", err)
+	}
+}
+
+// issuerTestConfig returns a test configuration with the given iss and aud claims
+func issuerTestConfig(issuer, audience string) *config.Config {
+	cfg := createTestConfig()
+	cfg.JWT.Issuer = issuer
+	cfg.JWT.Audience = audience
+	return cfg
+}
+
+func TestValidateToken_IssuerAndAudience(t *testing.T) {
+	service := newTestService(t, issuerTestConfig("schwiftybox", "schwiftybox-api"), nil)
+
+	tests := []struct {
+		name     string
+		issuer   string
+		audience string
+		wantErr  error
+	}{
+		{"matching", "schwiftybox", "schwiftybox-api", nil},
+		{"wrong issuer", "other-service", "schwiftybox-api", jwt.ErrTokenInvalidIssuer},
+		{"wrong audience", "schwiftybox", "other-api", jwt.ErrTokenInvalidAudience},
+		{"missing claims", "", "", jwt.ErrTokenRequiredClaimMissing},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issuer := newTestService(t, issuerTestConfig(tt.issuer, tt.audience), nil)
+			token, err := issuer.GenerateAccessToken("test@example.com")
+			if err != nil {
+				t.Fatalf("Failed to generate token: %v", err)
+			}
+
+			_, err = service.ValidateToken(token)
+			if tt.wantErr == nil && err != nil {
+				t.Errorf("Expected token to validate, got %v", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestValidateToken_AcceptLegacyTokens(t *testing.T) {
+	cfg := issuerTestConfig("schwiftybox", "schwiftybox-api")
+	cfg.JWT.AcceptLegacyTokens = true
+	service := newTestService(t, cfg, nil)
+
+	// Tokens issued before iss and aud were added are accepted
+	legacy, err := newTestService(t, issuerTestConfig("", ""), nil).GenerateAccessToken("test@example.com")
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+	if _, err := service.ValidateToken(legacy); err != nil {
+		t.Errorf("Expected legacy token to validate, got %v", err)
+	}
+
+	// Present claims must still match
+	foreign, err := newTestService(t, issuerTestConfig("other-service", "other-api"), nil).GenerateAccessToken("test@example.com")
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+	if _, err := service.ValidateToken(foreign); !errors.Is(err, jwt.ErrTokenInvalidIssuer) {
+		t.Errorf("Expected ErrTokenInvalidIssuer, got %v", err)
+	}
+
+	current, err := service.GenerateAccessToken("test@example.com")
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+	if _, err := service.ValidateToken(current); err != nil {
+		t.Errorf("Expected token to validate, got %v", err)
+	}
+}
+
 func TestValidateToken_WrongSecret(t *testing.T) {
 	cfg := createTestConfig()
-	service := NewJWTService(cfg)
+	service := newTestService(t, cfg, nil)
 
 	email := "test@example.com"
 	token, err := service.GenerateToken(email, time.Minute*10)
@@ -187,7 +344,7 @@ func TestValidateToken_WrongSecret(t *testing.T) {
 			SecretKey: "different-secret-key",
 		},
 	}
-	wrongService := NewJWTService(wrongCfg)
+	wrongService := newTestService(t, wrongCfg, nil)
 
 	_, err = wrongService.ValidateToken(token)
 	if err == nil {
@@ -197,7 +354,7 @@ func TestValidateToken_WrongSecret(t *testing.T) {
 
 func TestGetAccessTokenDuration(t *testing.T) {
 	cfg := createTestConfig()
-	service := NewJWTService(cfg)
+	service := newTestService(t, cfg, nil)
 
 	expectedDuration := time.Minute * 15
 	actualDuration := service.GetAccessTokenDuration()
@@ -209,7 +366,7 @@ func TestGetAccessTokenDuration(t *testing.T) {
 
 func TestGenerateToken_EmptyEmail(t *testing.T) {
 	cfg := createTestConfig()
-	service := NewJWTService(cfg)
+	service := newTestService(t, cfg, nil)
 
 	token, err := service.GenerateToken("", time.Minute*10)
 	if err != nil {
@@ -232,7 +389,7 @@ func TestGenerateToken_EmptyEmail(t *testing.T) {
 
 func TestGenerateTokenPair_EmptyEmail(t *testing.T) {
 	cfg := createTestConfig()
-	service := NewJWTService(cfg)
+	service := newTestService(t, cfg, nil)
 
 	tokenPair, err := service.GenerateTokenPair("")
 	if err != nil {
@@ -243,3 +400,442 @@ func TestGenerateTokenPair_EmptyEmail(t *testing.T) {
 		t.Error("Should generate both tokens even with empty email")
 	}
 }
+
+func setupTestStore(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+	if err := db.AutoMigrate(&database.RefreshToken{}, &database.RevokedToken{}, &database.User{}, &database.Organization{}, &database.OrganizationUser{}); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+	return db
+}
+
+func TestIssueTokenPair_RecordsRefreshToken(t *testing.T) {
+	service := newTestService(t, createTestConfig(), setupTestStore(t))
+
+	tokenPair, err := service.IssueTokenPair("test@example.com")
+	if err != nil {
+		t.Fatalf("Failed to issue token pair: %v", err)
+	}
+
+	active, err := service.IsRefreshTokenActive(tokenPair.RefreshToken)
+	if err != nil {
+		t.Fatalf("Failed to check refresh token: %v", err)
+	}
+	if !active {
+		t.Error("Issued refresh token should be active")
+	}
+
+	count, err := service.CountActiveSessions("test@example.com")
+	if err != nil {
+		t.Fatalf("Failed to count sessions: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("Expected 1 active session, got %d", count)
+	}
+}
+
+func TestRevokeRefreshToken(t *testing.T) {
+	service := newTestService(t, createTestConfig(), setupTestStore(t))
+
+	tokenPair, err := service.IssueTokenPair("test@example.com")
+	if err != nil {
+		t.Fatalf("Failed to issue token pair: %v", err)
+	}
+
+	if err := service.RevokeRefreshToken(tokenPair.RefreshToken); err != nil {
+		t.Fatalf("Failed to revoke refresh token: %v", err)
+	}
+
+	active, err := service.IsRefreshTokenActive(tokenPair.RefreshToken)
+	if err != nil {
+		t.Fatalf("Failed to check refresh token: %v", err)
+	}
+	if active {
+		t.Error("Revoked refresh token should not be active")
+	}
+
+	if err := service.RevokeRefreshToken(tokenPair.RefreshToken); !errors.Is(err, ErrRefreshTokenNotFound) {
+		t.Errorf("Expected ErrRefreshTokenNotFound, got %v", err)
+	}
+}
+
+func TestCountActiveSessions_IgnoresExpired(t *testing.T) {
+	db := setupTestStore(t)
+	service := newTestService(t, createTestConfig(), db)
+
+	if _, err := service.IssueTokenPair("test@example.com"); err != nil {
+		t.Fatalf("Failed to issue token pair: %v", err)
+	}
+	db.Create(&database.RefreshToken{JTI: "expired", UserEmail: "test@example.com", ExpiresAt: time.Now().Add(-time.Hour)})
+
+	count, err := service.CountActiveSessions("test@example.com")
+	if err != nil {
+		t.Fatalf("Failed to count sessions: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("Expected 1 active session, got %d", count)
+	}
+}
+
+// writeTestRSAKeys generates an RSA keypair and writes it as PEM files, returning their paths
+func writeTestRSAKeys(t *testing.T) (string, string) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatalf("Failed to marshal public key: %v", err)
+	}
+
+	dir := t.TempDir()
+	privatePath := filepath.Join(dir, "jwt.key")
+	publicPath := filepath.Join(dir, "jwt.pub")
+	privatePEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})
+	if err := os.WriteFile(privatePath, privatePEM, 0600); err != nil {
+		t.Fatalf("Failed to write private key: %v", err)
+	}
+	if err := os.WriteFile(publicPath, publicPEM, 0644); err != nil {
+		t.Fatalf("Failed to write public key: %v", err)
+	}
+	return privatePath, publicPath
+}
+
+func TestRS256_SignAndValidate(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.JWT.PrivateKeyPath, cfg.JWT.PublicKeyPath = writeTestRSAKeys(t)
+	service := newTestService(t, cfg, nil)
+
+	tokenPair, err := service.GenerateTokenPair("test@example.com")
+	if err != nil {
+		t.Fatalf("Failed to generate token pair: %v", err)
+	}
+
+	token, _, err := jwt.NewParser().ParseUnverified(tokenPair.Token, jwt.MapClaims{})
+	if err != nil {
+		t.Fatalf("Failed to parse token: %v", err)
+	}
+	if token.Method.Alg() != "RS256" {
+		t.Errorf("Expected RS256 signing method, got %s", token.Method.Alg())
+	}
+
+	email, err := service.ValidateToken(tokenPair.Token)
+	if err != nil {
+		t.Fatalf("Failed to validate access token: %v", err)
+	}
+	if email != "test@example.com" {
+		t.Errorf("Expected email 'test@example.com', got '%s'", email)
+	}
+
+	if _, err := service.ValidateRefreshToken(tokenPair.RefreshToken); err != nil {
+		t.Errorf("Failed to validate refresh token: %v", err)
+	}
+}
+
+func TestRS256_RejectsHS256Token(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.JWT.PrivateKeyPath, cfg.JWT.PublicKeyPath = writeTestRSAKeys(t)
+	service := newTestService(t, cfg, nil)
+
+	// Same secret, but signed with HS256
+	hsToken, err := newTestService(t, createTestConfig(), nil).GenerateAccessToken("test@example.com")
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+
+	if _, err := service.ValidateToken(hsToken); !errors.Is(err, ErrUnexpectedSigningMethod) {
+		t.Errorf("Expected ErrUnexpectedSigningMethod, got %v", err)
+	}
+}
+
+func TestNewJWTService_InvalidPrivateKey(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.JWT.PrivateKeyPath = filepath.Join(t.TempDir(), "missing.key")
+
+	if _, err := NewJWTService(cfg, nil, logger.Discard()); err == nil {
+		t.Error("Should return error for missing private key")
+	}
+}
+
+func TestGenerateAccessToken_SetsNotBefore(t *testing.T) {
+	service := newTestService(t, createTestConfig(), nil)
+
+	token, err := service.GenerateAccessToken("test@example.com")
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+
+	claims := jwt.MapClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(token, claims); err != nil {
+		t.Fatalf("Failed to parse token: %v", err)
+	}
+	nbf, err := claims.GetNotBefore()
+	if err != nil || nbf == nil {
+		t.Fatalf("Expected nbf claim, got %v (%v)", nbf, err)
+	}
+	if nbf.After(time.Now()) {
+		t.Errorf("Expected nbf to default to now, got %v", nbf)
+	}
+}
+
+func TestValidateToken_FutureNotBefore(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.JWT.NotBeforeOffset = time.Hour
+	service := newTestService(t, cfg, nil)
+
+	token, err := service.GenerateAccessToken("test@example.com")
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+
+	if _, err := service.ValidateToken(token); !errors.Is(err, jwt.ErrTokenNotValidYet) {
+		t.Errorf("Expected ErrTokenNotValidYet, got %v", err)
+	}
+
+	// Once the leeway covers the offset, the token is accepted
+	lenientCfg := createTestConfig()
+	lenientCfg.JWT.Leeway = time.Hour + time.Minute
+	lenientService := newTestService(t, lenientCfg, nil)
+
+	email, err := lenientService.ValidateToken(token)
+	if err != nil {
+		t.Fatalf("Expected token to be valid within leeway: %v", err)
+	}
+	if email != "test@example.com" {
+		t.Errorf("Expected email 'test@example.com', got '%s'", email)
+	}
+}
+
+func TestRevoke_AccessToken(t *testing.T) {
+	service := newTestService(t, createTestConfig(), setupTestStore(t))
+
+	token, err := service.GenerateAccessToken("test@example.com")
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+	if _, err := service.ValidateToken(token); err != nil {
+		t.Fatalf("Token should be valid before revocation: %v", err)
+	}
+
+	if err := service.Revoke(token); err != nil {
+		t.Fatalf("Failed to revoke token: %v", err)
+	}
+	if _, err := service.ValidateToken(token); !errors.Is(err, ErrTokenRevoked) {
+		t.Errorf("Expected ErrTokenRevoked, got %v", err)
+	}
+
+	// Revoking twice is a no-op
+	if err := service.Revoke(token); err != nil {
+		t.Errorf("Revoking an already revoked token should succeed: %v", err)
+	}
+}
+
+func TestRevoke_RefreshToken(t *testing.T) {
+	service := newTestService(t, createTestConfig(), setupTestStore(t))
+
+	token, err := service.GenerateRefreshToken("test@example.com")
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+
+	if err := service.Revoke(token); err != nil {
+		t.Fatalf("Failed to revoke token: %v", err)
+	}
+	if _, err := service.ValidateRefreshToken(token); !errors.Is(err, ErrTokenRevoked) {
+		t.Errorf("Expected ErrTokenRevoked, got %v", err)
+	}
+}
+
+func TestRevoke_OtherTokensStayValid(t *testing.T) {
+	service := newTestService(t, createTestConfig(), setupTestStore(t))
+
+	revoked, _ := service.GenerateAccessToken("test@example.com")
+	other, _ := service.GenerateAccessToken("test@example.com")
+
+	if err := service.Revoke(revoked); err != nil {
+		t.Fatalf("Failed to revoke token: %v", err)
+	}
+	if _, err := service.ValidateToken(other); err != nil {
+		t.Errorf("Unrevoked token should stay valid: %v", err)
+	}
+}
+
+func TestCleanupRevokedTokens(t *testing.T) {
+	db := setupTestStore(t)
+	service := newTestService(t, createTestConfig(), db)
+
+	db.Create(&database.RevokedToken{JTI: "expired", ExpiresAt: time.Now().Add(-time.Hour)})
+	db.Create(&database.RevokedToken{JTI: "active", ExpiresAt: time.Now().Add(time.Hour)})
+
+	if err := service.CleanupRevokedTokens(); err != nil {
+		t.Fatalf("Failed to clean up revoked tokens: %v", err)
+	}
+
+	var jtis []string
+	db.Model(&database.RevokedToken{}).Pluck("jti", &jtis)
+	if len(jtis) != 1 || jtis[0] != "active" {
+		t.Errorf("Expected only the unexpired entry to remain, got %v", jtis)
+	}
+}
+
+func TestRotateRefreshToken_Success(t *testing.T) {
+	service := newTestService(t, createTestConfig(), setupTestStore(t))
+
+	tokenPair, err := service.IssueTokenPair("test@example.com")
+	if err != nil {
+		t.Fatalf("Failed to issue token pair: %v", err)
+	}
+
+	rotated, err := service.RotateRefreshToken(tokenPair.RefreshToken)
+	if err != nil {
+		t.Fatalf("Failed to rotate refresh token: %v", err)
+	}
+	if rotated.RefreshToken == tokenPair.RefreshToken {
+		t.Error("Rotation should issue a new refresh token")
+	}
+
+	active, err := service.IsRefreshTokenActive(rotated.RefreshToken)
+	if err != nil {
+		t.Fatalf("Failed to check refresh token: %v", err)
+	}
+	if !active {
+		t.Error("Rotated refresh token should be active")
+	}
+
+	active, err = service.IsRefreshTokenActive(tokenPair.RefreshToken)
+	if err != nil {
+		t.Fatalf("Failed to check refresh token: %v", err)
+	}
+	if active {
+		t.Error("Used refresh token should not be active")
+	}
+}
+
+func TestRotateRefreshToken_CountsOneSession(t *testing.T) {
+	service := newTestService(t, createTestConfig(), setupTestStore(t))
+
+	tokenPair, err := service.IssueTokenPair("test@example.com")
+	if err != nil {
+		t.Fatalf("Failed to issue token pair: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		tokenPair, err = service.RotateRefreshToken(tokenPair.RefreshToken)
+		if err != nil {
+			t.Fatalf("Failed to rotate refresh token: %v", err)
+		}
+	}
+
+	count, err := service.CountActiveSessions("test@example.com")
+	if err != nil {
+		t.Fatalf("Failed to count sessions: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("Expected rotated tokens to count as 1 session, got %d", count)
+	}
+}
+
+func TestRotateRefreshToken_RollsBackOnFailure(t *testing.T) {
+	db := setupTestStore(t)
+	service := newTestService(t, createTestConfig(), db)
+
+	tokenPair, err := service.IssueTokenPair("test@example.com")
+	if err != nil {
+		t.Fatalf("Failed to issue token pair: %v", err)
+	}
+
+	// Fail recording the new refresh token, which runs after the old one was marked used
+	injected := errors.New("injected failure")
+	err = db.Callback().Create().Before("gorm:create").Register("test:fail_refresh_token", func(tx *gorm.DB) {
+		if tx.Statement.Table == "refresh_tokens" {
+			tx.AddError(injected)
+		}
+	})
+	if err != nil {
+		t.Fatalf("Failed to register callback: %v", err)
+	}
+
+	if _, err := service.RotateRefreshToken(tokenPair.RefreshToken); !errors.Is(err, injected) {
+		t.Fatalf("Expected the injected error, got %v", err)
+	}
+
+	active, err := service.IsRefreshTokenActive(tokenPair.RefreshToken)
+	if err != nil {
+		t.Fatalf("Failed to check refresh token: %v", err)
+	}
+	if !active {
+		t.Error("Expected the old refresh token to stay usable after a failed rotation")
+	}
+}
+
+func TestRotateRefreshToken_ReuseRevokesChain(t *testing.T) {
+	service := newTestService(t, createTestConfig(), setupTestStore(t))
+
+	tokenPair, err := service.IssueTokenPair("test@example.com")
+	if err != nil {
+		t.Fatalf("Failed to issue token pair: %v", err)
+	}
+	rotated, err := service.RotateRefreshToken(tokenPair.RefreshToken)
+	if err != nil {
+		t.Fatalf("Failed to rotate refresh token: %v", err)
+	}
+
+	// Replaying the old token is detected as reuse
+	if _, err := service.RotateRefreshToken(tokenPair.RefreshToken); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("Expected ErrRefreshTokenReused, got %v", err)
+	}
+
+	// and revokes the token issued by the legitimate rotation
+	if _, err := service.RotateRefreshToken(rotated.RefreshToken); !errors.Is(err, ErrRefreshTokenNotFound) {
+		t.Errorf("Expected ErrRefreshTokenNotFound after reuse, got %v", err)
+	}
+	count, err := service.CountActiveSessions("test@example.com")
+	if err != nil {
+		t.Fatalf("Failed to count sessions: %v", err)
+	}
+	if count != 0 {
+		t.Errorf("Expected no active sessions after reuse, got %d", count)
+	}
+}
+
+func TestGenerateAccessToken_Roles(t *testing.T) {
+	db := setupTestStore(t)
+	service := newTestService(t, createTestConfig(), db)
+
+	db.Create(&database.Organization{ID: 1, Name: "Camping Club"})
+	db.Create(&database.Organization{ID: 2, Name: "Hiking Club"})
+	db.Create(&database.User{Email: "test@example.com", ActiveOrganizationID: 1})
+	db.Create(&database.OrganizationUser{OrganizationID: 1, UserEmail: "test@example.com", Role: "admin"})
+	db.Create(&database.OrganizationUser{OrganizationID: 2, UserEmail: "test@example.com", Role: "owner"})
+
+	token, err := service.GenerateAccessToken("test@example.com")
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+
+	// Only the role in the active organization is carried
+	info, err := service.Inspect(token, TokenTypeAccess)
+	if err != nil {
+		t.Fatalf("Failed to inspect token: %v", err)
+	}
+	if len(info.Roles) != 1 || info.Roles[0] != "admin" {
+		t.Errorf("Expected roles [admin], got %v", info.Roles)
+	}
+
+	// Users without a membership get no roles claim
+	token, err = service.GenerateAccessToken("nobody@example.com")
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+	info, err = service.Inspect(token, TokenTypeAccess)
+	if err != nil {
+		t.Fatalf("Failed to inspect token: %v", err)
+	}
+	if len(info.Roles) != 0 {
+		t.Errorf("Expected no roles, got %v", info.Roles)
+	}
+}
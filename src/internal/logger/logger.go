@@ -0,0 +1,106 @@
+package logger
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"backend/internal/config"
+
+	"go.uber.org/fx"
+)
+
+// Module provides logger dependency injection
+var Module = fx.Module("logger",
+	fx.Provide(NewLogger),
+)
+
+// redactedValue replaces sensitive attribute values
+const redactedValue = "***"
+
+// sensitiveKeys are attribute keys whose values are never written to the log
+var sensitiveKeys = map[string]bool{
+	"password":      true,
+	"token":         true,
+	"access_token":  true,
+	"refresh_token": true,
+	"secret":        true,
+}
+
+// NewLogger creates a structured logger writing to stdout, as JSON in production and text otherwise
+func NewLogger(cfg *config.Config) *slog.Logger {
+	return New(os.Stdout, cfg.Log.Level, cfg.Env == config.EnvProduction)
+}
+
+// New creates a structured logger writing to w at the given level ("debug", "info",
+// "warn" or "error"; anything else means info), redacting sensitive attributes
+func New(w io.Writer, level string, json bool) *slog.Logger {
+	options := &slog.HandlerOptions{
+		Level:       parseLevel(level),
+		ReplaceAttr: redact,
+	}
+	var handler slog.Handler = slog.NewTextHandler(w, options)
+	if json {
+		handler = slog.NewJSONHandler(w, options)
+	}
+	return slog.New(&contextHandler{handler})
+}
+
+// Discard returns a logger that drops everything, for tests
+func Discard() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+// parseLevel converts a level name to a slog level, defaulting to info
+func parseLevel(level string) slog.Level {
+	var parsed slog.Level
+	if err := parsed.UnmarshalText([]byte(strings.TrimSpace(level))); err != nil {
+		return slog.LevelInfo
+	}
+	return parsed
+}
+
+// redact hides the values of sensitive attributes
+func redact(groups []string, attr slog.Attr) slog.Attr {
+	if sensitiveKeys[strings.ToLower(attr.Key)] {
+		return slog.String(attr.Key, redactedValue)
+	}
+	return attr
+}
+
+// requestIDKey is the context key holding the request ID
+type requestIDKey struct{}
+
+// WithRequestID returns a context carrying the request ID, which is added to
+// every entry logged with that context
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the request ID carried by the context, if any
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// contextHandler adds request-scoped attributes from the context to each record
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h *contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		record.AddAttrs(slog.String("request_id", id))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{h.Handler.WithGroup(name)}
+}
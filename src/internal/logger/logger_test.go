@@ -0,0 +1,83 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestNew_JSON(t *testing.T) {
+	var buf bytes.Buffer
+	New(&buf, "info", true).Info("user logged in", "email", "test@example.com")
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("Expected a JSON log line, got %q: %v", buf.String(), err)
+	}
+	if entry["msg"] != "user logged in" || entry["email"] != "test@example.com" {
+		t.Errorf("Expected message and email fields, got %v", entry)
+	}
+}
+
+func TestNew_Level(t *testing.T) {
+	tests := []struct {
+		level     string
+		wantDebug bool
+		wantInfo  bool
+	}{
+		{"debug", true, true},
+		{"info", false, true},
+		{"WARN", false, false},
+		{"", false, true},
+		{"verbose", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.level, func(t *testing.T) {
+			logger := New(&bytes.Buffer{}, tt.level, false)
+			if enabled := logger.Enabled(context.Background(), slog.LevelDebug); enabled != tt.wantDebug {
+				t.Errorf("Expected debug enabled %v, got %v", tt.wantDebug, enabled)
+			}
+			if enabled := logger.Enabled(context.Background(), slog.LevelInfo); enabled != tt.wantInfo {
+				t.Errorf("Expected info enabled %v, got %v", tt.wantInfo, enabled)
+			}
+		})
+	}
+}
+
+func TestNew_RedactsSensitiveAttributes(t *testing.T) {
+	var buf bytes.Buffer
+	New(&buf, "info", false).Info("login", "email", "test@example.com", "password", "hunter22", "refresh_token", "eyJhbGciOi")
+
+	output := buf.String()
+	for _, secret := range []string{"hunter22", "eyJhbGciOi"} {
+		if strings.Contains(output, secret) {
+			t.Errorf("Expected %q to be redacted, got: %s", secret, output)
+		}
+	}
+	if !strings.Contains(output, "email=test@example.com") {
+		t.Errorf("Expected non-sensitive fields to be kept, got: %s", output)
+	}
+}
+
+func TestNew_AddsRequestIDFromContext(t *testing.T) {
+	var buf bytes.Buffer
+	log := New(&buf, "info", true)
+
+	log.InfoContext(WithRequestID(context.Background(), "req-123"), "with id")
+	log.Info("without id")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 log lines, got %d", len(lines))
+	}
+	if !strings.Contains(lines[0], `"request_id":"req-123"`) {
+		t.Errorf("Expected the request ID in the first entry, got: %s", lines[0])
+	}
+	if strings.Contains(lines[1], "request_id") {
+		t.Errorf("Expected no request ID without one in the context, got: %s", lines[1])
+	}
+}
@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"backend/internal/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupAccessLogEngine(logs *bytes.Buffer) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(RequestID())
+	engine.Use(AccessLog(logger.New(logs, "info", true), []string{"/health"}))
+	engine.GET("/health", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	})
+	engine.GET("/items/:item_id", func(c *gin.Context) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+	})
+	return engine
+}
+
+func TestAccessLog_LogsRequestFields(t *testing.T) {
+	var logs bytes.Buffer
+	engine := setupAccessLogEngine(&logs)
+
+	req := httptest.NewRequest("GET", "/items/42?token=secret-value", nil)
+	req.Header.Set(RequestIDHeader, "req-42")
+	req.RemoteAddr = "203.0.113.7:51234"
+	engine.ServeHTTP(httptest.NewRecorder(), req)
+
+	var entry map[string]interface{}
+	if assert.NoError(t, json.Unmarshal(logs.Bytes(), &entry), logs.String()) {
+		assert.Equal(t, "request", entry["msg"])
+		assert.Equal(t, "GET", entry["method"])
+		assert.Equal(t, "/items/42", entry["path"])
+		assert.Equal(t, float64(http.StatusNotFound), entry["status"])
+		assert.Equal(t, "203.0.113.7", entry["client_ip"])
+		assert.Equal(t, "req-42", entry["request_id"])
+		assert.Contains(t, entry, "latency")
+	}
+	assert.NotContains(t, logs.String(), "secret-value")
+}
+
+func TestAccessLog_SkipsPaths(t *testing.T) {
+	var logs bytes.Buffer
+	engine := setupAccessLogEngine(&logs)
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest("GET", "/health", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, logs.String())
+}
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"net/http"
+
+	"backend/internal/handlers"
+	"backend/internal/user"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireAdmin only allows authenticated users with the admin role through
+func RequireAdmin(userService *user.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userEmail, exists := c.Get("user_email")
+		if !exists {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, handlers.APIError{Code: handlers.CodeUnauthenticated, Message: "User not authenticated"})
+			return
+		}
+
+		u, err := userService.GetUser(userEmail.(string))
+		if err != nil || u.Role != user.RoleAdmin {
+			c.AbortWithStatusJSON(http.StatusForbidden, handlers.APIError{Code: handlers.CodeForbidden, Message: "Admin access required"})
+			return
+		}
+
+		c.Next()
+	}
+}
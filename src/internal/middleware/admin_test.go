@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"backend/internal/config"
+	"backend/internal/database"
+	"backend/internal/handlers"
+	"backend/internal/user"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupAdminTest(t *testing.T, email string) (*gin.Engine, *gorm.DB) {
+	gin.SetMode(gin.TestMode)
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+	if err := db.AutoMigrate(&database.Organization{}, &database.User{}, &database.OrganizationUser{}); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+
+	userService := user.NewUserService(db, &config.Config{Security: config.SecurityConfig{BcryptCost: bcrypt.MinCost}})
+	if err := userService.CreateUser(email, "password123"); err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+
+	engine := gin.New()
+	engine.Use(func(c *gin.Context) {
+		c.Set("user_email", email)
+		c.Next()
+	})
+	engine.Use(RequireAdmin(userService))
+	engine.GET("/admin", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "success"})
+	})
+
+	return engine, db
+}
+
+func TestRequireAdmin_Admin(t *testing.T) {
+	engine, db := setupAdminTest(t, "admin@example.com")
+	db.Model(&database.User{}).Where("email = ?", "admin@example.com").Update("role", user.RoleAdmin)
+
+	req, _ := http.NewRequest("GET", "/admin", nil)
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestRequireAdmin_RegularUser(t *testing.T) {
+	engine, _ := setupAdminTest(t, "user@example.com")
+
+	req, _ := http.NewRequest("GET", "/admin", nil)
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assertAPIError(t, w, handlers.CodeForbidden)
+}
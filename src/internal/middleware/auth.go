@@ -4,41 +4,47 @@ import (
 	"net/http"
 	"strings"
 
+	"backend/internal/handlers"
 	"backend/internal/jwt"
 
 	"github.com/gin-gonic/gin"
 )
 
-// AuthMiddleware provides JWT authentication middleware
-func AuthMiddleware(jwtService *jwt.Service) gin.HandlerFunc {
+// AuthMiddleware provides JWT authentication middleware. The token is read from the
+// Authorization header or, when the header is absent and cookieName is set, from that cookie.
+func AuthMiddleware(jwtService *jwt.Service, cookieName string) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		var token string
+
 		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
-			c.Abort()
-			return
+		if authHeader != "" {
+			// Check if header starts with "Bearer "
+			if !strings.HasPrefix(authHeader, "Bearer ") {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, handlers.APIError{Code: handlers.CodeUnauthenticated, Message: "Invalid authorization header format"})
+				return
+			}
+
+			// Extract token
+			token = strings.TrimPrefix(authHeader, "Bearer ")
+		} else if cookieName != "" {
+			token, _ = c.Cookie(cookieName)
 		}
 
-		// Check if header starts with "Bearer "
-		if !strings.HasPrefix(authHeader, "Bearer ") {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authorization header format"})
-			c.Abort()
+		if authHeader == "" && token == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, handlers.APIError{Code: handlers.CodeUnauthenticated, Message: "Authorization header required"})
 			return
 		}
 
-		// Extract token
-		token := strings.TrimPrefix(authHeader, "Bearer ")
-
 		// Validate token
-		email, err := jwtService.ValidateToken(token)
+		info, err := jwtService.Inspect(token, jwt.TokenTypeAccess)
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
-			c.Abort()
+			c.AbortWithStatusJSON(http.StatusUnauthorized, handlers.APIError{Code: handlers.CodeInvalidToken, Message: "Invalid token"})
 			return
 		}
 
-		// Set user email in context
-		c.Set("user_email", email)
+		// Set user email and roles in context
+		c.Set("user_email", info.Email)
+		c.Set("user_roles", info.Roles)
 		c.Next()
 	}
 }
@@ -7,7 +7,9 @@ import (
 	"time"
 
 	"backend/internal/config"
+	"backend/internal/handlers"
 	"backend/internal/jwt"
+	"backend/internal/logger"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -25,7 +27,10 @@ func setupAuthTest(t *testing.T) (*gin.Engine, *jwt.Service) {
 		},
 	}
 
-	jwtService := jwt.NewJWTService(cfg)
+	jwtService, err := jwt.NewJWTService(cfg, nil, logger.Discard())
+	if err != nil {
+		t.Fatalf("Failed to create JWT service: %v", err)
+	}
 	return engine, jwtService
 }
 
@@ -33,7 +38,7 @@ func TestAuthMiddleware_ValidToken(t *testing.T) {
 	engine, jwtService := setupAuthTest(t)
 
 	// Add middleware to engine
-	engine.Use(AuthMiddleware(jwtService))
+	engine.Use(AuthMiddleware(jwtService, ""))
 
 	// Add test endpoint
 	engine.GET("/test", func(c *gin.Context) {
@@ -69,7 +74,7 @@ func TestAuthMiddleware_MissingHeader(t *testing.T) {
 	engine, jwtService := setupAuthTest(t)
 
 	// Add middleware to engine
-	engine.Use(AuthMiddleware(jwtService))
+	engine.Use(AuthMiddleware(jwtService, ""))
 
 	// Add test endpoint
 	engine.GET("/test", func(c *gin.Context) {
@@ -83,13 +88,14 @@ func TestAuthMiddleware_MissingHeader(t *testing.T) {
 	engine.ServeHTTP(w, req)
 
 	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assertAPIError(t, w, handlers.CodeUnauthenticated)
 }
 
 func TestAuthMiddleware_InvalidHeaderFormat(t *testing.T) {
 	engine, jwtService := setupAuthTest(t)
 
 	// Add middleware to engine
-	engine.Use(AuthMiddleware(jwtService))
+	engine.Use(AuthMiddleware(jwtService, ""))
 
 	// Add test endpoint
 	engine.GET("/test", func(c *gin.Context) {
@@ -104,13 +110,14 @@ func TestAuthMiddleware_InvalidHeaderFormat(t *testing.T) {
 	engine.ServeHTTP(w, req)
 
 	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assertAPIError(t, w, handlers.CodeUnauthenticated)
 }
 
 func TestAuthMiddleware_InvalidToken(t *testing.T) {
 	engine, jwtService := setupAuthTest(t)
 
 	// Add middleware to engine
-	engine.Use(AuthMiddleware(jwtService))
+	engine.Use(AuthMiddleware(jwtService, ""))
 
 	// Add test endpoint
 	engine.GET("/test", func(c *gin.Context) {
@@ -125,13 +132,14 @@ func TestAuthMiddleware_InvalidToken(t *testing.T) {
 	engine.ServeHTTP(w, req)
 
 	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assertAPIError(t, w, handlers.CodeInvalidToken)
 }
 
 func TestAuthMiddleware_EmptyToken(t *testing.T) {
 	engine, jwtService := setupAuthTest(t)
 
 	// Add middleware to engine
-	engine.Use(AuthMiddleware(jwtService))
+	engine.Use(AuthMiddleware(jwtService, ""))
 
 	// Add test endpoint
 	engine.GET("/test", func(c *gin.Context) {
@@ -147,3 +155,81 @@ func TestAuthMiddleware_EmptyToken(t *testing.T) {
 
 	assert.Equal(t, http.StatusUnauthorized, w.Code)
 }
+
+func TestAuthMiddleware_Cookie(t *testing.T) {
+	engine, jwtService := setupAuthTest(t)
+	engine.Use(AuthMiddleware(jwtService, "access_token"))
+	engine.GET("/test", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"email": c.GetString("user_email")})
+	})
+
+	token, err := jwtService.GenerateAccessToken("cookie@example.com")
+	assert.NoError(t, err)
+
+	req, _ := http.NewRequest("GET", "/test", nil)
+	req.AddCookie(&http.Cookie{Name: "access_token", Value: token})
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "cookie@example.com")
+
+	// An invalid cookie token is rejected
+	req, _ = http.NewRequest("GET", "/test", nil)
+	req.AddCookie(&http.Cookie{Name: "access_token", Value: "invalid-token"})
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	// A cookie with another name is ignored
+	req, _ = http.NewRequest("GET", "/test", nil)
+	req.AddCookie(&http.Cookie{Name: "other", Value: token})
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestAuthMiddleware_CookieDisabled(t *testing.T) {
+	engine, jwtService := setupAuthTest(t)
+	engine.Use(AuthMiddleware(jwtService, ""))
+	engine.GET("/test", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "success"})
+	})
+
+	token, err := jwtService.GenerateAccessToken("cookie@example.com")
+	assert.NoError(t, err)
+
+	req, _ := http.NewRequest("GET", "/test", nil)
+	req.AddCookie(&http.Cookie{Name: "access_token", Value: token})
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestAuthMiddleware_HeaderTakesPrecedence(t *testing.T) {
+	engine, jwtService := setupAuthTest(t)
+	engine.Use(AuthMiddleware(jwtService, "access_token"))
+	engine.GET("/test", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"email": c.GetString("user_email")})
+	})
+
+	headerToken, err := jwtService.GenerateAccessToken("header@example.com")
+	assert.NoError(t, err)
+	cookieToken, err := jwtService.GenerateAccessToken("cookie@example.com")
+	assert.NoError(t, err)
+
+	req, _ := http.NewRequest("GET", "/test", nil)
+	req.Header.Set("Authorization", "Bearer "+headerToken)
+	req.AddCookie(&http.Cookie{Name: "access_token", Value: cookieToken})
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "header@example.com")
+
+	// An invalid header is not rescued by a valid cookie
+	req, _ = http.NewRequest("GET", "/test", nil)
+	req.Header.Set("Authorization", "Bearer invalid-token")
+	req.AddCookie(&http.Cookie{Name: "access_token", Value: cookieToken})
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
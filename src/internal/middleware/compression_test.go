@@ -0,0 +1,105 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupCompressionEngine() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(Compression(1024))
+
+	items := make([]gin.H, 100)
+	for i := range items {
+		items[i] = gin.H{"id": i, "name": fmt.Sprintf("Item %d", i)}
+	}
+	engine.GET("/items", func(c *gin.Context) {
+		c.JSON(http.StatusOK, items)
+	})
+	engine.GET("/small", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+	engine.GET("/qr", func(c *gin.Context) {
+		c.Data(http.StatusOK, "image/png", make([]byte, 4096))
+	})
+	return engine
+}
+
+func TestCompression_LargeJSON(t *testing.T) {
+	engine := setupCompressionEngine()
+
+	plain := httptest.NewRecorder()
+	engine.ServeHTTP(plain, httptest.NewRequest("GET", "/items", nil))
+
+	req := httptest.NewRequest("GET", "/items", nil)
+	req.Header.Set("Accept-Encoding", "gzip, deflate")
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+	assert.Contains(t, w.Header().Values("Vary"), "Accept-Encoding")
+	assert.Less(t, w.Body.Len(), plain.Body.Len())
+
+	reader, err := gzip.NewReader(w.Body)
+	assert.NoError(t, err)
+	body, err := io.ReadAll(reader)
+	assert.NoError(t, err)
+	assert.Equal(t, plain.Body.String(), string(body))
+	assert.True(t, strings.HasPrefix(string(body), `[{"id":0,"name":"Item 0"}`))
+}
+
+func TestCompression_NotAccepted(t *testing.T) {
+	engine := setupCompressionEngine()
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest("GET", "/items", nil))
+
+	assert.Empty(t, w.Header().Get("Content-Encoding"))
+	assert.True(t, strings.HasPrefix(w.Body.String(), "["))
+}
+
+func TestCompression_SkipsSmallResponses(t *testing.T) {
+	engine := setupCompressionEngine()
+
+	req := httptest.NewRequest("GET", "/small", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+
+	assert.Empty(t, w.Header().Get("Content-Encoding"))
+	assert.JSONEq(t, `{"ok":true}`, w.Body.String())
+}
+
+func TestCompression_SkipsCompressedContentTypes(t *testing.T) {
+	engine := setupCompressionEngine()
+
+	req := httptest.NewRequest("GET", "/qr", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+
+	assert.Empty(t, w.Header().Get("Content-Encoding"))
+	assert.Equal(t, "image/png", w.Header().Get("Content-Type"))
+	assert.Equal(t, 4096, w.Body.Len())
+}
+
+func TestCompression_GzipRefused(t *testing.T) {
+	engine := setupCompressionEngine()
+
+	req := httptest.NewRequest("GET", "/items", nil)
+	req.Header.Set("Accept-Encoding", "gzip;q=0, identity")
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+
+	assert.Empty(t, w.Header().Get("Content-Encoding"))
+}
@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"backend/internal/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// wildcardOrigin allows requests from any origin
+const wildcardOrigin = "*"
+
+// CORS provides CORS middleware for the given configuration
+func CORS(logger *slog.Logger, cfg config.CORSConfig) gin.HandlerFunc {
+	allowedMethods := strings.Join(cfg.AllowedMethods, ", ")
+	allowedHeaders := strings.Join(cfg.AllowedHeaders, ", ")
+
+	// Browsers reject credentialed responses for a wildcard origin
+	wildcard := isOriginAllowed(cfg.AllowedOrigins, wildcardOrigin)
+	allowCredentials := cfg.AllowCredentials
+	if wildcard && allowCredentials {
+		logger.Warn("CORS credentials cannot be combined with a wildcard origin, disabling credentials")
+		allowCredentials = false
+	}
+
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+
+		allowed := wildcard || isOriginAllowed(cfg.AllowedOrigins, origin)
+		if wildcard {
+			c.Header("Access-Control-Allow-Origin", wildcardOrigin)
+		} else if allowed {
+			c.Header("Access-Control-Allow-Origin", origin)
+			c.Header("Vary", "Origin")
+			if allowCredentials {
+				c.Header("Access-Control-Allow-Credentials", "true")
+			}
+		}
+
+		// Short-circuit preflight requests
+		if c.Request.Method == http.MethodOptions {
+			if allowed {
+				c.Header("Access-Control-Allow-Methods", allowedMethods)
+				c.Header("Access-Control-Allow-Headers", allowedHeaders)
+			}
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// CORSWithPrefix applies prefixCfg to requests for prefix and the paths below it, and
+// defaultCfg to all others; sibling paths that merely share the prefix (/api/uploadsX)
+// get defaultCfg
+func CORSWithPrefix(logger *slog.Logger, defaultCfg config.CORSConfig, prefix string, prefixCfg config.CORSConfig) gin.HandlerFunc {
+	defaultCORS := CORS(logger, defaultCfg)
+	prefixCORS := CORS(logger, prefixCfg)
+
+	return func(c *gin.Context) {
+		path := c.Request.URL.Path
+		if path == prefix || strings.HasPrefix(path, prefix+"/") {
+			prefixCORS(c)
+			return
+		}
+		defaultCORS(c)
+	}
+}
+
+// isOriginAllowed checks whether the origin is in the allowed list
+func isOriginAllowed(allowedOrigins []string, origin string) bool {
+	for _, allowed := range allowedOrigins {
+		if allowed == origin {
+			return true
+		}
+	}
+	return false
+}
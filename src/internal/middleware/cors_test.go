@@ -0,0 +1,186 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"backend/internal/config"
+	"backend/internal/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupCORSTest() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+
+	defaultCfg := config.CORSConfig{
+		AllowedOrigins:   []string{"https://app.example.com"},
+		AllowedMethods:   []string{"GET", "POST"},
+		AllowedHeaders:   []string{"Authorization", "Content-Type"},
+		AllowCredentials: true,
+	}
+	uploadCfg := config.CORSConfig{
+		AllowedOrigins: []string{"https://cdn.example.com"},
+		AllowedMethods: []string{"POST"},
+		AllowedHeaders: []string{"Content-Type"},
+	}
+
+	engine.Use(CORSWithPrefix(logger.Discard(), defaultCfg, "/api/uploads", uploadCfg))
+	engine.GET("/api/items", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"items": []string{}})
+	})
+	engine.POST("/api/uploads", func(c *gin.Context) {
+		c.JSON(http.StatusCreated, gin.H{"url": "/uploads/file.png"})
+	})
+
+	return engine
+}
+
+func TestCORS_JSONRouteUsesDefaultConfig(t *testing.T) {
+	engine := setupCORSTest()
+
+	req, _ := http.NewRequest("GET", "/api/items", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "https://app.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+
+	// The upload origin is not allowed on JSON routes
+	req, _ = http.NewRequest("GET", "/api/items", nil)
+	req.Header.Set("Origin", "https://cdn.example.com")
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestCORS_UploadRouteUsesUploadConfig(t *testing.T) {
+	engine := setupCORSTest()
+
+	req, _ := http.NewRequest("POST", "/api/uploads", nil)
+	req.Header.Set("Origin", "https://cdn.example.com")
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "https://cdn.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Credentials"))
+
+	// The default origin is not allowed on upload routes
+	req, _ = http.NewRequest("POST", "/api/uploads", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestCORS_UploadPrefixMatchesWholeSegments(t *testing.T) {
+	engine := setupCORSTest()
+	engine.GET("/api/uploadsX", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	engine.POST("/api/uploads/nested", func(c *gin.Context) {
+		c.Status(http.StatusCreated)
+	})
+
+	// A sibling path sharing the prefix uses the default config
+	req, _ := http.NewRequest("GET", "/api/uploadsX", nil)
+	req.Header.Set("Origin", "https://cdn.example.com")
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+
+	req, _ = http.NewRequest("GET", "/api/uploadsX", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, "https://app.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+
+	// Paths below the prefix use the upload config
+	req, _ = http.NewRequest("POST", "/api/uploads/nested", nil)
+	req.Header.Set("Origin", "https://cdn.example.com")
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, "https://cdn.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestCORS_UploadPreflight(t *testing.T) {
+	engine := setupCORSTest()
+
+	req, _ := http.NewRequest("OPTIONS", "/api/uploads", nil)
+	req.Header.Set("Origin", "https://cdn.example.com")
+	req.Header.Set("Access-Control-Request-Method", "POST")
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "https://cdn.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "POST", w.Header().Get("Access-Control-Allow-Methods"))
+	assert.Equal(t, "Content-Type", w.Header().Get("Access-Control-Allow-Headers"))
+}
+
+func TestCORS_Preflight(t *testing.T) {
+	engine := setupCORSTest()
+
+	req, _ := http.NewRequest("OPTIONS", "/api/items", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	req.Header.Set("Access-Control-Request-Method", "GET")
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "https://app.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "GET, POST", w.Header().Get("Access-Control-Allow-Methods"))
+	assert.Equal(t, "Authorization, Content-Type", w.Header().Get("Access-Control-Allow-Headers"))
+	assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+}
+
+func TestCORS_PreflightDisallowedOrigin(t *testing.T) {
+	engine := setupCORSTest()
+
+	req, _ := http.NewRequest("OPTIONS", "/api/items", nil)
+	req.Header.Set("Origin", "https://evil.example.com")
+	req.Header.Set("Access-Control-Request-Method", "GET")
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Methods"))
+}
+
+func TestCORS_WildcardOriginWithoutCredentials(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	var logs bytes.Buffer
+	engine.Use(CORS(logger.New(&logs, "info", false), config.CORSConfig{
+		AllowedOrigins:   []string{"*"},
+		AllowedMethods:   []string{"GET"},
+		AllowedHeaders:   []string{"Content-Type"},
+		AllowCredentials: true,
+	}))
+	engine.GET("/api/items", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"items": []string{}})
+	})
+
+	req, _ := http.NewRequest("OPTIONS", "/api/items", nil)
+	req.Header.Set("Origin", "https://anywhere.example.com")
+	req.Header.Set("Access-Control-Request-Method", "GET")
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "GET", w.Header().Get("Access-Control-Allow-Methods"))
+	// Credentials are never allowed together with a wildcard origin
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Credentials"))
+	assert.Contains(t, logs.String(), "wildcard origin")
+}
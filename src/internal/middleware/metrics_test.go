@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupMetricsEngine(metrics *Metrics) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(metrics.Middleware())
+	engine.GET("/items/:item_id", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"id": c.Param("item_id")})
+	})
+	engine.GET("/metrics", gin.WrapH(metrics.Handler()))
+	return engine
+}
+
+func TestMetrics_CountsRequestsByRoute(t *testing.T) {
+	metrics := NewMetrics()
+	engine := setupMetricsEngine(metrics)
+
+	for _, path := range []string{"/items/1", "/items/2"} {
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+	}
+
+	// Both paths share the route template label
+	assert.Equal(t, 2.0, testutil.ToFloat64(metrics.requests.WithLabelValues("GET", "/items/:item_id", "200")))
+	assert.Equal(t, 0.0, testutil.ToFloat64(metrics.inFlight.WithLabelValues("GET", "/items/:item_id")))
+	assert.Equal(t, 1, testutil.CollectAndCount(metrics.duration))
+}
+
+func TestMetrics_UnmatchedRoute(t *testing.T) {
+	metrics := NewMetrics()
+	engine := setupMetricsEngine(metrics)
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest("GET", "/does/not/exist", nil))
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.requests.WithLabelValues("GET", unmatchedRoute, "404")))
+}
+
+func TestMetrics_Handler(t *testing.T) {
+	metrics := NewMetrics()
+	engine := setupMetricsEngine(metrics)
+
+	engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/items/1", nil))
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `http_requests_total{method="GET",route="/items/:item_id",status="200"} 1`)
+	assert.Contains(t, w.Body.String(), "go_goroutines")
+}
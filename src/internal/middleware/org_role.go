@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"backend/internal/handlers"
+	"backend/internal/organization"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireOrgRole only allows members of the organization in the org_id path
+// parameter through when they hold one of the given roles
+func RequireOrgRole(organizationService *organization.Service, roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userEmail, exists := c.Get("user_email")
+		if !exists {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, handlers.APIError{Code: handlers.CodeUnauthenticated, Message: "User not authenticated"})
+			return
+		}
+
+		organizationID, err := strconv.ParseUint(c.Param("org_id"), 10, 32)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, handlers.APIError{Code: handlers.CodeInvalidInput, Message: "Invalid organization ID"})
+			return
+		}
+
+		role, err := organizationService.GetUserRole(uint(organizationID), userEmail.(string))
+		if errors.Is(err, organization.ErrNotMember) {
+			c.AbortWithStatusJSON(http.StatusForbidden, handlers.APIError{Code: handlers.CodeNotMember, Message: "Not a member of this organization"})
+			return
+		}
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, handlers.APIError{Code: handlers.CodeInternal, Message: "Failed to check organization role"})
+			return
+		}
+
+		if !slices.Contains(roles, role) {
+			c.AbortWithStatusJSON(http.StatusForbidden, handlers.APIError{Code: handlers.CodeForbidden, Message: "Organization role not permitted"})
+			return
+		}
+
+		c.Next()
+	}
+}
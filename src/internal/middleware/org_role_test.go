@@ -0,0 +1,103 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"backend/internal/config"
+	"backend/internal/database"
+	"backend/internal/handlers"
+	"backend/internal/organization"
+	"backend/internal/user"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// setupOrgRoleTest creates an organization owned by owner@example.com with
+// member@example.com as a plain member, behind an owner-only route
+func setupOrgRoleTest(t *testing.T, email string) (*gin.Engine, uint) {
+	gin.SetMode(gin.TestMode)
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+	if err := db.AutoMigrate(&database.Organization{}, &database.User{}, &database.OrganizationUser{}); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+
+	userService := user.NewUserService(db, &config.Config{Security: config.SecurityConfig{BcryptCost: bcrypt.MinCost}})
+	organizationService := organization.NewOrganizationService(db)
+	for _, u := range []string{"owner@example.com", "member@example.com"} {
+		if err := userService.CreateUser(u, "password123"); err != nil {
+			t.Fatalf("Failed to create user: %v", err)
+		}
+	}
+
+	org, err := organizationService.CreateOrganizationForUser("Camping Club", "owner@example.com")
+	if err != nil {
+		t.Fatalf("Failed to create organization: %v", err)
+	}
+	if err := organizationService.AddUserToOrganization(org.ID, "member@example.com"); err != nil {
+		t.Fatalf("Failed to add member: %v", err)
+	}
+
+	engine := gin.New()
+	engine.Use(func(c *gin.Context) {
+		c.Set("user_email", email)
+		c.Next()
+	})
+	engine.DELETE("/organizations/:org_id", RequireOrgRole(organizationService, organization.RoleOwner), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "success"})
+	})
+
+	return engine, org.ID
+}
+
+// assertAPIError checks that the response is an APIError with the given code
+func assertAPIError(t *testing.T, w *httptest.ResponseRecorder, code string) {
+	t.Helper()
+	var body handlers.APIError
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, code, body.Code)
+	assert.NotEmpty(t, body.Message)
+}
+
+func TestRequireOrgRole_Owner(t *testing.T) {
+	engine, orgID := setupOrgRoleTest(t, "owner@example.com")
+
+	req, _ := http.NewRequest("DELETE", fmt.Sprintf("/organizations/%d", orgID), nil)
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestRequireOrgRole_Member(t *testing.T) {
+	engine, orgID := setupOrgRoleTest(t, "member@example.com")
+
+	req, _ := http.NewRequest("DELETE", fmt.Sprintf("/organizations/%d", orgID), nil)
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assertAPIError(t, w, handlers.CodeForbidden)
+}
+
+func TestRequireOrgRole_NotMember(t *testing.T) {
+	engine, _ := setupOrgRoleTest(t, "member@example.com")
+
+	req, _ := http.NewRequest("DELETE", "/organizations/9999", nil)
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assertAPIError(t, w, handlers.CodeNotMember)
+}
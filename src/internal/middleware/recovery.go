@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+
+	"backend/internal/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Recovery provides middleware that turns a panicking handler into a JSON 500 response.
+// The panic and its stack are logged with the request ID; the client only gets a
+// generic APIError.
+func Recovery(logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			// net/http uses this panic to abort a response on purpose
+			if err, ok := recovered.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+				panic(recovered)
+			}
+
+			logger.ErrorContext(c.Request.Context(), "panic recovered",
+				"error", recovered,
+				"method", c.Request.Method,
+				"path", c.Request.URL.Path,
+				"stack", string(debug.Stack()),
+			)
+
+			if c.Writer.Written() {
+				c.Abort()
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusInternalServerError, handlers.APIError{
+				Code:    handlers.CodeInternal,
+				Message: "Internal server error",
+			})
+		}()
+
+		c.Next()
+	}
+}
@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"backend/internal/handlers"
+	"backend/internal/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecovery_RespondsWithJSON(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var logs bytes.Buffer
+	engine := gin.New()
+	engine.Use(RequestID())
+	engine.Use(Recovery(logger.New(&logs, "info", true)))
+	engine.GET("/panic", func(c *gin.Context) {
+		panic("something went badly wrong")
+	})
+
+	req := httptest.NewRequest("GET", "/panic", nil)
+	req.Header.Set(RequestIDHeader, "req-panic")
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	var body handlers.APIError
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, handlers.CodeInternal, body.Code)
+	assert.NotContains(t, w.Body.String(), "something went badly wrong")
+	assert.NotContains(t, w.Body.String(), "goroutine")
+
+	// The details go to the log instead
+	assert.Contains(t, logs.String(), "something went badly wrong")
+	assert.Contains(t, logs.String(), `"request_id":"req-panic"`)
+	assert.Contains(t, logs.String(), "recovery_test.go")
+}
+
+func TestRecovery_PassesThrough(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(Recovery(logger.Discard()))
+	engine.GET("/ok", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest("GET", "/ok", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"ok":true}`, w.Body.String())
+}
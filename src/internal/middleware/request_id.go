@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"backend/internal/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader is the header carrying the request correlation ID
+const RequestIDHeader = "X-Request-ID"
+
+// RequestIDKey is the Gin context key holding the request ID
+const RequestIDKey = "request_id"
+
+// maxRequestIDLength caps client-provided request IDs so they can't flood the logs
+const maxRequestIDLength = 128
+
+// RequestID provides middleware that tags each request with the incoming X-Request-ID,
+// or a generated UUID, and echoes it on the response. The ID is stored in the Gin
+// context and the request context so log entries made with it carry the ID.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID(id) {
+			id = uuid.NewString()
+		}
+
+		c.Set(RequestIDKey, id)
+		c.Request = c.Request.WithContext(logger.WithRequestID(c.Request.Context(), id))
+		c.Header(RequestIDHeader, id)
+
+		c.Next()
+	}
+}
+
+// validRequestID accepts non-empty IDs of printable ASCII within the length limit
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
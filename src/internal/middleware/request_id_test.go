@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"backend/internal/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// setupRequestIDEngine returns an engine whose handler reports the request ID it saw
+func setupRequestIDEngine() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(RequestID())
+	engine.GET("/test", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"context_id": c.GetString(RequestIDKey),
+			"request_id": logger.RequestID(c.Request.Context()),
+		})
+	})
+	return engine
+}
+
+func TestRequestID_EchoesProvidedHeader(t *testing.T) {
+	engine := setupRequestIDEngine()
+
+	req := httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set(RequestIDHeader, "client-id-123")
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+
+	assert.Equal(t, "client-id-123", w.Header().Get(RequestIDHeader))
+	assert.JSONEq(t, `{"context_id":"client-id-123","request_id":"client-id-123"}`, w.Body.String())
+}
+
+func TestRequestID_GeneratesMissingHeader(t *testing.T) {
+	engine := setupRequestIDEngine()
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest("GET", "/test", nil))
+
+	id := w.Header().Get(RequestIDHeader)
+	_, err := uuid.Parse(id)
+	assert.NoError(t, err, "generated ID %q should be a UUID", id)
+	assert.Contains(t, w.Body.String(), id)
+}
+
+func TestRequestID_ReplacesInvalidHeader(t *testing.T) {
+	engine := setupRequestIDEngine()
+
+	for _, id := range []string{strings.Repeat("a", maxRequestIDLength+1), "line\nbreak"} {
+		req := httptest.NewRequest("GET", "/test", nil)
+		req.Header.Set(RequestIDHeader, id)
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+
+		_, err := uuid.Parse(w.Header().Get(RequestIDHeader))
+		assert.NoError(t, err, "invalid ID %q should be replaced", id)
+	}
+}
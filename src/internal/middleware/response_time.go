@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ResponseTimeHeader is the header carrying the handler duration in milliseconds
+const ResponseTimeHeader = "X-Response-Time"
+
+// ResponseTime provides middleware that reports how long the request took to handle
+func ResponseTime() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		writer := &responseTimeWriter{ResponseWriter: c.Writer, start: time.Now()}
+		c.Writer = writer
+
+		c.Next()
+
+		// Handlers that never wrote a body still get the header
+		writer.setHeader()
+	}
+}
+
+// responseTimeWriter sets the response time header right before the headers are sent
+type responseTimeWriter struct {
+	gin.ResponseWriter
+	start time.Time
+	set   bool
+}
+
+func (w *responseTimeWriter) setHeader() {
+	if w.set || w.ResponseWriter.Written() {
+		return
+	}
+	w.set = true
+	elapsed := float64(time.Since(w.start).Microseconds()) / 1000
+	w.Header().Set(ResponseTimeHeader, strconv.FormatFloat(elapsed, 'f', 3, 64))
+}
+
+func (w *responseTimeWriter) WriteHeaderNow() {
+	w.setHeader()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *responseTimeWriter) Write(data []byte) (int, error) {
+	w.setHeader()
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseTimeWriter) WriteString(s string) (int, error) {
+	w.setHeader()
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResponseTime_SetsHeader(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(ResponseTime())
+	engine.GET("/slow", func(c *gin.Context) {
+		time.Sleep(5 * time.Millisecond)
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest("GET", "/slow", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	header := w.Header().Get(ResponseTimeHeader)
+	ms, err := strconv.ParseFloat(header, 64)
+	assert.NoError(t, err, "header %q should be a number", header)
+	assert.GreaterOrEqual(t, ms, 5.0)
+}
+
+func TestResponseTime_NoBody(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(ResponseTime())
+	engine.DELETE("/items/1", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest("DELETE", "/items/1", nil))
+
+	_, err := strconv.ParseFloat(w.Header().Get(ResponseTimeHeader), 64)
+	assert.NoError(t, err)
+}
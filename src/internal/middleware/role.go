@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+
+	"backend/internal/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireRole only allows authenticated users whose token carries the given role through
+func RequireRole(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, exists := c.Get("user_email"); !exists {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, handlers.APIError{Code: handlers.CodeUnauthenticated, Message: "User not authenticated"})
+			return
+		}
+
+		// Tokens issued before roles were added carry none
+		roles, _ := c.Get("user_roles")
+		granted, _ := roles.([]string)
+		if !slices.Contains(granted, role) {
+			c.AbortWithStatusJSON(http.StatusForbidden, handlers.APIError{Code: handlers.CodeForbidden, Message: "Role not permitted"})
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,128 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/database"
+	"backend/internal/handlers"
+	"backend/internal/jwt"
+	"backend/internal/logger"
+	"backend/internal/organization"
+	"backend/internal/user"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// setupRoleTest creates an organization that owner@example.com owns and member@example.com
+// belongs to, active for both, behind an owner-only route
+func setupRoleTest(t *testing.T) (*gin.Engine, *jwt.Service) {
+	gin.SetMode(gin.TestMode)
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+	if err := db.AutoMigrate(&database.Organization{}, &database.User{}, &database.OrganizationUser{}, &database.RevokedToken{}); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+
+	cfg := &config.Config{
+		JWT:      config.JWTConfig{SecretKey: "test-secret-key", AccessTokenDuration: time.Hour},
+		Security: config.SecurityConfig{BcryptCost: bcrypt.MinCost},
+	}
+	userService := user.NewUserService(db, cfg)
+	organizationService := organization.NewOrganizationService(db)
+	for _, u := range []string{"owner@example.com", "member@example.com"} {
+		if err := userService.CreateUser(u, "password123"); err != nil {
+			t.Fatalf("Failed to create user: %v", err)
+		}
+	}
+
+	org, err := organizationService.CreateOrganizationForUser("Camping Club", "owner@example.com")
+	if err != nil {
+		t.Fatalf("Failed to create organization: %v", err)
+	}
+	if err := organizationService.AddUserToOrganization(org.ID, "member@example.com"); err != nil {
+		t.Fatalf("Failed to add member: %v", err)
+	}
+	if err := organizationService.SetUserActiveOrganization("member@example.com", org.ID); err != nil {
+		t.Fatalf("Failed to set active organization: %v", err)
+	}
+
+	jwtService, err := jwt.NewJWTService(cfg, db, logger.Discard())
+	if err != nil {
+		t.Fatalf("Failed to create JWT service: %v", err)
+	}
+
+	engine := gin.New()
+	engine.Use(AuthMiddleware(jwtService, ""))
+	engine.GET("/guarded", RequireRole(organization.RoleOwner), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "success"})
+	})
+
+	return engine, jwtService
+}
+
+// requestGuarded calls the guarded route with the given access token
+func requestGuarded(engine *gin.Engine, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", "/guarded", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	return w
+}
+
+func TestRequireRole(t *testing.T) {
+	engine, jwtService := setupRoleTest(t)
+
+	tests := []struct {
+		email string
+		want  int
+	}{
+		{"owner@example.com", http.StatusOK},
+		{"member@example.com", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.email, func(t *testing.T) {
+			token, err := jwtService.GenerateAccessToken(tt.email)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, requestGuarded(engine, token).Code)
+		})
+	}
+}
+
+func TestRequireRole_TokenWithoutRoles(t *testing.T) {
+	engine, jwtService := setupRoleTest(t)
+
+	// Tokens issued before roles were added still authenticate but hold no role
+	token, err := jwtService.GenerateToken("owner@example.com", time.Hour)
+	assert.NoError(t, err)
+	_, err = jwtService.ValidateToken(token)
+	assert.NoError(t, err)
+
+	w := requestGuarded(engine, token)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assertAPIError(t, w, handlers.CodeForbidden)
+}
+
+func TestRequireRole_Unauthenticated(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.GET("/guarded", RequireRole(organization.RoleOwner), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "success"})
+	})
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest("GET", "/guarded", nil))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assertAPIError(t, w, handlers.CodeUnauthenticated)
+}
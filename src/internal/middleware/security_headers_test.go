@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"backend/internal/config"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupSecurityHeadersRouter(cfg config.SecurityHeadersConfig) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(SecurityHeaders(cfg))
+	engine.GET("/items", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"items": []string{}})
+	})
+	return engine
+}
+
+func TestSecurityHeaders_SetsHeaders(t *testing.T) {
+	engine := setupSecurityHeadersRouter(config.SecurityHeadersConfig{
+		Enabled:        true,
+		FrameOptions:   "DENY",
+		ReferrerPolicy: "no-referrer",
+	})
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest("GET", "/items", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "nosniff", w.Header().Get("X-Content-Type-Options"))
+	assert.Equal(t, "DENY", w.Header().Get("X-Frame-Options"))
+	assert.Equal(t, "no-referrer", w.Header().Get("Referrer-Policy"))
+	assert.Empty(t, w.Header().Get("Strict-Transport-Security"))
+}
+
+func TestSecurityHeaders_HSTSOnlyOverTLS(t *testing.T) {
+	engine := setupSecurityHeadersRouter(config.SecurityHeadersConfig{
+		Enabled:    true,
+		HSTS:       true,
+		HSTSMaxAge: time.Hour,
+	})
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest("GET", "/items", nil))
+	assert.Empty(t, w.Header().Get("Strict-Transport-Security"))
+
+	req := httptest.NewRequest("GET", "/items", nil)
+	req.Header.Set("X-Forwarded-Proto", "https")
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	assert.Equal(t, "max-age=3600; includeSubDomains", w.Header().Get("Strict-Transport-Security"))
+	assert.Empty(t, w.Header().Get("X-Frame-Options"))
+}
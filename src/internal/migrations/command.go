@@ -0,0 +1,90 @@
+package migrations
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/golang-migrate/migrate/v4"
+)
+
+// Subcommands of the migrate CLI
+const (
+	CommandUp      = "up"
+	CommandDown    = "down"
+	CommandVersion = "version"
+	CommandForce   = "force"
+)
+
+// Usage describes the arguments of the migrate CLI
+const Usage = "migrate up|down|version|force <version>"
+
+// ErrInvalidCommand is returned for unknown migrate subcommands or malformed arguments
+var ErrInvalidCommand = errors.New("invalid migrate command")
+
+// Command is a parsed migrate subcommand
+type Command struct {
+	Name string
+	// Version is the version to force, only used by CommandForce
+	Version uint
+}
+
+// ParseCommand parses the arguments following "migrate"
+func ParseCommand(args []string) (Command, error) {
+	if len(args) == 0 {
+		return Command{}, fmt.Errorf("%w, usage: %s", ErrInvalidCommand, Usage)
+	}
+
+	cmd := Command{Name: args[0]}
+	switch cmd.Name {
+	case CommandUp, CommandDown, CommandVersion:
+		if len(args) == 1 {
+			return cmd, nil
+		}
+	case CommandForce:
+		if len(args) == 2 {
+			version, err := strconv.ParseUint(args[1], 10, 0)
+			if err != nil {
+				return Command{}, fmt.Errorf("%w, %q is not a migration version", ErrInvalidCommand, args[1])
+			}
+			cmd.Version = uint(version)
+			return cmd, nil
+		}
+	}
+	return Command{}, fmt.Errorf("%w, usage: %s", ErrInvalidCommand, Usage)
+}
+
+// Run executes a migrate subcommand, then writes the resulting schema version to out
+func Run(service *Service, cmd Command, out io.Writer) error {
+	var err error
+	switch cmd.Name {
+	case CommandUp:
+		err = service.Up()
+	case CommandDown:
+		err = service.Down()
+	case CommandForce:
+		err = service.Force(cmd.Version)
+	case CommandVersion:
+	default:
+		return fmt.Errorf("%w %q", ErrInvalidCommand, cmd.Name)
+	}
+	if err != nil {
+		return err
+	}
+
+	version, dirty, err := service.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		_, err = fmt.Fprintln(out, "No migrations applied")
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	if dirty {
+		_, err = fmt.Fprintf(out, "Version %d (dirty)\n", version)
+		return err
+	}
+	_, err = fmt.Fprintf(out, "Version %d\n", version)
+	return err
+}
@@ -0,0 +1,100 @@
+package migrations
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"testing"
+
+	"backend/internal/config"
+	"backend/internal/logger"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/stub"
+	"github.com/stretchr/testify/assert"
+	gormpostgres "gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestParseCommand(t *testing.T) {
+	for _, name := range []string{CommandUp, CommandDown, CommandVersion} {
+		cmd, err := ParseCommand([]string{name})
+		assert.NoError(t, err)
+		assert.Equal(t, Command{Name: name}, cmd)
+	}
+
+	cmd, err := ParseCommand([]string{CommandForce, "12"})
+	assert.NoError(t, err)
+	assert.Equal(t, Command{Name: CommandForce, Version: 12}, cmd)
+
+	invalid := [][]string{
+		nil,
+		{"sideways"},
+		{CommandUp, "1"},
+		{CommandForce},
+		{CommandForce, "-1"},
+		{CommandForce, "latest"},
+	}
+	for _, args := range invalid {
+		_, err := ParseCommand(args)
+		assert.ErrorIs(t, err, ErrInvalidCommand, "args %v", args)
+	}
+}
+
+func TestRun_UnknownCommand(t *testing.T) {
+	// The command is checked before the service is used
+	assert.ErrorIs(t, Run(nil, Command{Name: "sideways"}, &bytes.Buffer{}), ErrInvalidCommand)
+}
+
+func TestRun_Force(t *testing.T) {
+	driver, err := stub.WithInstance(nil, &stub.Config{})
+	assert.NoError(t, err)
+	service, err := newService(driver, "stub", logger.Discard())
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer service.Close()
+
+	// A migration that failed halfway leaves its version dirty
+	assert.NoError(t, driver.SetVersion(3, true))
+	assert.ErrorAs(t, service.Up(), &migrate.ErrDirty{})
+
+	var out bytes.Buffer
+	assert.NoError(t, Run(service, Command{Name: CommandVersion}, &out))
+	assert.Equal(t, "Version 3 (dirty)\n", out.String())
+
+	out.Reset()
+	assert.NoError(t, Run(service, Command{Name: CommandForce, Version: 3}, &out))
+	assert.Equal(t, "Version 3\n", out.String())
+
+	// Clean again, so pending migrations apply
+	assert.NoError(t, service.Up())
+}
+
+// TestRun_Version reports the schema version of the throwaway database in TEST_POSTGRES_DSN
+func TestRun_Version(t *testing.T) {
+	dsn := os.Getenv("TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("Skipping Postgres migration test - TEST_POSTGRES_DSN is not set")
+	}
+	db, err := gorm.Open(gormpostgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Skipf("Skipping Postgres migration test - database unavailable: %v", err)
+	}
+
+	service, err := NewMigrationService(db, &config.Config{}, logger.Discard())
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer service.Close()
+
+	var out bytes.Buffer
+	assert.NoError(t, Run(service, Command{Name: CommandUp}, &out))
+	out.Reset()
+	assert.NoError(t, Run(service, Command{Name: CommandVersion}, &out))
+
+	files, err := fs.Glob(Files, filesDir+"/*.up.sql")
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("Version %d\n", len(files)), out.String())
+}
@@ -1,57 +1,123 @@
 package migrations
 
 import (
+	"context"
+	"embed"
 	"errors"
-	"log"
+	"fmt"
+	"log/slog"
 
 	"backend/internal/config"
+	"backend/internal/database"
 
 	"github.com/golang-migrate/migrate/v4"
+	migratedb "github.com/golang-migrate/migrate/v4/database"
 	"github.com/golang-migrate/migrate/v4/database/postgres"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
 	"go.uber.org/fx"
 	"gorm.io/gorm"
 )
 
-// Module provides migration dependency injection
+// Module provides migration dependency injection and applies pending migrations on
+// startup when RUN_MIGRATIONS is set
 var Module = fx.Module("migrations",
 	fx.Provide(NewMigrationService),
+	fx.Invoke(RunOnStartup),
 )
 
+// ErrUnsupportedDriver is returned for SQLite databases, whose schema is created from the models
+var ErrUnsupportedDriver = errors.New("SQL migrations only apply to Postgres")
+
+// Files holds the SQL migrations, embedded so the binary carries its own schema
+//
+//go:embed sql/*.sql
+var Files embed.FS
+
+// filesDir is the directory of the migrations within Files
+const filesDir = "sql"
+
 // Service handles database migrations
 type Service struct {
 	migrate *migrate.Migrate
+	logger  *slog.Logger
 }
 
-// NewMigrationService creates a new migration service
-func NewMigrationService(db *gorm.DB, cfg *config.Config) (*Service, error) {
+// NewMigrationService creates a new migration service applying the embedded migrations.
+// It migrates over a dedicated connection from db's pool; Close releases that connection
+// but leaves db open.
+func NewMigrationService(db *gorm.DB, cfg *config.Config, logger *slog.Logger) (*Service, error) {
+	if cfg.Database.Driver == database.DriverSQLite {
+		return nil, ErrUnsupportedDriver
+	}
+
 	// Get underlying sql.DB from GORM
 	sqlDB, err := db.DB()
 	if err != nil {
 		return nil, err
 	}
+	conn, err := sqlDB.Conn(context.Background())
+	if err != nil {
+		return nil, err
+	}
 
 	// Create postgres driver instance
-	driver, err := postgres.WithInstance(sqlDB, &postgres.Config{})
+	driver, err := postgres.WithConnection(context.Background(), conn, &postgres.Config{})
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	service, err := newService(driver, "postgres", logger)
+	if err != nil {
+		driver.Close()
+		return nil, err
+	}
+	return service, nil
+}
+
+// newService creates a migration service applying the embedded migrations to driver
+func newService(driver migratedb.Driver, driverName string, logger *slog.Logger) (*Service, error) {
+	source, err := iofs.New(Files, filesDir)
 	if err != nil {
 		return nil, err
 	}
 
 	// Create migrate instance
-	m, err := migrate.NewWithDatabaseInstance(
-		"file://../../migrations", // Path to migrations folder
-		"postgres",
-		driver,
-	)
+	m, err := migrate.NewWithInstance("iofs", source, driverName, driver)
 	if err != nil {
+		source.Close()
 		return nil, err
 	}
 
 	return &Service{
 		migrate: m,
+		logger:  logger,
 	}, nil
 }
 
+// RunOnStartup applies all pending migrations before the server starts when
+// RUN_MIGRATIONS is set. SQLite databases are skipped; their schema comes from the models.
+func RunOnStartup(db *gorm.DB, cfg *config.Config, logger *slog.Logger) error {
+	if !cfg.Database.RunMigrations {
+		return nil
+	}
+	if cfg.Database.Driver == database.DriverSQLite {
+		logger.Info("skipping SQL migrations for SQLite")
+		return nil
+	}
+
+	service, err := NewMigrationService(db, cfg, logger)
+	if err != nil {
+		return fmt.Errorf("failed to prepare migrations: %w", err)
+	}
+	defer service.Close()
+
+	if err := service.Up(); err != nil {
+		return fmt.Errorf("failed to apply migrations: %w", err)
+	}
+	return nil
+}
+
 // Up runs all pending migrations
 func (s *Service) Up() error {
 	err := s.migrate.Up()
@@ -60,9 +126,9 @@ func (s *Service) Up() error {
 	}
 
 	if errors.Is(err, migrate.ErrNoChange) {
-		log.Println("No new migrations to apply")
+		s.logger.Info("no new migrations to apply")
 	} else {
-		log.Println("Migrations applied successfully")
+		s.logger.Info("migrations applied")
 	}
 
 	return nil
@@ -76,9 +142,9 @@ func (s *Service) Down() error {
 	}
 
 	if errors.Is(err, migrate.ErrNoChange) {
-		log.Println("No migrations to revert")
+		s.logger.Info("no migrations to revert")
 	} else {
-		log.Println("Migration reverted successfully")
+		s.logger.Info("migration reverted")
 	}
 
 	return nil
@@ -89,6 +155,17 @@ func (s *Service) Version() (uint, bool, error) {
 	return s.migrate.Version()
 }
 
+// Force sets the schema version and clears the dirty flag without running any migration.
+// Use it to recover after a failed migration once the schema has been repaired by hand.
+func (s *Service) Force(version uint) error {
+	s.logger.Warn("forcing migration version and clearing the dirty flag; no migrations are run", "version", version)
+	if err := s.migrate.Force(int(version)); err != nil {
+		return err
+	}
+	s.logger.Info("migration version forced", "version", version)
+	return nil
+}
+
 // Close closes the migration instance
 func (s *Service) Close() error {
 	sourceErr, dbErr := s.migrate.Close()
@@ -1,11 +1,17 @@
 package migrations
 
 import (
+	"io/fs"
+	"os"
 	"testing"
 
 	"backend/internal/config"
+	"backend/internal/database"
+	"backend/internal/logger"
 
+	"github.com/golang-migrate/migrate/v4/database/stub"
 	"github.com/stretchr/testify/assert"
+	gormpostgres "gorm.io/driver/postgres"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
@@ -30,7 +36,7 @@ func setupTestMigrationService(t *testing.T) *Service {
 	}
 
 	// Create migration service
-	service, err := NewMigrationService(db, cfg)
+	service, err := NewMigrationService(db, cfg, logger.Discard())
 	if err != nil {
 		t.Skipf("Skipping migration tests - SQLite doesn't support PostgreSQL migrations: %v", err)
 	}
@@ -54,7 +60,7 @@ func TestNewMigrationService(t *testing.T) {
 	}
 
 	// SQLite doesn't support some PostgreSQL functions, so we expect an error
-	service, err := NewMigrationService(db, cfg)
+	service, err := NewMigrationService(db, cfg, logger.Discard())
 	// We expect an error because SQLite doesn't support PostgreSQL-specific functions
 	if err != nil {
 		t.Logf("Expected error with SQLite: %v", err)
@@ -136,3 +142,123 @@ func TestMigrationService_VersionAfterClose(t *testing.T) {
 	_, _, err = service.Version()
 	assert.Error(t, err)
 }
+
+func TestFiles_NotEmpty(t *testing.T) {
+	ups, err := fs.Glob(Files, filesDir+"/*.up.sql")
+	assert.NoError(t, err)
+	downs, err := fs.Glob(Files, filesDir+"/*.down.sql")
+	assert.NoError(t, err)
+
+	assert.NotEmpty(t, ups)
+	assert.Len(t, downs, len(ups), "every migration needs an up and a down file")
+}
+
+func TestNewService_UsesEmbeddedMigrations(t *testing.T) {
+	driver, err := stub.WithInstance(nil, &stub.Config{})
+	assert.NoError(t, err)
+
+	service, err := newService(driver, "stub", logger.Discard())
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer service.Close()
+
+	assert.NoError(t, service.Up())
+
+	ups, err := fs.Glob(Files, filesDir+"/*.up.sql")
+	assert.NoError(t, err)
+	version, dirty, err := service.Version()
+	assert.NoError(t, err)
+	assert.False(t, dirty)
+	assert.Equal(t, uint(len(ups)), version)
+}
+
+func TestForce_ClearsDirtyState(t *testing.T) {
+	driver, err := stub.WithInstance(nil, &stub.Config{})
+	assert.NoError(t, err)
+	service, err := newService(driver, "stub", logger.Discard())
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer service.Close()
+
+	assert.NoError(t, driver.SetVersion(5, true))
+	_, dirty, err := service.Version()
+	assert.NoError(t, err)
+	assert.True(t, dirty)
+
+	assert.NoError(t, service.Force(4))
+
+	version, dirty, err := service.Version()
+	assert.NoError(t, err)
+	assert.False(t, dirty)
+	assert.Equal(t, uint(4), version)
+}
+
+func TestNewMigrationService_RejectsSQLite(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+
+	_, err = NewMigrationService(db, &config.Config{Database: config.DatabaseConfig{Driver: database.DriverSQLite}}, logger.Discard())
+	assert.ErrorIs(t, err, ErrUnsupportedDriver)
+}
+
+func TestRunOnStartup_Disabled(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+
+	// A Postgres config would fail against SQLite, so no error means nothing ran
+	cfg := &config.Config{Database: config.DatabaseConfig{Driver: database.DriverPostgres}}
+	assert.NoError(t, RunOnStartup(db, cfg, logger.Discard()))
+}
+
+func TestRunOnStartup_SkipsSQLite(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+
+	cfg := &config.Config{Database: config.DatabaseConfig{Driver: database.DriverSQLite, RunMigrations: true}}
+	assert.NoError(t, RunOnStartup(db, cfg, logger.Discard()))
+}
+
+// TestRunOnStartup_Postgres applies every migration to the throwaway database in
+// TEST_POSTGRES_DSN and checks the resulting schema matches the models
+func TestRunOnStartup_Postgres(t *testing.T) {
+	dsn := os.Getenv("TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("Skipping Postgres migration test - TEST_POSTGRES_DSN is not set")
+	}
+	db, err := gorm.Open(gormpostgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Skipf("Skipping Postgres migration test - database unavailable: %v", err)
+	}
+
+	cfg := &config.Config{Database: config.DatabaseConfig{Driver: database.DriverPostgres, RunMigrations: true}}
+	assert.NoError(t, RunOnStartup(db, cfg, logger.Discard()))
+	// Running again at the latest version is a no-op
+	assert.NoError(t, RunOnStartup(db, cfg, logger.Discard()))
+
+	files, err := fs.Glob(Files, filesDir+"/*.up.sql")
+	assert.NoError(t, err)
+	service, err := NewMigrationService(db, cfg, logger.Discard())
+	assert.NoError(t, err)
+	defer service.Close()
+	version, dirty, err := service.Version()
+	assert.NoError(t, err)
+	assert.False(t, dirty)
+	assert.Equal(t, uint(len(files)), version)
+
+	// RunOnStartup only released its own connection, so db is still usable
+	migrator := db.Migrator()
+	for _, model := range database.AllModels() {
+		stmt := &gorm.Statement{DB: db}
+		assert.NoError(t, stmt.Parse(model))
+		if !assert.True(t, migrator.HasTable(model), "missing table %s", stmt.Schema.Table) {
+			continue
+		}
+		for _, field := range stmt.Schema.Fields {
+			if field.DBName != "" {
+				assert.True(t, migrator.HasColumn(model, field.DBName), "missing column %s.%s", stmt.Schema.Table, field.DBName)
+			}
+		}
+	}
+}
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_items_organization_id;
+ALTER TABLE items DROP COLUMN IF EXISTS organization_id;
@@ -0,0 +1,4 @@
+ALTER TABLE items ADD COLUMN organization_id INTEGER REFERENCES organizations(id) ON DELETE SET NULL;
+
+-- Create index for faster lookups
+CREATE INDEX idx_items_organization_id ON items(organization_id);
@@ -0,0 +1 @@
+ALTER TABLE tags ADD COLUMN color VARCHAR(7) NOT NULL DEFAULT '';
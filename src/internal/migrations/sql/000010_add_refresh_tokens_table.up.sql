@@ -0,0 +1,11 @@
+CREATE TABLE refresh_tokens (
+    id SERIAL PRIMARY KEY,
+    jti VARCHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    user_email VARCHAR(255) REFERENCES users(email) ON DELETE CASCADE
+);
+
+-- Create index for faster lookups
+CREATE INDEX idx_refresh_tokens_user_email ON refresh_tokens(user_email);
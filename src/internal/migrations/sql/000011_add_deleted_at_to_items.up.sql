@@ -0,0 +1,4 @@
+ALTER TABLE items ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE;
+
+-- Create index for filtering out and syncing deleted items
+CREATE INDEX idx_items_deleted_at ON items(deleted_at);
@@ -0,0 +1 @@
+ALTER TABLE refresh_tokens ADD COLUMN used_at TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,8 @@
+ALTER TABLE users ADD COLUMN email_verified BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE users ADD COLUMN verification_token VARCHAR(64);
+
+-- Users registered before verification existed keep access
+UPDATE users SET email_verified = TRUE;
+
+-- Create index for verification token lookups
+CREATE INDEX idx_users_verification_token ON users(verification_token);
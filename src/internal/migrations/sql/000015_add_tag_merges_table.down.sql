@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS tag_merge_items;
+DROP INDEX IF EXISTS idx_tag_merges_organization_id;
+DROP TABLE IF EXISTS tag_merges;
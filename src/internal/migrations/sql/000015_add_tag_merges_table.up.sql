@@ -0,0 +1,21 @@
+CREATE TABLE tag_merges (
+    id SERIAL PRIMARY KEY,
+    organization_id INTEGER REFERENCES organizations(id) ON DELETE CASCADE,
+    source_tag_id INTEGER NOT NULL,
+    source_name VARCHAR(20) NOT NULL,
+    source_color VARCHAR(7),
+    target_tag_id INTEGER NOT NULL,
+    restored_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Items that carried the source tag when it was merged
+CREATE TABLE tag_merge_items (
+    tag_merge_id INTEGER REFERENCES tag_merges(id) ON DELETE CASCADE,
+    item_id INTEGER NOT NULL,
+    had_target BOOLEAN NOT NULL DEFAULT FALSE,
+    PRIMARY KEY (tag_merge_id, item_id)
+);
+
+-- Create index
+CREATE INDEX idx_tag_merges_organization_id ON tag_merges(organization_id);
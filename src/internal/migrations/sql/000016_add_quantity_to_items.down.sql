@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_items_quantity;
+ALTER TABLE items DROP COLUMN IF EXISTS quantity;
@@ -0,0 +1,4 @@
+ALTER TABLE items ADD COLUMN quantity INTEGER NOT NULL DEFAULT 1;
+
+-- Create index for quantity threshold filters
+CREATE INDEX idx_items_quantity ON items(quantity);
@@ -0,0 +1,6 @@
+ALTER TABLE users ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE tags ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE;
+
+-- Create indexes for filtering out deleted rows
+CREATE INDEX idx_users_deleted_at ON users(deleted_at);
+CREATE INDEX idx_tags_deleted_at ON tags(deleted_at);
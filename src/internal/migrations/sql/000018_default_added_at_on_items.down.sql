@@ -0,0 +1 @@
+ALTER TABLE items ALTER COLUMN added_at DROP DEFAULT;
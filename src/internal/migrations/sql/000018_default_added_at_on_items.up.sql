@@ -0,0 +1,2 @@
+-- added_at is now filled in on insert like created_at instead of by the application
+ALTER TABLE items ALTER COLUMN added_at SET DEFAULT CURRENT_TIMESTAMP;
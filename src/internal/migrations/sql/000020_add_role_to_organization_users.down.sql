@@ -0,0 +1 @@
+ALTER TABLE organization_users DROP COLUMN IF EXISTS role;
@@ -0,0 +1,7 @@
+ALTER TABLE organization_users ADD COLUMN role VARCHAR(20) NOT NULL DEFAULT 'member';
+
+-- Users own the personal organization created with their account
+UPDATE organization_users SET role = 'owner'
+FROM organizations
+WHERE organizations.id = organization_users.organization_id
+  AND organizations.name = organization_users.user_email || '_org';
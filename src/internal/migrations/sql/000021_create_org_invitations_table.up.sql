@@ -0,0 +1,13 @@
+CREATE TABLE org_invitations (
+    id SERIAL PRIMARY KEY,
+    token VARCHAR(64) NOT NULL,
+    organization_id INTEGER NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    accepted_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_org_invitations_token ON org_invitations(token);
+CREATE INDEX idx_org_invitations_organization_id ON org_invitations(organization_id);
@@ -0,0 +1,2 @@
+-- The backfilled organizations can't be told apart from assigned ones, so they are kept
+SELECT 1;
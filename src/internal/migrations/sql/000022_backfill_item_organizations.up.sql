@@ -0,0 +1,5 @@
+-- Share existing items with their owner's active organization
+UPDATE items SET organization_id = users.active_organization_id
+FROM users
+WHERE users.email = items.user_email
+  AND items.organization_id IS NULL;
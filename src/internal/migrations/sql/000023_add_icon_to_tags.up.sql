@@ -0,0 +1 @@
+ALTER TABLE tags ADD COLUMN icon VARCHAR(50) NOT NULL DEFAULT '';
@@ -0,0 +1,2 @@
+DELETE FROM reset_tokens WHERE LENGTH(token) > 30;
+ALTER TABLE reset_tokens ALTER COLUMN token TYPE VARCHAR(30);
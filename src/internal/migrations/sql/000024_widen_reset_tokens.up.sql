@@ -0,0 +1 @@
+ALTER TABLE reset_tokens ALTER COLUMN token TYPE VARCHAR(64);
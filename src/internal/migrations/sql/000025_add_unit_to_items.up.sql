@@ -0,0 +1 @@
+ALTER TABLE items ADD COLUMN unit VARCHAR(20) NOT NULL DEFAULT '';
@@ -0,0 +1,10 @@
+CREATE TABLE item_attachments (
+    id SERIAL PRIMARY KEY,
+    item_id INTEGER NOT NULL REFERENCES items(id) ON DELETE CASCADE,
+    url VARCHAR(500) NOT NULL,
+    content_type VARCHAR(100) NOT NULL DEFAULT '',
+    uploaded_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create index for faster lookups
+CREATE INDEX idx_item_attachments_item_id ON item_attachments(item_id);
@@ -0,0 +1,12 @@
+CREATE TABLE audit_logs (
+    id SERIAL PRIMARY KEY,
+    actor_email VARCHAR(255) NOT NULL,
+    entity_type VARCHAR(50) NOT NULL,
+    entity_id INTEGER NOT NULL,
+    action VARCHAR(20) NOT NULL,
+    diff JSONB,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create index for per-entity history lookups
+CREATE INDEX idx_audit_logs_entity ON audit_logs(entity_type, entity_id);
@@ -0,0 +1 @@
+ALTER TABLE back_pack_id_next_numbers RENAME TO backpack_id_next_numbers;
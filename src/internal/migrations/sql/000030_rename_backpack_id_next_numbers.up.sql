@@ -0,0 +1,2 @@
+-- Match the table name the BackPackIdNextNumber model uses
+ALTER TABLE backpack_id_next_numbers RENAME TO back_pack_id_next_numbers;
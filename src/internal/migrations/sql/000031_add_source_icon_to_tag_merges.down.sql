@@ -0,0 +1 @@
+ALTER TABLE tag_merges DROP COLUMN IF EXISTS source_icon;
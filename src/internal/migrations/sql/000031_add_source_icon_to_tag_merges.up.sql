@@ -0,0 +1,2 @@
+-- Keep the source tag's icon so restoring a merge brings it back
+ALTER TABLE tag_merges ADD COLUMN source_icon VARCHAR(50) NOT NULL DEFAULT '';
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_tags_organization_name;
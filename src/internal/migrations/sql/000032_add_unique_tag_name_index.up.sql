@@ -0,0 +1,13 @@
+-- Rename duplicate live tag names so the unique index can be created,
+-- keeping the oldest tag of each name untouched
+UPDATE tags t
+SET name = LEFT(t.name, 19 - LENGTH(t.id::text)) || '-' || t.id
+FROM tags o
+WHERE o.organization_id = t.organization_id
+  AND o.name = t.name
+  AND o.id < t.id
+  AND o.deleted_at IS NULL
+  AND t.deleted_at IS NULL;
+
+-- Tag names are unique per organization among non-deleted tags
+CREATE UNIQUE INDEX idx_tags_organization_name ON tags(organization_id, name) WHERE deleted_at IS NULL;
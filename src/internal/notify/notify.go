@@ -0,0 +1,158 @@
+package notify
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"backend/internal/config"
+
+	"go.uber.org/fx"
+)
+
+// Module provides notification dependency injection
+var Module = fx.Module("notify",
+	fx.Provide(NewMailer),
+)
+
+// Message represents an email message
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer sends email messages
+type Mailer interface {
+	Send(msg Message) error
+}
+
+// LogMailer logs who messages are addressed to instead of sending them (useful for
+// development). Bodies are never logged since they carry tokens.
+type LogMailer struct {
+	logger *slog.Logger
+}
+
+// NewLogMailer creates a mailer that writes to logger
+func NewLogMailer(logger *slog.Logger) *LogMailer {
+	return &LogMailer{logger: logger}
+}
+
+// SMTPTimeout bounds connecting to the SMTP server and delivering a message
+const SMTPTimeout = 30 * time.Second
+
+// SMTPMailer delivers messages through an SMTP server
+type SMTPMailer struct {
+	addr    string
+	host    string
+	auth    smtp.Auth
+	from    string
+	timeout time.Duration
+}
+
+// NewMailer creates an SMTP mailer when an SMTP host is configured and a log mailer
+// otherwise; config validation requires an SMTP host in production
+func NewMailer(cfg *config.Config, logger *slog.Logger) Mailer {
+	if cfg.SMTP.Host == "" {
+		return NewLogMailer(logger)
+	}
+	return NewSMTPMailer(cfg.SMTP)
+}
+
+// NewSMTPMailer creates a new SMTP mailer; authentication is skipped without a username
+func NewSMTPMailer(cfg config.SMTPConfig) *SMTPMailer {
+	mailer := &SMTPMailer{
+		addr:    net.JoinHostPort(cfg.Host, cfg.Port),
+		host:    cfg.Host,
+		from:    cfg.From,
+		timeout: SMTPTimeout,
+	}
+	if cfg.Username != "" {
+		mailer.auth = smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)
+	}
+	return mailer
+}
+
+// Send logs the recipient and subject of the message
+func (m *LogMailer) Send(msg Message) error {
+	m.logger.Info("email not sent, no SMTP server configured", "to", msg.To, "subject", msg.Subject)
+	return nil
+}
+
+// Send delivers the message through the SMTP server
+func (m *SMTPMailer) Send(msg Message) error {
+	if err := m.send(msg); err != nil {
+		return fmt.Errorf("failed to send email to %s: %w", msg.To, err)
+	}
+	return nil
+}
+
+// send delivers the message like smtp.SendMail, but with a deadline on the connection so
+// an unresponsive server can't block the sender forever
+func (m *SMTPMailer) send(msg Message) error {
+	conn, err := net.DialTimeout("tcp", m.addr, m.timeout)
+	if err != nil {
+		return err
+	}
+	if err := conn.SetDeadline(time.Now().Add(m.timeout)); err != nil {
+		conn.Close()
+		return err
+	}
+
+	client, err := smtp.NewClient(conn, m.host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: m.host}); err != nil {
+			return err
+		}
+	}
+	if m.auth != nil {
+		if ok, _ := client.Extension("AUTH"); !ok {
+			return errors.New("smtp: server doesn't support AUTH")
+		}
+		if err := client.Auth(m.auth); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(m.from); err != nil {
+		return err
+	}
+	if err := client.Rcpt(msg.To); err != nil {
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(formatMessage(m.from, msg)); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// formatMessage renders the message as a plain text email
+func formatMessage(from string, msg Message) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", msg.Subject)
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	return []byte(b.String())
+}
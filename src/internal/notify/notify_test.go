@@ -0,0 +1,158 @@
+package notify
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/logger"
+)
+
+func TestNewMailer_LogsWithoutSMTPHost(t *testing.T) {
+	mailer := NewMailer(&config.Config{}, logger.Discard())
+	if _, ok := mailer.(*LogMailer); !ok {
+		t.Errorf("Expected LogMailer without SMTP host, got %T", mailer)
+	}
+}
+
+func TestNewMailer_SMTP(t *testing.T) {
+	mailer := NewMailer(&config.Config{SMTP: config.SMTPConfig{Host: "smtp.example.com", Port: "2525", Username: "user", Password: "pass"}}, logger.Discard())
+	smtpMailer, ok := mailer.(*SMTPMailer)
+	if !ok {
+		t.Fatalf("Expected SMTPMailer with SMTP host, got %T", mailer)
+	}
+	if smtpMailer.addr != "smtp.example.com:2525" {
+		t.Errorf("Expected addr smtp.example.com:2525, got %s", smtpMailer.addr)
+	}
+	if smtpMailer.auth == nil {
+		t.Error("Expected authentication with a username set")
+	}
+}
+
+func TestFormatMessage(t *testing.T) {
+	raw := string(formatMessage("no-reply@example.com", Message{To: "user@example.com", Subject: "Hello", Body: "line one\nline two"}))
+
+	for _, expected := range []string{"From: no-reply@example.com\r\n", "To: user@example.com\r\n", "Subject: Hello\r\n", "\r\n\r\nline one\r\nline two"} {
+		if !strings.Contains(raw, expected) {
+			t.Errorf("Expected message to contain %q, got %q", expected, raw)
+		}
+	}
+}
+
+func TestLogMailer_OmitsBody(t *testing.T) {
+	var buf bytes.Buffer
+	mailer := NewLogMailer(logger.New(&buf, "info", false))
+	if err := mailer.Send(Message{To: "user@example.com", Subject: "Reset your password", Body: "token: secret-reset-token"}); err != nil {
+		t.Fatalf("Failed to send: %v", err)
+	}
+
+	if !strings.Contains(buf.String(), "user@example.com") {
+		t.Errorf("Expected the recipient to be logged, got: %s", buf.String())
+	}
+	if strings.Contains(buf.String(), "secret-reset-token") {
+		t.Errorf("Expected the body not to be logged, got: %s", buf.String())
+	}
+}
+
+// startFakeSMTPServer accepts one SMTP session and sends the received message data on the
+// returned channel
+func startFakeSMTPServer(t *testing.T) (string, <-chan string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	received := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		reader := bufio.NewReader(conn)
+		reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+		reply("220 localhost ESMTP")
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			switch command := strings.ToUpper(strings.TrimSpace(line)); {
+			case strings.HasPrefix(command, "EHLO"):
+				reply("250 localhost")
+			case command == "DATA":
+				reply("354 go ahead")
+				var data strings.Builder
+				for {
+					line, err := reader.ReadString('\n')
+					if err != nil || line == ".\r\n" {
+						break
+					}
+					data.WriteString(line)
+				}
+				received <- data.String()
+				reply("250 queued")
+			case command == "QUIT":
+				reply("221 bye")
+				return
+			default:
+				reply("250 OK")
+			}
+		}
+	}()
+
+	return listener.Addr().String(), received
+}
+
+func TestSMTPMailer_Send(t *testing.T) {
+	addr, received := startFakeSMTPServer(t)
+	host, port, _ := net.SplitHostPort(addr)
+	mailer := NewSMTPMailer(config.SMTPConfig{Host: host, Port: port, From: "no-reply@example.com"})
+
+	if err := mailer.Send(Message{To: "user@example.com", Subject: "Hello", Body: "Hi there"}); err != nil {
+		t.Fatalf("Failed to send: %v", err)
+	}
+
+	select {
+	case data := <-received:
+		if !strings.Contains(data, "Subject: Hello") || !strings.Contains(data, "Hi there") {
+			t.Errorf("Unexpected message data: %q", data)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the server to receive the message")
+	}
+}
+
+func TestSMTPMailer_TimesOut(t *testing.T) {
+	// The server accepts connections but never greets
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+	go func() {
+		conn, err := listener.Accept()
+		if err == nil {
+			defer conn.Close()
+			time.Sleep(5 * time.Second)
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	mailer := NewSMTPMailer(config.SMTPConfig{Host: host, Port: port})
+	mailer.timeout = 100 * time.Millisecond
+
+	start := time.Now()
+	if err := mailer.Send(Message{To: "user@example.com", Subject: "Hello"}); err == nil {
+		t.Fatal("Expected an error from an unresponsive server")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected Send to give up after the timeout, took %v", elapsed)
+	}
+}
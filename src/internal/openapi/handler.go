@@ -0,0 +1,59 @@
+package openapi
+
+import (
+	"embed"
+	"net/http"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Title and APIVersion identify the API in the generated document
+const (
+	Title      = "SchwiftyBox API"
+	APIVersion = "1.0.0"
+)
+
+// document builds the spec once; reflection over the operations is not free
+var document = sync.OnceValue(func() map[string]interface{} {
+	return Build(Title, APIVersion, Operations)
+})
+
+// Spec serves the OpenAPI 3 document as JSON
+func Spec(c *gin.Context) {
+	c.JSON(http.StatusOK, document())
+}
+
+// swaggerUI holds the Swagger UI files, embedded so the docs page works without
+// reaching a CDN
+//
+//go:embed swagger-ui/swagger-ui.css swagger-ui/swagger-ui-bundle.js
+var swaggerUI embed.FS
+
+// docsPage loads the embedded Swagger UI and points it at the spec
+const docsPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>SchwiftyBox API</title>
+  <link rel="stylesheet" href="/api/docs/assets/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="/api/docs/assets/swagger-ui-bundle.js"></script>
+  <script>
+    window.ui = SwaggerUIBundle({ url: "/api/openapi.json", dom_id: "#swagger-ui" });
+  </script>
+</body>
+</html>
+`
+
+// Docs serves a Swagger UI page for the spec
+func Docs(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(docsPage))
+}
+
+// DocsAsset serves the Swagger UI file named by the filepath parameter
+func DocsAsset(c *gin.Context) {
+	c.FileFromFS("swagger-ui"+c.Param("filepath"), http.FS(swaggerUI))
+}
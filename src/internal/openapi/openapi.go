@@ -0,0 +1,346 @@
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Version is the OpenAPI specification version of the generated document
+const Version = "3.0.3"
+
+// Schema is a JSON Schema object as used by OpenAPI 3
+type Schema map[string]interface{}
+
+// Param describes a path or query parameter of an operation; a Type of "[]integer" is a
+// repeatable query parameter
+type Param struct {
+	Name        string
+	In          string
+	Type        string
+	Description string
+	Required    bool
+}
+
+// Operation describes a single endpoint; Request and Responses hold zero values of the Go
+// types whose schemas are reflected into the document. Request bodies are JSON unless
+// RequestType names another media type, such as multipart/form-data for uploads.
+type Operation struct {
+	Method      string
+	Path        string
+	Tag         string
+	Summary     string
+	Auth        bool
+	Params      []Param
+	Request     interface{}
+	RequestType string
+	Responses   map[int]interface{}
+	Description string
+}
+
+// builder collects component schemas while operations are converted
+type builder struct {
+	schemas map[string]Schema
+	names   map[reflect.Type]string
+}
+
+// Build assembles an OpenAPI 3 document for the given operations
+func Build(title, version string, ops []Operation) map[string]interface{} {
+	b := &builder{
+		schemas: make(map[string]Schema),
+		names:   make(map[reflect.Type]string),
+	}
+
+	paths := make(map[string]map[string]interface{})
+	for _, op := range ops {
+		path := ginPathToOpenAPI(op.Path)
+		if paths[path] == nil {
+			paths[path] = make(map[string]interface{})
+		}
+		paths[path][strings.ToLower(op.Method)] = b.operation(op)
+	}
+
+	return map[string]interface{}{
+		"openapi": Version,
+		"info": map[string]interface{}{
+			"title":   title,
+			"version": version,
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": b.schemas,
+			"securitySchemes": map[string]interface{}{
+				"bearerAuth": map[string]interface{}{
+					"type":         "http",
+					"scheme":       "bearer",
+					"bearerFormat": "JWT",
+				},
+			},
+		},
+	}
+}
+
+// operation converts one Operation into an OpenAPI operation object
+func (b *builder) operation(op Operation) map[string]interface{} {
+	out := map[string]interface{}{
+		"summary": op.Summary,
+	}
+	if op.Description != "" {
+		out["description"] = op.Description
+	}
+	if op.Tag != "" {
+		out["tags"] = []string{op.Tag}
+	}
+	if op.Auth {
+		out["security"] = []map[string][]string{{"bearerAuth": {}}}
+	}
+
+	if len(op.Params) > 0 {
+		params := make([]map[string]interface{}, 0, len(op.Params))
+		for _, p := range op.Params {
+			param := map[string]interface{}{
+				"name":     p.Name,
+				"in":       p.In,
+				"required": p.Required || p.In == "path",
+				"schema":   paramSchema(p.Type),
+			}
+			if p.Description != "" {
+				param["description"] = p.Description
+			}
+			params = append(params, param)
+		}
+		out["parameters"] = params
+	}
+
+	if op.Request != nil {
+		requestType := op.RequestType
+		if requestType == "" {
+			requestType = "application/json"
+		}
+		out["requestBody"] = map[string]interface{}{
+			"required": true,
+			"content": map[string]interface{}{
+				requestType: map[string]interface{}{
+					"schema": b.schemaFor(reflect.TypeOf(op.Request)),
+				},
+			},
+		}
+	}
+
+	statuses := make([]int, 0, len(op.Responses))
+	for status := range op.Responses {
+		statuses = append(statuses, status)
+	}
+	sort.Ints(statuses)
+
+	responses := make(map[string]interface{}, len(statuses))
+	for _, status := range statuses {
+		response := map[string]interface{}{
+			"description": http.StatusText(status),
+		}
+		if body := op.Responses[status]; body != nil {
+			response["content"] = map[string]interface{}{
+				"application/json": map[string]interface{}{
+					"schema": b.schemaFor(reflect.TypeOf(body)),
+				},
+			}
+		}
+		responses[strconv.Itoa(status)] = response
+	}
+	out["responses"] = responses
+
+	return out
+}
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	deletedAtType = reflect.TypeOf(gorm.DeletedAt{})
+)
+
+// schemaFor returns the schema of t, registering named structs as components and
+// referencing them so recursive types (an item's parent and children) terminate
+func (b *builder) schemaFor(t reflect.Type) Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if override, ok := overrides[t]; ok {
+		schema := make(Schema, len(override))
+		for k, v := range override {
+			schema[k] = v
+		}
+		return schema
+	}
+
+	switch t {
+	case timeType, deletedAtType:
+		return Schema{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return Schema{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return Schema{"type": "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return Schema{"type": "integer", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		return Schema{"type": "number"}
+	case reflect.String:
+		return Schema{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return Schema{"type": "array", "items": b.schemaFor(t.Elem())}
+	case reflect.Map:
+		return Schema{"type": "object", "additionalProperties": b.schemaFor(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return b.structSchema(t)
+		}
+		return b.ref(t)
+	default:
+		return Schema{}
+	}
+}
+
+// ref registers a named struct as a component schema and returns a reference to it
+func (b *builder) ref(t reflect.Type) Schema {
+	name, ok := b.names[t]
+	if !ok {
+		name = t.Name()
+		if _, taken := b.schemas[name]; taken {
+			name = pkgName(t) + name
+		}
+		b.names[t] = name
+		// Reserve the name before descending so self-references resolve to it
+		b.schemas[name] = Schema{}
+		b.schemas[name] = b.structSchema(t)
+	}
+	return Schema{"$ref": "#/components/schemas/" + name}
+}
+
+// structSchema describes a struct's JSON fields, inlining embedded structs like encoding/json
+func (b *builder) structSchema(t reflect.Type) Schema {
+	properties := make(map[string]interface{})
+	var required []string
+	b.collectFields(t, properties, &required)
+
+	schema := Schema{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		sort.Strings(required)
+		schema["required"] = required
+	}
+	return schema
+}
+
+// collectFields adds t's exported fields to properties
+func (b *builder) collectFields(t reflect.Type, properties map[string]interface{}, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && field.Tag.Get("json") == "" && field.Type.Kind() == reflect.Struct {
+			b.collectFields(field.Type, properties, required)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		name, omitempty := parseJSONTag(field)
+		if name == "-" {
+			continue
+		}
+
+		schema := b.schemaFor(field.Type)
+		binding := strings.Split(field.Tag.Get("binding"), ",")
+		for _, rule := range binding {
+			applyBindingRule(schema, rule)
+		}
+		properties[name] = schema
+
+		if !omitempty && contains(binding, "required") {
+			*required = append(*required, name)
+		}
+	}
+}
+
+// applyBindingRule maps the validator rules the handlers use onto schema keywords
+func applyBindingRule(schema Schema, rule string) {
+	if _, isRef := schema["$ref"]; isRef {
+		return
+	}
+
+	key, value, _ := strings.Cut(rule, "=")
+	switch key {
+	case "email":
+		schema["format"] = "email"
+	case "min", "max":
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return
+		}
+		switch schema["type"] {
+		case "string":
+			schema[key+"Length"] = n
+		case "array":
+			schema[key+"Items"] = n
+		case "integer", "number":
+			schema[map[string]string{"min": "minimum", "max": "maximum"}[key]] = n
+		}
+	case "oneof":
+		schema["enum"] = strings.Fields(value)
+	}
+}
+
+// parseJSONTag returns the JSON property name of a field and whether it is omitempty
+func parseJSONTag(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	name, opts, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = field.Name
+	}
+	return name, strings.Contains(opts, "omitempty")
+}
+
+// paramSchema returns the schema of a parameter type, expanding "[]T" into an array of T
+func paramSchema(typ string) Schema {
+	if elem, ok := strings.CutPrefix(typ, "[]"); ok {
+		return Schema{"type": "array", "items": Schema{"type": elem}}
+	}
+	return Schema{"type": typ}
+}
+
+// ginPathToOpenAPI rewrites gin's :param segments as OpenAPI {param} templates
+func ginPathToOpenAPI(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// pkgName returns the last element of t's package path, capitalised for use as a prefix
+func pkgName(t reflect.Type) string {
+	pkg := t.PkgPath()
+	if i := strings.LastIndex(pkg, "/"); i >= 0 {
+		pkg = pkg[i+1:]
+	}
+	if pkg == "" {
+		return ""
+	}
+	return strings.ToUpper(pkg[:1]) + pkg[1:]
+}
+
+func contains(values []string, want string) bool {
+	for _, v := range values {
+		if v == want {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,113 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type testParent struct {
+	ID       uint         `json:"id"`
+	Name     string       `json:"name" binding:"required,max=20"`
+	Email    string       `json:"email" binding:"omitempty,email"`
+	Hidden   string       `json:"-"`
+	Parent   *testParent  `json:"parent"`
+	Children []testParent `json:"children"`
+	testEmbedded
+}
+
+type testEmbedded struct {
+	Note string `json:"note"`
+}
+
+func TestBuild_ReflectsRequestSchema(t *testing.T) {
+	doc := Build("Test", "1", []Operation{{
+		Method:    http.MethodPost,
+		Path:      "/api/things/:thing_id",
+		Summary:   "Create a thing",
+		Auth:      true,
+		Params:    []Param{{Name: "thing_id", In: "path", Type: "integer"}},
+		Request:   testParent{},
+		Responses: map[int]interface{}{http.StatusNoContent: nil},
+	}})
+
+	data, err := json.Marshal(doc)
+	assert.NoError(t, err)
+
+	var out struct {
+		Paths map[string]map[string]struct {
+			Security    []map[string][]string `json:"security"`
+			Parameters  []map[string]interface{}
+			RequestBody struct {
+				Content map[string]struct {
+					Schema map[string]string `json:"schema"`
+				} `json:"content"`
+			} `json:"requestBody"`
+			Responses map[string]interface{} `json:"responses"`
+		} `json:"paths"`
+		Components struct {
+			Schemas map[string]struct {
+				Required   []string                          `json:"required"`
+				Properties map[string]map[string]interface{} `json:"properties"`
+			} `json:"schemas"`
+		} `json:"components"`
+	}
+	assert.NoError(t, json.Unmarshal(data, &out))
+
+	op := out.Paths["/api/things/{thing_id}"]["post"]
+	assert.Equal(t, "#/components/schemas/testParent", op.RequestBody.Content["application/json"].Schema["$ref"])
+	assert.Equal(t, true, op.Parameters[0]["required"])
+	assert.Contains(t, op.Security[0], "bearerAuth")
+	assert.Contains(t, op.Responses, "204")
+
+	schema := out.Components.Schemas["testParent"]
+	assert.Equal(t, []string{"name"}, schema.Required)
+	assert.Equal(t, float64(20), schema.Properties["name"]["maxLength"])
+	assert.Equal(t, "email", schema.Properties["email"]["format"])
+	assert.Equal(t, "#/components/schemas/testParent", schema.Properties["parent"]["$ref"])
+	assert.Contains(t, schema.Properties, "note")
+	assert.NotContains(t, schema.Properties, "Hidden")
+}
+
+func TestSpec_CoversItemAndAuthEndpoints(t *testing.T) {
+	data, err := json.Marshal(document())
+	assert.NoError(t, err)
+
+	var out struct {
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	assert.NoError(t, json.Unmarshal(data, &out))
+
+	for _, path := range []string{"/api/items", "/api/items/{item_id}", "/api/token", "/api/token/refresh", "/api/users"} {
+		assert.Contains(t, out.Paths, path)
+	}
+	assert.Contains(t, out.Paths["/api/items"], "post")
+	assert.Contains(t, out.Paths["/api/items/{item_id}"], "delete")
+}
+
+func TestDocs_ServesEmbeddedSwaggerUI(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/api/docs", Docs)
+	router.GET("/api/docs/assets/*filepath", DocsAsset)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/docs", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "https://")
+
+	for _, asset := range []string{"swagger-ui.css", "swagger-ui-bundle.js"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/docs/assets/"+asset, nil))
+		assert.Equal(t, http.StatusOK, w.Code, asset)
+		assert.NotZero(t, w.Body.Len(), asset)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/docs/assets/missing.js", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
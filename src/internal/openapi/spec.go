@@ -0,0 +1,795 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"time"
+
+	"backend/internal/database"
+	"backend/internal/handlers"
+	"backend/internal/item"
+	"backend/internal/jwt"
+	"backend/internal/tag"
+)
+
+// overrides describes types whose JSON form is not their struct layout
+var overrides = map[reflect.Type]Schema{
+	// Tags are referenced by numeric ID or by name
+	reflect.TypeOf(handlers.TagRef{}): {
+		"oneOf": []Schema{{"type": "integer", "minimum": 0}, {"type": "string"}},
+	},
+	// Raw JSON is passed through as-is
+	reflect.TypeOf(json.RawMessage{}): {},
+	// Uploaded files are sent as multipart file parts
+	reflect.TypeOf(binaryFile("")): {"type": "string", "format": "binary"},
+}
+
+// binaryFile stands for a file part of a multipart form
+type binaryFile string
+
+// Response bodies that handlers build inline with gin.H
+type (
+	messageResponse struct {
+		Message string `json:"message"`
+	}
+	verifyResponse struct {
+		Valid     bool      `json:"valid"`
+		Email     string    `json:"email"`
+		TokenType string    `json:"token_type"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	itemsResponse struct {
+		Items []database.Item `json:"items"`
+	}
+	itemTreeResponse struct {
+		Items []item.TreeNode `json:"items"`
+	}
+	existsResponse struct {
+		Exists bool `json:"exists"`
+	}
+	historyResponse struct {
+		History []database.AuditLog `json:"history"`
+	}
+	tokenPairRequest struct {
+		Email string `json:"email" binding:"required,email"`
+	}
+	statusResponse struct {
+		Status string `json:"status"`
+		Error  string `json:"error,omitempty"`
+	}
+	totalResponse struct {
+		Total int64 `json:"total"`
+	}
+	countResponse struct {
+		Count int64 `json:"count"`
+	}
+	assignResponse struct {
+		OrganizationID uint  `json:"organization_id"`
+		Updated        int64 `json:"updated"`
+	}
+	memberResponse struct {
+		OrganizationID uint   `json:"organization_id"`
+		Email          string `json:"email"`
+	}
+	activeOrganizationResponse struct {
+		ActiveOrganizationID uint `json:"active_organization_id"`
+	}
+	searchResponse struct {
+		Items []database.Item `json:"items"`
+		Tags  []database.Tag  `json:"tags"`
+	}
+	tagColorsResponse struct {
+		Colors []handlers.TagColorCount `json:"colors"`
+	}
+	containersResponse struct {
+		Containers []handlers.ContainerCount `json:"containers"`
+	}
+	uploadForm struct {
+		File binaryFile `json:"file" binding:"required"`
+	}
+	uploadResponse struct {
+		URL         string `json:"url"`
+		ContentType string `json:"content_type"`
+		Size        int64  `json:"size"`
+	}
+	batchUsersResponse struct {
+		Created int                        `json:"created"`
+		Failed  int                        `json:"failed"`
+		Results []handlers.BatchUserResult `json:"results"`
+	}
+	usersPageResponse struct {
+		Users    []handlers.UserResponse `json:"users"`
+		Page     int                     `json:"page"`
+		PageSize int                     `json:"page_size"`
+		Total    int64                   `json:"total"`
+	}
+)
+
+var (
+	itemIDParam = Param{Name: "item_id", In: "path", Type: "integer", Description: "Item ID"}
+	tagIDParam  = Param{Name: "tag_id", In: "path", Type: "integer", Description: "Tag ID"}
+	orgIDParam  = Param{Name: "org_id", In: "path", Type: "integer", Description: "Organization ID"}
+	emailParam  = Param{Name: "email", In: "path", Type: "string", Description: "User email"}
+)
+
+// Operations lists the documented endpoints; a server package test fails when it falls out
+// of step with the registered routes
+var Operations = []Operation{
+	// Health
+	{
+		Method: http.MethodGet, Path: "/health", Tag: "health", Summary: "Liveness check",
+		Responses: map[int]interface{}{
+			http.StatusOK: statusResponse{},
+		},
+	},
+	{
+		Method: http.MethodGet, Path: "/ready", Tag: "health", Summary: "Readiness check",
+		Description: "Fails with 503 while the database is unreachable.",
+		Responses: map[int]interface{}{
+			http.StatusOK:                 statusResponse{},
+			http.StatusServiceUnavailable: statusResponse{},
+		},
+	},
+
+	// Authentication
+	{
+		Method: http.MethodPost, Path: "/api/users", Tag: "auth", Summary: "Register a user",
+		Request: handlers.RegisterRequest{},
+		Responses: map[int]interface{}{
+			http.StatusCreated:    messageResponse{},
+			http.StatusBadRequest: handlers.APIError{},
+			http.StatusConflict:   handlers.APIError{},
+		},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/token", Tag: "auth", Summary: "Log in with email and password",
+		Request: handlers.LoginRequest{},
+		Responses: map[int]interface{}{
+			http.StatusOK:           jwt.TokenResponse{},
+			http.StatusBadRequest:   handlers.APIError{},
+			http.StatusUnauthorized: handlers.APIError{},
+			http.StatusForbidden:    handlers.APIError{},
+		},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/token/refresh", Tag: "auth", Summary: "Exchange a refresh token for a new token pair",
+		Request: handlers.RefreshRequest{},
+		Responses: map[int]interface{}{
+			http.StatusOK:           jwt.TokenResponse{},
+			http.StatusBadRequest:   handlers.APIError{},
+			http.StatusUnauthorized: handlers.APIError{},
+		},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/token/pair", Tag: "auth", Summary: "Issue a token pair for a user (admins only)", Auth: true,
+		Request: tokenPairRequest{},
+		Responses: map[int]interface{}{
+			http.StatusOK:           jwt.TokenResponse{},
+			http.StatusBadRequest:   handlers.APIError{},
+			http.StatusUnauthorized: handlers.APIError{},
+			http.StatusForbidden:    handlers.APIError{},
+			http.StatusNotFound:     handlers.APIError{},
+		},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/token/verify", Tag: "auth", Summary: "Verify an access token",
+		Request: handlers.VerifyRequest{},
+		Responses: map[int]interface{}{
+			http.StatusOK:           verifyResponse{},
+			http.StatusBadRequest:   handlers.APIError{},
+			http.StatusUnauthorized: handlers.APIError{},
+		},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/token/introspect", Tag: "auth", Summary: "Verify an access or refresh token",
+		Description: "Refresh tokens are only valid until they are rotated or revoked.",
+		Request:     handlers.VerifyRequest{},
+		Responses: map[int]interface{}{
+			http.StatusOK:           verifyResponse{},
+			http.StatusBadRequest:   handlers.APIError{},
+			http.StatusUnauthorized: handlers.APIError{},
+		},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/logout", Tag: "auth", Summary: "Revoke a refresh token",
+		Description: "The access token from the Authorization header is revoked too when one is sent.",
+		Request:     handlers.RefreshRequest{},
+		Responses: map[int]interface{}{
+			http.StatusOK:           messageResponse{},
+			http.StatusBadRequest:   handlers.APIError{},
+			http.StatusUnauthorized: handlers.APIError{},
+		},
+	},
+
+	// Users
+	{
+		Method: http.MethodGet, Path: "/api/users", Tag: "users", Summary: "Count registered users",
+		Responses: map[int]interface{}{
+			http.StatusOK: totalResponse{},
+		},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/users/reset-password", Tag: "users", Summary: "Email a password reset token",
+		Description: "The response is the same whether or not the account exists.",
+		Request:     handlers.PasswordResetRequest{},
+		Responses: map[int]interface{}{
+			http.StatusOK:         messageResponse{},
+			http.StatusBadRequest: handlers.APIError{},
+		},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/users/send-password", Tag: "users", Summary: "Set a new password with a reset token",
+		Description: "All of the user's refresh tokens are revoked.",
+		Request:     handlers.NewPasswordRequest{},
+		Responses: map[int]interface{}{
+			http.StatusOK:         messageResponse{},
+			http.StatusBadRequest: handlers.APIError{},
+		},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/users/verify", Tag: "users", Summary: "Verify an email address",
+		Params: []Param{{Name: "token", In: "query", Type: "string", Required: true, Description: "Verification token from the email"}},
+		Responses: map[int]interface{}{
+			http.StatusOK:         messageResponse{},
+			http.StatusBadRequest: handlers.APIError{},
+		},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/users/deactivate", Tag: "users", Summary: "Deactivate the current user", Auth: true,
+		Description: "Logging in is blocked and all refresh tokens are revoked until an admin reactivates the account.",
+		Responses: map[int]interface{}{
+			http.StatusOK:           messageResponse{},
+			http.StatusUnauthorized: handlers.APIError{},
+			http.StatusNotFound:     handlers.APIError{},
+		},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/users/password", Tag: "users", Summary: "Change the current user's password", Auth: true,
+		Description: "All of the user's refresh tokens are revoked.",
+		Request:     handlers.ChangePasswordRequest{},
+		Responses: map[int]interface{}{
+			http.StatusOK:           messageResponse{},
+			http.StatusBadRequest:   handlers.APIError{},
+			http.StatusUnauthorized: handlers.APIError{},
+		},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/users/me", Tag: "users", Summary: "Get the current user", Auth: true,
+		Responses: map[int]interface{}{
+			http.StatusOK:           handlers.CurrentUserResponse{},
+			http.StatusUnauthorized: handlers.APIError{},
+			http.StatusNotFound:     handlers.APIError{},
+		},
+	},
+	{
+		Method: http.MethodPatch, Path: "/api/users/me", Tag: "users", Summary: "Update the current user's profile", Auth: true,
+		Request: handlers.ProfileUpdateRequest{},
+		Responses: map[int]interface{}{
+			http.StatusOK:           handlers.UserResponse{},
+			http.StatusBadRequest:   handlers.APIError{},
+			http.StatusUnauthorized: handlers.APIError{},
+			http.StatusNotFound:     handlers.APIError{},
+		},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/users/me/full", Tag: "users", Summary: "Get the current user with their organizations", Auth: true,
+		Responses: map[int]interface{}{
+			http.StatusOK:           handlers.FullProfileResponse{},
+			http.StatusUnauthorized: handlers.APIError{},
+			http.StatusNotFound:     handlers.APIError{},
+		},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/users/me/sessions/count", Tag: "users", Summary: "Count the current user's active sessions", Auth: true,
+		Responses: map[int]interface{}{
+			http.StatusOK:           countResponse{},
+			http.StatusUnauthorized: handlers.APIError{},
+		},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/users/me/prefix", Tag: "users", Summary: "Get the current user's backpack prefix", Auth: true,
+		Responses: map[int]interface{}{
+			http.StatusOK:           handlers.PrefixResponse{},
+			http.StatusUnauthorized: handlers.APIError{},
+			http.StatusNotFound:     handlers.APIError{},
+		},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/users/me/prefix", Tag: "users", Summary: "Assign the current user a new backpack prefix", Auth: true,
+		Responses: map[int]interface{}{
+			http.StatusOK:           handlers.PrefixResponse{},
+			http.StatusUnauthorized: handlers.APIError{},
+			http.StatusNotFound:     handlers.APIError{},
+			http.StatusConflict:     handlers.APIError{},
+		},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/users/:email", Tag: "users", Summary: "Get a user (self or admins)", Auth: true,
+		Params: []Param{emailParam},
+		Responses: map[int]interface{}{
+			http.StatusOK:           handlers.UserResponse{},
+			http.StatusUnauthorized: handlers.APIError{},
+			http.StatusForbidden:    handlers.APIError{},
+			http.StatusNotFound:     handlers.APIError{},
+		},
+	},
+	{
+		Method: http.MethodPut, Path: "/api/users/:email", Tag: "users", Summary: "Update a user (self or admins)", Auth: true,
+		Params:  []Param{emailParam},
+		Request: handlers.UserUpdateRequest{},
+		Responses: map[int]interface{}{
+			http.StatusOK:           handlers.UserResponse{},
+			http.StatusBadRequest:   handlers.APIError{},
+			http.StatusUnauthorized: handlers.APIError{},
+			http.StatusForbidden:    handlers.APIError{},
+			http.StatusNotFound:     handlers.APIError{},
+		},
+	},
+	{
+		Method: http.MethodDelete, Path: "/api/users/:email", Tag: "users", Summary: "Delete a user (self or admins)", Auth: true,
+		Params: []Param{emailParam},
+		Responses: map[int]interface{}{
+			http.StatusNoContent:    nil,
+			http.StatusUnauthorized: handlers.APIError{},
+			http.StatusForbidden:    handlers.APIError{},
+			http.StatusNotFound:     handlers.APIError{},
+		},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/users/me/items/assign-org", Tag: "users", Summary: "Move all of the current user's items to an organization", Auth: true,
+		Request: handlers.AssignOrganizationRequest{},
+		Responses: map[int]interface{}{
+			http.StatusOK:           assignResponse{},
+			http.StatusBadRequest:   handlers.APIError{},
+			http.StatusUnauthorized: handlers.APIError{},
+			http.StatusForbidden:    handlers.APIError{},
+			http.StatusNotFound:     handlers.APIError{},
+		},
+	},
+
+	// Items
+	{
+		Method: http.MethodGet, Path: "/api/items", Tag: "items", Summary: "List items", Auth: true,
+		Params: []Param{
+			{Name: "sort", In: "query", Type: "string", Description: "name, added_at or backpack_id, prefixed with - for descending (default -added_at)"},
+			{Name: "scope", In: "query", Type: "string", Description: "user (default) or org for the active organization's items"},
+			{Name: "name", In: "query", Type: "string", Description: "Case-insensitive name substring"},
+			{Name: "quantity_lt", In: "query", Type: "integer", Description: "Only items with a quantity below this value"},
+			{Name: "quantity_gt", In: "query", Type: "integer", Description: "Only items with a quantity above this value"},
+			{Name: "tags", In: "query", Type: "[]integer", Description: "Tag IDs to filter by"},
+			{Name: "match", In: "query", Type: "string", Description: "any (default) or all of the given tags"},
+		},
+		Responses: map[int]interface{}{
+			http.StatusOK:           itemsResponse{},
+			http.StatusBadRequest:   handlers.APIError{},
+			http.StatusUnauthorized: handlers.APIError{},
+		},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/items", Tag: "items", Summary: "Create an item", Auth: true,
+		Description: "The backpack ID is assigned from the user's prefix.",
+		Request:     handlers.ItemCreateRequest{},
+		Responses: map[int]interface{}{
+			http.StatusCreated:      database.Item{},
+			http.StatusBadRequest:   handlers.APIError{},
+			http.StatusUnauthorized: handlers.APIError{},
+			http.StatusForbidden:    handlers.APIError{},
+			http.StatusConflict:     handlers.APIError{},
+		},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/items/bulk", Tag: "items", Summary: "Create up to 500 items at once", Auth: true,
+		Description: "Items are created in one transaction. If any entry is invalid nothing is created and the error details name its index.",
+		Request:     handlers.ItemBulkCreateRequest{},
+		Responses: map[int]interface{}{
+			http.StatusCreated:      itemsResponse{},
+			http.StatusBadRequest:   handlers.APIError{},
+			http.StatusUnauthorized: handlers.APIError{},
+			http.StatusForbidden:    handlers.APIError{},
+			http.StatusConflict:     handlers.APIError{},
+		},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/items/backpack-id-exists", Tag: "items", Summary: "Check whether a backpack ID is in use", Auth: true,
+		Params: []Param{{Name: "id", In: "query", Type: "string", Required: true, Description: "Backpack ID"}},
+		Responses: map[int]interface{}{
+			http.StatusOK:           existsResponse{},
+			http.StatusBadRequest:   handlers.APIError{},
+			http.StatusUnauthorized: handlers.APIError{},
+		},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/items/deleted", Tag: "items", Summary: "List soft-deleted items", Auth: true,
+		Params: []Param{{Name: "since", In: "query", Type: "string", Description: "Only items deleted after this RFC 3339 timestamp"}},
+		Responses: map[int]interface{}{
+			http.StatusOK:           itemsResponse{},
+			http.StatusBadRequest:   handlers.APIError{},
+			http.StatusUnauthorized: handlers.APIError{},
+		},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/items/tree", Tag: "items", Summary: "Get the item hierarchy", Auth: true,
+		Responses: map[int]interface{}{
+			http.StatusOK:           itemTreeResponse{},
+			http.StatusUnauthorized: handlers.APIError{},
+		},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/items/:item_id", Tag: "items", Summary: "Get an item", Auth: true,
+		Params: []Param{itemIDParam},
+		Responses: map[int]interface{}{
+			http.StatusOK:           database.Item{},
+			http.StatusBadRequest:   handlers.APIError{},
+			http.StatusUnauthorized: handlers.APIError{},
+			http.StatusNotFound:     handlers.APIError{},
+		},
+	},
+	{
+		Method: http.MethodPut, Path: "/api/items/:item_id", Tag: "items", Summary: "Update an item", Auth: true,
+		Params:  []Param{itemIDParam, {Name: "create_missing_tags", In: "query", Type: "boolean", Description: "Create tags referenced by name that do not exist"}},
+		Request: handlers.ItemUpdateRequest{},
+		Responses: map[int]interface{}{
+			http.StatusOK:           database.Item{},
+			http.StatusBadRequest:   handlers.APIError{},
+			http.StatusUnauthorized: handlers.APIError{},
+			http.StatusNotFound:     handlers.APIError{},
+			http.StatusConflict:     handlers.APIError{},
+		},
+	},
+	{
+		Method: http.MethodPatch, Path: "/api/items/:item_id", Tag: "items", Summary: "Partially update an item", Auth: true,
+		Params:  []Param{itemIDParam, {Name: "create_missing_tags", In: "query", Type: "boolean", Description: "Create tags referenced by name that do not exist"}},
+		Request: handlers.ItemUpdateRequest{},
+		Responses: map[int]interface{}{
+			http.StatusOK:           database.Item{},
+			http.StatusBadRequest:   handlers.APIError{},
+			http.StatusUnauthorized: handlers.APIError{},
+			http.StatusNotFound:     handlers.APIError{},
+			http.StatusConflict:     handlers.APIError{},
+		},
+	},
+	{
+		Method: http.MethodDelete, Path: "/api/items/:item_id", Tag: "items", Summary: "Delete an item", Auth: true,
+		Params: []Param{
+			itemIDParam,
+			{Name: "cascade", In: "query", Type: "boolean", Description: "Delete the whole subtree instead of moving children up"},
+			{Name: "hard", In: "query", Type: "boolean", Description: "Delete permanently instead of soft-deleting"},
+		},
+		Responses: map[int]interface{}{
+			http.StatusNoContent:    nil,
+			http.StatusBadRequest:   handlers.APIError{},
+			http.StatusUnauthorized: handlers.APIError{},
+		},
+	},
+	{
+		Method: http.MethodPatch, Path: "/api/items/:item_id/move", Tag: "items", Summary: "Move an item under another parent", Auth: true,
+		Params:  []Param{itemIDParam},
+		Request: handlers.ItemMoveRequest{},
+		Responses: map[int]interface{}{
+			http.StatusOK:           database.Item{},
+			http.StatusBadRequest:   handlers.APIError{},
+			http.StatusUnauthorized: handlers.APIError{},
+			http.StatusNotFound:     handlers.APIError{},
+			http.StatusConflict:     handlers.APIError{},
+		},
+	},
+	{
+		Method: http.MethodPatch, Path: "/api/items/:item_id/quantity", Tag: "items", Summary: "Adjust an item's quantity by a delta", Auth: true,
+		Description: "The adjustment is atomic and rejected with 400 if it would leave a negative quantity.",
+		Params:      []Param{itemIDParam},
+		Request:     handlers.ItemQuantityRequest{},
+		Responses: map[int]interface{}{
+			http.StatusOK:           database.Item{},
+			http.StatusBadRequest:   handlers.APIError{},
+			http.StatusUnauthorized: handlers.APIError{},
+			http.StatusNotFound:     handlers.APIError{},
+		},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/items/:item_id/qr", Tag: "items", Summary: "Get a PNG QR code of an item's backpack ID", Auth: true,
+		Params: []Param{itemIDParam, {Name: "size", In: "query", Type: "integer", Description: "Image size in pixels, 64 to 1024 (default 256)"}},
+		Responses: map[int]interface{}{
+			http.StatusOK:           nil,
+			http.StatusBadRequest:   handlers.APIError{},
+			http.StatusUnauthorized: handlers.APIError{},
+			http.StatusNotFound:     handlers.APIError{},
+		},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/items/:item_id/history", Tag: "items", Summary: "Get an item's audit log", Auth: true,
+		Description: "Entries are oldest first; soft-deleted items keep their history.",
+		Params:      []Param{itemIDParam},
+		Responses: map[int]interface{}{
+			http.StatusOK:           historyResponse{},
+			http.StatusBadRequest:   handlers.APIError{},
+			http.StatusUnauthorized: handlers.APIError{},
+			http.StatusNotFound:     handlers.APIError{},
+		},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/items/:item_id/restore", Tag: "items", Summary: "Restore a soft-deleted item", Auth: true,
+		Params: []Param{itemIDParam},
+		Responses: map[int]interface{}{
+			http.StatusOK:           database.Item{},
+			http.StatusBadRequest:   handlers.APIError{},
+			http.StatusUnauthorized: handlers.APIError{},
+			http.StatusForbidden:    handlers.APIError{},
+			http.StatusNotFound:     handlers.APIError{},
+		},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/items/:item_id/attachments", Tag: "items", Summary: "Attach a file URL to an item", Auth: true,
+		Description: "The URL is a path returned by POST /api/uploads or an absolute http(s) URL. Without a content type one is guessed from the file extension.",
+		Params:      []Param{itemIDParam},
+		Request:     handlers.ItemAttachmentRequest{},
+		Responses: map[int]interface{}{
+			http.StatusCreated:      database.ItemAttachment{},
+			http.StatusBadRequest:   handlers.APIError{},
+			http.StatusUnauthorized: handlers.APIError{},
+			http.StatusNotFound:     handlers.APIError{},
+		},
+	},
+	{
+		Method: http.MethodDelete, Path: "/api/items/:item_id/attachments/:attachment_id", Tag: "items", Summary: "Remove an attachment from an item", Auth: true,
+		Params: []Param{itemIDParam, {Name: "attachment_id", In: "path", Type: "integer", Description: "Attachment ID"}},
+		Responses: map[int]interface{}{
+			http.StatusNoContent:    nil,
+			http.StatusBadRequest:   handlers.APIError{},
+			http.StatusUnauthorized: handlers.APIError{},
+			http.StatusNotFound:     handlers.APIError{},
+		},
+	},
+
+	// Tags
+	{
+		Method: http.MethodGet, Path: "/api/tags", Tag: "tags", Summary: "List the active organization's tags with usage counts", Auth: true,
+		Params: []Param{{Name: "sort", In: "query", Type: "string", Description: "name or created_at, prefixed with - for descending (default name)"}},
+		Responses: map[int]interface{}{
+			http.StatusOK:           []tag.TagUsage{},
+			http.StatusBadRequest:   handlers.APIError{},
+			http.StatusUnauthorized: handlers.APIError{},
+		},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/tags", Tag: "tags", Summary: "Create a tag", Auth: true,
+		Description: "The Location header points to the new tag.",
+		Request:     handlers.TagCreateRequest{},
+		Responses: map[int]interface{}{
+			http.StatusCreated:      database.Tag{},
+			http.StatusBadRequest:   handlers.APIError{},
+			http.StatusUnauthorized: handlers.APIError{},
+			http.StatusConflict:     handlers.APIError{},
+		},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/tags/:tag_id", Tag: "tags", Summary: "Get a tag", Auth: true,
+		Params: []Param{tagIDParam},
+		Responses: map[int]interface{}{
+			http.StatusOK:           database.Tag{},
+			http.StatusBadRequest:   handlers.APIError{},
+			http.StatusUnauthorized: handlers.APIError{},
+			http.StatusNotFound:     handlers.APIError{},
+		},
+	},
+	{
+		Method: http.MethodPut, Path: "/api/tags/:tag_id", Tag: "tags", Summary: "Update a tag", Auth: true,
+		Params:  []Param{tagIDParam},
+		Request: handlers.TagUpdateRequest{},
+		Responses: map[int]interface{}{
+			http.StatusOK:           database.Tag{},
+			http.StatusBadRequest:   handlers.APIError{},
+			http.StatusUnauthorized: handlers.APIError{},
+			http.StatusNotFound:     handlers.APIError{},
+			http.StatusConflict:     handlers.APIError{},
+		},
+	},
+	{
+		Method: http.MethodDelete, Path: "/api/tags/:tag_id", Tag: "tags", Summary: "Delete a tag", Auth: true,
+		Params: []Param{tagIDParam, {Name: "hard", In: "query", Type: "boolean", Description: "Delete permanently instead of soft-deleting"}},
+		Responses: map[int]interface{}{
+			http.StatusNoContent:    nil,
+			http.StatusBadRequest:   handlers.APIError{},
+			http.StatusUnauthorized: handlers.APIError{},
+			http.StatusNotFound:     handlers.APIError{},
+		},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/tags/:tag_id/merge", Tag: "tags", Summary: "Merge a tag into another", Auth: true,
+		Description: "Items carrying the tag get the target tag instead and the tag is deleted. The merge can be undone with restore-merge.",
+		Params:      []Param{tagIDParam},
+		Request:     handlers.TagMergeRequest{},
+		Responses: map[int]interface{}{
+			http.StatusOK:           database.TagMerge{},
+			http.StatusBadRequest:   handlers.APIError{},
+			http.StatusUnauthorized: handlers.APIError{},
+			http.StatusForbidden:    handlers.APIError{},
+			http.StatusNotFound:     handlers.APIError{},
+		},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/tags/restore-merge/:merge_id", Tag: "tags", Summary: "Undo a recent tag merge", Auth: true,
+		Description: "Fails with 409 if the merge was already restored or the tag's name has been reused, and with 410 once the merge is too old.",
+		Params:      []Param{{Name: "merge_id", In: "path", Type: "integer", Description: "Tag merge ID"}},
+		Responses: map[int]interface{}{
+			http.StatusOK:           database.Tag{},
+			http.StatusBadRequest:   handlers.APIError{},
+			http.StatusUnauthorized: handlers.APIError{},
+			http.StatusNotFound:     handlers.APIError{},
+			http.StatusConflict:     handlers.APIError{},
+			http.StatusGone:         handlers.APIError{},
+		},
+	},
+
+	// Organizations
+	{
+		Method: http.MethodGet, Path: "/api/organizations", Tag: "organizations", Summary: "List the current user's organizations", Auth: true,
+		Responses: map[int]interface{}{
+			http.StatusOK:           []handlers.ProfileOrganization{},
+			http.StatusUnauthorized: handlers.APIError{},
+			http.StatusNotFound:     handlers.APIError{},
+		},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/organizations", Tag: "organizations", Summary: "Create an organization owned by the current user", Auth: true,
+		Description: "The new organization becomes the active one.",
+		Request:     handlers.OrganizationCreateRequest{},
+		Responses: map[int]interface{}{
+			http.StatusCreated:      handlers.ProfileOrganization{},
+			http.StatusBadRequest:   handlers.APIError{},
+			http.StatusUnauthorized: handlers.APIError{},
+			http.StatusConflict:     handlers.APIError{},
+		},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/organizations/:org_id/members", Tag: "organizations", Summary: "Add a member (owners and admins)", Auth: true,
+		Params:  []Param{orgIDParam},
+		Request: handlers.OrganizationMemberRequest{},
+		Responses: map[int]interface{}{
+			http.StatusOK:           memberResponse{},
+			http.StatusBadRequest:   handlers.APIError{},
+			http.StatusUnauthorized: handlers.APIError{},
+			http.StatusForbidden:    handlers.APIError{},
+			http.StatusNotFound:     handlers.APIError{},
+		},
+	},
+	{
+		Method: http.MethodPut, Path: "/api/organizations/:org_id/active", Tag: "organizations", Summary: "Switch the current user's active organization", Auth: true,
+		Params: []Param{orgIDParam},
+		Responses: map[int]interface{}{
+			http.StatusOK:           activeOrganizationResponse{},
+			http.StatusBadRequest:   handlers.APIError{},
+			http.StatusUnauthorized: handlers.APIError{},
+			http.StatusForbidden:    handlers.APIError{},
+			http.StatusNotFound:     handlers.APIError{},
+		},
+	},
+	{
+		Method: http.MethodDelete, Path: "/api/organizations/:org_id", Tag: "organizations", Summary: "Delete an organization (owners)", Auth: true,
+		Description: "Fails with 409 if it is the only organization of one of its members.",
+		Params:      []Param{orgIDParam},
+		Responses: map[int]interface{}{
+			http.StatusNoContent:    nil,
+			http.StatusBadRequest:   handlers.APIError{},
+			http.StatusUnauthorized: handlers.APIError{},
+			http.StatusForbidden:    handlers.APIError{},
+			http.StatusNotFound:     handlers.APIError{},
+			http.StatusConflict:     handlers.APIError{},
+		},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/organizations/:org_id/invitations", Tag: "organizations", Summary: "Invite someone by email (owners and admins)", Auth: true,
+		Params:  []Param{orgIDParam},
+		Request: handlers.OrganizationInvitationRequest{},
+		Responses: map[int]interface{}{
+			http.StatusCreated:      database.OrgInvitation{},
+			http.StatusBadRequest:   handlers.APIError{},
+			http.StatusUnauthorized: handlers.APIError{},
+			http.StatusForbidden:    handlers.APIError{},
+			http.StatusNotFound:     handlers.APIError{},
+		},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/organizations/:org_id/members/:email/reactivate", Tag: "organizations", Summary: "Reactivate a deactivated member (admins only)", Auth: true,
+		Description: "Reactivation lifts the deactivation for the whole account, not just this organization.",
+		Params:      []Param{orgIDParam, emailParam},
+		Responses: map[int]interface{}{
+			http.StatusOK:           handlers.UserResponse{},
+			http.StatusBadRequest:   handlers.APIError{},
+			http.StatusUnauthorized: handlers.APIError{},
+			http.StatusForbidden:    handlers.APIError{},
+			http.StatusNotFound:     handlers.APIError{},
+		},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/invitations/:token/accept", Tag: "organizations", Summary: "Accept an invitation", Auth: true,
+		Description: "Must be called by the invited user. Fails with 409 if already accepted and with 410 once expired.",
+		Params:      []Param{{Name: "token", In: "path", Type: "string", Description: "Invitation token"}},
+		Responses: map[int]interface{}{
+			http.StatusOK:           database.OrgInvitation{},
+			http.StatusUnauthorized: handlers.APIError{},
+			http.StatusForbidden:    handlers.APIError{},
+			http.StatusNotFound:     handlers.APIError{},
+			http.StatusConflict:     handlers.APIError{},
+			http.StatusGone:         handlers.APIError{},
+		},
+	},
+
+	// Search and statistics
+	{
+		Method: http.MethodGet, Path: "/api/search", Tag: "search", Summary: "Search items by name or description and tags by name", Auth: true,
+		Params: []Param{{Name: "q", In: "query", Type: "string", Required: true, Description: "Case-insensitive substring to look for"}},
+		Responses: map[int]interface{}{
+			http.StatusOK:           searchResponse{},
+			http.StatusBadRequest:   handlers.APIError{},
+			http.StatusUnauthorized: handlers.APIError{},
+		},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/stats/tags/by-color", Tag: "stats", Summary: "Count tagged items per tag color", Auth: true,
+		Responses: map[int]interface{}{
+			http.StatusOK:           tagColorsResponse{},
+			http.StatusUnauthorized: handlers.APIError{},
+		},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/stats/containers", Tag: "stats", Summary: "Rank items by how many items they directly contain", Auth: true,
+		Params: []Param{{Name: "limit", In: "query", Type: "integer", Description: "Number of containers, 1 to 100 (default 10)"}},
+		Responses: map[int]interface{}{
+			http.StatusOK:           containersResponse{},
+			http.StatusBadRequest:   handlers.APIError{},
+			http.StatusUnauthorized: handlers.APIError{},
+		},
+	},
+
+	// Uploads
+	{
+		Method: http.MethodPost, Path: "/api/uploads", Tag: "uploads", Summary: "Upload a file", Auth: true,
+		Description: "The file's type is sniffed from its content. The Location header and the url field point to the stored file.",
+		Request:     uploadForm{},
+		RequestType: "multipart/form-data",
+		Responses: map[int]interface{}{
+			http.StatusCreated:               uploadResponse{},
+			http.StatusBadRequest:            handlers.APIError{},
+			http.StatusUnauthorized:          handlers.APIError{},
+			http.StatusRequestEntityTooLarge: handlers.APIError{},
+		},
+	},
+
+	// Administration
+	{
+		Method: http.MethodGet, Path: "/api/admin/users", Tag: "admin", Summary: "List users (admins only)", Auth: true,
+		Params: []Param{
+			{Name: "email", In: "query", Type: "string", Description: "Case-insensitive email substring"},
+			{Name: "page", In: "query", Type: "integer", Description: "Page number, starting at 1"},
+			{Name: "page_size", In: "query", Type: "integer", Description: "Users per page, 1 to 100 (default 20)"},
+		},
+		Responses: map[int]interface{}{
+			http.StatusOK:           usersPageResponse{},
+			http.StatusBadRequest:   handlers.APIError{},
+			http.StatusUnauthorized: handlers.APIError{},
+			http.StatusForbidden:    handlers.APIError{},
+		},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/admin/users/batch", Tag: "admin", Summary: "Create up to 500 users at once (admins only)", Auth: true,
+		Description: "Each user is created independently and reported in results. Users without a password are emailed a token to set one.",
+		Request:     handlers.BatchUsersRequest{},
+		Responses: map[int]interface{}{
+			http.StatusOK:           batchUsersResponse{},
+			http.StatusBadRequest:   handlers.APIError{},
+			http.StatusUnauthorized: handlers.APIError{},
+			http.StatusForbidden:    handlers.APIError{},
+		},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/admin/users/:email/reactivate", Tag: "admin", Summary: "Reactivate a deactivated user (admins only)", Auth: true,
+		Params: []Param{emailParam},
+		Responses: map[int]interface{}{
+			http.StatusOK:           handlers.UserResponse{},
+			http.StatusUnauthorized: handlers.APIError{},
+			http.StatusForbidden:    handlers.APIError{},
+			http.StatusNotFound:     handlers.APIError{},
+		},
+	},
+}
@@ -1,7 +1,11 @@
 package organization
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
+	"strings"
+	"time"
 
 	"backend/internal/database"
 
@@ -19,11 +23,36 @@ type Service struct {
 	db *gorm.DB
 }
 
+// Invitation statuses
+const (
+	InvitationPending  = "pending"
+	InvitationAccepted = "accepted"
+)
+
+// Organization membership roles
+const (
+	RoleOwner  = "owner"
+	RoleAdmin  = "admin"
+	RoleMember = "member"
+)
+
 var (
 	// ErrOrganizationNotFound is returned when organization is not found
 	ErrOrganizationNotFound = errors.New("organization not found")
 	// ErrOrganizationAlreadyExists is returned when trying to create an organization that already exists
 	ErrOrganizationAlreadyExists = errors.New("organization already exists")
+	// ErrNotMember is returned when a user is not a member of an organization
+	ErrNotMember = errors.New("user is not a member of the organization")
+	// ErrLastOrganization is returned when deleting an organization would leave a member without one
+	ErrLastOrganization = errors.New("organization is the last one of a member")
+	// ErrInvitationNotFound is returned when an invitation token is unknown
+	ErrInvitationNotFound = errors.New("invitation not found")
+	// ErrInvitationExpired is returned when an invitation is accepted after it expired
+	ErrInvitationExpired = errors.New("invitation expired")
+	// ErrInvitationUsed is returned when an invitation has already been accepted
+	ErrInvitationUsed = errors.New("invitation already used")
+	// ErrInvitationEmailMismatch is returned when a user accepts an invitation sent to someone else
+	ErrInvitationEmailMismatch = errors.New("invitation was sent to a different email")
 )
 
 // NewOrganizationService creates a new organization service
@@ -49,6 +78,41 @@ func (s *Service) CreateOrganization(name string) (*database.Organization, error
 	return organization, nil
 }
 
+// CreateOrganizationForUser creates an organization with the user as its first member
+// and makes it the user's active organization
+func (s *Service) CreateOrganizationForUser(name, userEmail string) (*database.Organization, error) {
+	organization := &database.Organization{
+		Name: name,
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(organization).Error; err != nil {
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				return ErrOrganizationAlreadyExists
+			}
+			return err
+		}
+
+		membership := &database.OrganizationUser{
+			OrganizationID: organization.ID,
+			UserEmail:      userEmail,
+			Role:           RoleOwner,
+		}
+		if err := tx.Create(membership).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&database.User{}).
+			Where("email = ?", userEmail).
+			Update("active_organization_id", organization.ID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return organization, nil
+}
+
 // GetOrganization retrieves an organization by ID
 func (s *Service) GetOrganization(id uint) (*database.Organization, error) {
 	var organization database.Organization
@@ -76,7 +140,7 @@ func (s *Service) GetOrganizationsByUser(userEmail string) ([]database.Organizat
 	return organizations, nil
 }
 
-// AddUserToOrganization adds a user to an organization
+// AddUserToOrganization adds a user to an organization as a member
 func (s *Service) AddUserToOrganization(organizationID uint, userEmail string) error {
 	// Check if organization exists
 	if _, err := s.GetOrganization(organizationID); err != nil {
@@ -84,13 +148,181 @@ func (s *Service) AddUserToOrganization(organizationID uint, userEmail string) e
 	}
 
 	// Add user to organization using raw SQL to avoid GORM many-to-many complexity
-	return s.db.Exec("INSERT INTO organization_users (organization_id, user_email) VALUES (?, ?) ON CONFLICT DO NOTHING",
-		organizationID, userEmail).Error
+	return s.db.Exec("INSERT INTO organization_users (organization_id, user_email, role) VALUES (?, ?, ?) ON CONFLICT DO NOTHING",
+		organizationID, userEmail, RoleMember).Error
+}
+
+// IsMember checks whether a user is a member of an organization
+func (s *Service) IsMember(organizationID uint, userEmail string) (bool, error) {
+	var count int64
+
+	if err := s.db.Table("organization_users").
+		Where("organization_id = ? AND user_email = ?", organizationID, userEmail).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
 }
 
-// SetUserActiveOrganization sets the active organization for a user
+// GetUserRole returns the role of a user in an organization
+func (s *Service) GetUserRole(organizationID uint, userEmail string) (string, error) {
+	var membership database.OrganizationUser
+
+	if err := s.db.Where("organization_id = ? AND user_email = ?", organizationID, userEmail).
+		First(&membership).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", ErrNotMember
+		}
+		return "", err
+	}
+
+	return membership.Role, nil
+}
+
+// SetUserActiveOrganization sets the active organization for a user, who must be a member of it
 func (s *Service) SetUserActiveOrganization(userEmail string, organizationID uint) error {
+	if _, err := s.GetOrganization(organizationID); err != nil {
+		return err
+	}
+
+	isMember, err := s.IsMember(organizationID, userEmail)
+	if err != nil {
+		return err
+	}
+	if !isMember {
+		return ErrNotMember
+	}
+
 	return s.db.Model(&database.User{}).
 		Where("email = ?", userEmail).
 		Update("active_organization_id", organizationID).Error
 }
+
+// DeleteOrganization deletes an organization along with its memberships and tags.
+// Members who had it active are moved to another of their organizations, so it
+// can't be deleted while it is the only organization of any member.
+func (s *Service) DeleteOrganization(organizationID uint) error {
+	if _, err := s.GetOrganization(organizationID); err != nil {
+		return err
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var stranded int64
+		if err := tx.Model(&database.OrganizationUser{}).
+			Where("organization_id = ?", organizationID).
+			Where("NOT EXISTS (SELECT 1 FROM organization_users other WHERE other.user_email = organization_users.user_email AND other.organization_id <> ?)", organizationID).
+			Count(&stranded).Error; err != nil {
+			return err
+		}
+		if stranded > 0 {
+			return ErrLastOrganization
+		}
+
+		if err := tx.Model(&database.User{}).
+			Where("active_organization_id = ?", organizationID).
+			Update("active_organization_id", gorm.Expr("(SELECT MIN(organization_id) FROM organization_users WHERE organization_users.user_email = users.email AND organization_users.organization_id <> ?)", organizationID)).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Exec("DELETE FROM item_tags WHERE tag_id IN (SELECT id FROM tags WHERE organization_id = ?)", organizationID).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("organization_id = ?", organizationID).Delete(&database.Tag{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Model(&database.Item{}).Where("organization_id = ?", organizationID).Update("organization_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("organization_id = ?", organizationID).Delete(&database.OrgInvitation{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("organization_id = ?", organizationID).Delete(&database.OrganizationUser{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Delete(&database.Organization{}, organizationID).Error
+	})
+}
+
+// CreateInvitation invites an email address to join an organization; the
+// invitation can be accepted once, until ttl has passed
+func (s *Service) CreateInvitation(organizationID uint, email string, ttl time.Duration) (*database.OrgInvitation, error) {
+	if _, err := s.GetOrganization(organizationID); err != nil {
+		return nil, err
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		return nil, err
+	}
+
+	invitation := &database.OrgInvitation{
+		Token:          token,
+		OrganizationID: organizationID,
+		Email:          strings.ToLower(email),
+		Status:         InvitationPending,
+		ExpiresAt:      time.Now().Add(ttl),
+	}
+
+	if err := s.db.Create(invitation).Error; err != nil {
+		return nil, err
+	}
+
+	return invitation, nil
+}
+
+// AcceptInvitation adds the invited user to the organization and marks the invitation used
+func (s *Service) AcceptInvitation(token, userEmail string) (*database.OrgInvitation, error) {
+	var invitation database.OrgInvitation
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("token = ?", token).First(&invitation).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrInvitationNotFound
+			}
+			return err
+		}
+
+		if invitation.Status != InvitationPending {
+			return ErrInvitationUsed
+		}
+		if time.Now().After(invitation.ExpiresAt) {
+			return ErrInvitationExpired
+		}
+		if !strings.EqualFold(invitation.Email, userEmail) {
+			return ErrInvitationEmailMismatch
+		}
+
+		// Only one concurrent accept can move the invitation out of pending
+		now := time.Now()
+		result := tx.Model(&database.OrgInvitation{}).
+			Where("id = ? AND status = ?", invitation.ID, InvitationPending).
+			Updates(map[string]interface{}{"status": InvitationAccepted, "accepted_at": now})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrInvitationUsed
+		}
+		invitation.Status = InvitationAccepted
+		invitation.AcceptedAt = &now
+
+		return tx.Exec("INSERT INTO organization_users (organization_id, user_email, role) VALUES (?, ?, ?) ON CONFLICT DO NOTHING",
+			invitation.OrganizationID, userEmail, RoleMember).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &invitation, nil
+}
+
+// generateToken generates a random hex-encoded invitation token
+func generateToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,259 @@
+package organization
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"backend/internal/database"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+
+	err = db.AutoMigrate(&database.Organization{}, &database.User{}, &database.OrganizationUser{}, &database.OrgInvitation{}, &database.Item{}, &database.Tag{})
+	if err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+
+	return db
+}
+
+// createTestUser creates a user whose active organization is a fresh personal organization
+func createTestUser(t *testing.T, service *Service, email string) *database.Organization {
+	organization, err := service.CreateOrganization(email + "_org")
+	if err != nil {
+		t.Fatalf("Failed to create organization: %v", err)
+	}
+	if err := service.db.Create(&database.User{Email: email, ActiveOrganizationID: organization.ID}).Error; err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+	if err := service.AddUserToOrganization(organization.ID, email); err != nil {
+		t.Fatalf("Failed to add user to organization: %v", err)
+	}
+	return organization
+}
+
+// activeOrganizationID returns the stored active organization of a user
+func activeOrganizationID(t *testing.T, service *Service, email string) uint {
+	var user database.User
+	if err := service.db.First(&user, "email = ?", email).Error; err != nil {
+		t.Fatalf("Failed to find user: %v", err)
+	}
+	return user.ActiveOrganizationID
+}
+
+func TestSetUserActiveOrganization_Success(t *testing.T) {
+	service := NewOrganizationService(setupTestDB(t))
+	createTestUser(t, service, "test@example.com")
+
+	shared, err := service.CreateOrganization("Shared")
+	if err != nil {
+		t.Fatalf("Failed to create organization: %v", err)
+	}
+	if err := service.AddUserToOrganization(shared.ID, "test@example.com"); err != nil {
+		t.Fatalf("Failed to add user to organization: %v", err)
+	}
+
+	if err := service.SetUserActiveOrganization("test@example.com", shared.ID); err != nil {
+		t.Fatalf("Failed to set active organization: %v", err)
+	}
+	if id := activeOrganizationID(t, service, "test@example.com"); id != shared.ID {
+		t.Errorf("Expected active organization %d, got %d", shared.ID, id)
+	}
+}
+
+func TestSetUserActiveOrganization_NotFound(t *testing.T) {
+	service := NewOrganizationService(setupTestDB(t))
+	personal := createTestUser(t, service, "test@example.com")
+
+	err := service.SetUserActiveOrganization("test@example.com", 9999)
+	if !errors.Is(err, ErrOrganizationNotFound) {
+		t.Errorf("Expected ErrOrganizationNotFound, got %v", err)
+	}
+	if id := activeOrganizationID(t, service, "test@example.com"); id != personal.ID {
+		t.Errorf("Active organization should be unchanged, got %d", id)
+	}
+}
+
+func TestSetUserActiveOrganization_NotMember(t *testing.T) {
+	service := NewOrganizationService(setupTestDB(t))
+	personal := createTestUser(t, service, "test@example.com")
+	other := createTestUser(t, service, "other@example.com")
+
+	err := service.SetUserActiveOrganization("test@example.com", other.ID)
+	if !errors.Is(err, ErrNotMember) {
+		t.Errorf("Expected ErrNotMember, got %v", err)
+	}
+	if id := activeOrganizationID(t, service, "test@example.com"); id != personal.ID {
+		t.Errorf("Active organization should be unchanged, got %d", id)
+	}
+}
+
+func TestCreateOrganizationForUser(t *testing.T) {
+	service := NewOrganizationService(setupTestDB(t))
+	createTestUser(t, service, "test@example.com")
+
+	created, err := service.CreateOrganizationForUser("Camping Club", "test@example.com")
+	if err != nil {
+		t.Fatalf("Failed to create organization: %v", err)
+	}
+
+	isMember, err := service.IsMember(created.ID, "test@example.com")
+	if err != nil {
+		t.Fatalf("Failed to check membership: %v", err)
+	}
+	if !isMember {
+		t.Error("Expected creator to be a member of the organization")
+	}
+	if id := activeOrganizationID(t, service, "test@example.com"); id != created.ID {
+		t.Errorf("Expected active organization %d, got %d", created.ID, id)
+	}
+}
+
+func TestGetUserRole(t *testing.T) {
+	service := NewOrganizationService(setupTestDB(t))
+	createTestUser(t, service, "owner@example.com")
+	createTestUser(t, service, "member@example.com")
+	createTestUser(t, service, "outsider@example.com")
+
+	created, err := service.CreateOrganizationForUser("Camping Club", "owner@example.com")
+	if err != nil {
+		t.Fatalf("Failed to create organization: %v", err)
+	}
+	if err := service.AddUserToOrganization(created.ID, "member@example.com"); err != nil {
+		t.Fatalf("Failed to add user to organization: %v", err)
+	}
+
+	if role, err := service.GetUserRole(created.ID, "owner@example.com"); err != nil || role != RoleOwner {
+		t.Errorf("Expected role %q, got %q (%v)", RoleOwner, role, err)
+	}
+	if role, err := service.GetUserRole(created.ID, "member@example.com"); err != nil || role != RoleMember {
+		t.Errorf("Expected role %q, got %q (%v)", RoleMember, role, err)
+	}
+	if _, err := service.GetUserRole(created.ID, "outsider@example.com"); !errors.Is(err, ErrNotMember) {
+		t.Errorf("Expected ErrNotMember, got %v", err)
+	}
+}
+
+func TestDeleteOrganization(t *testing.T) {
+	service := NewOrganizationService(setupTestDB(t))
+	personal := createTestUser(t, service, "test@example.com")
+
+	created, err := service.CreateOrganizationForUser("Camping Club", "test@example.com")
+	if err != nil {
+		t.Fatalf("Failed to create organization: %v", err)
+	}
+	if err := service.db.Create(&database.Tag{Name: "camping", OrganizationID: created.ID}).Error; err != nil {
+		t.Fatalf("Failed to create tag: %v", err)
+	}
+
+	if err := service.DeleteOrganization(created.ID); err != nil {
+		t.Fatalf("Failed to delete organization: %v", err)
+	}
+
+	if _, err := service.GetOrganization(created.ID); !errors.Is(err, ErrOrganizationNotFound) {
+		t.Errorf("Expected ErrOrganizationNotFound, got %v", err)
+	}
+	if id := activeOrganizationID(t, service, "test@example.com"); id != personal.ID {
+		t.Errorf("Expected active organization to fall back to %d, got %d", personal.ID, id)
+	}
+
+	var tags int64
+	service.db.Unscoped().Model(&database.Tag{}).Where("organization_id = ?", created.ID).Count(&tags)
+	if tags != 0 {
+		t.Errorf("Expected organization tags to be deleted, found %d", tags)
+	}
+}
+
+func TestDeleteOrganization_LastOrganization(t *testing.T) {
+	service := NewOrganizationService(setupTestDB(t))
+	personal := createTestUser(t, service, "test@example.com")
+
+	if err := service.DeleteOrganization(personal.ID); !errors.Is(err, ErrLastOrganization) {
+		t.Errorf("Expected ErrLastOrganization, got %v", err)
+	}
+	if _, err := service.GetOrganization(personal.ID); err != nil {
+		t.Errorf("Organization should still exist: %v", err)
+	}
+}
+
+// createTestInvitation invites invited@example.com to a new organization
+func createTestInvitation(t *testing.T, service *Service, ttl time.Duration) *database.OrgInvitation {
+	createTestUser(t, service, "owner@example.com")
+	createTestUser(t, service, "invited@example.com")
+
+	created, err := service.CreateOrganizationForUser("Camping Club", "owner@example.com")
+	if err != nil {
+		t.Fatalf("Failed to create organization: %v", err)
+	}
+
+	invitation, err := service.CreateInvitation(created.ID, "invited@example.com", ttl)
+	if err != nil {
+		t.Fatalf("Failed to create invitation: %v", err)
+	}
+	return invitation
+}
+
+func TestAcceptInvitation(t *testing.T) {
+	service := NewOrganizationService(setupTestDB(t))
+	invitation := createTestInvitation(t, service, time.Minute*5)
+
+	if invitation.Token == "" || invitation.Status != InvitationPending {
+		t.Fatalf("Expected a pending invitation with a token, got %+v", invitation)
+	}
+
+	accepted, err := service.AcceptInvitation(invitation.Token, "invited@example.com")
+	if err != nil {
+		t.Fatalf("Failed to accept invitation: %v", err)
+	}
+	if accepted.Status != InvitationAccepted || accepted.AcceptedAt == nil {
+		t.Errorf("Expected invitation to be accepted, got %+v", accepted)
+	}
+
+	if role, err := service.GetUserRole(invitation.OrganizationID, "invited@example.com"); err != nil || role != RoleMember {
+		t.Errorf("Expected role %q, got %q (%v)", RoleMember, role, err)
+	}
+}
+
+func TestAcceptInvitation_Expired(t *testing.T) {
+	service := NewOrganizationService(setupTestDB(t))
+	invitation := createTestInvitation(t, service, -time.Minute)
+
+	if _, err := service.AcceptInvitation(invitation.Token, "invited@example.com"); !errors.Is(err, ErrInvitationExpired) {
+		t.Errorf("Expected ErrInvitationExpired, got %v", err)
+	}
+	if isMember, _ := service.IsMember(invitation.OrganizationID, "invited@example.com"); isMember {
+		t.Error("Expected expired invitation not to add a member")
+	}
+}
+
+func TestAcceptInvitation_AlreadyAccepted(t *testing.T) {
+	service := NewOrganizationService(setupTestDB(t))
+	invitation := createTestInvitation(t, service, time.Minute*5)
+
+	if _, err := service.AcceptInvitation(invitation.Token, "invited@example.com"); err != nil {
+		t.Fatalf("Failed to accept invitation: %v", err)
+	}
+	if _, err := service.AcceptInvitation(invitation.Token, "invited@example.com"); !errors.Is(err, ErrInvitationUsed) {
+		t.Errorf("Expected ErrInvitationUsed, got %v", err)
+	}
+}
+
+func TestAcceptInvitation_WrongUser(t *testing.T) {
+	service := NewOrganizationService(setupTestDB(t))
+	invitation := createTestInvitation(t, service, time.Minute*5)
+
+	if _, err := service.AcceptInvitation(invitation.Token, "owner@example.com"); !errors.Is(err, ErrInvitationEmailMismatch) {
+		t.Errorf("Expected ErrInvitationEmailMismatch, got %v", err)
+	}
+	if _, err := service.AcceptInvitation("unknown", "invited@example.com"); !errors.Is(err, ErrInvitationNotFound) {
+		t.Errorf("Expected ErrInvitationNotFound, got %v", err)
+	}
+}
@@ -1,10 +1,14 @@
 package reset
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
 	"errors"
-	"math/rand"
+	"log/slog"
 	"time"
 
+	"backend/internal/config"
 	"backend/internal/database"
 
 	"go.uber.org/fx"
@@ -14,11 +18,13 @@ import (
 // Module provides reset service dependency injection
 var Module = fx.Module("reset",
 	fx.Provide(NewResetService),
+	fx.Invoke(registerCleanup),
 )
 
 // Service handles password reset operations
 type Service struct {
-	db *gorm.DB
+	db     *gorm.DB
+	logger *slog.Logger
 }
 
 var (
@@ -29,24 +35,39 @@ var (
 )
 
 // NewResetService creates a new reset service
-func NewResetService(db *gorm.DB) *Service {
+func NewResetService(db *gorm.DB, logger *slog.Logger) *Service {
 	return &Service{
-		db: db,
+		db:     db,
+		logger: logger,
 	}
 }
 
-// generateToken generates a random 15-character token
-func (s *Service) generateToken() string {
-	const letters = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
-	result := make([]byte, 15)
-	for i := range result {
-		result[i] = letters[rand.Intn(len(letters))]
+// WithTx returns a copy of the service that runs its queries in tx
+func (s *Service) WithTx(tx *gorm.DB) *Service {
+	clone := *s
+	clone.db = tx
+	return &clone
+}
+
+// tokenBytes is the amount of randomness in a reset token
+const tokenBytes = 32
+
+// generateToken generates a random URL-safe token from crypto/rand
+func (s *Service) generateToken() (string, error) {
+	b := make([]byte, tokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
 	}
-	return string(result)
+	return base64.RawURLEncoding.EncodeToString(b), nil
 }
 
 // CreateResetToken creates a new reset token for a user
 func (s *Service) CreateResetToken(userEmail string) (string, error) {
+	return s.CreateResetTokenWithExpiry(userEmail, 5*time.Minute) // Token expires in 5 minutes
+}
+
+// CreateResetTokenWithExpiry creates a new reset token for a user that expires after ttl
+func (s *Service) CreateResetTokenWithExpiry(userEmail string, ttl time.Duration) (string, error) {
 	// Check if user exists
 	var user database.User
 	if err := s.db.Where("email = ?", userEmail).First(&user).Error; err != nil {
@@ -60,8 +81,11 @@ func (s *Service) CreateResetToken(userEmail string) (string, error) {
 	s.db.Where("user_email = ?", userEmail).Delete(&database.ResetToken{})
 
 	// Generate new token
-	token := s.generateToken()
-	expiredAt := time.Now().Add(5 * time.Minute) // Token expires in 5 minutes
+	token, err := s.generateToken()
+	if err != nil {
+		return "", err
+	}
+	expiredAt := time.Now().Add(ttl)
 
 	resetToken := &database.ResetToken{
 		Token:     token,
@@ -97,27 +121,80 @@ func (s *Service) ValidateResetToken(token string) (*database.ResetToken, error)
 	return &resetToken, nil
 }
 
-// ResetPassword resets a user's password using a valid token
-func (s *Service) ResetPassword(token, newPassword string) error {
+// ResetPassword sets a user's password hash using a valid token, which is then used up.
+// Both happen in one transaction, so a failure leaves the password and the token unchanged.
+func (s *Service) ResetPassword(token, passwordHash string) error {
 	resetToken, err := s.ValidateResetToken(token)
 	if err != nil {
 		return err
 	}
 
-	// Update user password
-	if err := s.db.Model(&database.User{}).
-		Where("email = ?", resetToken.UserEmail).
-		Update("password", newPassword).Error; err != nil {
-		return err
-	}
+	return database.WithTx(s.db, func(tx *gorm.DB) error {
+		// Use up the token first so a concurrent reset with the same token fails
+		result := tx.Delete(resetToken)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrResetTokenNotFound
+		}
+
+		return tx.Model(&database.User{}).
+			Where("email = ?", resetToken.UserEmail).
+			Update("password", passwordHash).Error
+	})
+}
 
-	// Delete the used token
-	s.db.Delete(resetToken)
+// CleanupExpiredTokens removes all expired reset tokens, returning how many were removed
+func (s *Service) CleanupExpiredTokens() (int64, error) {
+	result := s.db.Where("expired_at < ?", time.Now()).Delete(&database.ResetToken{})
+	return result.RowsAffected, result.Error
+}
 
-	return nil
+// runCleanup removes expired reset tokens every interval until ctx is cancelled
+func (s *Service) runCleanup(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			removed, err := s.CleanupExpiredTokens()
+			if err != nil {
+				s.logger.Error("failed to clean up expired reset tokens", "error", err)
+				continue
+			}
+			s.logger.Info("removed expired reset tokens", "count", removed)
+		case <-ctx.Done():
+			return
+		}
+	}
 }
 
-// CleanupExpiredTokens removes all expired reset tokens
-func (s *Service) CleanupExpiredTokens() error {
-	return s.db.Where("expired_at < ?", time.Now()).Delete(&database.ResetToken{}).Error
+// registerCleanup periodically removes expired reset tokens while the app runs
+func registerCleanup(lc fx.Lifecycle, cfg *config.Config, s *Service) {
+	if cfg.Reset.CleanupInterval <= 0 {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go func() {
+				defer close(stopped)
+				s.runCleanup(ctx, cfg.Reset.CleanupInterval)
+			}()
+			return nil
+		},
+		OnStop: func(stopCtx context.Context) error {
+			cancel()
+			select {
+			case <-stopped:
+				return nil
+			case <-stopCtx.Done():
+				return stopCtx.Err()
+			}
+		},
+	})
 }
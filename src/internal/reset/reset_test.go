@@ -0,0 +1,205 @@
+package reset
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/database"
+	"backend/internal/logger"
+
+	"go.uber.org/fx/fxtest"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+	// Every :memory: connection is a separate database, so keep to one for background work
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("Failed to get database handle: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+
+	err = db.AutoMigrate(&database.Organization{}, &database.User{}, &database.ResetToken{})
+	if err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+
+	return db
+}
+
+func TestGenerateToken(t *testing.T) {
+	service := NewResetService(setupTestDB(t), logger.Discard())
+	urlSafe := regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		token, err := service.generateToken()
+		if err != nil {
+			t.Fatalf("Failed to generate token: %v", err)
+		}
+		// 32 random bytes encode to 43 unpadded base64url characters
+		if len(token) != 43 {
+			t.Errorf("Expected a 43-character token, got %d characters", len(token))
+		}
+		if !urlSafe.MatchString(token) {
+			t.Errorf("Expected a URL-safe token, got %q", token)
+		}
+		if seen[token] {
+			t.Fatalf("Generated the same token twice: %q", token)
+		}
+		seen[token] = true
+	}
+}
+
+func TestCreateResetToken(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewResetService(db, logger.Discard())
+	db.Create(&database.User{Email: "test@example.com"})
+
+	first, err := service.CreateResetToken("test@example.com")
+	if err != nil {
+		t.Fatalf("Failed to create reset token: %v", err)
+	}
+	second, err := service.CreateResetToken("test@example.com")
+	if err != nil {
+		t.Fatalf("Failed to create reset token: %v", err)
+	}
+	if first == second {
+		t.Error("Expected successive reset tokens to differ")
+	}
+
+	// Only the newest token stays valid
+	if _, err := service.ValidateResetToken(first); !errors.Is(err, ErrResetTokenNotFound) {
+		t.Errorf("Expected ErrResetTokenNotFound for the replaced token, got %v", err)
+	}
+	if _, err := service.ValidateResetToken(second); err != nil {
+		t.Errorf("Expected the newest token to be valid, got %v", err)
+	}
+}
+
+func TestResetPassword(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewResetService(db, logger.Discard())
+	db.Create(&database.User{Email: "test@example.com", Password: "old-hash"})
+
+	token, err := service.CreateResetToken("test@example.com")
+	if err != nil {
+		t.Fatalf("Failed to create reset token: %v", err)
+	}
+	if err := service.ResetPassword(token, "new-hash"); err != nil {
+		t.Fatalf("Failed to reset password: %v", err)
+	}
+
+	var user database.User
+	db.First(&user, "email = ?", "test@example.com")
+	if user.Password != "new-hash" {
+		t.Errorf("Expected password hash 'new-hash', got '%s'", user.Password)
+	}
+	if err := service.ResetPassword(token, "other-hash"); !errors.Is(err, ErrResetTokenNotFound) {
+		t.Errorf("Expected the token to be used up, got %v", err)
+	}
+}
+
+func TestResetPassword_RollsBackOnFailure(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewResetService(db, logger.Discard())
+	db.Create(&database.User{Email: "test@example.com", Password: "old-hash"})
+
+	token, err := service.CreateResetToken("test@example.com")
+	if err != nil {
+		t.Fatalf("Failed to create reset token: %v", err)
+	}
+
+	// Fail the password update, which runs after the token was deleted
+	injected := errors.New("injected failure")
+	err = db.Callback().Update().Before("gorm:update").Register("test:fail_user_update", func(tx *gorm.DB) {
+		if tx.Statement.Table == "users" {
+			tx.AddError(injected)
+		}
+	})
+	if err != nil {
+		t.Fatalf("Failed to register callback: %v", err)
+	}
+
+	if err := service.ResetPassword(token, "new-hash"); !errors.Is(err, injected) {
+		t.Fatalf("Expected the injected error, got %v", err)
+	}
+
+	var user database.User
+	db.First(&user, "email = ?", "test@example.com")
+	if user.Password != "old-hash" {
+		t.Errorf("Expected password to be unchanged, got '%s'", user.Password)
+	}
+	if _, err := service.ValidateResetToken(token); err != nil {
+		t.Errorf("Expected the token deletion to be rolled back, got %v", err)
+	}
+}
+
+func TestCleanupExpiredTokens(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewResetService(db, logger.Discard())
+	db.Create(&database.ResetToken{Token: "expired", UserEmail: "test@example.com", ExpiredAt: time.Now().Add(-time.Minute)})
+	db.Create(&database.ResetToken{Token: "valid", UserEmail: "test@example.com", ExpiredAt: time.Now().Add(time.Hour)})
+
+	removed, err := service.CleanupExpiredTokens()
+	if err != nil {
+		t.Fatalf("Failed to clean up expired tokens: %v", err)
+	}
+	if removed != 1 {
+		t.Errorf("Expected 1 removed token, got %d", removed)
+	}
+	if _, err := service.ValidateResetToken("valid"); err != nil {
+		t.Errorf("Expected the unexpired token to remain, got %v", err)
+	}
+}
+
+func TestRegisterCleanup_RemovesExpiredTokens(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewResetService(db, logger.Discard())
+	db.Create(&database.ResetToken{Token: "expired", UserEmail: "test@example.com", ExpiredAt: time.Now().Add(-time.Minute)})
+
+	lc := fxtest.NewLifecycle(t)
+	registerCleanup(lc, &config.Config{Reset: config.ResetConfig{CleanupInterval: 10 * time.Millisecond}}, service)
+	lc.RequireStart()
+
+	var count int64
+	for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		db.Model(&database.ResetToken{}).Count(&count)
+		if count == 0 {
+			break
+		}
+	}
+	if count != 0 {
+		t.Errorf("Expected the expired token to be removed, %d tokens left", count)
+	}
+
+	// OnStop waits for the cleanup goroutine to exit
+	lc.RequireStop()
+}
+
+func TestRunCleanup_StopsOnCancel(t *testing.T) {
+	service := NewResetService(setupTestDB(t), logger.Discard())
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan struct{})
+	go func() {
+		service.runCleanup(ctx, time.Hour)
+		close(done)
+	}()
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the cleanup loop to exit after cancellation")
+	}
+}
@@ -2,12 +2,19 @@ package server
 
 import (
 	"context"
-	"log"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
 	"net/http"
+	"sync"
 
 	"backend/internal/config"
 	"backend/internal/handlers"
 	"backend/internal/middleware"
+	"backend/internal/openapi"
+	"backend/internal/organization"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/fx"
@@ -20,30 +27,70 @@ var Module = fx.Module("server",
 
 // Server represents the HTTP server
 type Server struct {
-	engine *gin.Engine
-	config *config.ServerConfig
+	engine     *gin.Engine
+	httpServer *http.Server
+	listener   net.Listener
+	config     *config.ServerConfig
+	// shutdowner stops the application when the server fails after starting
+	shutdowner fx.Shutdowner
 }
 
+// setGinMode guards the process-wide Gin mode so it is set only once, even when
+// several servers are created concurrently in tests
+var setGinMode sync.Once
+
 // NewServer creates a new HTTP server
-func NewServer(lc fx.Lifecycle, cfg *config.Config, handlers *handlers.Handlers) *Server {
-	// Set Gin mode based on environment (you can make this configurable)
-	gin.SetMode(gin.ReleaseMode)
+func NewServer(lc fx.Lifecycle, shutdowner fx.Shutdowner, cfg *config.Config, handlers *handlers.Handlers, logger *slog.Logger) *Server {
+	setGinMode.Do(func() {
+		gin.SetMode(cfg.GinMode())
+	})
 
 	engine := gin.New()
 
-	// Add middleware
-	engine.Use(gin.Recovery())
-	engine.Use(gin.Logger())
+	// Add middleware; the request ID comes first so everything after can log it
+	metrics := middleware.NewMetrics()
+	engine.Use(middleware.RequestID())
+	engine.Use(metrics.Middleware())
+	if cfg.Server.ResponseTimeHeader {
+		engine.Use(middleware.ResponseTime())
+	}
+	engine.Use(middleware.Recovery(logger))
+	engine.Use(middleware.AccessLog(logger, cfg.Server.AccessLogSkipPaths))
+	if cfg.Server.Compression {
+		engine.Use(middleware.Compression(cfg.Server.CompressionMinSize))
+	}
+	if cfg.Headers.Enabled {
+		engine.Use(middleware.SecurityHeaders(cfg.Headers))
+	}
+	engine.Use(middleware.CORSWithPrefix(logger, cfg.CORS, "/api/uploads", cfg.UploadCORS))
+
+	// Health checks (no auth required)
+	engine.GET("/health", handlers.Health)
+	engine.GET("/ready", handlers.Ready)
+
+	// Prometheus metrics (no auth required)
+	engine.GET("/metrics", gin.WrapH(metrics.Handler()))
+
+	// Uploaded files are downloaded rather than rendered, so uploaded markup can't run
+	// on this origin
+	uploads := engine.Group("/uploads", middleware.ForceDownload())
+	uploads.Static("/", cfg.Upload.Dir)
 
 	// Setup routes
 	api := engine.Group("/api")
 	{
+		// API documentation (no auth required)
+		api.GET("/openapi.json", openapi.Spec)
+		api.GET("/docs", openapi.Docs)
+		api.GET("/docs/assets/*filepath", openapi.DocsAsset)
+
 		// Authentication endpoints (no auth required)
 		api.POST("/users", handlers.RegisterUser)
 		api.POST("/token", handlers.Login)
 		api.POST("/token/refresh", handlers.RefreshToken)
-		api.POST("/token/pair", handlers.TokenPair)
 		api.POST("/token/verify", handlers.VerifyToken)
+		api.POST("/token/introspect", handlers.IntrospectToken)
+		api.POST("/logout", handlers.Logout)
 
 		// User statistics (no auth required)
 		api.GET("/users", handlers.GetUserStatistics)
@@ -52,54 +99,196 @@ func NewServer(lc fx.Lifecycle, cfg *config.Config, handlers *handlers.Handlers)
 		api.POST("/users/reset-password", handlers.RequestPasswordReset)
 		api.POST("/users/send-password", handlers.SetNewPassword)
 
+		// Email verification (no auth required)
+		api.GET("/users/verify", handlers.VerifyEmail)
+
 		// Protected routes (require JWT authentication)
 		protected := api.Group("")
-		protected.Use(middleware.AuthMiddleware(handlers.GetJWTService()))
+		protected.Use(middleware.AuthMiddleware(handlers.GetJWTService(), cfg.JWT.CookieName))
 		{
 			// User management
 			protected.POST("/users/deactivate", handlers.DeactivateUser)
-			protected.GET("/users/:user_id", handlers.GetUserDetails)
-			protected.PUT("/users/:user_id", handlers.UpdateUserDetails)
-			protected.DELETE("/users/:user_id", handlers.DeleteUser)
+			protected.POST("/users/password", handlers.ChangePassword)
+			protected.GET("/users/me", handlers.GetCurrentUser)
+			protected.PATCH("/users/me", handlers.UpdateCurrentUser)
+			protected.GET("/users/me/full", handlers.GetFullProfile)
+			protected.GET("/users/me/sessions/count", handlers.GetSessionCount)
+			protected.GET("/users/me/prefix", handlers.GetPrefix)
+			protected.POST("/users/me/prefix", handlers.RegeneratePrefix)
+			protected.GET("/users/:email", handlers.GetUserDetails)
+			protected.PUT("/users/:email", handlers.UpdateUserDetails)
+			protected.DELETE("/users/:email", handlers.DeleteUser)
+			protected.POST("/users/me/items/assign-org", handlers.AssignItemsToOrganization)
 
 			// Items management
-			protected.GET("/items", handlers.GetItems)
-			protected.GET("/items/:item_id", handlers.GetItem)
-			protected.POST("/items", handlers.CreateItem)
-			protected.PATCH("/items/:item_id", handlers.UpdateItem)
-			protected.DELETE("/items/:item_id", handlers.DeleteItem)
+			items := protected.Group("/items")
+			{
+				items.GET("", handlers.GetItems)
+				items.POST("", handlers.CreateItem)
+				items.POST("/bulk", handlers.CreateItems)
+				items.GET("/backpack-id-exists", handlers.BackpackIDExists)
+				items.GET("/deleted", handlers.GetDeletedItems)
+				items.GET("/tree", handlers.GetItemTree)
+				items.GET("/:item_id", handlers.GetItem)
+				items.PUT("/:item_id", handlers.UpdateItem)
+				items.PATCH("/:item_id", handlers.UpdateItem)
+				items.PATCH("/:item_id/move", handlers.MoveItem)
+				items.PATCH("/:item_id/quantity", handlers.AdjustItemQuantity)
+				items.GET("/:item_id/qr", handlers.GetItemQRCode)
+				items.GET("/:item_id/history", handlers.GetItemHistory)
+				items.POST("/:item_id/restore", handlers.RestoreItem)
+				items.DELETE("/:item_id", handlers.DeleteItem)
+				items.POST("/:item_id/attachments", handlers.AddItemAttachment)
+				items.DELETE("/:item_id/attachments/:attachment_id", handlers.DeleteItemAttachment)
+			}
 
 			// Tags management
-			protected.GET("/tags", handlers.GetTags)
-			protected.POST("/tags", handlers.CreateTag)
+			tags := protected.Group("/tags")
+			{
+				tags.GET("", handlers.GetTags)
+				tags.POST("", handlers.CreateTag)
+				tags.GET("/:tag_id", handlers.GetTag)
+				tags.PUT("/:tag_id", handlers.UpdateTag)
+				tags.DELETE("/:tag_id", handlers.DeleteTag)
+				tags.POST("/:tag_id/merge", handlers.MergeTag)
+				tags.POST("/restore-merge/:merge_id", handlers.RestoreTagMerge)
+			}
+
+			// Organizations management
+			organizations := protected.Group("/organizations")
+			{
+				organizations.GET("", handlers.GetOrganizations)
+				organizations.POST("", handlers.CreateOrganization)
+				organizations.POST("/:org_id/members", middleware.RequireOrgRole(handlers.GetOrganizationService(), organization.RoleOwner, organization.RoleAdmin), handlers.AddOrganizationMember)
+				organizations.PUT("/:org_id/active", handlers.SetActiveOrganization)
+				organizations.DELETE("/:org_id", middleware.RequireOrgRole(handlers.GetOrganizationService(), organization.RoleOwner), handlers.DeleteOrganization)
+				organizations.POST("/:org_id/invitations", middleware.RequireOrgRole(handlers.GetOrganizationService(), organization.RoleOwner, organization.RoleAdmin), handlers.CreateOrganizationInvitation)
+				// Reactivation lifts a global deactivation, so it is reserved for admins
+				organizations.POST("/:org_id/members/:email/reactivate", middleware.RequireAdmin(handlers.GetUserService()), handlers.ReactivateOrganizationMember)
+			}
+			protected.POST("/invitations/:token/accept", handlers.AcceptOrganizationInvitation)
+
+			// Search
+			protected.GET("/search", handlers.Search)
+
+			// Statistics
+			protected.GET("/stats/tags/by-color", handlers.GetItemCountsByTagColor)
+			protected.GET("/stats/containers", handlers.GetContainerCounts)
+
+			// File uploads (CORS configured separately via UPLOAD_CORS_*)
+			protected.POST("/uploads", handlers.UploadFile)
+
+			// Administration
+			admin := protected.Group("/admin")
+			admin.Use(middleware.RequireAdmin(handlers.GetUserService()))
+			{
+				admin.GET("/users", handlers.ListUsers)
+				admin.POST("/users/batch", handlers.BatchCreateUsers)
+				admin.POST("/users/:email/reactivate", handlers.ReactivateUser)
+			}
+
+			// Minting tokens for another user is an admin operation
+			protected.POST("/token/pair", middleware.RequireAdmin(handlers.GetUserService()), handlers.TokenPair)
 		}
 	}
 
 	server := &Server{
 		engine: engine,
-		config: &cfg.Server,
+		httpServer: &http.Server{
+			Addr:         cfg.Server.Port,
+			Handler:      engine,
+			ReadTimeout:  cfg.Server.ReadTimeout,
+			WriteTimeout: cfg.Server.WriteTimeout,
+			IdleTimeout:  cfg.Server.IdleTimeout,
+		},
+		config:     &cfg.Server,
+		shutdowner: shutdowner,
 	}
 
 	lc.Append(fx.Hook{
 		OnStart: func(ctx context.Context) error {
-			log.Printf("Starting HTTP server on %s", server.config.Port)
+			// Load the certificate and listen synchronously so bad certificates and bind errors fail startup
+			tlsConfig, err := loadTLSConfig(server.config)
+			if err != nil {
+				return err
+			}
+			server.httpServer.TLSConfig = tlsConfig
+
+			if tlsConfig != nil {
+				logger.Info("starting HTTPS server", "addr", server.config.Port)
+			} else {
+				logger.Info("starting HTTP server", "addr", server.config.Port)
+			}
+
+			listener, err := net.Listen("tcp", server.httpServer.Addr)
+			if err != nil {
+				return err
+			}
+			server.listener = listener
+
 			go func() {
-				if err := server.engine.Run(server.config.Port); err != nil && err != http.ErrServerClosed {
-					log.Fatalf("Failed to start server: %v", err)
+				var err error
+				if tlsConfig != nil {
+					// The certificate is already in TLSConfig
+					err = server.httpServer.ServeTLS(listener, "", "")
+				} else {
+					err = server.httpServer.Serve(listener)
+				}
+				if err != nil && err != http.ErrServerClosed {
+					// Stop through fx so the other OnStop hooks still run
+					logger.Error("HTTP server failed", "error", err)
+					if err := server.shutdowner.Shutdown(fx.ExitCode(1)); err != nil {
+						logger.Error("failed to shut down", "error", err)
+					}
 				}
 			}()
 			return nil
 		},
 		OnStop: func(ctx context.Context) error {
-			log.Println("Stopping HTTP server")
-			return nil
+			logger.Info("stopping HTTP server")
+
+			// Wait for in-flight requests, bounded by the shutdown timeout
+			shutdownCtx, cancel := context.WithTimeout(ctx, server.config.ShutdownTimeout)
+			defer cancel()
+			return server.httpServer.Shutdown(shutdownCtx)
 		},
 	})
 
 	return server
 }
 
+// errIncompleteTLSConfig is returned when only one of the TLS certificate and key files is set
+var errIncompleteTLSConfig = errors.New("TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+
+// loadTLSConfig returns the TLS configuration for the configured certificate pair, or nil
+// to serve plain HTTP when neither file is set
+func loadTLSConfig(cfg *config.ServerConfig) (*tls.Config, error) {
+	if cfg.TLSCertFile == "" && cfg.TLSKeyFile == "" {
+		return nil, nil
+	}
+	if cfg.TLSCertFile == "" || cfg.TLSKeyFile == "" {
+		return nil, errIncompleteTLSConfig
+	}
+
+	cert, err := tls.LoadX509KeyPair(cfg.TLSCertFile, cfg.TLSKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
 // GetEngine returns the Gin engine (useful for testing)
 func (s *Server) GetEngine() *gin.Engine {
 	return s.engine
 }
+
+// Addr returns the address the server is listening on, once started
+func (s *Server) Addr() string {
+	if s.listener == nil {
+		return ""
+	}
+	return s.listener.Addr().String()
+}
@@ -0,0 +1,587 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/database"
+	"backend/internal/handlers"
+	"backend/internal/item"
+	"backend/internal/jwt"
+	"backend/internal/logger"
+	"backend/internal/notify"
+	"backend/internal/openapi"
+	"backend/internal/organization"
+	"backend/internal/reset"
+	"backend/internal/tag"
+	"backend/internal/user"
+	"backend/internal/webhook"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupTestServer(t *testing.T) *Server {
+	return newTestServer(t, fxtest.NewLifecycle(t))
+}
+
+// newTestServer creates a server bound to a random local port, applying configure to its configuration
+func newTestServer(t *testing.T, lc *fxtest.Lifecycle, configure ...func(*config.Config)) *Server {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+
+	// Auto migrate all models
+	err = database.AutoMigrate(db)
+	if err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+
+	cfg := &config.Config{
+		Env: config.EnvTest,
+		JWT: config.JWTConfig{
+			SecretKey:            "test-secret-key",
+			AccessTokenDuration:  time.Minute * 15,
+			RefreshTokenDuration: time.Hour * 24,
+		},
+		Upload: config.UploadConfig{
+			Dir: t.TempDir(),
+		},
+		Security: config.SecurityConfig{
+			BcryptCost: bcrypt.MinCost,
+		},
+		Server: config.ServerConfig{
+			Port:            "127.0.0.1:0",
+			ShutdownTimeout: time.Second * 5,
+		},
+	}
+
+	for _, fn := range configure {
+		fn(cfg)
+	}
+
+	jwtService, err := jwt.NewJWTService(cfg, db, logger.Discard())
+	if err != nil {
+		t.Fatalf("Failed to create JWT service: %v", err)
+	}
+
+	h := handlers.NewHandlers(
+		user.NewUserService(db, cfg),
+		jwtService,
+		item.NewItemService(db, cfg),
+		tag.NewTagService(db),
+		organization.NewOrganizationService(db),
+		reset.NewResetService(db, logger.Discard()),
+		notify.NewMailer(cfg, logger.Discard()),
+		webhook.NewDispatcher(lc, cfg, logger.Discard()),
+		db,
+		cfg,
+		logger.Discard(),
+	)
+
+	return NewServer(lc, &fakeShutdowner{}, cfg, h, logger.Discard())
+}
+
+// fakeShutdowner records shutdown requests instead of stopping an fx application
+type fakeShutdowner struct {
+	called chan struct{}
+}
+
+func (f *fakeShutdowner) Shutdown(...fx.ShutdownOption) error {
+	if f.called != nil {
+		close(f.called)
+	}
+	return nil
+}
+
+// serve sends a JSON request through the server's router
+func serve(s *Server, method, path, token string, body interface{}) *httptest.ResponseRecorder {
+	var buf bytes.Buffer
+	if body != nil {
+		json.NewEncoder(&buf).Encode(body)
+	}
+
+	req := httptest.NewRequest(method, path, &buf)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	w := httptest.NewRecorder()
+	s.GetEngine().ServeHTTP(w, req)
+	return w
+}
+
+// loginTestUser registers a user and returns an access token
+func loginTestUser(t *testing.T, s *Server, email string) string {
+	credentials := map[string]string{"email": email, "password": "password123"}
+
+	if w := serve(s, "POST", "/api/users", "", credentials); w.Code != http.StatusCreated {
+		t.Fatalf("Failed to register user: %d %s", w.Code, w.Body.String())
+	}
+
+	w := serve(s, "POST", "/api/token", "", credentials)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Failed to log in: %d %s", w.Code, w.Body.String())
+	}
+
+	var tokens jwt.TokenResponse
+	json.Unmarshal(w.Body.Bytes(), &tokens)
+	return tokens.Token
+}
+
+func TestItemsRoutes_RequireAuthentication(t *testing.T) {
+	s := setupTestServer(t)
+
+	w := serve(s, "GET", "/api/items", "", nil)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestItemsRoutes_Authenticated(t *testing.T) {
+	s := setupTestServer(t)
+	token := loginTestUser(t, s, "server@example.com")
+
+	w := serve(s, "GET", "/api/items", token, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Create, read, replace and delete an item through the router
+	w = serve(s, "POST", "/api/items", token, map[string]string{"name": "Tent"})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	location := w.Header().Get("Location")
+
+	w = serve(s, "GET", location, token, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = serve(s, "PUT", location, token, map[string]interface{}{"name": "Big Tent", "version": 1})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = serve(s, "DELETE", location, token, nil)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
+
+func TestCurrentUserRoute(t *testing.T) {
+	s := setupTestServer(t)
+	token := loginTestUser(t, s, "me@example.com")
+
+	w := serve(s, "GET", "/api/users/me", token, nil)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "password")
+
+	var response map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "me@example.com", response["email"])
+	assert.Contains(t, response, "display_name")
+	assert.Contains(t, response, "active_organization_id")
+
+	w = serve(s, "GET", "/api/users/me", "", nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestUserDetailsRoute(t *testing.T) {
+	s := setupTestServer(t)
+	token := loginTestUser(t, s, "details@example.com")
+
+	// Users are addressed by email
+	w := serve(s, "GET", "/api/users/details@example.com", token, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "details@example.com", response["email"])
+
+	// Other accounts are off limits to regular users
+	w = serve(s, "GET", "/api/users/nobody@example.com", token, nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = serve(s, "DELETE", "/api/users/nobody@example.com?hard=true", token, nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestAdminUsersRoute_RequiresAdmin(t *testing.T) {
+	s := setupTestServer(t)
+	token := loginTestUser(t, s, "regular@example.com")
+
+	w := serve(s, "GET", "/api/admin/users", token, nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = serve(s, "GET", "/api/admin/users", "", nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestTokenPairRoute_RequiresAdmin(t *testing.T) {
+	s := setupTestServer(t)
+	token := loginTestUser(t, s, "regular@example.com")
+	body := map[string]string{"email": "regular@example.com"}
+
+	w := serve(s, "POST", "/api/token/pair", "", body)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = serve(s, "POST", "/api/token/pair", token, body)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestOrganizationsRoutes_OwnerOnlyDelete(t *testing.T) {
+	s := setupTestServer(t)
+	ownerToken := loginTestUser(t, s, "owner@example.com")
+	memberToken := loginTestUser(t, s, "member@example.com")
+
+	w := serve(s, "POST", "/api/organizations", ownerToken, map[string]string{"name": "Camping Club"})
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var created handlers.ProfileOrganization
+	json.Unmarshal(w.Body.Bytes(), &created)
+	path := "/api/organizations/" + strconv.FormatUint(uint64(created.ID), 10)
+
+	w = serve(s, "POST", path+"/members", ownerToken, map[string]string{"email": "member@example.com"})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = serve(s, "DELETE", path, memberToken, nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// Plain members can't add others
+	loginTestUser(t, s, "bystander@example.com")
+	w = serve(s, "POST", path+"/members", memberToken, map[string]string{"email": "bystander@example.com"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = serve(s, "DELETE", path, ownerToken, nil)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
+
+func TestReactivateMemberRoute_RequiresAdmin(t *testing.T) {
+	s := setupTestServer(t)
+	ownerToken := loginTestUser(t, s, "owner@example.com")
+	memberToken := loginTestUser(t, s, "member@example.com")
+
+	w := serve(s, "POST", "/api/organizations", ownerToken, map[string]string{"name": "Camping Club"})
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var created handlers.ProfileOrganization
+	json.Unmarshal(w.Body.Bytes(), &created)
+	path := "/api/organizations/" + strconv.FormatUint(uint64(created.ID), 10)
+
+	w = serve(s, "POST", "/api/users/deactivate", memberToken, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = serve(s, "POST", path+"/members", ownerToken, map[string]string{"email": "member@example.com"})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Owning an organization the user was added to doesn't undo a deactivation
+	w = serve(s, "POST", path+"/members/member@example.com/reactivate", ownerToken, nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = serve(s, "POST", "/api/token", "", map[string]string{"email": "member@example.com", "password": "password123"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestTagsRoutes_CreateThenList(t *testing.T) {
+	s := setupTestServer(t)
+	token := loginTestUser(t, s, "server@example.com")
+	otherToken := loginTestUser(t, s, "other@example.com")
+
+	w := serve(s, "POST", "/api/tags", token, map[string]string{"name": "camping", "color": "#00ff00"})
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var created database.Tag
+	json.Unmarshal(w.Body.Bytes(), &created)
+
+	// The Location header leads to the new tag
+	location := w.Header().Get("Location")
+	w = serve(s, "GET", location, token, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var fetched database.Tag
+	json.Unmarshal(w.Body.Bytes(), &fetched)
+	assert.Equal(t, created.ID, fetched.ID)
+
+	w = serve(s, "GET", location, otherToken, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = serve(s, "GET", "/api/tags", token, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var tags []database.Tag
+	json.Unmarshal(w.Body.Bytes(), &tags)
+	if assert.Len(t, tags, 1) {
+		assert.Equal(t, created.ID, tags[0].ID)
+		assert.Equal(t, "camping", tags[0].Name)
+		assert.Equal(t, created.OrganizationID, tags[0].OrganizationID)
+	}
+
+	// Tags are scoped to the organization
+	w = serve(s, "GET", "/api/tags", otherToken, nil)
+	json.Unmarshal(w.Body.Bytes(), &tags)
+	assert.Empty(t, tags)
+
+	w = serve(s, "DELETE", "/api/tags/"+strconv.FormatUint(uint64(created.ID), 10), otherToken, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = serve(s, "DELETE", "/api/tags/"+strconv.FormatUint(uint64(created.ID), 10), token, nil)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
+
+func TestUploadsRoute_ForcesDownload(t *testing.T) {
+	dir := t.TempDir()
+	s := newTestServer(t, fxtest.NewLifecycle(t), func(cfg *config.Config) {
+		cfg.Upload.Dir = dir
+	})
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "page.html"), []byte("<script>alert(1)</script>"), 0o644))
+
+	w := serve(s, "GET", "/uploads/page.html", "", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "attachment", w.Header().Get("Content-Disposition"))
+	assert.Equal(t, "nosniff", w.Header().Get("X-Content-Type-Options"))
+}
+
+func TestServer_GracefulShutdown(t *testing.T) {
+	lc := fxtest.NewLifecycle(t)
+	s := newTestServer(t, lc)
+
+	// A slow endpoint to simulate an in-flight request during shutdown
+	started := make(chan struct{})
+	s.GetEngine().GET("/slow", func(c *gin.Context) {
+		close(started)
+		time.Sleep(200 * time.Millisecond)
+		c.String(http.StatusOK, "done")
+	})
+
+	lc.RequireStart()
+	baseURL := "http://" + s.Addr()
+
+	resp, err := http.Get(baseURL + "/api/users")
+	if assert.NoError(t, err) {
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+
+	slowResult := make(chan int, 1)
+	go func() {
+		resp, err := http.Get(baseURL + "/slow")
+		if err != nil {
+			slowResult <- 0
+			return
+		}
+		resp.Body.Close()
+		slowResult <- resp.StatusCode
+	}()
+	<-started
+
+	lc.RequireStop()
+
+	// The in-flight request completes and the listener is closed
+	assert.Equal(t, http.StatusOK, <-slowResult)
+	_, err = net.DialTimeout("tcp", s.Addr(), time.Second)
+	assert.Error(t, err)
+}
+
+// writeSelfSignedCert writes a self-signed certificate for 127.0.0.1 and its key to dir
+func writeSelfSignedCert(t *testing.T, dir string) (certFile, keyFile string, pool *x509.CertPool) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to encode key: %v", err)
+	}
+
+	certFile = filepath.Join(dir, "cert.pem")
+	keyFile = filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatalf("Failed to write certificate: %v", err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatalf("Failed to write key: %v", err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("Failed to parse certificate: %v", err)
+	}
+	pool = x509.NewCertPool()
+	pool.AddCert(cert)
+	return certFile, keyFile, pool
+}
+
+func TestServer_TLS(t *testing.T) {
+	certFile, keyFile, pool := writeSelfSignedCert(t, t.TempDir())
+
+	lc := fxtest.NewLifecycle(t)
+	s := newTestServer(t, lc, func(cfg *config.Config) {
+		cfg.Server.TLSCertFile = certFile
+		cfg.Server.TLSKeyFile = keyFile
+	})
+	lc.RequireStart()
+	defer lc.RequireStop()
+
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}}}
+	resp, err := client.Get("https://" + s.Addr() + "/api/users")
+	if assert.NoError(t, err) {
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.NotNil(t, resp.TLS)
+	}
+
+	// Plain HTTP is not served
+	resp, err = http.Get("http://" + s.Addr() + "/api/users")
+	if err == nil {
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	}
+}
+
+func TestServer_TLSRequiresCertAndKey(t *testing.T) {
+	certFile, _, _ := writeSelfSignedCert(t, t.TempDir())
+
+	lc := fxtest.NewLifecycle(t)
+	newTestServer(t, lc, func(cfg *config.Config) {
+		cfg.Server.TLSCertFile = certFile
+	})
+	assert.ErrorIs(t, lc.Start(context.Background()), errIncompleteTLSConfig)
+}
+
+func TestServer_ReadTimeoutDisconnectsSlowClient(t *testing.T) {
+	lc := fxtest.NewLifecycle(t)
+	s := newTestServer(t, lc, func(cfg *config.Config) {
+		cfg.Server.ReadTimeout = 100 * time.Millisecond
+	})
+	lc.RequireStart()
+	defer lc.RequireStop()
+
+	conn, err := net.Dial("tcp", s.Addr())
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer conn.Close()
+
+	// Send only part of the request headers and stall
+	_, err = conn.Write([]byte("GET /api/users HTTP/1.1\r\nHost: localhost\r\n"))
+	assert.NoError(t, err)
+
+	// The server closes the connection once the read timeout passes
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	start := time.Now()
+	_, err = io.ReadAll(conn)
+	assert.NoError(t, err)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestMetricsRoute(t *testing.T) {
+	s := setupTestServer(t)
+	token := loginTestUser(t, s, "metrics@example.com")
+
+	serve(s, "GET", "/api/items/1", token, nil)
+	w := serve(s, "GET", "/metrics", "", nil)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `http_requests_total{method="GET",route="/api/items/:item_id",status="404"} 1`)
+}
+
+func TestOpenAPIRoutes(t *testing.T) {
+	s := setupTestServer(t)
+
+	w := serve(s, "GET", "/api/openapi.json", "", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var spec struct {
+		OpenAPI string                     `json:"openapi"`
+		Paths   map[string]json.RawMessage `json:"paths"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &spec))
+	assert.Equal(t, "3.0.3", spec.OpenAPI)
+	assert.Contains(t, spec.Paths, "/api/items")
+	assert.Contains(t, spec.Paths, "/api/token")
+
+	w = serve(s, "GET", "/api/docs", "", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/html")
+	assert.Contains(t, w.Body.String(), "/api/openapi.json")
+}
+
+// undocumentedRoutes are served outside the JSON API, so the spec leaves them out
+var undocumentedRoutes = map[string]bool{
+	"/metrics":                   true,
+	"/uploads/*filepath":         true,
+	"/api/openapi.json":          true,
+	"/api/docs":                  true,
+	"/api/docs/assets/*filepath": true,
+}
+
+func TestRoutes_MatchOpenAPIOperations(t *testing.T) {
+	s := setupTestServer(t)
+
+	documented := make(map[string]bool)
+	for _, op := range openapi.Operations {
+		documented[op.Method+" "+op.Path] = true
+	}
+
+	registered := make(map[string]bool)
+	for _, route := range s.GetEngine().Routes() {
+		if undocumentedRoutes[route.Path] {
+			continue
+		}
+		key := route.Method + " " + route.Path
+		registered[key] = true
+		assert.True(t, documented[key], "route %s has no entry in openapi.Operations", key)
+	}
+	for key := range documented {
+		assert.True(t, registered[key], "openapi.Operations entry %s has no route", key)
+	}
+}
+
+func TestServer_ShutsDownWhenServingFails(t *testing.T) {
+	lc := fxtest.NewLifecycle(t)
+	s := newTestServer(t, lc)
+	shutdowner := &fakeShutdowner{called: make(chan struct{})}
+	s.shutdowner = shutdowner
+	lc.RequireStart()
+	defer lc.RequireStop()
+
+	// Closing the listener makes Serve fail without a graceful shutdown
+	s.listener.Close()
+
+	select {
+	case <-shutdowner.called:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the application to be shut down")
+	}
+}
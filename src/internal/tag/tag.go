@@ -2,6 +2,9 @@ package tag
 
 import (
 	"errors"
+	"regexp"
+	"strings"
+	"time"
 
 	"backend/internal/database"
 
@@ -24,8 +27,37 @@ var (
 	ErrTagNotFound = errors.New("tag not found")
 	// ErrTagAlreadyExists is returned when trying to create a tag that already exists
 	ErrTagAlreadyExists = errors.New("tag already exists")
+	// ErrTagOutsideOrganization is returned when a tag belongs to a different organization
+	ErrTagOutsideOrganization = errors.New("tag belongs to another organization")
+	// ErrMergeIntoSelf is returned when merging a tag into itself
+	ErrMergeIntoSelf = errors.New("cannot merge a tag into itself")
+	// ErrMergeNotFound is returned when a tag merge is not found
+	ErrMergeNotFound = errors.New("tag merge not found")
+	// ErrMergeAlreadyRestored is returned when a tag merge has already been undone
+	ErrMergeAlreadyRestored = errors.New("tag merge already restored")
+	// ErrMergeRestoreExpired is returned when a tag merge is too old to undo
+	ErrMergeRestoreExpired = errors.New("tag merge can no longer be restored")
+	// ErrInvalidColor is returned when a tag color is not a #RRGGBB hex value
+	ErrInvalidColor = errors.New("invalid tag color")
 )
 
+// colorPattern matches hex colors like #1a2B3c
+var colorPattern = regexp.MustCompile(`^#[0-9A-Fa-f]{6}$`)
+
+// ValidateColor checks that a tag color is empty or a #RRGGBB hex value
+func ValidateColor(color string) error {
+	if color != "" && !colorPattern.MatchString(color) {
+		return ErrInvalidColor
+	}
+	return nil
+}
+
+// TagUsage is a tag along with the number of items carrying it
+type TagUsage struct {
+	database.Tag
+	UsageCount int64 `json:"usage_count"`
+}
+
 // NewTagService creates a new tag service
 func NewTagService(db *gorm.DB) *Service {
 	return &Service{
@@ -35,13 +67,22 @@ func NewTagService(db *gorm.DB) *Service {
 
 // CreateTag creates a new tag
 func (s *Service) CreateTag(name string, organizationID uint) (*database.Tag, error) {
+	taken, err := s.IsNameTaken(organizationID, name, 0)
+	if err != nil {
+		return nil, err
+	}
+	if taken {
+		return nil, ErrTagAlreadyExists
+	}
+
 	tag := &database.Tag{
 		Name:           name,
 		OrganizationID: organizationID,
 	}
 
 	if err := s.db.Create(tag).Error; err != nil {
-		if errors.Is(err, gorm.ErrDuplicatedKey) {
+		// A concurrent create can still win the race after the check above
+		if database.IsUniqueViolation(err) {
 			return nil, ErrTagAlreadyExists
 		}
 		return nil, err
@@ -50,6 +91,19 @@ func (s *Service) CreateTag(name string, organizationID uint) (*database.Tag, er
 	return tag, nil
 }
 
+// IsNameTaken reports whether a tag of the organization other than excludeID has the name
+func (s *Service) IsNameTaken(organizationID uint, name string, excludeID uint) (bool, error) {
+	return isNameTaken(s.db, organizationID, name, excludeID)
+}
+
+func isNameTaken(db *gorm.DB, organizationID uint, name string, excludeID uint) (bool, error) {
+	var count int64
+	err := db.Model(&database.Tag{}).
+		Where("organization_id = ? AND name = ? AND id <> ?", organizationID, name, excludeID).
+		Count(&count).Error
+	return count > 0, err
+}
+
 // GetTag retrieves a tag by ID
 func (s *Service) GetTag(id uint) (*database.Tag, error) {
 	var tag database.Tag
@@ -75,9 +129,91 @@ func (s *Service) GetTagsByOrganization(organizationID uint) ([]database.Tag, er
 	return tags, nil
 }
 
-// DeleteTag deletes a tag
-func (s *Service) DeleteTag(id uint) error {
-	result := s.db.Delete(&database.Tag{}, id)
+// GetTagsWithUsage retrieves all tags for an organization in the given order, counting
+// the items that carry each tag in the same query; deleted items are not counted
+func (s *Service) GetTagsWithUsage(organizationID uint, order string) ([]TagUsage, error) {
+	tags := []TagUsage{}
+
+	if err := s.db.Model(&database.Tag{}).
+		Select("tags.*, COUNT(items.id) AS usage_count").
+		Joins("LEFT JOIN item_tags ON item_tags.tag_id = tags.id").
+		Joins("LEFT JOIN items ON items.id = item_tags.item_id AND items.deleted_at IS NULL").
+		Where("tags.organization_id = ?", organizationID).
+		Group("tags.id").
+		Order(order).
+		Find(&tags).Error; err != nil {
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+// SearchTags retrieves up to limit of an organization's tags whose name contains query
+func (s *Service) SearchTags(organizationID uint, query string, limit int) ([]database.Tag, error) {
+	tags := []database.Tag{}
+
+	if err := s.db.Where(`organization_id = ? AND LOWER(name) LIKE ? ESCAPE '\'`, organizationID, database.ContainsPattern(strings.ToLower(query))).
+		Order("name, id").
+		Limit(limit).
+		Find(&tags).Error; err != nil {
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+// UpdateTag changes a tag's name, color and icon; nil values are left unchanged
+func (s *Service) UpdateTag(id uint, name, color, icon *string) (*database.Tag, error) {
+	if color != nil {
+		if err := ValidateColor(*color); err != nil {
+			return nil, err
+		}
+	}
+
+	tag, err := s.GetTag(id)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{}
+	if name != nil && *name != tag.Name {
+		taken, err := s.IsNameTaken(tag.OrganizationID, *name, tag.ID)
+		if err != nil {
+			return nil, err
+		}
+		if taken {
+			return nil, ErrTagAlreadyExists
+		}
+		updates["name"] = *name
+	}
+	if color != nil {
+		updates["color"] = *color
+	}
+	if icon != nil {
+		updates["icon"] = *icon
+	}
+	if len(updates) == 0 {
+		return tag, nil
+	}
+
+	if err := s.db.Model(tag).Updates(updates).Error; err != nil {
+		if database.IsUniqueViolation(err) {
+			return nil, ErrTagAlreadyExists
+		}
+		return nil, err
+	}
+
+	return s.GetTag(id)
+}
+
+// DeleteTag soft-deletes a tag, or removes it permanently when hard is set
+func (s *Service) DeleteTag(id uint, hard bool) error {
+	db := s.db
+	if hard {
+		db = db.Unscoped()
+	}
+
+	result := db.Delete(&database.Tag{}, id)
 	if result.Error != nil {
 		return result.Error
 	}
@@ -111,3 +247,160 @@ func (s *Service) GetTagsByItem(itemID uint) ([]database.Tag, error) {
 
 	return tags, nil
 }
+
+// MergeTags moves all items tagged with the source tag to the target tag and deletes the source,
+// recording the merge so it can be restored later
+func (s *Service) MergeTags(sourceID, targetID, organizationID uint) (*database.TagMerge, error) {
+	if sourceID == targetID {
+		return nil, ErrMergeIntoSelf
+	}
+
+	var merge *database.TagMerge
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		source, err := getOrganizationTag(tx, sourceID, organizationID)
+		if err != nil {
+			return err
+		}
+		target, err := getOrganizationTag(tx, targetID, organizationID)
+		if err != nil {
+			return err
+		}
+
+		var itemIDs, targetItemIDs []uint
+		if err := tx.Table("item_tags").Where("tag_id = ?", source.ID).Pluck("item_id", &itemIDs).Error; err != nil {
+			return err
+		}
+		if err := tx.Table("item_tags").Where("tag_id = ? AND item_id IN ?", target.ID, itemIDs).Pluck("item_id", &targetItemIDs).Error; err != nil {
+			return err
+		}
+		hadTarget := make(map[uint]bool, len(targetItemIDs))
+		for _, id := range targetItemIDs {
+			hadTarget[id] = true
+		}
+
+		merge = &database.TagMerge{
+			OrganizationID: organizationID,
+			SourceTagID:    source.ID,
+			SourceName:     source.Name,
+			SourceColor:    source.Color,
+			SourceIcon:     source.Icon,
+			TargetTagID:    target.ID,
+		}
+		for _, id := range itemIDs {
+			merge.Items = append(merge.Items, database.TagMergeItem{ItemID: id, HadTarget: hadTarget[id]})
+		}
+		if err := tx.Create(merge).Error; err != nil {
+			return err
+		}
+
+		// Reassign items, skipping those that already carry the target tag
+		if err := tx.Exec(`INSERT INTO item_tags (item_id, tag_id)
+			SELECT item_id, ? FROM item_tags
+			WHERE tag_id = ? AND item_id NOT IN (SELECT item_id FROM item_tags WHERE tag_id = ?)`,
+			target.ID, source.ID, target.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM item_tags WHERE tag_id = ?", source.ID).Error; err != nil {
+			return err
+		}
+		// Removed for good so a restore can recreate the tag under its original ID
+		return tx.Unscoped().Delete(source).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return merge, nil
+}
+
+// getOrganizationTag retrieves a tag by ID, making sure it belongs to the organization
+func getOrganizationTag(tx *gorm.DB, id, organizationID uint) (*database.Tag, error) {
+	var tag database.Tag
+	if err := tx.First(&tag, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTagNotFound
+		}
+		return nil, err
+	}
+	if tag.OrganizationID != organizationID {
+		return nil, ErrTagOutsideOrganization
+	}
+	return &tag, nil
+}
+
+// RestoreMerge undoes a tag merge made within the given window, recreating the
+// source tag and reassigning it to the items that carried it
+func (s *Service) RestoreMerge(mergeID, organizationID uint, window time.Duration) (*database.Tag, error) {
+	var restored *database.Tag
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var merge database.TagMerge
+		if err := tx.Preload("Items").First(&merge, mergeID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrMergeNotFound
+			}
+			return err
+		}
+		if merge.OrganizationID != organizationID {
+			return ErrMergeNotFound
+		}
+		if merge.RestoredAt != nil {
+			return ErrMergeAlreadyRestored
+		}
+		if time.Since(merge.CreatedAt) > window {
+			return ErrMergeRestoreExpired
+		}
+
+		// The source name may have been given to another tag since the merge
+		taken, err := isNameTaken(tx, merge.OrganizationID, merge.SourceName, 0)
+		if err != nil {
+			return err
+		}
+		if taken {
+			return ErrTagAlreadyExists
+		}
+
+		// Recreate the source tag under its original ID so existing references stay valid
+		restored = &database.Tag{
+			ID:             merge.SourceTagID,
+			Name:           merge.SourceName,
+			Color:          merge.SourceColor,
+			Icon:           merge.SourceIcon,
+			OrganizationID: merge.OrganizationID,
+		}
+		if err := tx.Create(restored).Error; err != nil {
+			if database.IsUniqueViolation(err) {
+				return ErrTagAlreadyExists
+			}
+			return err
+		}
+
+		var itemIDs, targetOnly []uint
+		for _, mergeItem := range merge.Items {
+			itemIDs = append(itemIDs, mergeItem.ItemID)
+			if !mergeItem.HadTarget {
+				targetOnly = append(targetOnly, mergeItem.ItemID)
+			}
+		}
+
+		if len(itemIDs) > 0 {
+			// Items removed or moved to the trash since the merge are skipped
+			if err := tx.Exec("INSERT INTO item_tags (item_id, tag_id) SELECT id, ? FROM items WHERE id IN ? AND deleted_at IS NULL",
+				restored.ID, itemIDs).Error; err != nil {
+				return err
+			}
+		}
+		if len(targetOnly) > 0 {
+			if err := tx.Exec("DELETE FROM item_tags WHERE tag_id = ? AND item_id IN ?",
+				merge.TargetTagID, targetOnly).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&merge).Update("restored_at", time.Now()).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return restored, nil
+}
@@ -0,0 +1,407 @@
+package tag
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"backend/internal/database"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+
+	// Auto migrate all models
+	err = db.AutoMigrate(&database.Organization{}, &database.User{}, &database.OrganizationUser{}, &database.Item{}, &database.Tag{}, &database.TagMerge{}, &database.TagMergeItem{})
+	if err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+
+	return db
+}
+
+// createTestItem creates an item tagged with the given tags
+func createTestItem(t *testing.T, db *gorm.DB, name string, tags ...*database.Tag) database.Item {
+	item := database.Item{Name: name, UserEmail: "test@example.com"}
+	if err := db.Create(&item).Error; err != nil {
+		t.Fatalf("Failed to create item: %v", err)
+	}
+	for _, tag := range tags {
+		if err := db.Exec("INSERT INTO item_tags (item_id, tag_id) VALUES (?, ?)", item.ID, tag.ID).Error; err != nil {
+			t.Fatalf("Failed to tag item: %v", err)
+		}
+	}
+	return item
+}
+
+// taggedItemIDs returns the sorted IDs of items carrying a tag
+func taggedItemIDs(t *testing.T, db *gorm.DB, tagID uint) []uint {
+	var ids []uint
+	if err := db.Table("item_tags").Where("tag_id = ?", tagID).Order("item_id").Pluck("item_id", &ids).Error; err != nil {
+		t.Fatalf("Failed to get tagged items: %v", err)
+	}
+	return ids
+}
+
+func equalIDs(a, b []uint) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestMergeTags_ReassignsItems(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewTagService(db)
+
+	source, _ := service.CreateTag("tools", 1)
+	target, _ := service.CreateTag("Tools", 1)
+	hammer := createTestItem(t, db, "Hammer", source)
+	saw := createTestItem(t, db, "Saw", source, target)
+	drill := createTestItem(t, db, "Drill", target)
+
+	merge, err := service.MergeTags(source.ID, target.ID, 1)
+	if err != nil {
+		t.Fatalf("Failed to merge tags: %v", err)
+	}
+	if len(merge.Items) != 2 {
+		t.Errorf("Expected 2 affected items recorded, got %d", len(merge.Items))
+	}
+
+	if ids := taggedItemIDs(t, db, target.ID); !equalIDs(ids, []uint{hammer.ID, saw.ID, drill.ID}) {
+		t.Errorf("Expected all items tagged with target, got %v", ids)
+	}
+	if _, err := service.GetTag(source.ID); !errors.Is(err, ErrTagNotFound) {
+		t.Errorf("Expected source tag to be deleted, got %v", err)
+	}
+}
+
+func TestMergeTags_OtherOrganization(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewTagService(db)
+
+	source, _ := service.CreateTag("tools", 1)
+	target, _ := service.CreateTag("Tools", 2)
+
+	if _, err := service.MergeTags(source.ID, target.ID, 1); !errors.Is(err, ErrTagOutsideOrganization) {
+		t.Errorf("Expected ErrTagOutsideOrganization, got %v", err)
+	}
+}
+
+func TestRestoreMerge_RestoresAssociations(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewTagService(db)
+
+	source, _ := service.CreateTag("tools", 1)
+	target, _ := service.CreateTag("Tools", 1)
+	hammer := createTestItem(t, db, "Hammer", source)
+	saw := createTestItem(t, db, "Saw", source, target)
+	drill := createTestItem(t, db, "Drill", target)
+
+	merge, err := service.MergeTags(source.ID, target.ID, 1)
+	if err != nil {
+		t.Fatalf("Failed to merge tags: %v", err)
+	}
+
+	restored, err := service.RestoreMerge(merge.ID, 1, time.Hour)
+	if err != nil {
+		t.Fatalf("Failed to restore merge: %v", err)
+	}
+	if restored.ID != source.ID || restored.Name != "tools" {
+		t.Errorf("Expected source tag %d 'tools' to be recreated, got %d '%s'", source.ID, restored.ID, restored.Name)
+	}
+
+	if ids := taggedItemIDs(t, db, source.ID); !equalIDs(ids, []uint{hammer.ID, saw.ID}) {
+		t.Errorf("Expected source tag back on its items, got %v", ids)
+	}
+	if ids := taggedItemIDs(t, db, target.ID); !equalIDs(ids, []uint{saw.ID, drill.ID}) {
+		t.Errorf("Expected target tag only on its original items, got %v", ids)
+	}
+
+	if _, err := service.RestoreMerge(merge.ID, 1, time.Hour); !errors.Is(err, ErrMergeAlreadyRestored) {
+		t.Errorf("Expected ErrMergeAlreadyRestored, got %v", err)
+	}
+}
+
+func TestRestoreMerge_SkipsTrashedItems(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewTagService(db)
+
+	source, _ := service.CreateTag("tools", 1)
+	target, _ := service.CreateTag("Tools", 1)
+	hammer := createTestItem(t, db, "Hammer", source)
+	saw := createTestItem(t, db, "Saw", source)
+
+	merge, err := service.MergeTags(source.ID, target.ID, 1)
+	if err != nil {
+		t.Fatalf("Failed to merge tags: %v", err)
+	}
+	if err := db.Delete(&saw).Error; err != nil {
+		t.Fatalf("Failed to trash item: %v", err)
+	}
+
+	if _, err := service.RestoreMerge(merge.ID, 1, time.Hour); err != nil {
+		t.Fatalf("Failed to restore merge: %v", err)
+	}
+	if ids := taggedItemIDs(t, db, source.ID); !equalIDs(ids, []uint{hammer.ID}) {
+		t.Errorf("Expected source tag back only on items outside the trash, got %v", ids)
+	}
+}
+
+func TestRestoreMerge_RestoresIcon(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewTagService(db)
+
+	source, _ := service.CreateTag("tools", 1)
+	target, _ := service.CreateTag("Tools", 1)
+	icon := "wrench"
+	if _, err := service.UpdateTag(source.ID, nil, nil, &icon); err != nil {
+		t.Fatalf("Failed to set icon: %v", err)
+	}
+
+	merge, err := service.MergeTags(source.ID, target.ID, 1)
+	if err != nil {
+		t.Fatalf("Failed to merge tags: %v", err)
+	}
+	if merge.SourceIcon != icon {
+		t.Errorf("Expected the merge to record icon '%s', got '%s'", icon, merge.SourceIcon)
+	}
+
+	restored, err := service.RestoreMerge(merge.ID, 1, time.Hour)
+	if err != nil {
+		t.Fatalf("Failed to restore merge: %v", err)
+	}
+	if restored.Icon != icon {
+		t.Errorf("Expected restored icon '%s', got '%s'", icon, restored.Icon)
+	}
+}
+
+func TestRestoreMerge_Expired(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewTagService(db)
+
+	source, _ := service.CreateTag("tools", 1)
+	target, _ := service.CreateTag("Tools", 1)
+
+	merge, err := service.MergeTags(source.ID, target.ID, 1)
+	if err != nil {
+		t.Fatalf("Failed to merge tags: %v", err)
+	}
+	db.Model(merge).Update("created_at", time.Now().Add(-2*time.Hour))
+
+	if _, err := service.RestoreMerge(merge.ID, 1, time.Hour); !errors.Is(err, ErrMergeRestoreExpired) {
+		t.Errorf("Expected ErrMergeRestoreExpired, got %v", err)
+	}
+}
+
+func TestRestoreMerge_OtherOrganization(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewTagService(db)
+
+	source, _ := service.CreateTag("tools", 1)
+	target, _ := service.CreateTag("Tools", 1)
+
+	merge, err := service.MergeTags(source.ID, target.ID, 1)
+	if err != nil {
+		t.Fatalf("Failed to merge tags: %v", err)
+	}
+
+	if _, err := service.RestoreMerge(merge.ID, 2, time.Hour); !errors.Is(err, ErrMergeNotFound) {
+		t.Errorf("Expected ErrMergeNotFound, got %v", err)
+	}
+}
+
+func TestRestoreMerge_NameReused(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewTagService(db)
+
+	source, _ := service.CreateTag("tools", 1)
+	target, _ := service.CreateTag("Tools", 1)
+
+	merge, err := service.MergeTags(source.ID, target.ID, 1)
+	if err != nil {
+		t.Fatalf("Failed to merge tags: %v", err)
+	}
+	service.CreateTag("tools", 1)
+
+	if _, err := service.RestoreMerge(merge.ID, 1, time.Hour); !errors.Is(err, ErrTagAlreadyExists) {
+		t.Errorf("Expected ErrTagAlreadyExists, got %v", err)
+	}
+
+	var restored database.TagMerge
+	db.First(&restored, merge.ID)
+	if restored.RestoredAt != nil {
+		t.Error("Expected the merge to stay restorable")
+	}
+}
+
+func TestDeleteTag_SoftAndHard(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewTagService(db)
+
+	soft := &database.Tag{Name: "soft", OrganizationID: 1}
+	hard := &database.Tag{Name: "hard", OrganizationID: 1}
+	db.Create(soft)
+	db.Create(hard)
+
+	if err := service.DeleteTag(soft.ID, false); err != nil {
+		t.Fatalf("Failed to delete tag: %v", err)
+	}
+	if err := service.DeleteTag(hard.ID, true); err != nil {
+		t.Fatalf("Failed to delete tag: %v", err)
+	}
+
+	if _, err := service.GetTag(soft.ID); !errors.Is(err, ErrTagNotFound) {
+		t.Errorf("Expected soft-deleted tag to be hidden, got %v", err)
+	}
+
+	var count int64
+	db.Unscoped().Model(&database.Tag{}).Where("id = ?", soft.ID).Count(&count)
+	if count != 1 {
+		t.Errorf("Expected soft-deleted tag to be kept, got %d rows", count)
+	}
+	db.Unscoped().Model(&database.Tag{}).Where("id = ?", hard.ID).Count(&count)
+	if count != 0 {
+		t.Errorf("Expected hard-deleted tag to be removed, got %d rows", count)
+	}
+}
+
+func TestValidateColor(t *testing.T) {
+	for _, color := range []string{"", "#000000", "#1a2B3c", "#FFFFFF"} {
+		if err := ValidateColor(color); err != nil {
+			t.Errorf("Expected color %q to be valid, got %v", color, err)
+		}
+	}
+	for _, color := range []string{"red", "#fff", "#12345g", "1a2b3c", "#1a2b3c4d"} {
+		if err := ValidateColor(color); !errors.Is(err, ErrInvalidColor) {
+			t.Errorf("Expected color %q to be rejected, got %v", color, err)
+		}
+	}
+}
+
+func TestUpdateTag(t *testing.T) {
+	service := NewTagService(setupTestDB(t))
+	created, err := service.CreateTag("camping", 1)
+	if err != nil {
+		t.Fatalf("Failed to create tag: %v", err)
+	}
+
+	color, icon := "#00ff00", "tent"
+	updated, err := service.UpdateTag(created.ID, nil, &color, &icon)
+	if err != nil {
+		t.Fatalf("Failed to update tag: %v", err)
+	}
+	if updated.Name != "camping" || updated.Color != color || updated.Icon != icon {
+		t.Errorf("Unexpected tag after update: %+v", updated)
+	}
+
+	invalid := "green"
+	if _, err := service.UpdateTag(created.ID, nil, &invalid, nil); !errors.Is(err, ErrInvalidColor) {
+		t.Errorf("Expected ErrInvalidColor, got %v", err)
+	}
+}
+
+func TestUpdateTag_DuplicateName(t *testing.T) {
+	service := NewTagService(setupTestDB(t))
+	camping, _ := service.CreateTag("camping", 1)
+	service.CreateTag("hiking", 1)
+	service.CreateTag("fishing", 2)
+
+	name := "hiking"
+	if _, err := service.UpdateTag(camping.ID, &name, nil, nil); !errors.Is(err, ErrTagAlreadyExists) {
+		t.Errorf("Expected ErrTagAlreadyExists, got %v", err)
+	}
+
+	// Keeping its own name or taking a name used in another organization is fine
+	for _, name := range []string{"camping", "fishing"} {
+		if _, err := service.UpdateTag(camping.ID, &name, nil, nil); err != nil {
+			t.Errorf("Expected renaming to '%s' to succeed, got %v", name, err)
+		}
+	}
+}
+
+func TestCreateTag_DuplicateName(t *testing.T) {
+	service := NewTagService(setupTestDB(t))
+	service.CreateTag("camping", 1)
+
+	if _, err := service.CreateTag("camping", 1); !errors.Is(err, ErrTagAlreadyExists) {
+		t.Errorf("Expected ErrTagAlreadyExists, got %v", err)
+	}
+	if _, err := service.CreateTag("camping", 2); err != nil {
+		t.Errorf("Expected the name to be free in another organization, got %v", err)
+	}
+}
+
+func TestCreateTag_ConcurrentDuplicate(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewTagService(db)
+
+	// Simulate a concurrent request creating the same tag after the name check
+	raced := false
+	db.Callback().Create().Before("gorm:create").Register("test:race", func(tx *gorm.DB) {
+		if raced || tx.Statement.Table != "tags" {
+			return
+		}
+		raced = true
+		if _, err := tx.Statement.ConnPool.ExecContext(tx.Statement.Context,
+			"INSERT INTO tags (name, organization_id) VALUES ('camping', 1)"); err != nil {
+			t.Fatalf("Failed to insert racing tag: %v", err)
+		}
+	})
+
+	if _, err := service.CreateTag("camping", 1); !errors.Is(err, ErrTagAlreadyExists) {
+		t.Errorf("Expected ErrTagAlreadyExists, got %v", err)
+	}
+}
+
+func TestCreateTag_ReusesDeletedName(t *testing.T) {
+	service := NewTagService(setupTestDB(t))
+	camping, _ := service.CreateTag("camping", 1)
+	if err := service.DeleteTag(camping.ID, false); err != nil {
+		t.Fatalf("Failed to delete tag: %v", err)
+	}
+
+	if _, err := service.CreateTag("camping", 1); err != nil {
+		t.Errorf("Expected the name of a deleted tag to be free, got %v", err)
+	}
+}
+
+func TestGetTagsWithUsage(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewTagService(db)
+	camping, _ := service.CreateTag("camping", 1)
+	tools, _ := service.CreateTag("tools", 1)
+	unused, _ := service.CreateTag("unused", 1)
+	service.CreateTag("elsewhere", 2)
+
+	createTestItem(t, db, "Tent", camping)
+	createTestItem(t, db, "Stove", camping, tools)
+	deleted := createTestItem(t, db, "Old Tent", camping)
+	db.Delete(&deleted)
+
+	tags, err := service.GetTagsWithUsage(1, "tags.name")
+	if err != nil {
+		t.Fatalf("Failed to get tags: %v", err)
+	}
+
+	expected := map[uint]int64{camping.ID: 2, tools.ID: 1, unused.ID: 0}
+	if len(tags) != len(expected) {
+		t.Fatalf("Expected %d tags, got %d", len(expected), len(tags))
+	}
+	for _, tag := range tags {
+		if tag.UsageCount != expected[tag.ID] {
+			t.Errorf("Expected tag %q to be used %d times, got %d", tag.Name, expected[tag.ID], tag.UsageCount)
+		}
+	}
+}
@@ -1,12 +1,20 @@
 package user
 
 import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
 	"errors"
-	"log"
+	"fmt"
+	"strings"
 
+	"backend/internal/config"
 	"backend/internal/database"
+	"backend/internal/item"
+	"backend/internal/organization"
 
 	"go.uber.org/fx"
+	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
@@ -17,7 +25,9 @@ var Module = fx.Module("user",
 
 // Service handles user operations
 type Service struct {
-	db *gorm.DB
+	db                *gorm.DB
+	bcryptCost        int
+	minPasswordLength int
 }
 
 var (
@@ -27,15 +37,78 @@ var (
 	ErrInvalidCredentials = errors.New("invalid credentials")
 	// ErrUserNotFound is returned when user is not found
 	ErrUserNotFound = errors.New("user not found")
+	// ErrInvalidVerificationToken is returned when an email verification token is unknown or already used
+	ErrInvalidVerificationToken = errors.New("invalid verification token")
+	// ErrPasswordTooShort is returned when a password is shorter than the configured minimum
+	ErrPasswordTooShort = errors.New("password too short")
+	// ErrUserInactive is returned when a deactivated user presents valid credentials
+	ErrUserInactive = errors.New("user inactive")
 )
 
+const (
+	// RoleUser is the role of regular users
+	RoleUser = "user"
+	// RoleAdmin is the role of administrators
+	RoleAdmin = "admin"
+)
+
+// NewUser describes a user to create in a batch
+type NewUser struct {
+	Email string
+	Role  string
+	// Password is optional; a random password is generated when it is empty
+	Password string
+}
+
+// CreateResult reports the outcome of creating a single user in a batch
+type CreateResult struct {
+	Email string
+	Err   error
+}
+
 // NewUserService creates a new user service
-func NewUserService(db *gorm.DB) *Service {
+func NewUserService(db *gorm.DB, cfg *config.Config) *Service {
+	minPasswordLength := cfg.Security.MinPasswordLength
+	if minPasswordLength <= 0 {
+		minPasswordLength = config.DefaultMinPasswordLength
+	}
+
 	return &Service{
-		db: db,
+		db:                db,
+		bcryptCost:        cfg.Security.BcryptCost,
+		minPasswordLength: minPasswordLength,
 	}
 }
 
+// WithTx returns a copy of the service that runs its queries in tx
+func (s *Service) WithTx(tx *gorm.DB) *Service {
+	clone := *s
+	clone.db = tx
+	return &clone
+}
+
+// MinPasswordLength returns the shortest password the service accepts
+func (s *Service) MinPasswordLength() int {
+	return s.minPasswordLength
+}
+
+// ValidatePassword checks a password against the configured strength requirements
+func (s *Service) ValidatePassword(password string) error {
+	if len([]rune(password)) < s.minPasswordLength {
+		return ErrPasswordTooShort
+	}
+	return nil
+}
+
+// HashPassword hashes a password with the configured bcrypt cost
+func (s *Service) HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), s.bcryptCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
 // CreateUser creates a new user
 func (s *Service) CreateUser(email, password string) error {
 	// Validate input
@@ -45,55 +118,196 @@ func (s *Service) CreateUser(email, password string) error {
 	if password == "" {
 		return errors.New("password cannot be empty")
 	}
+	if err := s.ValidatePassword(password); err != nil {
+		return err
+	}
 
-	// Start a transaction
-	tx := s.db.Begin()
-	if tx.Error != nil {
-		return tx.Error
+	hash, err := s.HashPassword(password)
+	if err != nil {
+		return err
 	}
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		return createUser(tx, email, hash, RoleUser)
+	})
+}
+
+// CreateUsers creates several users in one transaction, generating random passwords where none is given,
+// reporting the outcome for each user instead of failing the whole batch
+func (s *Service) CreateUsers(users []NewUser) ([]CreateResult, error) {
+	results := make([]CreateResult, len(users))
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		for i, newUser := range users {
+			results[i].Email = newUser.Email
+
+			// Skip users that already exist (including duplicates within the batch)
+			var count int64
+			if err := tx.Unscoped().Model(&database.User{}).Where("email = ?", newUser.Email).Count(&count).Error; err != nil {
+				return err
+			}
+			if count > 0 {
+				results[i].Err = ErrUserAlreadyExists
+				continue
+			}
+
+			password := newUser.Password
+			if password == "" {
+				generated, err := generatePassword()
+				if err != nil {
+					return err
+				}
+				password = generated
+			} else if err := s.ValidatePassword(password); err != nil {
+				results[i].Err = err
+				continue
+			}
+			hash, err := s.HashPassword(password)
+			if err != nil {
+				return err
+			}
+
+			// Use a savepoint so a failed row doesn't abort the whole transaction
+			savepoint := fmt.Sprintf("create_user_%d", i)
+			if err := tx.SavePoint(savepoint).Error; err != nil {
+				return err
+			}
+			if err := createUser(tx, newUser.Email, hash, newUser.Role); err != nil {
+				if err := tx.RollbackTo(savepoint).Error; err != nil {
+					return err
+				}
+				results[i].Err = err
+			}
 		}
-	}()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// createUser creates a user with an already hashed password and their personal organization within a transaction
+func createUser(tx *gorm.DB, email, passwordHash, role string) error {
+	if role == "" {
+		role = RoleUser
+	}
+
+	// Check up front so the duplicate is reported before anything is written;
+	// soft-deleted users keep their email until they are removed for good
+	var count int64
+	if err := tx.Unscoped().Model(&database.User{}).Where("email = ?", email).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrUserAlreadyExists
+	}
 
 	// Create organization first
-	organization := &database.Organization{
+	personal := &database.Organization{
 		Name: email + "_org", // Simple organization name
 	}
 
-	if err := tx.Create(organization).Error; err != nil {
-		tx.Rollback()
+	if err := tx.Create(personal).Error; err != nil {
 		return err
 	}
 
 	// Create user with organization
 	user := &database.User{
 		Email:                email,
-		Password:             password,
-		ActiveOrganizationID: organization.ID,
-		Prefix:               email[:3], // Simple prefix from email
+		Password:             passwordHash,
+		Role:                 role,
+		ActiveOrganizationID: personal.ID,
+		Prefix:               prefixFromEmail(email),
+		Active:               true,
 	}
 
 	if err := tx.Create(user).Error; err != nil {
-		tx.Rollback()
-		// Check if it's a duplicate key error
-		if errors.Is(err, gorm.ErrDuplicatedKey) {
+		// A concurrent registration can still win the race after the check above
+		if database.IsUniqueViolation(err) {
 			return ErrUserAlreadyExists
 		}
 		return err
 	}
 
-	// Add user to organization_users junction table using GORM
-	// Note: This might fail in SQLite tests, so we'll skip it for now
-	// In production with PostgreSQL, this will work correctly
-	if err := tx.Model(&organization).Association("Users").Append(user); err != nil {
-		// Log the error but don't fail - this is expected in SQLite tests
-		log.Printf("Warning: Could not add user to organization association: %v", err)
+	// The user owns their personal organization
+	membership := &database.OrganizationUser{
+		OrganizationID: personal.ID,
+		UserEmail:      email,
+		Role:           organization.RoleOwner,
+	}
+
+	return tx.Create(membership).Error
+}
+
+// prefixFromEmail derives a backpack prefix from the first letters of an email,
+// falling back to a random prefix when there are not enough letters
+func prefixFromEmail(email string) string {
+	var letters []rune
+	for _, r := range strings.ToUpper(email) {
+		if r == '@' || len(letters) == 3 {
+			break
+		}
+		if r >= 'A' && r <= 'Z' {
+			letters = append(letters, r)
+		}
+	}
+
+	prefix := string(letters)
+	if item.ValidatePrefix(prefix) != nil {
+		return item.GeneratePrefix()
+	}
+	return prefix
+}
+
+// generatePassword generates a random password for users created on someone else's behalf
+func generatePassword() (string, error) {
+	return generateRandomString(18)
+}
+
+// generateRandomString generates a URL-safe random string from n random bytes
+func generateRandomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// CreateVerificationToken generates and stores a new email verification token for a user
+func (s *Service) CreateVerificationToken(email string) (string, error) {
+	token, err := generateRandomString(32)
+	if err != nil {
+		return "", err
+	}
+
+	result := s.db.Model(&database.User{}).Where("email = ?", email).Update("verification_token", token)
+	if result.Error != nil {
+		return "", result.Error
+	}
+	if result.RowsAffected == 0 {
+		return "", ErrUserNotFound
+	}
+	return token, nil
+}
+
+// VerifyEmail marks the user owning the verification token as verified and clears the token
+func (s *Service) VerifyEmail(token string) error {
+	if token == "" {
+		return ErrInvalidVerificationToken
 	}
 
-	// Commit transaction
-	return tx.Commit().Error
+	result := s.db.Model(&database.User{}).
+		Where("verification_token = ?", token).
+		Updates(map[string]interface{}{"email_verified": true, "verification_token": ""})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInvalidVerificationToken
+	}
+	return nil
 }
 
 // ValidateUser validates user credentials
@@ -107,13 +321,114 @@ func (s *Service) ValidateUser(email, password string) error {
 		return err
 	}
 
-	if user.Password != password {
+	if _, err := bcrypt.Cost([]byte(user.Password)); err != nil {
+		// Passwords stored before hashing was introduced are plaintext; hash them on first use
+		if user.Password == "" || subtle.ConstantTimeCompare([]byte(user.Password), []byte(password)) != 1 {
+			return ErrInvalidCredentials
+		}
+		if err := s.rehashPlaintextPassword(email, user.Password); err != nil {
+			return err
+		}
+	} else if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
 		return ErrInvalidCredentials
 	}
 
+	// Checked after the password so the account state isn't revealed to guesses
+	if !user.Active {
+		return ErrUserInactive
+	}
+
 	return nil
 }
 
+// rehashPlaintextPassword replaces a plaintext password with its hash, unless the
+// password was changed in the meantime
+func (s *Service) rehashPlaintextPassword(email, plaintext string) error {
+	hash, err := s.HashPassword(plaintext)
+	if err != nil {
+		return err
+	}
+	return s.db.Model(&database.User{}).
+		Where("email = ? AND password = ?", email, plaintext).
+		Update("password", hash).Error
+}
+
+// SetActive deactivates or reactivates a user
+func (s *Service) SetActive(email string, active bool) error {
+	result := s.db.Model(&database.User{}).Where("email = ?", email).Update("active", active)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// UpdatePassword hashes and stores a new password for a user
+func (s *Service) UpdatePassword(email, password string) error {
+	if err := s.ValidatePassword(password); err != nil {
+		return err
+	}
+
+	hash, err := s.HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	result := s.db.Model(&database.User{}).Where("email = ?", email).Update("password", hash)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// UpdateProfile sets the user's display name and avatar URL; nil values are left unchanged
+func (s *Service) UpdateProfile(email string, displayName, avatarURL *string) (*database.User, error) {
+	updates := make(map[string]interface{})
+	if displayName != nil {
+		updates["display_name"] = strings.TrimSpace(*displayName)
+	}
+	if avatarURL != nil {
+		updates["avatar_url"] = *avatarURL
+	}
+
+	if len(updates) > 0 {
+		result := s.db.Model(&database.User{}).Where("email = ?", email).Updates(updates)
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil, ErrUserNotFound
+		}
+	}
+
+	return s.GetUser(email)
+}
+
+// ListUsers returns a page of users ordered by email along with the total number of
+// users; a non-empty emailFilter keeps users whose email contains it, ignoring case
+func (s *Service) ListUsers(emailFilter string, offset, limit int) ([]database.User, int64, error) {
+	query := s.db.Model(&database.User{})
+	if emailFilter != "" {
+		query = query.Where(`LOWER(email) LIKE LOWER(?) ESCAPE '\'`, database.ContainsPattern(emailFilter))
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	users := []database.User{}
+	if err := query.Order("email").Offset(offset).Limit(limit).Find(&users).Error; err != nil {
+		return nil, 0, err
+	}
+	return users, total, nil
+}
+
 // GetUser retrieves a user by email
 func (s *Service) GetUser(email string) (*database.User, error) {
 	var user database.User
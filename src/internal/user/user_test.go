@@ -1,11 +1,17 @@
 package user
 
 import (
+	"encoding/json"
 	"errors"
+	"strings"
 	"testing"
+	"time"
 
+	"backend/internal/config"
 	"backend/internal/database"
+	"backend/internal/item"
 
+	"golang.org/x/crypto/bcrypt"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
@@ -17,7 +23,7 @@ func setupTestDB(t *testing.T) *gorm.DB {
 	}
 
 	// Auto migrate all models
-	err = db.AutoMigrate(&database.Organization{}, &database.User{}, &database.Item{}, &database.Tag{}, &database.ResetToken{}, &database.BackPackIdNextNumber{})
+	err = db.AutoMigrate(&database.Organization{}, &database.User{}, &database.OrganizationUser{}, &database.Item{}, &database.Tag{}, &database.ResetToken{}, &database.BackPackIdNextNumber{}, &database.RefreshToken{})
 	if err != nil {
 		t.Fatalf("Failed to migrate test database: %v", err)
 	}
@@ -25,9 +31,17 @@ func setupTestDB(t *testing.T) *gorm.DB {
 	return db
 }
 
+func createTestConfig(bcryptCost int) *config.Config {
+	return &config.Config{
+		Security: config.SecurityConfig{
+			BcryptCost: bcryptCost,
+		},
+	}
+}
+
 func TestNewUserService(t *testing.T) {
 	db := setupTestDB(t)
-	service := NewUserService(db)
+	service := NewUserService(db, createTestConfig(bcrypt.MinCost))
 
 	if service.db == nil {
 		t.Error("User service database should not be nil")
@@ -36,7 +50,7 @@ func TestNewUserService(t *testing.T) {
 
 func TestCreateUser_Success(t *testing.T) {
 	db := setupTestDB(t)
-	service := NewUserService(db)
+	service := NewUserService(db, createTestConfig(bcrypt.MinCost))
 
 	email := "test@example.com"
 	password := "password123"
@@ -56,14 +70,36 @@ func TestCreateUser_Success(t *testing.T) {
 	if user.Email != email {
 		t.Errorf("Expected email '%s', got '%s'", email, user.Email)
 	}
-	if user.Password != password {
-		t.Errorf("Expected password '%s', got '%s'", password, user.Password)
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		t.Errorf("Expected stored password to be a hash of '%s': %v", password, err)
+	}
+}
+
+func TestUser_PasswordNotSerialized(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewUserService(db, createTestConfig(bcrypt.MinCost))
+
+	if err := service.CreateUser("test@example.com", "password123"); err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+
+	var user database.User
+	if err := db.First(&user, "email = ?", "test@example.com").Error; err != nil {
+		t.Fatalf("Failed to find created user: %v", err)
+	}
+
+	data, err := json.Marshal(user)
+	if err != nil {
+		t.Fatalf("Failed to marshal user: %v", err)
+	}
+	if strings.Contains(string(data), "password") || strings.Contains(string(data), user.Password) {
+		t.Errorf("Expected password to be omitted from JSON, got %s", data)
 	}
 }
 
 func TestCreateUser_DuplicateUser(t *testing.T) {
 	db := setupTestDB(t)
-	service := NewUserService(db)
+	service := NewUserService(db, createTestConfig(bcrypt.MinCost))
 
 	email := "test@example.com"
 	password := "password123"
@@ -76,24 +112,14 @@ func TestCreateUser_DuplicateUser(t *testing.T) {
 
 	// Try to create same user again
 	err = service.CreateUser(email, password)
-	if err == nil {
-		t.Error("Should return error for duplicate user")
-	}
-	// SQLite returns a different error message, so we check for the error type
-	if err != nil && !errors.Is(err, ErrUserAlreadyExists) {
-		// Check if it's a constraint violation error
-		if !errors.Is(err, gorm.ErrDuplicatedKey) {
-			// SQLite returns a different error message, so we check the error string
-			if err.Error() != "UNIQUE constraint failed: users.email" {
-				t.Errorf("Expected duplicate user error, got %v", err)
-			}
-		}
+	if !errors.Is(err, ErrUserAlreadyExists) {
+		t.Errorf("Expected ErrUserAlreadyExists, got %v", err)
 	}
 }
 
 func TestValidateUser_Success(t *testing.T) {
 	db := setupTestDB(t)
-	service := NewUserService(db)
+	service := NewUserService(db, createTestConfig(bcrypt.MinCost))
 
 	email := "test@example.com"
 	password := "password123"
@@ -113,7 +139,7 @@ func TestValidateUser_Success(t *testing.T) {
 
 func TestValidateUser_UserNotFound(t *testing.T) {
 	db := setupTestDB(t)
-	service := NewUserService(db)
+	service := NewUserService(db, createTestConfig(bcrypt.MinCost))
 
 	email := "nonexistent@example.com"
 	password := "password123"
@@ -129,7 +155,7 @@ func TestValidateUser_UserNotFound(t *testing.T) {
 
 func TestValidateUser_WrongPassword(t *testing.T) {
 	db := setupTestDB(t)
-	service := NewUserService(db)
+	service := NewUserService(db, createTestConfig(bcrypt.MinCost))
 
 	email := "test@example.com"
 	password := "password123"
@@ -151,9 +177,39 @@ func TestValidateUser_WrongPassword(t *testing.T) {
 	}
 }
 
+func TestValidateUser_RehashesPlaintextPassword(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewUserService(db, createTestConfig(bcrypt.MinCost))
+
+	email := "legacy@example.com"
+	if err := service.CreateUser(email, "password123"); err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+	// Simulate a password stored before hashing was introduced
+	db.Model(&database.User{}).Where("email = ?", email).Update("password", "plaintext-secret")
+
+	if err := service.ValidateUser(email, "wrongpassword"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Expected ErrInvalidCredentials, got %v", err)
+	}
+
+	if err := service.ValidateUser(email, "plaintext-secret"); err != nil {
+		t.Fatalf("Failed to validate user with plaintext password: %v", err)
+	}
+
+	var stored database.User
+	db.First(&stored, "email = ?", email)
+	if err := bcrypt.CompareHashAndPassword([]byte(stored.Password), []byte("plaintext-secret")); err != nil {
+		t.Errorf("Expected the password to be rehashed, got %q", stored.Password)
+	}
+
+	if err := service.ValidateUser(email, "plaintext-secret"); err != nil {
+		t.Errorf("Failed to validate user after rehash: %v", err)
+	}
+}
+
 func TestGetUser_Success(t *testing.T) {
 	db := setupTestDB(t)
-	service := NewUserService(db)
+	service := NewUserService(db, createTestConfig(bcrypt.MinCost))
 
 	email := "test@example.com"
 	password := "password123"
@@ -173,14 +229,14 @@ func TestGetUser_Success(t *testing.T) {
 	if user.Email != email {
 		t.Errorf("Expected email '%s', got '%s'", email, user.Email)
 	}
-	if user.Password != password {
-		t.Errorf("Expected password '%s', got '%s'", password, user.Password)
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		t.Errorf("Expected stored password to be a hash of '%s': %v", password, err)
 	}
 }
 
 func TestGetUser_UserNotFound(t *testing.T) {
 	db := setupTestDB(t)
-	service := NewUserService(db)
+	service := NewUserService(db, createTestConfig(bcrypt.MinCost))
 
 	email := "nonexistent@example.com"
 
@@ -198,7 +254,7 @@ func TestGetUser_UserNotFound(t *testing.T) {
 
 func TestCreateUser_EmptyEmail(t *testing.T) {
 	db := setupTestDB(t)
-	service := NewUserService(db)
+	service := NewUserService(db, createTestConfig(bcrypt.MinCost))
 
 	email := ""
 	password := "password123"
@@ -208,3 +264,189 @@ func TestCreateUser_EmptyEmail(t *testing.T) {
 		t.Error("Should fail with empty email")
 	}
 }
+
+func TestCreateUser_UsesConfiguredBcryptCost(t *testing.T) {
+	db := setupTestDB(t)
+	cost := bcrypt.MinCost + 1
+	service := NewUserService(db, createTestConfig(cost))
+
+	if err := service.CreateUser("test@example.com", "password123"); err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+
+	var user database.User
+	if err := db.First(&user, "email = ?", "test@example.com").Error; err != nil {
+		t.Fatalf("Failed to find created user: %v", err)
+	}
+
+	hashCost, err := bcrypt.Cost([]byte(user.Password))
+	if err != nil {
+		t.Fatalf("Stored password is not a bcrypt hash: %v", err)
+	}
+	if hashCost != cost {
+		t.Errorf("Expected bcrypt cost %d, got %d", cost, hashCost)
+	}
+}
+
+func TestPrefixFromEmail(t *testing.T) {
+	tests := []struct {
+		email    string
+		expected string
+	}{
+		{"test@example.com", "TES"},
+		{"a.b-c@example.com", "ABC"},
+		{"j2doe@example.com", "JDO"},
+	}
+
+	for _, tt := range tests {
+		if prefix := prefixFromEmail(tt.email); prefix != tt.expected {
+			t.Errorf("Expected prefix '%s' for %s, got '%s'", tt.expected, tt.email, prefix)
+		}
+	}
+
+	// Too few letters before the @ falls back to a random valid prefix
+	if prefix := prefixFromEmail("ab@example.com"); item.ValidatePrefix(prefix) != nil {
+		t.Errorf("Expected a valid fallback prefix, got '%s'", prefix)
+	}
+}
+
+func TestValidatePassword(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewUserService(db, &config.Config{Security: config.SecurityConfig{MinPasswordLength: 10}})
+
+	if err := service.ValidatePassword("123456789"); !errors.Is(err, ErrPasswordTooShort) {
+		t.Errorf("Expected ErrPasswordTooShort, got %v", err)
+	}
+	if err := service.ValidatePassword("1234567890"); err != nil {
+		t.Errorf("Expected password to be accepted, got %v", err)
+	}
+	if err := service.CreateUser("short@example.com", "123456789"); !errors.Is(err, ErrPasswordTooShort) {
+		t.Errorf("Expected CreateUser to reject a short password, got %v", err)
+	}
+
+	// Unset config falls back to the default minimum
+	service = NewUserService(db, &config.Config{})
+	if service.MinPasswordLength() != config.DefaultMinPasswordLength {
+		t.Errorf("Expected default minimum %d, got %d", config.DefaultMinPasswordLength, service.MinPasswordLength())
+	}
+}
+
+func TestCreateUser_Timestamps(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewUserService(db, createTestConfig(bcrypt.MinCost))
+
+	if err := service.CreateUser("test@example.com", "password123"); err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+	created, err := service.GetUser("test@example.com")
+	if err != nil {
+		t.Fatalf("Failed to get user: %v", err)
+	}
+	if created.CreatedAt.IsZero() || created.UpdatedAt.IsZero() {
+		t.Fatalf("Expected timestamps to be set, got created_at=%v updated_at=%v", created.CreatedAt, created.UpdatedAt)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	if err := service.UpdatePassword("test@example.com", "newpassword123"); err != nil {
+		t.Fatalf("Failed to update password: %v", err)
+	}
+	updated, _ := service.GetUser("test@example.com")
+	if !updated.UpdatedAt.After(created.UpdatedAt) {
+		t.Errorf("Expected updated_at to advance past %v, got %v", created.UpdatedAt, updated.UpdatedAt)
+	}
+	if !updated.CreatedAt.Equal(created.CreatedAt) {
+		t.Errorf("Expected created_at to stay %v, got %v", created.CreatedAt, updated.CreatedAt)
+	}
+}
+
+func TestSetActive(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewUserService(db, createTestConfig(bcrypt.MinCost))
+
+	email := "test@example.com"
+	if err := service.CreateUser(email, "password123"); err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+
+	created, err := service.GetUser(email)
+	if err != nil {
+		t.Fatalf("Failed to get user: %v", err)
+	}
+	if !created.Active {
+		t.Error("New users should be active")
+	}
+
+	if err := service.SetActive(email, false); err != nil {
+		t.Fatalf("Failed to deactivate user: %v", err)
+	}
+
+	// The flag persists and blocks valid credentials, but not invalid ones
+	deactivated, err := service.GetUser(email)
+	if err != nil {
+		t.Fatalf("Failed to get user: %v", err)
+	}
+	if deactivated.Active {
+		t.Error("Deactivated user should not be active")
+	}
+	if err := service.ValidateUser(email, "password123"); !errors.Is(err, ErrUserInactive) {
+		t.Errorf("Expected ErrUserInactive, got %v", err)
+	}
+	if err := service.ValidateUser(email, "wrongpassword"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Expected ErrInvalidCredentials, got %v", err)
+	}
+
+	if err := service.SetActive(email, true); err != nil {
+		t.Fatalf("Failed to reactivate user: %v", err)
+	}
+	if err := service.ValidateUser(email, "password123"); err != nil {
+		t.Errorf("Reactivated user should validate, got %v", err)
+	}
+
+	if err := service.SetActive("nobody@example.com", false); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("Expected ErrUserNotFound, got %v", err)
+	}
+}
+
+func TestListUsers(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewUserService(db, createTestConfig(bcrypt.MinCost))
+
+	for _, email := range []string{"carol@example.com", "alice@example.com", "bob@example.org"} {
+		if err := service.CreateUser(email, "password123"); err != nil {
+			t.Fatalf("Failed to create user: %v", err)
+		}
+	}
+
+	users, total, err := service.ListUsers("", 1, 1)
+	if err != nil {
+		t.Fatalf("Failed to list users: %v", err)
+	}
+	if total != 3 {
+		t.Errorf("Expected 3 users in total, got %d", total)
+	}
+	if len(users) != 1 || users[0].Email != "bob@example.org" {
+		t.Errorf("Expected the second user by email, got %v", users)
+	}
+
+	users, total, err = service.ListUsers("Example.COM", 0, 10)
+	if err != nil {
+		t.Fatalf("Failed to list users: %v", err)
+	}
+	if total != 2 || len(users) != 2 {
+		t.Errorf("Expected 2 filtered users, got %d of %d", len(users), total)
+	}
+
+	// Wildcards in the filter only match themselves
+	if err := service.CreateUser("dave_smith@example.com", "password123"); err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+	for filter, want := range map[string]int64{"e_": 1, "%": 0} {
+		users, total, err = service.ListUsers(filter, 0, 10)
+		if err != nil {
+			t.Fatalf("Failed to list users: %v", err)
+		}
+		if total != want || int64(len(users)) != want {
+			t.Errorf("Expected %d users matching %q, got %d of %d", want, filter, len(users), total)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"backend/internal/config"
+
+	"go.uber.org/fx"
+)
+
+// Module provides webhook dependency injection
+var Module = fx.Module("webhook",
+	fx.Provide(NewDispatcher),
+)
+
+// SignatureHeader is the header carrying the HMAC-SHA256 signature of the body
+const SignatureHeader = "X-Webhook-Signature"
+
+const (
+	// EventItemCreated is sent when an item is created
+	EventItemCreated = "item.created"
+	// EventItemUpdated is sent when an item is updated
+	EventItemUpdated = "item.updated"
+	// EventItemDeleted is sent when an item is deleted
+	EventItemDeleted = "item.deleted"
+)
+
+// Event represents a webhook payload
+type Event struct {
+	Type      string      `json:"event"`
+	Timestamp time.Time   `json:"timestamp"`
+	Data      interface{} `json:"data"`
+}
+
+// Dispatcher delivers webhook events asynchronously with retries
+type Dispatcher struct {
+	url        string
+	secret     string
+	maxRetries int
+	backoff    time.Duration
+	client     *http.Client
+	logger     *slog.Logger
+	wg         sync.WaitGroup
+}
+
+// NewDispatcher creates a new webhook dispatcher
+func NewDispatcher(lc fx.Lifecycle, cfg *config.Config, logger *slog.Logger) *Dispatcher {
+	d := newDispatcher(cfg.Webhook, logger)
+
+	lc.Append(fx.Hook{
+		OnStop: d.Wait,
+	})
+
+	return d
+}
+
+// newDispatcher creates a dispatcher for the given configuration
+func newDispatcher(cfg config.WebhookConfig, logger *slog.Logger) *Dispatcher {
+	return &Dispatcher{
+		url:        cfg.ItemURL,
+		secret:     cfg.Secret,
+		maxRetries: cfg.MaxRetries,
+		backoff:    time.Second,
+		client:     &http.Client{Timeout: cfg.Timeout},
+		logger:     logger,
+	}
+}
+
+// Enabled reports whether a webhook URL is configured
+func (d *Dispatcher) Enabled() bool {
+	return d.url != ""
+}
+
+// Send delivers an event in the background; failures are logged and never returned
+func (d *Dispatcher) Send(eventType string, data interface{}) {
+	if !d.Enabled() {
+		return
+	}
+
+	body, err := json.Marshal(Event{
+		Type:      eventType,
+		Timestamp: time.Now().UTC(),
+		Data:      data,
+	})
+	if err != nil {
+		d.logger.Error("failed to encode webhook event", "event", eventType, "error", err)
+		return
+	}
+
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		if err := d.deliver(body); err != nil {
+			d.logger.Error("failed to deliver webhook event", "event", eventType, "error", err)
+		}
+	}()
+}
+
+// Wait blocks until all pending deliveries have finished or ctx is done, in which
+// case the remaining deliveries are abandoned and ctx's error is returned
+func (d *Dispatcher) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// deliver posts the body, retrying with exponential backoff
+func (d *Dispatcher) deliver(body []byte) error {
+	var err error
+	backoff := d.backoff
+	for attempt := 0; attempt <= d.maxRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+		if err = d.post(body); err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// post sends a single signed request
+func (d *Dispatcher) post(body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, d.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, "sha256="+Sign(d.secret, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Sign returns the hex-encoded HMAC-SHA256 of body using secret
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
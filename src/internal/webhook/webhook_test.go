@@ -0,0 +1,130 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/logger"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/fx/fxtest"
+)
+
+// recorder is a test webhook receiver
+type recorder struct {
+	mu       sync.Mutex
+	failures int
+	attempts int
+	bodies   [][]byte
+	headers  []http.Header
+}
+
+func (r *recorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.attempts++
+	if r.attempts <= r.failures {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	body, _ := io.ReadAll(req.Body)
+	r.bodies = append(r.bodies, body)
+	r.headers = append(r.headers, req.Header.Clone())
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func setupDispatcher(t *testing.T, url string) *Dispatcher {
+	d := newDispatcher(config.WebhookConfig{
+		ItemURL:    url,
+		Secret:     "shared-secret",
+		MaxRetries: 2,
+		Timeout:    time.Second,
+	}, logger.Discard())
+	d.backoff = time.Millisecond
+	return d
+}
+
+func TestSend_DeliversSignedPayload(t *testing.T) {
+	rec := &recorder{}
+	srv := httptest.NewServer(rec)
+	defer srv.Close()
+
+	d := setupDispatcher(t, srv.URL)
+	d.Send(EventItemCreated, map[string]string{"name": "Tent"})
+	assert.NoError(t, d.Wait(context.Background()))
+
+	if assert.Len(t, rec.bodies, 1) {
+		body := rec.bodies[0]
+		assert.Equal(t, "sha256="+Sign("shared-secret", body), rec.headers[0].Get(SignatureHeader))
+		assert.Equal(t, "application/json", rec.headers[0].Get("Content-Type"))
+
+		var event struct {
+			Type string            `json:"event"`
+			Data map[string]string `json:"data"`
+		}
+		assert.NoError(t, json.Unmarshal(body, &event))
+		assert.Equal(t, EventItemCreated, event.Type)
+		assert.Equal(t, "Tent", event.Data["name"])
+	}
+}
+
+func TestSend_RetriesOnFailure(t *testing.T) {
+	rec := &recorder{failures: 2}
+	srv := httptest.NewServer(rec)
+	defer srv.Close()
+
+	d := setupDispatcher(t, srv.URL)
+	d.Send(EventItemUpdated, map[string]string{"name": "Tent"})
+	assert.NoError(t, d.Wait(context.Background()))
+
+	assert.Equal(t, 3, rec.attempts)
+	assert.Len(t, rec.bodies, 1)
+}
+
+func TestSend_GivesUpAfterMaxRetries(t *testing.T) {
+	rec := &recorder{failures: 10}
+	srv := httptest.NewServer(rec)
+	defer srv.Close()
+
+	d := setupDispatcher(t, srv.URL)
+	d.Send(EventItemDeleted, map[string]int{"id": 1})
+	assert.NoError(t, d.Wait(context.Background()))
+
+	assert.Equal(t, 3, rec.attempts)
+	assert.Empty(t, rec.bodies)
+}
+
+func TestWait_BoundedByContext(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	d := setupDispatcher(t, srv.URL)
+	d.Send(EventItemCreated, map[string]int{"id": 1})
+
+	// A stuck delivery can't hold up shutdown past the stop deadline
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, d.Wait(ctx), context.DeadlineExceeded)
+}
+
+func TestSend_DisabledWithoutURL(t *testing.T) {
+	d := NewDispatcher(fxtest.NewLifecycle(t), &config.Config{}, logger.Discard())
+
+	assert.False(t, d.Enabled())
+	d.Send(EventItemCreated, nil)
+	assert.NoError(t, d.Wait(context.Background()))
+}
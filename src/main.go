@@ -3,52 +3,110 @@ package main
 import (
 	"context"
 	"log"
+	"log/slog"
+	"os"
 
 	"backend/internal/config"
 	"backend/internal/database"
 	"backend/internal/handlers"
+	"backend/internal/item"
 	"backend/internal/jwt"
+	"backend/internal/logger"
 	"backend/internal/middleware"
+	"backend/internal/migrations"
+	"backend/internal/notify"
+	"backend/internal/organization"
+	"backend/internal/reset"
 	"backend/internal/server"
+	"backend/internal/tag"
 	"backend/internal/user"
+	"backend/internal/webhook"
 
 	"go.uber.org/fx"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(os.Args[2:]); err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		return
+	}
+
 	log.Println("Starting SchwiftyBox application!!...")
 
 	app := fx.New(
 		// Provide configuration
-		fx.Provide(config.NewConfig),
+		fx.Provide(config.LoadConfig),
+		logger.Module,
+		// Route the standard library logger through the structured logger too
+		fx.Invoke(func(l *slog.Logger) {
+			slog.SetDefault(l)
+		}),
+		fx.Invoke(func(cfg *config.Config, l *slog.Logger) error {
+			cfg.LogEffective(l)
+			return cfg.Validate(l)
+		}),
 
 		// Include all modules
 		database.Module,
+		migrations.Module,
 		jwt.Module,
 		user.Module,
+		item.Module,
+		tag.Module,
+		organization.Module,
+		reset.Module,
+		notify.Module,
+		webhook.Module,
 		handlers.Module,
 		middleware.Module,
 		server.Module,
 
 		// Add lifecycle hooks
-		fx.Invoke(func(lc fx.Lifecycle) {
+		fx.Invoke(func(lc fx.Lifecycle, l *slog.Logger) {
 			lc.Append(fx.Hook{
 				OnStart: func(ctx context.Context) error {
-					log.Println("Application started successfully")
+					l.Info("application started")
 					return nil
 				},
 				OnStop: func(ctx context.Context) error {
-					log.Println("Application stopped")
+					l.Info("application stopped")
 					return nil
 				},
 			})
 		}),
 
 		// Ensure server is started
-		fx.Invoke(func(s *server.Server) {
-			log.Println("Server dependency injected and will be started")
+		fx.Invoke(func(s *server.Server, l *slog.Logger) {
+			l.Debug("server dependency injected and will be started")
 		}),
 	)
 
 	app.Run()
 }
+
+// runMigrate runs "migrate up|down|version|force <version>" with only the configuration,
+// database and migrations wired up, so no HTTP server is started
+func runMigrate(args []string) error {
+	cmd, err := migrations.ParseCommand(args)
+	if err != nil {
+		return err
+	}
+
+	app := fx.New(
+		fx.NopLogger,
+		fx.Provide(config.LoadConfig),
+		// Logs go to stderr so stdout carries only the command's output
+		fx.Provide(func(cfg *config.Config) *slog.Logger {
+			return logger.New(os.Stderr, cfg.Log.Level, cfg.Env == config.EnvProduction)
+		}),
+		database.Module,
+		fx.Provide(migrations.NewMigrationService),
+		fx.Invoke(func(service *migrations.Service) error {
+			defer service.Close()
+			return migrations.Run(service, cmd, os.Stdout)
+		}),
+	)
+	return app.Err()
+}